@@ -1,16 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/LeonLow97/go-clean-architecture/delivery/http/app"
+	"github.com/LeonLow97/go-clean-architecture/delivery/scheduler"
 	"github.com/LeonLow97/go-clean-architecture/infrastructure"
 	logger "github.com/LeonLow97/go-clean-architecture/infrastructure/logger"
 	"github.com/LeonLow97/go-clean-architecture/repository"
 	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
 	"github.com/LeonLow97/go-clean-architecture/utils/constants/headers"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/rs/cors"
 )
 
@@ -51,29 +55,77 @@ func main() {
 	totpInstance := infrastructure.NewTOTPMultiFactor(cfg)
 
 	// Initiating handlers, service, and repository
+	// the currency catalogue rarely changes, so it is kept in redis in front of the database
+	currencyRepo := repository.NewCachedCurrencyRepository(redisClient, repository.NewCurrencyRepository(dbConn), cfg.Currency.CacheTTL)
+	currencyUsecase := usecase.NewCurrencyUsecase(currencyRepo)
+
+	// amounts are rounded with the minor units configured in the catalogue
+	currencies, err := currencyRepo.GetCurrencies(context.Background())
+	if err != nil {
+		log.Fatalln("error loading currency catalogue", err)
+	}
+	for _, currency := range currencies {
+		money.SetExponent(currency.Code, currency.Exponent)
+	}
+
 	userRepo := repository.NewUserRepository(dbConn)
-	userUsecase := usecase.NewUserUsecase(*cfg, userRepo, redisClient, *smtpClient, totpInstance)
+	userUsecase := usecase.NewUserUsecase(*cfg, userRepo, redisClient, *smtpClient, totpInstance, currencyRepo)
+
+	txManager := usecase.NewTxManager(dbConn)
+	ledgerRepo := repository.NewLedgerRepository(dbConn)
+
+	// rates are read from the database and kept in redis so that a rate change does not need a redeploy
+	exchangeRateRepo := repository.NewExchangeRateRepository(dbConn)
+	exchangeRateProvider := repository.NewCachedExchangeRateProvider(redisClient, exchangeRateRepo, cfg.ExchangeRate.CacheTTL)
+	exchangeRateUsecase := usecase.NewExchangeRateUsecase(exchangeRateRepo)
+	exchangeQuoteRepo := repository.NewExchangeQuoteRepository(redisClient)
+	fxConversionRepo := repository.NewFXConversionRepository(dbConn)
+
+	// fees are priced with the fee schedule in effect, admins publish new versions of it
+	feeRepo := repository.NewFeeRepository(dbConn)
+	feeUsecase := usecase.NewFeeUsecase(txManager, userRepo, feeRepo, currencyRepo)
 
 	balanceRepo := repository.NewBalanceRepository(dbConn)
-	balanceUsecase := usecase.NewBalanceUsecase(dbConn, userRepo, balanceRepo)
+	balanceUsecase := usecase.NewBalanceUsecase(*cfg, txManager, userRepo, balanceRepo, ledgerRepo, exchangeRateProvider, exchangeQuoteRepo, currencyRepo, fxConversionRepo, feeRepo)
 
 	beneficiaryRepo := repository.NewBeneficiaryRepository(dbConn)
 	beneficiaryUsecase := usecase.NewBeneficiaryUsecase(beneficiaryRepo)
 
 	walletRepo := repository.NewWalletRepository(dbConn)
-	walletUsecase := usecase.NewWalletUsecase(dbConn, walletRepo, balanceRepo)
+	walletUsecase := usecase.NewWalletUsecase(*cfg, txManager, walletRepo, balanceRepo, ledgerRepo, exchangeRateProvider, currencyRepo, fxConversionRepo, feeRepo)
 
 	transactionRepo := repository.NewTransactionRepository(dbConn)
-	transactionUsecase := usecase.NewTransactionUsecase(dbConn, transactionRepo, walletRepo, balanceRepo, userRepo)
+	transactionUsecase := usecase.NewTransactionUsecase(*cfg, txManager, transactionRepo, walletRepo, balanceRepo, userRepo, ledgerRepo, exchangeRateProvider, exchangeQuoteRepo, currencyRepo, fxConversionRepo, feeRepo)
+
+	// auto-save rules top up wallets through the wallet usecase, run by the scheduler in the background
+	autoSaveRepo := repository.NewAutoSaveRepository(dbConn)
+	autoSaveUsecase := usecase.NewAutoSaveUsecase(*cfg, txManager, autoSaveRepo, walletRepo, transactionRepo, walletUsecase, currencyRepo)
+
+	schedulerInterval := cfg.Scheduler.Interval
+	if schedulerInterval <= 0 {
+		schedulerInterval = constants.DEFAULT_SCHEDULER_INTERVAL
+	}
+	jobScheduler := scheduler.New(schedulerInterval,
+		scheduler.Job{Name: "auto-save", Run: autoSaveUsecase.RunDueAutoSaveRules},
+		scheduler.Job{Name: "scheduled-transfers", Run: transactionUsecase.RunDueScheduledTransfers},
+		scheduler.Job{Name: "scheduled-transfer-recovery", Run: transactionUsecase.RecoverScheduledTransferRuns},
+		scheduler.Job{Name: "payment-request-recovery", Run: transactionUsecase.RecoverPaymentRequests},
+		scheduler.Job{Name: "payment-request-expiry", Run: transactionUsecase.ExpirePaymentRequests},
+	)
+	go jobScheduler.Start(context.Background())
 
 	application := &app.Application{
-		Cfg:                cfg,
-		RedisClient:        redisClient,
-		UserUsecase:        userUsecase,
-		BalanceUsecase:     balanceUsecase,
-		BeneficiaryUsecase: beneficiaryUsecase,
-		WalletUsecase:      walletUsecase,
-		TransactionUsecase: transactionUsecase,
+		Cfg:                 cfg,
+		RedisClient:         redisClient,
+		UserUsecase:         userUsecase,
+		BalanceUsecase:      balanceUsecase,
+		BeneficiaryUsecase:  beneficiaryUsecase,
+		WalletUsecase:       walletUsecase,
+		TransactionUsecase:  transactionUsecase,
+		CurrencyUsecase:     currencyUsecase,
+		ExchangeRateUsecase: exchangeRateUsecase,
+		FeeUsecase:          feeUsecase,
+		AutoSaveUsecase:     autoSaveUsecase,
 	}
 
 	apiRouter, err := application.CreateRouter()
@@ -100,6 +152,7 @@ func main() {
 			headers.Accept,
 			headers.CacheControl,
 			headers.ContentType,
+			headers.IdempotencyKey,
 			headers.Origin,
 			headers.XCSRFToken,
 		},
@@ -108,6 +161,7 @@ func main() {
 			headers.CacheControl,
 			headers.ContentType,
 			headers.ContentSecurityPolicy,
+			headers.IdempotentReplayed,
 			headers.Pragma,
 			headers.ReferrerPolicy,
 			headers.StrictTransportSecurity,
@@ -116,6 +170,7 @@ func main() {
 			headers.XFrameOptions,
 			headers.XHasNextPage,
 			headers.XHasPreviousPage,
+			headers.XNextCursor,
 			headers.XPage,
 			headers.XPageSize,
 			headers.XTotal,
@@ -0,0 +1,80 @@
+-- add_double_entry_ledger.sql
+-- Every money movement is recorded as a journal entry made of immutable postings.
+-- balances and wallet_balances are kept as a cache of the user accounts in the ledger.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS ledger_journal_entries (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id SERIAL PRIMARY KEY,
+    entry_id INT NOT NULL REFERENCES ledger_journal_entries(id),
+    account VARCHAR(255) NOT NULL,
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('DEBIT', 'CREDIT')),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON ledger_postings(entry_id);
+CREATE INDEX ON ledger_postings(account, currency);
+
+-- postings are append only
+CREATE OR REPLACE FUNCTION ledger_postings_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger postings are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_postings_no_update_delete
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_postings_immutable();
+
+-- every journal entry must sum to zero per currency, checked at commit so that
+-- the postings of one entry can be inserted one at a time
+CREATE OR REPLACE FUNCTION ledger_entry_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM ledger_postings
+        WHERE entry_id = NEW.entry_id
+        GROUP BY currency
+        HAVING SUM(CASE WHEN direction = 'DEBIT' THEN amount ELSE -amount END) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % does not sum to zero per currency', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_entry_balanced();
+
+-- open the ledger with the balances that exist today
+INSERT INTO ledger_journal_entries (type, reference) VALUES ('opening_balance', 'migration');
+
+INSERT INTO ledger_postings (entry_id, account, direction, currency, amount)
+SELECT currval('ledger_journal_entries_id_seq'), 'user:' || user_id || ':balance', 'CREDIT', currency, balance
+FROM balances
+WHERE balance > 0
+UNION ALL
+SELECT currval('ledger_journal_entries_id_seq'), 'user:' || user_id || ':wallet:' || wallet_id, 'CREDIT', currency, amount
+FROM wallet_balances
+WHERE amount > 0
+UNION ALL
+SELECT currval('ledger_journal_entries_id_seq'), 'system:external', 'DEBIT', currency, SUM(total)
+FROM (
+    SELECT currency, balance AS total FROM balances WHERE balance > 0
+    UNION ALL
+    SELECT currency, amount AS total FROM wallet_balances WHERE amount > 0
+) opening
+GROUP BY currency;
+
+COMMIT;
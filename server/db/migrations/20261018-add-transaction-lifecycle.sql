@@ -0,0 +1,19 @@
+-- add_transaction_lifecycle.sql
+-- Transactions move CREATED -> PENDING -> SUCCESS, CREATED/PENDING -> FAILED and SUCCESS -> REVERSED.
+-- An attempt is recorded before the beneficiary and destination are known, and a failed
+-- attempt keeps the reason code it failed with.
+
+BEGIN;
+
+ALTER TABLE transactions
+ALTER COLUMN beneficiary_id DROP NOT NULL,
+ALTER COLUMN destination_currency DROP NOT NULL;
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS failure_reason VARCHAR(64),
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+ALTER TABLE transactions
+ADD CONSTRAINT transactions_status_check CHECK (status IN ('CREATED', 'PENDING', 'SUCCESS', 'FAILED', 'REVERSED'));
+
+COMMIT;
@@ -0,0 +1,32 @@
+-- add_transaction_refunds.sql
+-- A sender can ask for some or all of a transfer back. The beneficiary has to approve the
+-- request before funds move, and the reversal is recorded as a transaction linked to the original.
+
+BEGIN;
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS sender_wallet_id INT REFERENCES wallets(id),
+ADD COLUMN IF NOT EXISTS original_transaction_id INT REFERENCES transactions(id);
+
+CREATE TABLE IF NOT EXISTS transaction_refunds (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id),
+    reversal_transaction_id INT REFERENCES transactions(id),
+    sender_id INT NOT NULL REFERENCES users(id),
+    beneficiary_id INT NOT NULL REFERENCES users(id),
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    refund_amount NUMERIC(20,2) NOT NULL DEFAULT 0,
+    refund_currency CHAR(3) NOT NULL,
+    rate_type VARCHAR(10) NOT NULL CHECK (rate_type IN ('original', 'current')),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('REQUESTED', 'COMPLETED', 'DECLINED')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_refunds_transaction_id ON transaction_refunds (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_refunds_sender_id ON transaction_refunds (sender_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_refunds_beneficiary_id ON transaction_refunds (beneficiary_id);
+
+COMMIT;
@@ -0,0 +1,42 @@
+-- add_transaction_reference.sql
+-- Every transfer gets a public ULID reference that is shared by the sender's and the beneficiary's
+-- copy of the transaction, along with the exchange rate and profit it was priced with.
+
+BEGIN;
+
+ALTER TABLE transactions
+ADD COLUMN IF NOT EXISTS reference CHAR(26),
+ADD COLUMN IF NOT EXISTS fx_rate NUMERIC(20,8),
+ADD COLUMN IF NOT EXISTS profit NUMERIC(20,2) NOT NULL DEFAULT 0;
+
+-- generates a ULID for the time a transaction was created, only lives as long as this session
+CREATE FUNCTION pg_temp.generate_ulid(created TIMESTAMP WITH TIME ZONE) RETURNS CHAR(26) AS $$
+DECLARE
+    alphabet CONSTANT TEXT := '0123456789ABCDEFGHJKMNPQRSTVWXYZ';
+    ms BIGINT := FLOOR(EXTRACT(EPOCH FROM created) * 1000);
+    result TEXT := '';
+BEGIN
+    FOR i IN 1..10 LOOP
+        result := SUBSTR(alphabet, (ms % 32)::INT + 1, 1) || result;
+        ms := ms / 32;
+    END LOOP;
+    FOR i IN 1..16 LOOP
+        result := result || SUBSTR(alphabet, FLOOR(RANDOM() * 32)::INT + 1, 1);
+    END LOOP;
+    RETURN result;
+END;
+$$ LANGUAGE plpgsql;
+
+-- the two copies of an existing transfer were never linked and cannot be paired reliably,
+-- so each existing row gets a reference of its own
+UPDATE transactions
+SET reference = pg_temp.generate_ulid(created_at)
+WHERE reference IS NULL;
+
+ALTER TABLE transactions
+ALTER COLUMN reference SET NOT NULL;
+
+-- a reference is shared by the copies of a transfer, but a user only ever holds one of them
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_user_id_reference ON transactions (user_id, reference);
+
+COMMIT;
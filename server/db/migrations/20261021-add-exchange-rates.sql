@@ -0,0 +1,38 @@
+-- add_exchange_rates.sql
+-- Exchange rates and spreads move out of the code into a table so that they can be changed
+-- without a redeploy. A pair can have several rates, the latest one already in effect applies.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20,8) NOT NULL CHECK (rate > 0),
+    spread NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (spread >= 0),
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (from_currency, to_currency, effective_at),
+    CHECK (from_currency <> to_currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_effective_at ON exchange_rates (from_currency, to_currency, effective_at DESC);
+
+-- the rates that were hardcoded until now
+INSERT INTO exchange_rates (from_currency, to_currency, rate, spread, effective_at)
+VALUES
+('SGD', 'USD', 0.76, 0.005, '2024-01-01 00:00:00+00'),
+('SGD', 'AUD', 1.03, 0.004, '2024-01-01 00:00:00+00'),
+('SGD', 'MYR', 3.29, 0.007, '2024-01-01 00:00:00+00'),
+('USD', 'SGD', 1.32, 0.006, '2024-01-01 00:00:00+00'),
+('USD', 'AUD', 1.41, 0.005, '2024-01-01 00:00:00+00'),
+('USD', 'MYR', 4.43, 0.008, '2024-01-01 00:00:00+00'),
+('AUD', 'SGD', 0.97, 0.003, '2024-01-01 00:00:00+00'),
+('AUD', 'USD', 0.71, 0.004, '2024-01-01 00:00:00+00'),
+('AUD', 'MYR', 3.35, 0.006, '2024-01-01 00:00:00+00'),
+('MYR', 'SGD', 0.30, 0.008, '2024-01-01 00:00:00+00'),
+('MYR', 'USD', 0.22, 0.007, '2024-01-01 00:00:00+00'),
+('MYR', 'AUD', 0.30, 0.009, '2024-01-01 00:00:00+00')
+ON CONFLICT (from_currency, to_currency, effective_at) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,41 @@
+-- add_currency_catalogue.sql
+-- The supported currencies and the home currency of each mobile country code move out of the code
+-- into tables so that a currency can be added or disabled without a redeploy.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS currencies (
+    code CHAR(3) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    exponent SMALLINT NOT NULL DEFAULT 2 CHECK (exponent BETWEEN 0 AND 4),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS country_currencies (
+    mobile_country_code VARCHAR(5) PRIMARY KEY,
+    currency CHAR(3) NOT NULL REFERENCES currencies(code),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- the currencies that were hardcoded until now
+INSERT INTO currencies (code, name, symbol, exponent)
+VALUES
+('AUD', 'Australian Dollar', 'A$', 2),
+('MYR', 'Malaysian Ringgit', 'RM', 2),
+('SGD', 'Singapore Dollar', 'S$', 2),
+('USD', 'US Dollar', '$', 2)
+ON CONFLICT (code) DO NOTHING;
+
+-- +1 and +61 were swapped in the hardcoded map, +1 is the United States and +61 is Australia
+INSERT INTO country_currencies (mobile_country_code, currency)
+VALUES
+('+1', 'USD'),
+('+60', 'MYR'),
+('+61', 'AUD'),
+('+65', 'SGD')
+ON CONFLICT (mobile_country_code) DO NOTHING;
+
+COMMIT;
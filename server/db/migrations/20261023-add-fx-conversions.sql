@@ -0,0 +1,28 @@
+-- add_fx_conversions.sql
+-- Every conversion keeps the mid rate, spread, profit and where the rate came from, so that
+-- the rate applied to a transfer or exchange can be looked up long after the rate has changed.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS fx_conversions (
+    id SERIAL PRIMARY KEY,
+    journal_entry_id INT NOT NULL REFERENCES ledger_journal_entries(id),
+    transaction_id INT REFERENCES transactions(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    from_amount NUMERIC(20,2) NOT NULL,
+    to_amount NUMERIC(20,2) NOT NULL,
+    mid_rate NUMERIC(20,8) NOT NULL CHECK (mid_rate > 0),
+    spread NUMERIC(20,8) NOT NULL DEFAULT 0,
+    profit NUMERIC(20,2) NOT NULL DEFAULT 0,
+    rate_source VARCHAR(10) NOT NULL CHECK (rate_source IN ('direct', 'cross', 'quote', 'original')),
+    conversion_path VARCHAR(64) NOT NULL,
+    rate_effective_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_conversions_transaction_id ON fx_conversions (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_fx_conversions_pair_created_at ON fx_conversions (from_currency, to_currency, created_at);
+
+COMMIT;
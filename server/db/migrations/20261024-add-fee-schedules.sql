@@ -0,0 +1,69 @@
+-- add_fee_schedules.sql
+-- Fees are priced from versioned fee schedules instead of a fixed share of the spread, and every
+-- fee charged is kept as a line item. The first version charges what was charged before: 2% of
+-- the spread on exchanges and transfers, nothing on withdrawals. creator_profit is no longer written.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS fee_schedules (
+    id SERIAL PRIMARY KEY,
+    version INT NOT NULL UNIQUE CHECK (version > 0),
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by INT REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_schedules_effective_at ON fee_schedules (effective_at);
+
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id SERIAL PRIMARY KEY,
+    fee_schedule_id INT NOT NULL REFERENCES fee_schedules(id),
+    product VARCHAR(20) NOT NULL CHECK (product IN ('exchange', 'transfer', 'withdrawal')),
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    spread_share NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (spread_share >= 0 AND spread_share <= 1),
+    percentage NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage < 1),
+    fixed_amount NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (fixed_amount >= 0),
+    min_fee NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (min_fee >= 0),
+    max_fee NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (max_fee >= 0),
+    UNIQUE (fee_schedule_id, product, from_currency, to_currency)
+);
+
+CREATE TABLE IF NOT EXISTS fee_rule_tiers (
+    id SERIAL PRIMARY KEY,
+    fee_rule_id INT NOT NULL REFERENCES fee_rules(id),
+    min_amount NUMERIC(20,2) NOT NULL CHECK (min_amount >= 0),
+    percentage NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage < 1),
+    fixed_amount NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (fixed_amount >= 0),
+    UNIQUE (fee_rule_id, min_amount)
+);
+
+CREATE TABLE IF NOT EXISTS fee_charges (
+    id SERIAL PRIMARY KEY,
+    journal_entry_id INT NOT NULL REFERENCES ledger_journal_entries(id),
+    transaction_id INT REFERENCES transactions(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    fee_schedule_id INT NOT NULL REFERENCES fee_schedules(id),
+    fee_rule_id INT NOT NULL REFERENCES fee_rules(id),
+    product VARCHAR(20) NOT NULL,
+    fee_type VARCHAR(20) NOT NULL CHECK (fee_type IN ('spread', 'percentage', 'fixed', 'minimum')),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_charges_transaction_id ON fee_charges (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_fee_charges_currency_created_at ON fee_charges (currency, created_at);
+
+INSERT INTO fee_schedules (version, note, effective_at)
+VALUES (1, 'Initial fee schedule', '1970-01-01T00:00:00Z')
+ON CONFLICT (version) DO NOTHING;
+
+INSERT INTO fee_rules (fee_schedule_id, product, from_currency, to_currency, spread_share)
+SELECT id, product, '*', '*', 0.02
+FROM fee_schedules, (VALUES ('exchange'), ('transfer')) AS products (product)
+WHERE version = 1
+ON CONFLICT (fee_schedule_id, product, from_currency, to_currency) DO NOTHING;
+
+COMMIT;
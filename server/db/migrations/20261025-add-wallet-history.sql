@@ -0,0 +1,21 @@
+-- add_wallet_history.sql
+-- Funds can be moved directly between two wallets of the same user. Each currency moved is kept
+-- as a line on both wallets, pointing at the other wallet and at the journal entry of the move.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS wallet_history (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    counterparty_wallet_id INT REFERENCES wallets(id) ON DELETE SET NULL,
+    journal_entry_id INT REFERENCES ledger_journal_entries(id),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_history_wallet_id ON wallet_history (wallet_id, created_at);
+
+COMMIT;
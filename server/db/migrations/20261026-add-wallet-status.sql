@@ -0,0 +1,12 @@
+-- add_wallet_status.sql
+-- Wallets can be given a name, archived and closed. A closed wallet keeps its row so that the
+-- transactions and history pointing at it stay intact, it is only left out of the wallet list.
+
+BEGIN;
+
+ALTER TABLE wallets
+ADD COLUMN IF NOT EXISTS name VARCHAR(50),
+ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'archived', 'closed')),
+ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP WITH TIME ZONE;
+
+COMMIT;
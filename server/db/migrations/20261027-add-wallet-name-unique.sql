@@ -0,0 +1,26 @@
+-- add_wallet_name_unique.sql
+-- Users can hold several wallets of the same type, so wallets are now told apart by name instead.
+-- Wallets without a name are named after their wallet type, and names repeated by the same user
+-- get the wallet id appended, before the name becomes required and unique among open wallets.
+
+BEGIN;
+
+UPDATE wallets w
+SET name = wt.type
+FROM wallet_types wt
+WHERE w.wallet_type_id = wt.id AND (w.name IS NULL OR TRIM(w.name) = '');
+
+UPDATE wallets w
+SET name = LEFT(w.name, 40) || ' ' || w.id
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, LOWER(name) ORDER BY id) AS rn
+    FROM wallets
+    WHERE status <> 'closed'
+) d
+WHERE w.id = d.id AND d.rn > 1;
+
+ALTER TABLE wallets ALTER COLUMN name SET NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_id_name ON wallets (user_id, LOWER(name)) WHERE status <> 'closed';
+
+COMMIT;
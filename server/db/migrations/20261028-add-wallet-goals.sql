@@ -0,0 +1,35 @@
+-- add_wallet_goals.sql
+-- Wallets can have a savings goal, a target amount in one currency with an optional deadline.
+-- The top up that reaches a goal completes it and writes a wallet_goal_events row in the same
+-- transaction, for whatever notifies the user to pick up.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS wallet_goals (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL UNIQUE REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_amount NUMERIC(20,2) NOT NULL CHECK (target_amount > 0),
+    currency CHAR(3) NOT NULL,
+    deadline DATE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_goals_user_id ON wallet_goals (user_id);
+
+CREATE TABLE IF NOT EXISTS wallet_goal_events (
+    id SERIAL PRIMARY KEY,
+    goal_id INT NOT NULL REFERENCES wallet_goals(id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    saved_amount NUMERIC(20,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_goal_events_user_id ON wallet_goal_events (user_id, created_at);
+
+COMMIT;
@@ -0,0 +1,42 @@
+-- add_auto_save_rules.sql
+-- Auto-save rules move money from the main balance into a wallet, either a fixed amount on a
+-- schedule or the round ups of the transfers sent since the last run. The scheduler records
+-- every run in auto_save_runs and pauses a rule after repeated insufficient funds failures.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS auto_save_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    currency CHAR(3) NOT NULL,
+    frequency VARCHAR(20) NOT NULL,
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    last_transaction_id INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_save_rules_user_id ON auto_save_rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_auto_save_rules_next_run_at ON auto_save_rules (next_run_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS auto_save_runs (
+    id SERIAL PRIMARY KEY,
+    rule_id INT NOT NULL REFERENCES auto_save_rules(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount >= 0),
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    failure_reason VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_save_runs_rule_id ON auto_save_runs (rule_id, created_at);
+
+COMMIT;
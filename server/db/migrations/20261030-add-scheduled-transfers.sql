@@ -0,0 +1,44 @@
+-- add_scheduled_transfers.sql
+-- Scheduled transfers send money to a linked beneficiary on a future date, once or again on a
+-- daily, weekly or monthly schedule until an end date or run count. The scheduler claims every
+-- run in scheduled_transfer_runs before making its transfer, and records the transaction it made.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sender_wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    source_amount NUMERIC(20,2) NOT NULL CHECK (source_amount > 0),
+    source_currency CHAR(3) NOT NULL,
+    beneficiary_mobile_country_code VARCHAR(5) NOT NULL,
+    beneficiary_mobile_number VARCHAR(255) NOT NULL,
+    auto_convert BOOLEAN NOT NULL DEFAULT FALSE,
+    frequency VARCHAR(20) NOT NULL,
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_at TIMESTAMP WITH TIME ZONE,
+    max_runs INT NOT NULL DEFAULT 0 CHECK (max_runs >= 0),
+    run_count INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_user_id ON scheduled_transfers (user_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_next_run_at ON scheduled_transfers (next_run_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    id SERIAL PRIMARY KEY,
+    scheduled_transfer_id INT NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id INT REFERENCES transactions(id),
+    status VARCHAR(20) NOT NULL,
+    failure_reason VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_scheduled_transfer_id ON scheduled_transfer_runs (scheduled_transfer_id, created_at);
+
+COMMIT;
@@ -0,0 +1,27 @@
+-- add_payment_requests.sql
+-- Payment requests ask a linked beneficiary for money. The payer pays a pending request from one
+-- of their wallets, or declines it, the requester can cancel it, and the scheduler expires the
+-- requests left pending past expires_at. A paid request records the transaction that paid it.
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS payment_requests (
+    id SERIAL PRIMARY KEY,
+    requester_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    failure_reason VARCHAR(50),
+    transaction_id INT REFERENCES transactions(id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_requester_id ON payment_requests (requester_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_payer_id ON payment_requests (payer_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_expires_at ON payment_requests (expires_at) WHERE status = 'pending';
+
+COMMIT;
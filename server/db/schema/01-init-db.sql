@@ -41,10 +41,16 @@ CREATE TABLE IF NOT EXISTS wallets (
     id SERIAL PRIMARY KEY,
     wallet_type_id INT NOT NULL REFERENCES wallet_types(id),
     user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'archived', 'closed')),
+    closed_at TIMESTAMP WITH TIME ZONE,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
 
+-- wallet names are unique per user among wallets that are not closed
+CREATE UNIQUE INDEX IF NOT EXISTS idx_wallets_user_id_name ON wallets (user_id, LOWER(name)) WHERE status <> 'closed';
+
 CREATE TABLE IF NOT EXISTS wallet_balances (
     id SERIAL PRIMARY KEY,
     amount NUMERIC(20,2) NOT NULL,
@@ -80,16 +86,45 @@ CREATE TABLE IF NOT EXISTS transactions (
     id SERIAL PRIMARY KEY,
     user_id INT NOT NULL REFERENCES users(id),
     sender_id INT NOT NULL REFERENCES users(id),
-    beneficiary_id INT NOT NULL REFERENCES users(id),
+    beneficiary_id INT REFERENCES users(id),
     source_of_transfer VARCHAR(255) NOT NULL,
     source_amount NUMERIC(20,2) NOT NULL,
     source_currency CHAR(3) NOT NULL,
     destination_amount NUMERIC(20,2) NOT NULL,
-    destination_currency CHAR(3) NOT NULL,
-    status VARCHAR(50) NOT NULL,
-    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+    destination_currency CHAR(3),
+    status VARCHAR(50) NOT NULL CHECK (status IN ('CREATED', 'PENDING', 'SUCCESS', 'FAILED', 'REVERSED')),
+    failure_reason VARCHAR(64),
+    sender_wallet_id INT REFERENCES wallets(id),
+    original_transaction_id INT REFERENCES transactions(id),
+    reference CHAR(26) NOT NULL,
+    fx_rate NUMERIC(20,8),
+    profit NUMERIC(20,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, reference)
+);
+
+CREATE TABLE IF NOT EXISTS transaction_refunds (
+    id SERIAL PRIMARY KEY,
+    transaction_id INT NOT NULL REFERENCES transactions(id),
+    reversal_transaction_id INT REFERENCES transactions(id),
+    sender_id INT NOT NULL REFERENCES users(id),
+    beneficiary_id INT NOT NULL REFERENCES users(id),
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    refund_amount NUMERIC(20,2) NOT NULL DEFAULT 0,
+    refund_currency CHAR(3) NOT NULL,
+    rate_type VARCHAR(10) NOT NULL CHECK (rate_type IN ('original', 'current')),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('REQUESTED', 'COMPLETED', 'DECLINED')),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
 );
 
+CREATE INDEX IF NOT EXISTS idx_transaction_refunds_transaction_id ON transaction_refunds (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_refunds_sender_id ON transaction_refunds (sender_id);
+CREATE INDEX IF NOT EXISTS idx_transaction_refunds_beneficiary_id ON transaction_refunds (beneficiary_id);
+
 CREATE TABLE IF NOT EXISTS creator_profit (
     id SERIAL PRIMARY KEY,
     amount NUMERIC (20, 2) NOT NULL,
@@ -98,3 +133,288 @@ CREATE TABLE IF NOT EXISTS creator_profit (
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
     UNIQUE(currency)
 );
+
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20,8) NOT NULL CHECK (rate > 0),
+    spread NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (spread >= 0),
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (from_currency, to_currency, effective_at),
+    CHECK (from_currency <> to_currency)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair_effective_at ON exchange_rates (from_currency, to_currency, effective_at DESC);
+
+CREATE TABLE IF NOT EXISTS currencies (
+    code CHAR(3) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    exponent SMALLINT NOT NULL DEFAULT 2 CHECK (exponent BETWEEN 0 AND 4),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS country_currencies (
+    mobile_country_code VARCHAR(5) PRIMARY KEY,
+    currency CHAR(3) NOT NULL REFERENCES currencies(code),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_journal_entries (
+    id SERIAL PRIMARY KEY,
+    type VARCHAR(50) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id SERIAL PRIMARY KEY,
+    entry_id INT NOT NULL REFERENCES ledger_journal_entries(id),
+    account VARCHAR(255) NOT NULL,
+    direction VARCHAR(6) NOT NULL CHECK (direction IN ('DEBIT', 'CREDIT')),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON ledger_postings(entry_id);
+CREATE INDEX ON ledger_postings(account, currency);
+
+-- postings are append only
+CREATE OR REPLACE FUNCTION ledger_postings_immutable() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'ledger postings are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_postings_no_update_delete
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_postings_immutable();
+
+-- every journal entry must sum to zero per currency, checked at commit so that
+-- the postings of one entry can be inserted one at a time
+CREATE OR REPLACE FUNCTION ledger_entry_balanced() RETURNS TRIGGER AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1
+        FROM ledger_postings
+        WHERE entry_id = NEW.entry_id
+        GROUP BY currency
+        HAVING SUM(CASE WHEN direction = 'DEBIT' THEN amount ELSE -amount END) <> 0
+    ) THEN
+        RAISE EXCEPTION 'journal entry % does not sum to zero per currency', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_entry_balanced();
+
+CREATE TABLE IF NOT EXISTS fx_conversions (
+    id SERIAL PRIMARY KEY,
+    journal_entry_id INT NOT NULL REFERENCES ledger_journal_entries(id),
+    transaction_id INT REFERENCES transactions(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    from_amount NUMERIC(20,2) NOT NULL,
+    to_amount NUMERIC(20,2) NOT NULL,
+    mid_rate NUMERIC(20,8) NOT NULL CHECK (mid_rate > 0),
+    spread NUMERIC(20,8) NOT NULL DEFAULT 0,
+    profit NUMERIC(20,2) NOT NULL DEFAULT 0,
+    rate_source VARCHAR(10) NOT NULL CHECK (rate_source IN ('direct', 'cross', 'quote', 'original')),
+    conversion_path VARCHAR(64) NOT NULL,
+    rate_effective_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fx_conversions_transaction_id ON fx_conversions (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_fx_conversions_pair_created_at ON fx_conversions (from_currency, to_currency, created_at);
+
+CREATE TABLE IF NOT EXISTS fee_schedules (
+    id SERIAL PRIMARY KEY,
+    version INT NOT NULL UNIQUE CHECK (version > 0),
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    created_by INT REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_schedules_effective_at ON fee_schedules (effective_at);
+
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id SERIAL PRIMARY KEY,
+    fee_schedule_id INT NOT NULL REFERENCES fee_schedules(id),
+    product VARCHAR(20) NOT NULL CHECK (product IN ('exchange', 'transfer', 'withdrawal')),
+    from_currency VARCHAR(3) NOT NULL,
+    to_currency VARCHAR(3) NOT NULL,
+    spread_share NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (spread_share >= 0 AND spread_share <= 1),
+    percentage NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage < 1),
+    fixed_amount NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (fixed_amount >= 0),
+    min_fee NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (min_fee >= 0),
+    max_fee NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (max_fee >= 0),
+    UNIQUE (fee_schedule_id, product, from_currency, to_currency)
+);
+
+CREATE TABLE IF NOT EXISTS fee_rule_tiers (
+    id SERIAL PRIMARY KEY,
+    fee_rule_id INT NOT NULL REFERENCES fee_rules(id),
+    min_amount NUMERIC(20,2) NOT NULL CHECK (min_amount >= 0),
+    percentage NUMERIC(20,8) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage < 1),
+    fixed_amount NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (fixed_amount >= 0),
+    UNIQUE (fee_rule_id, min_amount)
+);
+
+CREATE TABLE IF NOT EXISTS fee_charges (
+    id SERIAL PRIMARY KEY,
+    journal_entry_id INT NOT NULL REFERENCES ledger_journal_entries(id),
+    transaction_id INT REFERENCES transactions(id),
+    user_id INT NOT NULL REFERENCES users(id),
+    fee_schedule_id INT NOT NULL REFERENCES fee_schedules(id),
+    fee_rule_id INT NOT NULL REFERENCES fee_rules(id),
+    product VARCHAR(20) NOT NULL,
+    fee_type VARCHAR(20) NOT NULL CHECK (fee_type IN ('spread', 'percentage', 'fixed', 'minimum')),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_fee_charges_transaction_id ON fee_charges (transaction_id);
+CREATE INDEX IF NOT EXISTS idx_fee_charges_currency_created_at ON fee_charges (currency, created_at);
+
+CREATE TABLE IF NOT EXISTS wallet_history (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    counterparty_wallet_id INT REFERENCES wallets(id) ON DELETE SET NULL,
+    journal_entry_id INT REFERENCES ledger_journal_entries(id),
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_history_wallet_id ON wallet_history (wallet_id, created_at);
+
+CREATE TABLE IF NOT EXISTS wallet_goals (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL UNIQUE REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_amount NUMERIC(20,2) NOT NULL CHECK (target_amount > 0),
+    currency CHAR(3) NOT NULL,
+    deadline DATE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_goals_user_id ON wallet_goals (user_id);
+
+CREATE TABLE IF NOT EXISTS wallet_goal_events (
+    id SERIAL PRIMARY KEY,
+    goal_id INT NOT NULL REFERENCES wallet_goals(id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    saved_amount NUMERIC(20,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_goal_events_user_id ON wallet_goal_events (user_id, created_at);
+
+CREATE TABLE IF NOT EXISTS auto_save_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    amount NUMERIC(20,2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    currency CHAR(3) NOT NULL,
+    frequency VARCHAR(20) NOT NULL,
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    consecutive_failures INT NOT NULL DEFAULT 0,
+    last_transaction_id INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_save_rules_user_id ON auto_save_rules (user_id);
+CREATE INDEX IF NOT EXISTS idx_auto_save_rules_next_run_at ON auto_save_rules (next_run_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS auto_save_runs (
+    id SERIAL PRIMARY KEY,
+    rule_id INT NOT NULL REFERENCES auto_save_rules(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount >= 0),
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    failure_reason VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auto_save_runs_rule_id ON auto_save_runs (rule_id, created_at);
+
+CREATE TABLE IF NOT EXISTS scheduled_transfers (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    sender_wallet_id INT NOT NULL REFERENCES wallets(id) ON DELETE CASCADE,
+    source_amount NUMERIC(20,2) NOT NULL CHECK (source_amount > 0),
+    source_currency CHAR(3) NOT NULL,
+    beneficiary_mobile_country_code VARCHAR(5) NOT NULL,
+    beneficiary_mobile_number VARCHAR(255) NOT NULL,
+    auto_convert BOOLEAN NOT NULL DEFAULT FALSE,
+    frequency VARCHAR(20) NOT NULL,
+    start_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    end_at TIMESTAMP WITH TIME ZONE,
+    max_runs INT NOT NULL DEFAULT 0 CHECK (max_runs >= 0),
+    run_count INT NOT NULL DEFAULT 0,
+    next_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_user_id ON scheduled_transfers (user_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfers_next_run_at ON scheduled_transfers (next_run_at) WHERE status = 'active';
+
+CREATE TABLE IF NOT EXISTS scheduled_transfer_runs (
+    id SERIAL PRIMARY KEY,
+    scheduled_transfer_id INT NOT NULL REFERENCES scheduled_transfers(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    transaction_id INT REFERENCES transactions(id),
+    status VARCHAR(20) NOT NULL,
+    failure_reason VARCHAR(50),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_transfer_runs_scheduled_transfer_id ON scheduled_transfer_runs (scheduled_transfer_id, created_at);
+
+CREATE TABLE IF NOT EXISTS payment_requests (
+    id SERIAL PRIMARY KEY,
+    requester_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    payer_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount NUMERIC(20,2) NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    note VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    failure_reason VARCHAR(50),
+    transaction_id INT REFERENCES transactions(id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_requests_requester_id ON payment_requests (requester_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_payer_id ON payment_requests (payer_id);
+CREATE INDEX IF NOT EXISTS idx_payment_requests_expires_at ON payment_requests (expires_at) WHERE status = 'pending';
@@ -24,47 +24,102 @@ VALUES
 (4300, 'USD', 'deposit', 1, 3, '2024-04-20 12:30:00'),
 (700, 'USD', 'deposit', 1, 3, '2024-05-25 14:00:00');
 
+INSERT INTO exchange_rates (from_currency, to_currency, rate, spread, effective_at)
+VALUES
+('SGD', 'USD', 0.76, 0.005, '2024-01-01 00:00:00+00'),
+('SGD', 'AUD', 1.03, 0.004, '2024-01-01 00:00:00+00'),
+('SGD', 'MYR', 3.29, 0.007, '2024-01-01 00:00:00+00'),
+('USD', 'SGD', 1.32, 0.006, '2024-01-01 00:00:00+00'),
+('USD', 'AUD', 1.41, 0.005, '2024-01-01 00:00:00+00'),
+('USD', 'MYR', 4.43, 0.008, '2024-01-01 00:00:00+00'),
+('AUD', 'SGD', 0.97, 0.003, '2024-01-01 00:00:00+00'),
+('AUD', 'USD', 0.71, 0.004, '2024-01-01 00:00:00+00'),
+('AUD', 'MYR', 3.35, 0.006, '2024-01-01 00:00:00+00'),
+('MYR', 'SGD', 0.30, 0.008, '2024-01-01 00:00:00+00'),
+('MYR', 'USD', 0.22, 0.007, '2024-01-01 00:00:00+00'),
+('MYR', 'AUD', 0.30, 0.009, '2024-01-01 00:00:00+00');
+
+INSERT INTO currencies (code, name, symbol, exponent)
+VALUES
+('AUD', 'Australian Dollar', 'A$', 2),
+('MYR', 'Malaysian Ringgit', 'RM', 2),
+('SGD', 'Singapore Dollar', 'S$', 2),
+('USD', 'US Dollar', '$', 2);
+
+INSERT INTO country_currencies (mobile_country_code, currency)
+VALUES
+('+1', 'USD'),
+('+60', 'MYR'),
+('+61', 'AUD'),
+('+65', 'SGD');
+
 INSERT INTO wallet_types (type)
 VALUES ('personal'), ('savings'), ('investment'), ('business');
 
-INSERT INTO wallets (wallet_type_id, user_id)
+INSERT INTO wallets (wallet_type_id, user_id, name)
 VALUES
-(4, 1);
+(4, 1, 'business');
 
 INSERT INTO wallet_balances (amount, currency, wallet_id, user_id)
 VALUES
 (10000, 'AUD', 1, 1);
 
+-- Opening ledger entry so the seeded balances are backed by postings
+INSERT INTO ledger_journal_entries (type, reference)
+VALUES ('opening_balance', 'seed');
+
+INSERT INTO ledger_postings (entry_id, account, direction, currency, amount)
+VALUES
+(1, 'system:external', 'DEBIT', 'AUD', 60000),
+(1, 'system:external', 'DEBIT', 'SGD', 19000),
+(1, 'system:external', 'DEBIT', 'USD', 5000),
+(1, 'user:1:balance', 'CREDIT', 'AUD', 20000),
+(1, 'user:1:balance', 'CREDIT', 'SGD', 15000),
+(1, 'user:1:balance', 'CREDIT', 'USD', 5000),
+(1, 'user:2:balance', 'CREDIT', 'AUD', 30000),
+(1, 'user:2:balance', 'CREDIT', 'SGD', 4000),
+(1, 'user:1:wallet:1', 'CREDIT', 'AUD', 10000);
+
 -- Inserting dummy data for testing pagination
-INSERT INTO transactions (user_id, sender_id, beneficiary_id, source_of_transfer, source_amount, source_currency, destination_amount, destination_currency, status, created_at)
+INSERT INTO transactions (user_id, sender_id, beneficiary_id, source_of_transfer, source_amount, source_currency, destination_amount, destination_currency, status, reference, created_at)
 VALUES
-(1, 1, 2, 'Bank Transfer', 100.00, 'SGD', 70.00, 'USD', 'SUCCESS', '2022-01-01 10:00:00'),
-(1, 1, 2, 'Credit Card', 500.00, 'SGD', 350.00, 'USD', 'PENDING', '2022-01-02 12:00:00'),
-(1, 1, 2, 'PayPal', 200.00, 'SGD', 140.00, 'USD', 'FAILED', '2022-01-03 14:00:00'),
-(1, 1, 2, 'Bank Transfer', 800.00, 'SGD', 560.00, 'USD', 'SUCCESS', '2022-01-04 16:00:00'),
-(1, 1, 2, 'Credit Card', 300.00, 'SGD', 210.00, 'USD', 'PENDING', '2022-01-05 18:00:00'),
-(1, 1, 2, 'Bank Transfer', 400.00, 'SGD', 280.00, 'USD', 'SUCCESS', '2022-01-06 20:00:00'),
-(1, 1, 2, 'PayPal', 600.00, 'SGD', 420.00, 'USD', 'FAILED', '2022-01-07 22:00:00'),
-(1, 1, 2, 'Credit Card', 900.00, 'SGD', 630.00, 'USD', 'PENDING', '2022-01-08 00:00:00'),
-(1, 1, 2, 'Bank Transfer', 700.00, 'SGD', 490.00, 'USD', 'SUCCESS', '2022-01-09 02:00:00'),
-(1, 1, 2, 'Bank Transfer', 950.00, 'SGD', 665.00, 'USD', 'SUCCESS', '2022-01-10 04:00:00'),
-(1, 1, 2, 'PayPal', 250.00, 'SGD', 175.00, 'USD', 'FAILED', '2022-01-11 06:00:00'),
-(1, 1, 2, 'Credit Card', 750.00, 'SGD', 525.00, 'USD', 'PENDING', '2022-01-12 08:00:00'),
-(1, 1, 2, 'Bank Transfer', 650.00, 'SGD', 455.00, 'USD', 'SUCCESS', '2022-01-13 10:00:00'),
-(1, 1, 2, 'Bank Transfer', 900.00, 'SGD', 630.00, 'USD', 'SUCCESS', '2022-01-14 12:00:00'),
-(1, 1, 2, 'PayPal', 800.00, 'SGD', 560.00, 'USD', 'FAILED', '2022-01-15 14:00:00'),
-(1, 1, 2, 'Credit Card', 950.00, 'SGD', 665.00, 'USD', 'PENDING', '2022-01-16 16:00:00'),
-(1, 1, 2, 'Bank Transfer', 700.00, 'SGD', 490.00, 'USD', 'SUCCESS', '2022-01-17 18:00:00'),
-(1, 1, 2, 'Bank Transfer', 500.00, 'SGD', 350.00, 'USD', 'SUCCESS', '2022-01-18 20:00:00'),
-(1, 1, 2, 'PayPal', 650.00, 'SGD', 455.00, 'USD', 'FAILED', '2022-01-19 22:00:00'),
-(1, 1, 2, 'Credit Card', 800.00, 'SGD', 560.00, 'USD', 'PENDING', '2022-01-20 00:00:00'),
-(1, 1, 2, 'Bank Transfer', 950.00, 'SGD', 665.00, 'USD', 'SUCCESS', '2022-01-21 02:00:00'),
-(1, 1, 2, 'PayPal', 750.00, 'SGD', 525.00, 'USD', 'FAILED', '2022-01-22 04:00:00'),
-(1, 1, 2, 'Credit Card', 600.00, 'SGD', 420.00, 'USD', 'PENDING', '2022-01-23 06:00:00'),
-(1, 1, 2, 'Bank Transfer', 400.00, 'SGD', 280.00, 'USD', 'SUCCESS', '2022-01-24 08:00:00'),
-(1, 1, 2, 'Bank Transfer', 250.00, 'SGD', 175.00, 'USD', 'SUCCESS', '2022-01-25 10:00:00'),
-(1, 1, 2, 'PayPal', 900.00, 'SGD', 630.00, 'USD', 'FAILED', '2022-01-26 12:00:00'),
-(1, 1, 2, 'Credit Card', 700.00, 'SGD', 490.00, 'USD', 'PENDING', '2022-01-27 14:00:00'),
-(1, 1, 2, 'Bank Transfer', 650.00, 'SGD', 455.00, 'USD', 'SUCCESS', '2022-01-28 16:00:00'),
-(1, 1, 2, 'Bank Transfer', 500.00, 'SGD', 350.00, 'USD', 'SUCCESS', '2022-01-29 18:00:00'),
-(1, 1, 2, 'PayPal', 800.00, 'SGD', 560.00, 'USD', 'FAILED', '2022-01-30 20:00:00');
\ No newline at end of file
+(1, 1, 2, 'Bank Transfer', 100.00, 'SGD', 70.00, 'USD', 'SUCCESS', '01FRAH9V80XMDY3Z592MD4FV5E', '2022-01-01 10:00:00'),
+(1, 1, 2, 'Credit Card', 500.00, 'SGD', 350.00, 'USD', 'PENDING', '01FRDAJ9G0D932A7HXTHDW5MD5', '2022-01-02 12:00:00'),
+(1, 1, 2, 'PayPal', 200.00, 'SGD', 140.00, 'USD', 'FAILED', '01FRG3TQR03DXDXMAK852Q6C5S', '2022-01-03 14:00:00'),
+(1, 1, 2, 'Bank Transfer', 800.00, 'SGD', 560.00, 'USD', 'SUCCESS', '01FRJX3600C0SS8ASSDBJYPXTX', '2022-01-04 16:00:00'),
+(1, 1, 2, 'Credit Card', 300.00, 'SGD', 210.00, 'USD', 'PENDING', '01FRNPBM80WFD11C49PB37BXZA', '2022-01-05 18:00:00'),
+(1, 1, 2, 'Bank Transfer', 400.00, 'SGD', 280.00, 'USD', 'SUCCESS', '01FRRFM2G0J0QHGWWERYNEW5HY', '2022-01-06 20:00:00'),
+(1, 1, 2, 'PayPal', 600.00, 'SGD', 420.00, 'USD', 'FAILED', '01FRV8WGR0Z75RRR1EW80VKZZR', '2022-01-07 22:00:00'),
+(1, 1, 2, 'Credit Card', 900.00, 'SGD', 630.00, 'USD', 'PENDING', '01FRVFR8002TN3JTY84C3CDMPD', '2022-01-08 00:00:00'),
+(1, 1, 2, 'Bank Transfer', 700.00, 'SGD', 490.00, 'USD', 'SUCCESS', '01FRY90P803PV6AK69JWANXP2D', '2022-01-09 02:00:00'),
+(1, 1, 2, 'Bank Transfer', 950.00, 'SGD', 665.00, 'USD', 'SUCCESS', '01FS1294G0YTD5XHSPX2VNATRA', '2022-01-10 04:00:00'),
+(1, 1, 2, 'PayPal', 250.00, 'SGD', 175.00, 'USD', 'FAILED', '01FS3VHJR0964401E8JP77G1N6', '2022-01-11 06:00:00'),
+(1, 1, 2, 'Credit Card', 750.00, 'SGD', 525.00, 'USD', 'PENDING', '01FS6MT100B3R3N7FDN7SG4SS1', '2022-01-12 08:00:00'),
+(1, 1, 2, 'Bank Transfer', 650.00, 'SGD', 455.00, 'USD', 'SUCCESS', '01FS9E2F80VB17QBH1QKSVF7QK', '2022-01-13 10:00:00'),
+(1, 1, 2, 'Bank Transfer', 900.00, 'SGD', 630.00, 'USD', 'SUCCESS', '01FSC7AXG0F45PKJBA84SS6JMJ', '2022-01-14 12:00:00'),
+(1, 1, 2, 'PayPal', 800.00, 'SGD', 560.00, 'USD', 'FAILED', '01FSF0KBR0RQKWC6BHRBKQYBPF', '2022-01-15 14:00:00'),
+(1, 1, 2, 'Credit Card', 950.00, 'SGD', 665.00, 'USD', 'PENDING', '01FSHSVT00QBSAPZZ6JEMKCBWK', '2022-01-16 16:00:00'),
+(1, 1, 2, 'Bank Transfer', 700.00, 'SGD', 490.00, 'USD', 'SUCCESS', '01FSMK488084PXXGJR287YGVVX', '2022-01-17 18:00:00'),
+(1, 1, 2, 'Bank Transfer', 500.00, 'SGD', 350.00, 'USD', 'SUCCESS', '01FSQCCPG076Q85P5ZMZWKWP5K', '2022-01-18 20:00:00'),
+(1, 1, 2, 'PayPal', 650.00, 'SGD', 455.00, 'USD', 'FAILED', '01FST5N4R0AYZTP6ABHCH8R9W3', '2022-01-19 22:00:00'),
+(1, 1, 2, 'Credit Card', 800.00, 'SGD', 560.00, 'USD', 'PENDING', '01FSTCGW001PESNARDPP1FTNH8', '2022-01-20 00:00:00'),
+(1, 1, 2, 'Bank Transfer', 950.00, 'SGD', 665.00, 'USD', 'SUCCESS', '01FSX5SA8012EK7KC76MC7CW1A', '2022-01-21 02:00:00'),
+(1, 1, 2, 'PayPal', 750.00, 'SGD', 525.00, 'USD', 'FAILED', '01FSZZ1RG0T0H53N4PK3S4V3DQ', '2022-01-22 04:00:00'),
+(1, 1, 2, 'Credit Card', 600.00, 'SGD', 420.00, 'USD', 'PENDING', '01FT2RA6R01FFZV6Q9105CX72N', '2022-01-23 06:00:00'),
+(1, 1, 2, 'Bank Transfer', 400.00, 'SGD', 280.00, 'USD', 'SUCCESS', '01FT5HJN003FEK53C180JZRZ3V', '2022-01-24 08:00:00'),
+(1, 1, 2, 'Bank Transfer', 250.00, 'SGD', 175.00, 'USD', 'SUCCESS', '01FT8AV380JRQVT9XCW3JW6EAT', '2022-01-25 10:00:00'),
+(1, 1, 2, 'PayPal', 900.00, 'SGD', 630.00, 'USD', 'FAILED', '01FTB43HG0J0E2TVFV77NBM6N2', '2022-01-26 12:00:00'),
+(1, 1, 2, 'Credit Card', 700.00, 'SGD', 490.00, 'USD', 'PENDING', '01FTDXBZR0JN0GMQ7D2247TX1Q', '2022-01-27 14:00:00'),
+(1, 1, 2, 'Bank Transfer', 650.00, 'SGD', 455.00, 'USD', 'SUCCESS', '01FTGPME002YB6418YFD0GDDSQ', '2022-01-28 16:00:00'),
+(1, 1, 2, 'Bank Transfer', 500.00, 'SGD', 350.00, 'USD', 'SUCCESS', '01FTKFWW80F9BESRZW2PX00KN2', '2022-01-29 18:00:00'),
+(1, 1, 2, 'PayPal', 800.00, 'SGD', 560.00, 'USD', 'FAILED', '01FTP95AG0CGC60V1K326TS0CJ', '2022-01-30 20:00:00');
+
+INSERT INTO fee_schedules (version, note, effective_at)
+VALUES (1, 'Initial fee schedule', '1970-01-01T00:00:00Z')
+ON CONFLICT (version) DO NOTHING;
+
+INSERT INTO fee_rules (fee_schedule_id, product, from_currency, to_currency, spread_share)
+SELECT id, product, '*', '*', 0.02
+FROM fee_schedules, (VALUES ('exchange'), ('transfer')) AS products (product)
+WHERE version = 1
+ON CONFLICT (fee_schedule_id, product, from_currency, to_currency) DO NOTHING;
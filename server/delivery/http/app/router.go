@@ -11,13 +11,17 @@ import (
 )
 
 type Application struct {
-	Cfg                *infrastructure.Config
-	RedisClient        infrastructure.RedisClient
-	UserUsecase        domain.UserUsecase
-	BalanceUsecase     domain.BalanceUsecase
-	BeneficiaryUsecase domain.BeneficiaryUsecase
-	WalletUsecase      domain.WalletUsecase
-	TransactionUsecase domain.TransactionUsecase
+	Cfg                 *infrastructure.Config
+	RedisClient         infrastructure.RedisClient
+	UserUsecase         domain.UserUsecase
+	BalanceUsecase      domain.BalanceUsecase
+	BeneficiaryUsecase  domain.BeneficiaryUsecase
+	WalletUsecase       domain.WalletUsecase
+	TransactionUsecase  domain.TransactionUsecase
+	CurrencyUsecase     domain.CurrencyUsecase
+	ExchangeRateUsecase domain.ExchangeRateUsecase
+	FeeUsecase          domain.FeeUsecase
+	AutoSaveUsecase     domain.AutoSaveUsecase
 }
 
 func (app Application) CreateRouter() (*mux.Router, error) {
@@ -35,10 +39,15 @@ func (app Application) CreateRouter() (*mux.Router, error) {
 	beneficiaryHandler := handlers.NewBeneficiaryHandler(app.BeneficiaryUsecase)
 	walletHandler := handlers.NewWalletHandler(app.WalletUsecase)
 	transactionHandler := handlers.NewTransactionHandler(app.TransactionUsecase)
+	currencyHandler := handlers.NewCurrencyHandler(app.CurrencyUsecase)
+	exchangeRateHandler := handlers.NewExchangeRateHandler(app.ExchangeRateUsecase)
+	feeHandler := handlers.NewFeeHandler(app.FeeUsecase)
+	autoSaveHandler := handlers.NewAutoSaveHandler(app.AutoSaveUsecase)
 
 	apiRouter.Use(
 		middleware.NewAuthenticationMiddleware(*app.Cfg, app.RedisClient, app.UserUsecase).Middleware,
 		middleware.NewCSRFMiddleware(*app.Cfg, app.RedisClient).Middleware,
+		middleware.NewIdempotencyMiddleware(*app.Cfg, app.RedisClient).Middleware,
 		middleware.NewHeadersMiddleware().Middleware,
 	)
 
@@ -58,6 +67,16 @@ func (app Application) CreateRouter() (*mux.Router, error) {
 	apiRouter.HandleFunc("/users/profile", userHandler.UpdateUser).Methods(http.MethodPut)
 	apiRouter.HandleFunc("/users/me", userHandler.GetUserDetail).Methods(http.MethodGet)
 
+	// currency routes
+	apiRouter.HandleFunc("/currencies", currencyHandler.GetCurrencies).Methods(http.MethodGet)
+
+	// exchange rate routes
+	apiRouter.HandleFunc("/fx/rates/history", exchangeRateHandler.GetExchangeRateHistory).Methods(http.MethodGet)
+
+	// admin fee schedule routes
+	apiRouter.HandleFunc("/admin/fees/schedules", feeHandler.GetFeeSchedules).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/admin/fees/schedules", feeHandler.CreateFeeSchedule).Methods(http.MethodPost)
+
 	// balance routes
 	apiRouter.HandleFunc("/balances", balanceHandler.GetBalances).Methods(http.MethodGet)
 	apiRouter.HandleFunc("/balances/{id:[0-9]+}", balanceHandler.GetBalance).Methods(http.MethodGet)
@@ -79,11 +98,42 @@ func (app Application) CreateRouter() (*mux.Router, error) {
 	apiRouter.HandleFunc("/wallet/all", walletHandler.GetWallets).Methods(http.MethodGet)
 	apiRouter.HandleFunc("/wallet/types", walletHandler.GetWalletTypes).Methods(http.MethodGet)
 	apiRouter.HandleFunc("/wallet", walletHandler.CreateWallet).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/wallet/transfer", walletHandler.TransferBetweenWallets).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/wallet/{id:[0-9]+}/exchange", walletHandler.ExchangeWalletCurrency).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/wallet/{id:[0-9]+}/name", walletHandler.RenameWallet).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/wallet/{id:[0-9]+}/archive", walletHandler.ArchiveWallet).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/wallet/{id:[0-9]+}/restore", walletHandler.RestoreWallet).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/wallet/{id:[0-9]+}/close", walletHandler.CloseWallet).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/wallet/{id:[0-9]+}/goal", walletHandler.SetWalletGoal).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/wallet/{id:[0-9]+}/goal", walletHandler.DeleteWalletGoal).Methods(http.MethodDelete)
 	apiRouter.HandleFunc("/wallet/update/{id:[0-9]+}/{operation}", walletHandler.UpdateWallet).Methods(http.MethodPut)
 
+	// auto-save routes
+	apiRouter.HandleFunc("/auto-save", autoSaveHandler.CreateAutoSaveRule).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/auto-save/all", autoSaveHandler.GetAutoSaveRules).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/auto-save/{id:[0-9]+}/runs", autoSaveHandler.GetAutoSaveRuns).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/auto-save/{id:[0-9]+}/pause", autoSaveHandler.PauseAutoSaveRule).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/auto-save/{id:[0-9]+}/resume", autoSaveHandler.ResumeAutoSaveRule).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/auto-save/{id:[0-9]+}", autoSaveHandler.DeleteAutoSaveRule).Methods(http.MethodDelete)
+
 	// transaction routes
 	apiRouter.HandleFunc("/transaction", transactionHandler.CreateTransaction).Methods(http.MethodPost)
 	apiRouter.HandleFunc("/transaction/all", transactionHandler.GetTransactions).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/transaction/{ref:[0-9A-Za-z]{26}}", transactionHandler.GetTransaction).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/transaction/{id:[0-9]+}/refund", transactionHandler.RequestRefund).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/transaction/refund/all", transactionHandler.GetRefunds).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/transaction/refund/{id:[0-9]+}/{decision}", transactionHandler.RespondToRefund).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/transaction/scheduled", transactionHandler.CreateScheduledTransfer).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/transaction/scheduled/all", transactionHandler.GetScheduledTransfers).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/transaction/scheduled/{id:[0-9]+}/runs", transactionHandler.GetScheduledTransferRuns).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/transaction/scheduled/{id:[0-9]+}/pause", transactionHandler.PauseScheduledTransfer).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/transaction/scheduled/{id:[0-9]+}/resume", transactionHandler.ResumeScheduledTransfer).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/transaction/scheduled/{id:[0-9]+}/cancel", transactionHandler.CancelScheduledTransfer).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/transaction/request", transactionHandler.RequestPayment).Methods(http.MethodPost)
+	apiRouter.HandleFunc("/transaction/request/all", transactionHandler.GetPaymentRequests).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/transaction/request/{id:[0-9]+}/pay", transactionHandler.PayPaymentRequest).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/transaction/request/{id:[0-9]+}/decline", transactionHandler.DeclinePaymentRequest).Methods(http.MethodPut)
+	apiRouter.HandleFunc("/transaction/request/{id:[0-9]+}/cancel", transactionHandler.CancelPaymentRequest).Methods(http.MethodPut)
 
 	return apiRouter, nil
 }
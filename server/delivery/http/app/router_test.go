@@ -1,37 +1,40 @@
 package app_test
 
 import (
+	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/LeonLow97/go-clean-architecture/delivery/http/app"
+	"github.com/LeonLow97/go-clean-architecture/delivery/http/middleware"
 	"github.com/LeonLow97/go-clean-architecture/infrastructure"
 	mocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 )
 
-func TestCreateRouter(t *testing.T) {
-	mockConfig := new(infrastructure.Config)
-	mockRedisClient := *new(infrastructure.RedisClient)
-	mockUserUsecase := new(mocks.UserUsecase)
-	mockBalanceUsecase := new(mocks.BalanceUsecase)
-	mockBeneficiaryUsecase := new(mocks.BeneficiaryUsecase)
-	mockWalletUsecase := new(mocks.WalletUsecase)
-	mockTransactionUsecase := new(mocks.TransactionUsecase)
-
+func newRouter(t *testing.T) *mux.Router {
 	app := app.Application{
-		Cfg:                mockConfig,
-		RedisClient:        mockRedisClient,
-		UserUsecase:        mockUserUsecase,
-		BalanceUsecase:     mockBalanceUsecase,
-		BeneficiaryUsecase: mockBeneficiaryUsecase,
-		WalletUsecase:      mockWalletUsecase,
-		TransactionUsecase: mockTransactionUsecase,
+		Cfg:                 new(infrastructure.Config),
+		RedisClient:         *new(infrastructure.RedisClient),
+		UserUsecase:         new(mocks.UserUsecase),
+		BalanceUsecase:      new(mocks.BalanceUsecase),
+		BeneficiaryUsecase:  new(mocks.BeneficiaryUsecase),
+		WalletUsecase:       new(mocks.WalletUsecase),
+		TransactionUsecase:  new(mocks.TransactionUsecase),
+		CurrencyUsecase:     new(mocks.CurrencyUsecase),
+		ExchangeRateUsecase: new(mocks.ExchangeRateUsecase),
+		FeeUsecase:          new(mocks.FeeUsecase),
+		AutoSaveUsecase:     new(mocks.AutoSaveUsecase),
 	}
 
 	router, err := app.CreateRouter()
 	require.NoError(t, err)
+	return router
+}
+
+func TestCreateRouter(t *testing.T) {
+	router := newRouter(t)
 
 	expectedRoutes := []struct {
 		Path   string
@@ -48,6 +51,10 @@ func TestCreateRouter(t *testing.T) {
 		{"/api/v1/password-reset/reset", "PATCH"},
 		{"/api/v1/users/profile", "PUT"},
 		{"/api/v1/users/me", "GET"},
+		{"/api/v1/currencies", "GET"},
+		{"/api/v1/fx/rates/history", "GET"},
+		{"/api/v1/admin/fees/schedules", "GET"},
+		{"/api/v1/admin/fees/schedules", "POST"},
 		{"/api/v1/balances", "GET"},
 		{"/api/v1/balances/{id:[0-9]+}", "GET"},
 		{"/api/v1/balances/history/{id:[0-9]+}", "GET"},
@@ -64,9 +71,38 @@ func TestCreateRouter(t *testing.T) {
 		{"/api/v1/wallet/all", "GET"},
 		{"/api/v1/wallet/types", "GET"},
 		{"/api/v1/wallet", "POST"},
+		{"/api/v1/wallet/transfer", "POST"},
+		{"/api/v1/wallet/{id:[0-9]+}/exchange", "POST"},
+		{"/api/v1/wallet/{id:[0-9]+}/name", "PUT"},
+		{"/api/v1/wallet/{id:[0-9]+}/archive", "PUT"},
+		{"/api/v1/wallet/{id:[0-9]+}/restore", "PUT"},
+		{"/api/v1/wallet/{id:[0-9]+}/close", "POST"},
+		{"/api/v1/wallet/{id:[0-9]+}/goal", "PUT"},
+		{"/api/v1/wallet/{id:[0-9]+}/goal", "DELETE"},
 		{"/api/v1/wallet/update/{id:[0-9]+}/{operation}", "PUT"},
+		{"/api/v1/auto-save", "POST"},
+		{"/api/v1/auto-save/all", "GET"},
+		{"/api/v1/auto-save/{id:[0-9]+}/runs", "GET"},
+		{"/api/v1/auto-save/{id:[0-9]+}/pause", "PUT"},
+		{"/api/v1/auto-save/{id:[0-9]+}/resume", "PUT"},
+		{"/api/v1/auto-save/{id:[0-9]+}", "DELETE"},
 		{"/api/v1/transaction", "POST"},
 		{"/api/v1/transaction/all", "GET"},
+		{"/api/v1/transaction/{ref:[0-9A-Za-z]{26}}", "GET"},
+		{"/api/v1/transaction/{id:[0-9]+}/refund", "POST"},
+		{"/api/v1/transaction/refund/all", "GET"},
+		{"/api/v1/transaction/refund/{id:[0-9]+}/{decision}", "PUT"},
+		{"/api/v1/transaction/scheduled", "POST"},
+		{"/api/v1/transaction/scheduled/all", "GET"},
+		{"/api/v1/transaction/scheduled/{id:[0-9]+}/runs", "GET"},
+		{"/api/v1/transaction/scheduled/{id:[0-9]+}/pause", "PUT"},
+		{"/api/v1/transaction/scheduled/{id:[0-9]+}/resume", "PUT"},
+		{"/api/v1/transaction/scheduled/{id:[0-9]+}/cancel", "PUT"},
+		{"/api/v1/transaction/request", "POST"},
+		{"/api/v1/transaction/request/all", "GET"},
+		{"/api/v1/transaction/request/{id:[0-9]+}/pay", "PUT"},
+		{"/api/v1/transaction/request/{id:[0-9]+}/decline", "PUT"},
+		{"/api/v1/transaction/request/{id:[0-9]+}/cancel", "PUT"},
 	}
 
 	// Check if each expected route exists in the router with the correct method
@@ -75,6 +111,72 @@ func TestCreateRouter(t *testing.T) {
 	}
 }
 
+// TestCreateRouter_MoneyMovingRoutesAreIdempotent makes sure a retried request cannot move money
+// twice. Every route that changes state either honours the Idempotency-Key header or is listed
+// here as one that moves no money, so that a new route has to be put in one or the other.
+func TestCreateRouter_MoneyMovingRoutesAreIdempotent(t *testing.T) {
+	router := newRouter(t)
+
+	noMoneyMovedRoutes := map[string]struct{}{
+		"POST /api/v1/login":                                   {},
+		"POST /api/v1/signup":                                  {},
+		"POST /api/v1/logout":                                  {},
+		"PATCH /api/v1/change-password":                        {},
+		"POST /api/v1/configure-mfa":                           {},
+		"POST /api/v1/verify-mfa":                              {},
+		"POST /api/v1/password-reset/send":                     {},
+		"PATCH /api/v1/password-reset/reset":                   {},
+		"PUT /api/v1/users/profile":                            {},
+		"POST /api/v1/admin/fees/schedules":                    {},
+		"POST /api/v1/balances/preview-exchange":               {},
+		"POST /api/v1/beneficiary":                             {},
+		"PUT /api/v1/beneficiary":                              {},
+		"PUT /api/v1/wallet/{id:[0-9]+}/name":                  {},
+		"PUT /api/v1/wallet/{id:[0-9]+}/archive":               {},
+		"PUT /api/v1/wallet/{id:[0-9]+}/restore":               {},
+		"PUT /api/v1/wallet/{id:[0-9]+}/goal":                  {},
+		"DELETE /api/v1/wallet/{id:[0-9]+}/goal":               {},
+		"POST /api/v1/auto-save":                               {},
+		"PUT /api/v1/auto-save/{id:[0-9]+}/pause":              {},
+		"PUT /api/v1/auto-save/{id:[0-9]+}/resume":             {},
+		"DELETE /api/v1/auto-save/{id:[0-9]+}":                 {},
+		"PUT /api/v1/transaction/scheduled/{id:[0-9]+}/pause":  {},
+		"PUT /api/v1/transaction/scheduled/{id:[0-9]+}/resume": {},
+		"PUT /api/v1/transaction/scheduled/{id:[0-9]+}/cancel": {},
+		"POST /api/v1/transaction/request":                     {},
+		"PUT /api/v1/transaction/request/{id:[0-9]+}/decline":  {},
+		"PUT /api/v1/transaction/request/{id:[0-9]+}/cancel":   {},
+	}
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		for _, method := range methods {
+			if method == http.MethodGet {
+				continue
+			}
+			_, idempotent := middleware.IdempotentEndpoints[path]
+			_, noMoneyMoved := noMoneyMovedRoutes[method+" "+path]
+			require.True(t, idempotent || noMoneyMoved, "%s %s moves money and should be in middleware.IdempotentEndpoints", method, path)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// an idempotent endpoint that matches no route would leave the route it was meant for unprotected
+	for path := range middleware.IdempotentEndpoints {
+		require.True(t, routeExists(router, path, http.MethodPost) || routeExists(router, path, http.MethodPut) || routeExists(router, path, http.MethodPatch),
+			"Route not found for idempotent endpoint: %s", path)
+	}
+}
+
 func routeExists(router *mux.Router, testPath, testMethod string) bool {
 	found := false
 
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	apiErr "github.com/LeonLow97/go-clean-architecture/exception/response"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/contextstore"
+	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
+)
+
+type AutoSaveHandler struct {
+	autoSaveUsecase domain.AutoSaveUsecase
+}
+
+func NewAutoSaveHandler(uc domain.AutoSaveUsecase) *AutoSaveHandler {
+	handler := &AutoSaveHandler{
+		autoSaveUsecase: uc,
+	}
+
+	return handler
+}
+
+// CreateAutoSaveRule sets up a rule that moves money from the main balance into a wallet on a schedule
+func (h *AutoSaveHandler) CreateAutoSaveRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreateAutoSaveRuleRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		log.Println("error validating req struct in create auto-save rule handler", err)
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	rule, err := h.autoSaveUsecase.CreateAutoSaveRule(ctx, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAutoSaveAmountRequired):
+			jsonutil.ErrorJSON(w, apiErr.ErrAutoSaveAmountRequired, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletArchived):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletArchived, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, rule)
+}
+
+func (h *AutoSaveHandler) GetAutoSaveRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := h.autoSaveUsecase.GetAutoSaveRules(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoAutoSaveRulesFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoAutoSaveRulesFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, rules)
+}
+
+// GetAutoSaveRuns lists every run of a rule with its outcome, latest first
+func (h *AutoSaveHandler) GetAutoSaveRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve rule id from url params
+	ruleID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	runs, err := h.autoSaveUsecase.GetAutoSaveRuns(ctx, userID, ruleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAutoSaveRuleNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrAutoSaveRuleNotFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, runs)
+}
+
+func (h *AutoSaveHandler) PauseAutoSaveRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve rule id from url params
+	ruleID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	rule, err := h.autoSaveUsecase.PauseAutoSaveRule(ctx, userID, ruleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAutoSaveRuleNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrAutoSaveRuleNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrAutoSaveRulePaused):
+			jsonutil.ErrorJSON(w, apiErr.ErrAutoSaveRulePaused, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, rule)
+}
+
+// ResumeAutoSaveRule activates a paused rule again from its next scheduled run
+func (h *AutoSaveHandler) ResumeAutoSaveRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve rule id from url params
+	ruleID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	rule, err := h.autoSaveUsecase.ResumeAutoSaveRule(ctx, userID, ruleID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAutoSaveRuleNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrAutoSaveRuleNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrAutoSaveRuleNotPaused):
+			jsonutil.ErrorJSON(w, apiErr.ErrAutoSaveRuleNotPaused, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, rule)
+}
+
+// DeleteAutoSaveRule removes a rule together with the record of its runs
+func (h *AutoSaveHandler) DeleteAutoSaveRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve rule id from url params
+	ruleID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	if err := h.autoSaveUsecase.DeleteAutoSaveRule(ctx, userID, ruleID); err != nil {
+		switch {
+		case errors.Is(err, exception.ErrAutoSaveRuleNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrAutoSaveRuleNotFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteNoContent(w, http.StatusNoContent)
+}
@@ -0,0 +1,207 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	handlers "github.com/LeonLow97/go-clean-architecture/delivery/http/handler"
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoSaveHandler_NewAutoSaveHandler(t *testing.T) {
+	t.Run("ReturnsAnInstanceOfAutoSaveHandler", func(t *testing.T) {
+		autoSaveUsecase := &mocks.AutoSaveUsecase{}
+		instance := handlers.NewAutoSaveHandler(autoSaveUsecase)
+
+		require.IsType(t, handlers.AutoSaveHandler{}, *instance)
+	})
+}
+
+func TestAutoSaveHandler_CreateAutoSaveRule(t *testing.T) {
+	startAt := time.Date(2026, time.November, 2, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Title                       string
+		GivenUserIDWithContext      int
+		GivenRequest                string
+		AutoSaveUsecaseReturnValues mocks.AutoSaveUsecaseReturnValues
+		ExpectedStatus              int
+		ExpectedResponse            string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"wallet_id":2,"type":"schedule","amount":50,"currency":"sgd","frequency":"weekly","start_at":"2026-11-02T09:00:00Z"}`,
+			AutoSaveUsecaseReturnValues: mocks.AutoSaveUsecaseReturnValues{
+				CreateAutoSaveRule: []interface{}{&domain.AutoSaveRule{
+					ID:        1,
+					UserID:    1,
+					WalletID:  2,
+					Type:      domain.AutoSaveTypeSchedule,
+					Amount:    5000,
+					Currency:  "SGD",
+					Frequency: domain.FrequencyWeekly,
+					StartAt:   startAt,
+					NextRunAt: startAt,
+					Status:    domain.AutoSaveStatusActive,
+					CreatedAt: startAt.Add(-time.Hour),
+					UpdatedAt: "2026-11-02T08:00:00Z",
+				}, nil},
+			},
+			ExpectedStatus:   http.StatusCreated,
+			ExpectedResponse: `{"id":1,"user_id":1,"wallet_id":2,"type":"schedule","amount":50.00,"currency":"SGD","frequency":"weekly","start_at":"2026-11-02T09:00:00Z","next_run_at":"2026-11-02T09:00:00Z","status":"active","consecutive_failures":0,"created_at":"2026-11-02T08:00:00Z","updated_at":"2026-11-02T08:00:00Z"}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			GivenRequest:           `{"wallet_id":2,"type":"schedule","amount":50,"currency":"SGD","frequency":"weekly"}`,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_InvalidFrequency",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"wallet_id":2,"type":"schedule","amount":50,"currency":"SGD","frequency":"hourly"}`,
+			ExpectedStatus:         http.StatusBadRequest,
+		},
+		{
+			Title:                  "ReturnsError_AutoSaveAmountRequired",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"wallet_id":2,"type":"schedule","currency":"SGD","frequency":"weekly"}`,
+			AutoSaveUsecaseReturnValues: mocks.AutoSaveUsecaseReturnValues{
+				CreateAutoSaveRule: []interface{}{nil, exception.ErrAutoSaveAmountRequired},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Scheduled auto-save rules need an amount greater than 0."}`,
+		},
+		{
+			Title:                  "ReturnsError_NoWalletFound",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"wallet_id":2,"type":"round_up","currency":"SGD","frequency":"daily"}`,
+			AutoSaveUsecaseReturnValues: mocks.AutoSaveUsecaseReturnValues{
+				CreateAutoSaveRule: []interface{}{nil, exception.ErrNoWalletFound},
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedResponse: `{"status":404,"message":"Wallet not found."}`,
+		},
+		{
+			Title:                  "ReturnsError_InternalServerError",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"wallet_id":2,"type":"round_up","currency":"SGD","frequency":"daily"}`,
+			AutoSaveUsecaseReturnValues: mocks.AutoSaveUsecaseReturnValues{
+				CreateAutoSaveRule: []interface{}{nil, errors.New("internal server error")},
+			},
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedResponse: `{"status":500,"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			autoSaveUsecase := &mocks.AutoSaveUsecase{}
+
+			autoSaveUsecase.On("CreateAutoSaveRule", mock.Anything, 1, mock.MatchedBy(func(req dto.CreateAutoSaveRuleRequest) bool {
+				return req.Currency == "SGD"
+			})).
+				Return(tc.AutoSaveUsecaseReturnValues.CreateAutoSaveRule...)
+
+			autoSaveHandler := handlers.NewAutoSaveHandler(autoSaveUsecase)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/auto-save", strings.NewReader(tc.GivenRequest))
+			require.NoError(t, err)
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			autoSaveHandler.CreateAutoSaveRule(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			if tc.ExpectedResponse != "" {
+				require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+			}
+		})
+	}
+}
+
+func TestAutoSaveHandler_PauseAutoSaveRule(t *testing.T) {
+	testCases := []struct {
+		Title                       string
+		GivenUserIDWithContext      int
+		AutoSaveUsecaseReturnValues mocks.AutoSaveUsecaseReturnValues
+		ExpectedStatus              int
+		ExpectedResponse            string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			AutoSaveUsecaseReturnValues: mocks.AutoSaveUsecaseReturnValues{
+				PauseAutoSaveRule: []interface{}{&domain.AutoSaveRule{ID: 1, Status: domain.AutoSaveStatusPaused}, nil},
+			},
+			ExpectedStatus: http.StatusOK,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_AutoSaveRuleNotFound",
+			GivenUserIDWithContext: 1,
+			AutoSaveUsecaseReturnValues: mocks.AutoSaveUsecaseReturnValues{
+				PauseAutoSaveRule: []interface{}{nil, exception.ErrAutoSaveRuleNotFound},
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedResponse: `{"status":404,"message":"Auto-save rule not found."}`,
+		},
+		{
+			Title:                  "ReturnsError_AutoSaveRulePaused",
+			GivenUserIDWithContext: 1,
+			AutoSaveUsecaseReturnValues: mocks.AutoSaveUsecaseReturnValues{
+				PauseAutoSaveRule: []interface{}{nil, exception.ErrAutoSaveRulePaused},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Auto-save rule is already paused."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			autoSaveUsecase := &mocks.AutoSaveUsecase{}
+
+			autoSaveUsecase.On("PauseAutoSaveRule", mock.Anything, 1, 1).
+				Return(tc.AutoSaveUsecaseReturnValues.PauseAutoSaveRule...)
+
+			autoSaveHandler := handlers.NewAutoSaveHandler(autoSaveUsecase)
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/auto-save/1/pause", nil)
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			autoSaveHandler.PauseAutoSaveRule(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			if tc.ExpectedResponse != "" {
+				require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+			}
+		})
+	}
+}
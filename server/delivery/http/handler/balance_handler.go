@@ -12,6 +12,7 @@ import (
 	"github.com/LeonLow97/go-clean-architecture/infrastructure"
 	"github.com/LeonLow97/go-clean-architecture/utils/contextstore"
 	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
+	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 )
 
 type BalanceHandler struct {
@@ -42,17 +43,30 @@ func (h *BalanceHandler) GetBalanceHistory(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	resp, err := h.balanceUsecase.GetBalanceHistory(ctx, userID, balanceID)
+	// retrieve pagination values from query params
+	var paginator pagination.Paginator
+	if err := jsonutil.ReadQueryParams(&paginator, r); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	// SanitizePaginator sanitizes pagination data
+	paginator.SanitizePaginator()
+
+	resp, err := h.balanceUsecase.GetBalanceHistory(ctx, userID, balanceID, &paginator)
 	if err != nil {
 		switch {
 		case errors.Is(err, exception.ErrBalanceHistoryNotFound):
 			jsonutil.ErrorJSON(w, apiErr.ErrBalanceHistoryNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrInvalidCursor):
+			jsonutil.ErrorJSON(w, apiErr.ErrInvalidCursor, http.StatusBadRequest)
 		default:
 			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
 		}
 		return
 	}
 
+	jsonutil.SetPaginatorHeaders(w, &paginator)
 	jsonutil.WriteJSON(w, http.StatusOK, resp)
 }
 
@@ -164,6 +178,10 @@ func (h *BalanceHandler) Deposit(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, exception.ErrDepositCurrencyNotAllowed):
 			jsonutil.ErrorJSON(w, apiErr.ErrDepositCurrencyNotAllowed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCountryCurrencyNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrCountryCurrencyNotFound, http.StatusBadRequest)
 		default:
 			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
 		}
@@ -203,6 +221,14 @@ func (h *BalanceHandler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, exception.ErrWithdrawCurrencyNotAllowed):
 			jsonutil.ErrorJSON(w, apiErr.ErrWithdrawCurrencyNotAllowed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCountryCurrencyNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrCountryCurrencyNotFound, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrFeesExceedAmount):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeesExceedAmount, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrAmountTooLarge):
+			jsonutil.ErrorJSON(w, apiErr.ErrAmountTooLarge, http.StatusBadRequest)
 		case errors.Is(err, exception.ErrInsufficientFunds):
 			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsForWithdrawal, http.StatusBadRequest)
 		case errors.Is(err, exception.ErrBalanceNotFound):
@@ -247,6 +273,26 @@ func (h *BalanceHandler) CurrencyExchange(w http.ResponseWriter, r *http.Request
 			jsonutil.ErrorJSON(w, apiErr.ErrBalanceNotFound, http.StatusNotFound)
 		case errors.Is(err, exception.ErrInsufficientFundsForCurrencyExchange):
 			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsForCurrencyExchange, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrToCurrencyNotAllowed):
+			jsonutil.ErrorJSON(w, apiErr.ErrToCurrencyNotAllowed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCountryCurrencyNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrCountryCurrencyNotFound, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrExchangeRateNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeRateNotFound, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrFeesExceedAmount):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeesExceedAmount, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrAmountTooLarge):
+			jsonutil.ErrorJSON(w, apiErr.ErrAmountTooLarge, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrExchangeQuoteInvalid):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteInvalid, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrExchangeQuoteExpired):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteExpired, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrExchangeQuoteUsed):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteUsed, http.StatusConflict)
+		case errors.Is(err, exception.ErrExchangeQuoteMismatch):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteMismatch, http.StatusBadRequest)
 		default:
 			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
 		}
@@ -256,10 +302,16 @@ func (h *BalanceHandler) CurrencyExchange(w http.ResponseWriter, r *http.Request
 	jsonutil.WriteNoContent(w, http.StatusNoContent)
 }
 
-// PreviewExchange allows users to preview the exchange rate, no data manipulation
+// PreviewExchange allows users to preview the exchange rate and locks it in with a quote, balances are not touched
 func (h *BalanceHandler) PreviewExchange(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
 	var req dto.PreviewExchangeRequest
 	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
 		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
@@ -275,6 +327,20 @@ func (h *BalanceHandler) PreviewExchange(w http.ResponseWriter, r *http.Request)
 
 	req.PreviewExchangeSanitize()
 
-	resp := h.balanceUsecase.PreviewExchange(ctx, req)
+	resp, err := h.balanceUsecase.PreviewExchange(ctx, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrExchangeRateNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeRateNotFound, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrFeesExceedAmount):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeesExceedAmount, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrAmountTooLarge):
+			jsonutil.ErrorJSON(w, apiErr.ErrAmountTooLarge, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
 	jsonutil.WriteJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	apiErr "github.com/LeonLow97/go-clean-architecture/exception/response"
+	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
+)
+
+type CurrencyHandler struct {
+	currencyUsecase domain.CurrencyUsecase
+}
+
+func NewCurrencyHandler(uc domain.CurrencyUsecase) *CurrencyHandler {
+	handler := &CurrencyHandler{
+		currencyUsecase: uc,
+	}
+
+	return handler
+}
+
+func (h *CurrencyHandler) GetCurrencies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	resp, err := h.currencyUsecase.GetCurrencies(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, resp)
+}
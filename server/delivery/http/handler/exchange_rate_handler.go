@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	apiErr "github.com/LeonLow97/go-clean-architecture/exception/response"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
+)
+
+type ExchangeRateHandler struct {
+	exchangeRateUsecase domain.ExchangeRateUsecase
+}
+
+func NewExchangeRateHandler(uc domain.ExchangeRateUsecase) *ExchangeRateHandler {
+	handler := &ExchangeRateHandler{
+		exchangeRateUsecase: uc,
+	}
+
+	return handler
+}
+
+func (h *ExchangeRateHandler) GetExchangeRateHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req dto.GetExchangeRateHistoryRequest
+	if err := jsonutil.ReadQueryParams(&req, r); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	resp, err := h.exchangeRateUsecase.GetExchangeRateHistory(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrExchangeRateNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeRateNotFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	apiErr "github.com/LeonLow97/go-clean-architecture/exception/response"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/contextstore"
+	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
+)
+
+type FeeHandler struct {
+	feeUsecase domain.FeeUsecase
+}
+
+func NewFeeHandler(uc domain.FeeUsecase) *FeeHandler {
+	handler := &FeeHandler{
+		feeUsecase: uc,
+	}
+
+	return handler
+}
+
+func (h *FeeHandler) GetFeeSchedules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	resp, err := h.feeUsecase.GetFeeSchedules(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotAdmin):
+			jsonutil.ErrorJSON(w, apiErr.ErrForbidden, http.StatusForbidden)
+		case errors.Is(err, exception.ErrUserNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		case errors.Is(err, exception.ErrFeeScheduleNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeeScheduleNotFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// CreateFeeSchedule publishes a new version of the fee schedule, earlier versions are kept
+func (h *FeeHandler) CreateFeeSchedule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreateFeeScheduleRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		log.Println("error validating req struct in create fee schedule handler", err)
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	resp, err := h.feeUsecase.CreateFeeSchedule(ctx, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotAdmin):
+			jsonutil.ErrorJSON(w, apiErr.ErrForbidden, http.StatusForbidden)
+		case errors.Is(err, exception.ErrUserNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		case errors.Is(err, exception.ErrFeeRuleInvalid):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeeRuleInvalid, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrFeeRuleDuplicated):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeeRuleDuplicated, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrFeeRuleCurrencyRequired):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeeRuleCurrencyRequired, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, resp)
+}
@@ -1,17 +1,21 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
 	apiErr "github.com/LeonLow97/go-clean-architecture/exception/response"
 	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
 	"github.com/LeonLow97/go-clean-architecture/utils/contextstore"
 	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
 	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
+	"github.com/LeonLow97/go-clean-architecture/utils/ulid"
 )
 
 type TransactionHandler struct {
@@ -51,25 +55,50 @@ func (h *TransactionHandler) CreateTransaction(w http.ResponseWriter, r *http.Re
 	req.Sanitize()
 
 	if err = h.transactionUsecase.CreateTransaction(ctx, req, userID); err != nil {
-		switch {
-		case errors.Is(err, exception.ErrBeneficiaryIsInactive) ||
-			errors.Is(err, exception.ErrBeneficiaryMFANotConfigured):
-			jsonutil.ErrorJSON(w, apiErr.ErrBeneficiaryAccountNotRegistered, http.StatusBadRequest)
-		case errors.Is(err, exception.ErrUserIDEqualBeneficiaryID):
-			jsonutil.ErrorJSON(w, apiErr.ErrUserIDEqualBeneficiaryID, http.StatusBadRequest)
-		case errors.Is(err, exception.ErrSenderWalletInvalid):
-			jsonutil.ErrorJSON(w, apiErr.ErrSenderWalletInvalid, http.StatusForbidden)
-		case errors.Is(err, exception.ErrInsufficientFundsInWallet):
-			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsInWallet, http.StatusBadRequest)
-		default:
-			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
-		}
+		writeTransferError(w, err)
 		return
 	}
 
 	jsonutil.WriteNoContent(w, http.StatusCreated)
 }
 
+// writeTransferError responds with why a transfer made through CreateTransaction's path failed
+func writeTransferError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, exception.ErrBeneficiaryIsInactive) ||
+		errors.Is(err, exception.ErrBeneficiaryMFANotConfigured):
+		jsonutil.ErrorJSON(w, apiErr.ErrBeneficiaryAccountNotRegistered, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrUserIDEqualBeneficiaryID):
+		jsonutil.ErrorJSON(w, apiErr.ErrUserIDEqualBeneficiaryID, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrSenderWalletInvalid):
+		jsonutil.ErrorJSON(w, apiErr.ErrSenderWalletInvalid, http.StatusForbidden)
+	case errors.Is(err, exception.ErrInsufficientFundsInWallet):
+		jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsInWallet, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrCurrencyNotSupported):
+		jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrCurrencyDisabled):
+		jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrCountryCurrencyNotFound):
+		jsonutil.ErrorJSON(w, apiErr.ErrCountryCurrencyNotFound, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrExchangeRateNotFound):
+		jsonutil.ErrorJSON(w, apiErr.ErrExchangeRateNotFound, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrFeesExceedAmount):
+		jsonutil.ErrorJSON(w, apiErr.ErrFeesExceedAmount, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrAmountTooLarge):
+		jsonutil.ErrorJSON(w, apiErr.ErrAmountTooLarge, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrExchangeQuoteInvalid):
+		jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteInvalid, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrExchangeQuoteExpired):
+		jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteExpired, http.StatusBadRequest)
+	case errors.Is(err, exception.ErrExchangeQuoteUsed):
+		jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteUsed, http.StatusConflict)
+	case errors.Is(err, exception.ErrExchangeQuoteMismatch):
+		jsonutil.ErrorJSON(w, apiErr.ErrExchangeQuoteMismatch, http.StatusBadRequest)
+	default:
+		jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+	}
+}
+
 func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -90,11 +119,30 @@ func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Requ
 	// SanitizePaginator sanitizes pagination data
 	paginator.SanitizePaginator()
 
-	transactions, err := h.transactionUsecase.GetTransactions(ctx, userID, &paginator)
+	// retrieve filters and sort order from query params
+	var filter dto.TransactionFilter
+	if err := jsonutil.ReadQueryParams(&filter, r); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(filter)
+	if err != nil {
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	filter.Sanitize()
+
+	transactions, err := h.transactionUsecase.GetTransactions(ctx, userID, filter, &paginator)
 	if err != nil {
 		switch {
 		case errors.Is(err, exception.ErrNoTransactionsFound):
 			jsonutil.ErrorJSON(w, apiErr.ErrNoTransactionsFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrInvalidCursor):
+			jsonutil.ErrorJSON(w, apiErr.ErrInvalidCursor, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCursorSortNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCursorSortNotSupported, http.StatusBadRequest)
 		default:
 			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
 		}
@@ -104,3 +152,488 @@ func (h *TransactionHandler) GetTransactions(w http.ResponseWriter, r *http.Requ
 	jsonutil.SetPaginatorHeaders(w, &paginator)
 	jsonutil.WriteJSON(w, http.StatusOK, transactions)
 }
+
+func (h *TransactionHandler) GetTransaction(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve transaction reference from url params, references are case insensitive
+	reference, err := jsonutil.ReadURLParamsString(w, r, "ref")
+	if err != nil {
+		return
+	}
+	reference = strings.ToUpper(reference)
+	if !ulid.IsValid(reference) {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.transactionUsecase.GetTransaction(ctx, userID, reference)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrTransactionNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrTransactionNotFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, transaction)
+}
+
+func (h *TransactionHandler) RequestRefund(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve transaction id from url params
+	transactionID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var req dto.RefundTransactionRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	refund, err := h.transactionUsecase.RequestRefund(ctx, userID, transactionID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrTransactionNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrTransactionNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrTransactionNotRefundable):
+			jsonutil.ErrorJSON(w, apiErr.ErrTransactionNotRefundable, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrRefundWindowExpired):
+			jsonutil.ErrorJSON(w, apiErr.ErrRefundWindowExpired, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrRefundAmountExceeded):
+			jsonutil.ErrorJSON(w, apiErr.ErrRefundAmountExceeded, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, refund)
+}
+
+func (h *TransactionHandler) RespondToRefund(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve refund id and decision from url params
+	refundID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+	decision, err := jsonutil.ReadURLParamsString(w, r, "decision")
+	if err != nil {
+		return
+	}
+	if decision != constants.REFUND_APPROVE && decision != constants.REFUND_DECLINE {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	refund, err := h.transactionUsecase.RespondToRefund(ctx, userID, refundID, decision)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrRefundNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrRefundNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrRefundAlreadyResolved):
+			jsonutil.ErrorJSON(w, apiErr.ErrRefundAlreadyResolved, http.StatusConflict)
+		case errors.Is(err, exception.ErrTransactionNotRefundable):
+			jsonutil.ErrorJSON(w, apiErr.ErrTransactionNotRefundable, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrRefundWindowExpired):
+			jsonutil.ErrorJSON(w, apiErr.ErrRefundWindowExpired, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrInsufficientFundsForRefund):
+			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsForRefund, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrFeesExceedAmount):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeesExceedAmount, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrAmountTooLarge):
+			jsonutil.ErrorJSON(w, apiErr.ErrAmountTooLarge, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, refund)
+}
+
+func (h *TransactionHandler) GetRefunds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	refunds, err := h.transactionUsecase.GetRefunds(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoRefundsFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoRefundsFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, refunds)
+}
+
+// CreateScheduledTransfer schedules a future dated or recurring transfer to a linked beneficiary
+func (h *TransactionHandler) CreateScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.CreateScheduledTransferRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	transfer, err := h.transactionUsecase.CreateScheduledTransfer(ctx, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrScheduledTransferStartPassed):
+			jsonutil.ErrorJSON(w, apiErr.ErrScheduledTransferStartPassed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrScheduledTransferEndBeforeStart):
+			jsonutil.ErrorJSON(w, apiErr.ErrScheduledTransferEndBeforeStart, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrUserNotLinkedToBeneficiary):
+			jsonutil.ErrorJSON(w, apiErr.ErrUserNotLinkedToBeneficiary, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrBeneficiaryIsInactive) ||
+			errors.Is(err, exception.ErrBeneficiaryMFANotConfigured):
+			jsonutil.ErrorJSON(w, apiErr.ErrBeneficiaryAccountNotRegistered, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrUserIDEqualBeneficiaryID):
+			jsonutil.ErrorJSON(w, apiErr.ErrUserIDEqualBeneficiaryID, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrSenderWalletInvalid):
+			jsonutil.ErrorJSON(w, apiErr.ErrSenderWalletInvalid, http.StatusForbidden)
+		case errors.Is(err, exception.ErrCurrencyNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, transfer)
+}
+
+func (h *TransactionHandler) GetScheduledTransfers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	transfers, err := h.transactionUsecase.GetScheduledTransfers(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoScheduledTransfersFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoScheduledTransfersFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, transfers)
+}
+
+// GetScheduledTransferRuns lists every run of a scheduled transfer with its outcome, latest first
+func (h *TransactionHandler) GetScheduledTransferRuns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve scheduled transfer id from url params
+	scheduledTransferID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	runs, err := h.transactionUsecase.GetScheduledTransferRuns(ctx, userID, scheduledTransferID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrScheduledTransferNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrScheduledTransferNotFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, runs)
+}
+
+func (h *TransactionHandler) PauseScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	h.changeScheduledTransfer(w, r, h.transactionUsecase.PauseScheduledTransfer)
+}
+
+// ResumeScheduledTransfer activates a paused transfer again from its next scheduled run
+func (h *TransactionHandler) ResumeScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	h.changeScheduledTransfer(w, r, h.transactionUsecase.ResumeScheduledTransfer)
+}
+
+// CancelScheduledTransfer stops a transfer for good, it stays listed along with its runs
+func (h *TransactionHandler) CancelScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	h.changeScheduledTransfer(w, r, h.transactionUsecase.CancelScheduledTransfer)
+}
+
+// changeScheduledTransfer handles the requests that move the scheduled transfer in the url to another status
+func (h *TransactionHandler) changeScheduledTransfer(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error)) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve scheduled transfer id from url params
+	scheduledTransferID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	transfer, err := change(ctx, userID, scheduledTransferID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrScheduledTransferNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrScheduledTransferNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrScheduledTransferNotActive):
+			jsonutil.ErrorJSON(w, apiErr.ErrScheduledTransferNotActive, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrScheduledTransferNotPaused):
+			jsonutil.ErrorJSON(w, apiErr.ErrScheduledTransferNotPaused, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrScheduledTransferFinished):
+			jsonutil.ErrorJSON(w, apiErr.ErrScheduledTransferFinished, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, transfer)
+}
+
+// RequestPayment asks one of the user's beneficiaries for money
+func (h *TransactionHandler) RequestPayment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.RequestPaymentRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	paymentRequest, err := h.transactionUsecase.RequestPayment(ctx, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrUserNotLinkedToBeneficiary):
+			jsonutil.ErrorJSON(w, apiErr.ErrUserNotLinkedToBeneficiary, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrBeneficiaryIsInactive):
+			jsonutil.ErrorJSON(w, apiErr.ErrBeneficiaryAccountNotRegistered, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrUserIDEqualBeneficiaryID):
+			jsonutil.ErrorJSON(w, apiErr.ErrUserIDEqualBeneficiaryID, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusCreated, paymentRequest)
+}
+
+// GetPaymentRequests lists the payment requests the user made or was asked to pay, latest first
+func (h *TransactionHandler) GetPaymentRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	paymentRequests, err := h.transactionUsecase.GetPaymentRequests(ctx, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoPaymentRequestsFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoPaymentRequestsFound, http.StatusNotFound)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, paymentRequests)
+}
+
+// PayPaymentRequest pays a payment request the user was asked to pay from one of their wallets
+func (h *TransactionHandler) PayPaymentRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve payment request id from url params
+	paymentRequestID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var req dto.PayPaymentRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	paymentRequest, err := h.transactionUsecase.PayPaymentRequest(ctx, userID, paymentRequestID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPaymentRequestNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrPaymentRequestNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrPaymentRequestExpired):
+			jsonutil.ErrorJSON(w, apiErr.ErrPaymentRequestExpired, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrPaymentRequestNotPending):
+			jsonutil.ErrorJSON(w, apiErr.ErrPaymentRequestNotPending, http.StatusConflict)
+		case errors.Is(err, exception.ErrUserNotLinkedToBeneficiary):
+			jsonutil.ErrorJSON(w, apiErr.ErrUserNotLinkedToBeneficiary, http.StatusBadRequest)
+		default:
+			writeTransferError(w, err)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, paymentRequest)
+}
+
+// DeclinePaymentRequest turns down a payment request the user was asked to pay
+func (h *TransactionHandler) DeclinePaymentRequest(w http.ResponseWriter, r *http.Request) {
+	h.resolvePaymentRequest(w, r, h.transactionUsecase.DeclinePaymentRequest)
+}
+
+// CancelPaymentRequest withdraws a payment request the user made before it is paid
+func (h *TransactionHandler) CancelPaymentRequest(w http.ResponseWriter, r *http.Request) {
+	h.resolvePaymentRequest(w, r, h.transactionUsecase.CancelPaymentRequest)
+}
+
+// resolvePaymentRequest handles the requests that close the payment request in the url without paying it
+func (h *TransactionHandler) resolvePaymentRequest(w http.ResponseWriter, r *http.Request, resolve func(ctx context.Context, userID, paymentRequestID int) (*domain.PaymentRequest, error)) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve payment request id from url params
+	paymentRequestID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	paymentRequest, err := resolve(ctx, userID, paymentRequestID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrPaymentRequestNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrPaymentRequestNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrPaymentRequestExpired):
+			jsonutil.ErrorJSON(w, apiErr.ErrPaymentRequestExpired, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrPaymentRequestNotPending):
+			jsonutil.ErrorJSON(w, apiErr.ErrPaymentRequestNotPending, http.StatusConflict)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, paymentRequest)
+}
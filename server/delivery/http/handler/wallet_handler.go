@@ -68,7 +68,13 @@ func (h *WalletHandler) GetWallets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := h.walletUseCase.GetWallets(ctx, userID)
+	var req dto.GetWalletsRequest
+	if err := jsonutil.ReadQueryParams(&req, r); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	resp, err := h.walletUseCase.GetWallets(ctx, userID, req)
 	if err != nil {
 		switch {
 		case errors.Is(err, exception.ErrNoWalletsFound):
@@ -134,6 +140,8 @@ func (h *WalletHandler) CreateWallet(w http.ResponseWriter, r *http.Request) {
 			jsonutil.ErrorJSON(w, apiErr.ErrWalletTypeInvalid, http.StatusBadRequest)
 		case errors.Is(err, exception.ErrWalletAlreadyExists):
 			jsonutil.ErrorJSON(w, apiErr.ErrWalletAlreadyExists, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletLimitReached):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletLimitReached, http.StatusBadRequest)
 		case errors.Is(err, exception.ErrInsufficientFunds):
 			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsInAccount, http.StatusBadRequest)
 		default:
@@ -192,6 +200,10 @@ func (h *WalletHandler) UpdateWallet(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case errors.Is(err, exception.ErrNoWalletFound):
 			jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		case errors.Is(err, exception.ErrWalletArchived):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletArchived, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
 		case errors.Is(err, exception.ErrWalletBalancesNotFound):
 			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletBalancesFound, http.StatusNotFound)
 		case errors.Is(err, exception.ErrBalanceNotFound):
@@ -200,6 +212,364 @@ func (h *WalletHandler) UpdateWallet(w http.ResponseWriter, r *http.Request) {
 			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsInAccount, http.StatusBadRequest)
 		case errors.Is(err, exception.ErrInsufficientFundsForWithdrawal):
 			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsForWithdrawal, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteNoContent(w, http.StatusNoContent)
+}
+
+// TransferBetweenWallets moves funds between two wallets of the user without going through the main balance
+func (h *WalletHandler) TransferBetweenWallets(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	var req dto.TransferWalletRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		log.Println("error validating req struct in transfer between wallets handler", err)
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	resp, err := h.walletUseCase.TransferBetweenWallets(ctx, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrWalletTransferSameWallet):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletTransferSameWallet, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletArchived):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletArchived, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletBalanceNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletBalanceNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrInsufficientFundsInWallet):
+			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsInWallet, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ExchangeWalletCurrency converts between two currencies held in the same wallet
+func (h *WalletHandler) ExchangeWalletCurrency(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve wallet id from url params
+	walletID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var req dto.WalletExchangeRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		log.Println("error validating req struct in exchange wallet currency handler", err)
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	resp, err := h.walletUseCase.ExchangeWalletCurrency(ctx, userID, walletID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrFromCurrencyEqualToCurrency):
+			jsonutil.ErrorJSON(w, apiErr.ErrFromCurrencyEqualToCurrency, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletArchived):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletArchived, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletBalancesNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletBalancesFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletBalanceNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletBalanceNotFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrInsufficientFundsInWallet):
+			jsonutil.ErrorJSON(w, apiErr.ErrInsufficientFundsInWallet, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrFeesExceedAmount):
+			jsonutil.ErrorJSON(w, apiErr.ErrFeesExceedAmount, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrAmountTooLarge):
+			jsonutil.ErrorJSON(w, apiErr.ErrAmountTooLarge, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrExchangeRateNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrExchangeRateNotFound, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// RenameWallet labels a wallet with a name of the user's choosing
+func (h *WalletHandler) RenameWallet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve wallet id from url params
+	walletID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var req dto.RenameWalletRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		log.Println("error validating req struct in rename wallet handler", err)
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	if err := h.walletUseCase.RenameWallet(ctx, userID, walletID, req); err != nil {
+		switch {
+		case errors.Is(err, exception.ErrWalletNameInvalid):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletNameInvalid, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletAlreadyExists):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletAlreadyExists, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteNoContent(w, http.StatusNoContent)
+}
+
+// ArchiveWallet freezes a wallet with its balances until it is restored or closed
+func (h *WalletHandler) ArchiveWallet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve wallet id from url params
+	walletID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	if err := h.walletUseCase.ArchiveWallet(ctx, userID, walletID); err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletArchived):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletArchived, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteNoContent(w, http.StatusNoContent)
+}
+
+// RestoreWallet makes an archived wallet active again
+func (h *WalletHandler) RestoreWallet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve wallet id from url params
+	walletID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	if err := h.walletUseCase.RestoreWallet(ctx, userID, walletID); err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletNotArchived):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletNotArchived, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteNoContent(w, http.StatusNoContent)
+}
+
+// CloseWallet sweeps the balances of a wallet back to the main balance and closes it for good
+func (h *WalletHandler) CloseWallet(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve wallet id from url params
+	walletID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	resp, err := h.walletUseCase.CloseWallet(ctx, userID, walletID)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, resp)
+}
+
+// SetWalletGoal sets the savings goal of a wallet, replacing the goal it had
+func (h *WalletHandler) SetWalletGoal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve wallet id from url params
+	walletID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	var req dto.SetWalletGoalRequest
+	if err := jsonutil.ReadJSONBody(w, r, &req); err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+		return
+	}
+
+	errMessage, err := infrastructure.ValidateStruct(req)
+	if err != nil {
+		log.Println("error validating req struct in set wallet goal handler", err)
+		jsonutil.ErrorJSON(w, errMessage, http.StatusBadRequest)
+		return
+	}
+
+	req.Sanitize()
+
+	goal, err := h.walletUseCase.SetWalletGoal(ctx, userID, walletID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, exception.ErrNoWalletFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrNoWalletFound, http.StatusNotFound)
+		case errors.Is(err, exception.ErrWalletClosed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletClosed, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyNotSupported):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyNotSupported, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrCurrencyDisabled):
+			jsonutil.ErrorJSON(w, apiErr.ErrCurrencyDisabled, http.StatusBadRequest)
+		case errors.Is(err, exception.ErrWalletGoalDeadlinePassed):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletGoalDeadlinePassed, http.StatusBadRequest)
+		default:
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	jsonutil.WriteJSON(w, http.StatusOK, goal)
+}
+
+// DeleteWalletGoal removes the savings goal of a wallet
+func (h *WalletHandler) DeleteWalletGoal(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// retrieve user id from context
+	userID, err := contextstore.UserIDFromContext(ctx)
+	if err != nil {
+		jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+		return
+	}
+
+	// retrieve wallet id from url params
+	walletID, err := jsonutil.ReadURLParamsInt(w, r, "id")
+	if err != nil {
+		return
+	}
+
+	if err := h.walletUseCase.DeleteWalletGoal(ctx, userID, walletID); err != nil {
+		switch {
+		case errors.Is(err, exception.ErrWalletGoalNotFound):
+			jsonutil.ErrorJSON(w, apiErr.ErrWalletGoalNotFound, http.StatusNotFound)
 		default:
 			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
 		}
@@ -127,6 +127,8 @@ func TestWalletHandler_GetWallets(t *testing.T) {
 	type testCase struct {
 		Title                     string
 		GivenUserIDWithContext    int
+		GivenQuery                string
+		ExpectedIncludeClosed     bool
 		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
 		ExpectedStatus            int
 		ExpectedErrorResponse     string
@@ -141,6 +143,16 @@ func TestWalletHandler_GetWallets(t *testing.T) {
 			},
 			ExpectedStatus: http.StatusOK,
 		},
+		{
+			Title:                  "ReturnsSuccessfully_IncludeClosed",
+			GivenUserIDWithContext: 1,
+			GivenQuery:             "?include_closed=true",
+			ExpectedIncludeClosed:  true,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				GetWallets: []interface{}{&wallets, nil},
+			},
+			ExpectedStatus: http.StatusOK,
+		},
 		{
 			Title:                  "ReturnsError_NoWalletsFound",
 			GivenUserIDWithContext: 1,
@@ -180,12 +192,13 @@ func TestWalletHandler_GetWallets(t *testing.T) {
 		t.Run(tc.Title, func(t *testing.T) {
 			walletUsecase := &mocks.WalletUsecase{}
 
-			walletUsecase.On("GetWallets", mock.Anything, mock.Anything).
+			// closed wallets are only asked for when the query says so
+			walletUsecase.On("GetWallets", mock.Anything, mock.Anything, dto.GetWalletsRequest{IncludeClosed: tc.ExpectedIncludeClosed}).
 				Return(tc.WalletUsecaseReturnValues.GetWallets...)
 
 			walletHandler := handlers.NewWalletHandler(walletUsecase)
 
-			req, err := http.NewRequest(http.MethodGet, "/api/v1/wallet/all", nil)
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/wallet/all"+tc.GivenQuery, nil)
 			require.NoError(t, err)
 
 			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
@@ -340,7 +353,17 @@ func TestWalletHandler_CreateWallet(t *testing.T) {
 				CreateWallet: []interface{}{exception.ErrWalletAlreadyExists},
 			},
 			ExpectedStatus:        http.StatusBadRequest,
-			ExpectedErrorResponse: `{"status":400,"message":"The wallet you are trying to create already exist. Please try again."}`,
+			ExpectedErrorResponse: `{"status":400,"message":"A wallet with this name already exists. Please choose another name."}`,
+		},
+		{
+			Title:                    "ReturnsError_WalletLimitReached",
+			GivenUserIDWithContext:   1,
+			GivenCreateWalletRequest: `{"wallet_type_id":1,"name":"Travel","currency_amount":[{"amount":100.0,"currency":"USD"}]}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				CreateWallet: []interface{}{exception.ErrWalletLimitReached},
+			},
+			ExpectedStatus:        http.StatusBadRequest,
+			ExpectedErrorResponse: `{"status":400,"message":"You have reached the maximum number of wallets. Please close a wallet before creating another."}`,
 		},
 		{
 			Title:                    "ReturnsError_InsufficientFunds",
@@ -512,6 +535,17 @@ func TestWalletHandler_UpdateWallet(t *testing.T) {
 			ExpectedStatus:        http.StatusBadRequest,
 			ExpectedErrorResponse: `{"status":400,"message":"Insufficient funds in account. Please top up."}`,
 		},
+		{
+			Title:                    "ReturnsError_TopUpWallet_ErrCurrencyDisabled",
+			GivenUserIDWithContext:   1,
+			GivenUpdateWalletRequest: `{"currency_amount":[{"amount":10.50,"currency":"USD"},{"amount":20.00,"currency":"MYR"}]}`,
+			GivenOperation:           "topup",
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				TopUpWallet: []interface{}{exception.ErrCurrencyDisabled},
+			},
+			ExpectedStatus:        http.StatusBadRequest,
+			ExpectedErrorResponse: `{"status":400,"message":"Currency is currently unavailable. Please try again later."}`,
+		},
 		{
 			Title:                    "ReturnsError_CashOutWallet_ErrInsufficientFundsForWithdrawal",
 			GivenUserIDWithContext:   1,
@@ -579,3 +613,623 @@ func TestWalletHandler_UpdateWallet(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletHandler_TransferBetweenWallets(t *testing.T) {
+	basicRequest := `{"from_wallet_id":1,"to_wallet_id":2,"currency_amount":[{"amount":10.0,"currency":"usd"}]}`
+
+	testCases := []struct {
+		Title                     string
+		GivenUserIDWithContext    int
+		GivenTransferRequest      string
+		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
+		ExpectedStatus            int
+		ExpectedResponse          string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			GivenTransferRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				TransferBetweenWallets: []interface{}{&dto.TransferWalletResponse{
+					FromWallet: dto.WalletBalances{WalletID: 1, CurrencyAmount: []dto.CurrencyAmount{{Amount: 4000, Currency: "USD"}}},
+					ToWallet:   dto.WalletBalances{WalletID: 2, CurrencyAmount: []dto.CurrencyAmount{{Amount: 1000, Currency: "USD"}}},
+				}, nil},
+			},
+			ExpectedStatus:   http.StatusOK,
+			ExpectedResponse: `{"from_wallet":{"wallet_id":1,"currency_amount":[{"amount":40.00,"currency":"USD"}]},"to_wallet":{"wallet_id":2,"currency_amount":[{"amount":10.00,"currency":"USD"}]}}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingCurrencyAmount",
+			GivenUserIDWithContext: 1,
+			GivenTransferRequest:   `{"from_wallet_id":1,"to_wallet_id":2}`,
+			ExpectedStatus:         http.StatusBadRequest,
+			ExpectedResponse:       `{"status":400,"message":"Key: 'TransferWalletRequest.CurrencyAmount' Error:Field validation for 'CurrencyAmount' failed on the 'required' tag"}`,
+		},
+		{
+			Title:                  "ReturnsError_SameWallet",
+			GivenUserIDWithContext: 1,
+			GivenTransferRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				TransferBetweenWallets: []interface{}{nil, exception.ErrWalletTransferSameWallet},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Unable to transfer as both wallets are the same. Please choose another wallet."}`,
+		},
+		{
+			Title:                  "ReturnsError_NoWalletFound",
+			GivenUserIDWithContext: 1,
+			GivenTransferRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				TransferBetweenWallets: []interface{}{nil, exception.ErrNoWalletFound},
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedResponse: `{"status":404,"message":"Wallet not found."}`,
+		},
+		{
+			Title:                  "ReturnsError_InsufficientFundsInWallet",
+			GivenUserIDWithContext: 1,
+			GivenTransferRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				TransferBetweenWallets: []interface{}{nil, exception.ErrInsufficientFundsInWallet},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Insufficient funds in the specified wallet. Please top up."}`,
+		},
+		{
+			Title:                  "ReturnsError_InternalServerError",
+			GivenUserIDWithContext: 1,
+			GivenTransferRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				TransferBetweenWallets: []interface{}{nil, errors.New("internal server error")},
+			},
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedResponse: `{"status":500,"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletUsecase := &mocks.WalletUsecase{}
+
+			// the usecase only ever sees sanitized currencies
+			walletUsecase.On("TransferBetweenWallets", mock.Anything, mock.Anything, mock.MatchedBy(func(req dto.TransferWalletRequest) bool {
+				return req.CurrencyAmount[0].Currency == "USD"
+			})).Return(tc.WalletUsecaseReturnValues.TransferBetweenWallets...)
+
+			walletHandler := handlers.NewWalletHandler(walletUsecase)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/wallet/transfer", strings.NewReader(tc.GivenTransferRequest))
+			require.NoError(t, err)
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			walletHandler.TransferBetweenWallets(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+		})
+	}
+}
+
+func TestWalletHandler_ExchangeWalletCurrency(t *testing.T) {
+	basicRequest := `{"from_currency":"sgd","to_currency":"usd","from_amount":100.0}`
+
+	testCases := []struct {
+		Title                     string
+		GivenUserIDWithContext    int
+		GivenExchangeRequest      string
+		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
+		ExpectedStatus            int
+		ExpectedResponse          string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			GivenExchangeRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				ExchangeWalletCurrency: []interface{}{&dto.WalletExchangeResponse{
+					WalletID:       1,
+					FromAmount:     10000,
+					FromCurrency:   "SGD",
+					ToAmount:       7599,
+					ToCurrency:     "USD",
+					Fee:            1,
+					Rate:           76000000,
+					CurrencyAmount: []dto.CurrencyAmount{{Amount: 7599, Currency: "USD"}},
+				}, nil},
+			},
+			ExpectedStatus:   http.StatusOK,
+			ExpectedResponse: `{"wallet_id":1,"from_amount":100.00,"from_currency":"SGD","to_amount":75.99,"to_currency":"USD","fee":0.01,"rate":0.76,"currency_amount":[{"amount":75.99,"currency":"USD"}]}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingFromAmount",
+			GivenUserIDWithContext: 1,
+			GivenExchangeRequest:   `{"from_currency":"sgd","to_currency":"usd"}`,
+			ExpectedStatus:         http.StatusBadRequest,
+			ExpectedResponse:       `{"status":400,"message":"Key: 'WalletExchangeRequest.FromAmount' Error:Field validation for 'FromAmount' failed on the 'required' tag"}`,
+		},
+		{
+			Title:                  "ReturnsError_InsufficientFundsInWallet",
+			GivenUserIDWithContext: 1,
+			GivenExchangeRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				ExchangeWalletCurrency: []interface{}{nil, exception.ErrInsufficientFundsInWallet},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Insufficient funds in the specified wallet. Please top up."}`,
+		},
+		{
+			Title:                  "ReturnsError_ExchangeRateNotFound",
+			GivenUserIDWithContext: 1,
+			GivenExchangeRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				ExchangeWalletCurrency: []interface{}{nil, &exception.NoExchangeRouteError{FromCurrency: "SGD", ToCurrency: "USD"}},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Currency exchange between the specified currencies is not supported."}`,
+		},
+		{
+			Title:                  "ReturnsError_InternalServerError",
+			GivenUserIDWithContext: 1,
+			GivenExchangeRequest:   basicRequest,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				ExchangeWalletCurrency: []interface{}{nil, errors.New("internal server error")},
+			},
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedResponse: `{"status":500,"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletUsecase := &mocks.WalletUsecase{}
+
+			// the usecase only ever sees sanitized currencies
+			walletUsecase.On("ExchangeWalletCurrency", mock.Anything, mock.Anything, 1, dto.WalletExchangeRequest{FromCurrency: "SGD", ToCurrency: "USD", FromAmount: 10000}).
+				Return(tc.WalletUsecaseReturnValues.ExchangeWalletCurrency...)
+
+			walletHandler := handlers.NewWalletHandler(walletUsecase)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/wallet/1/exchange", strings.NewReader(tc.GivenExchangeRequest))
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			walletHandler.ExchangeWalletCurrency(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+		})
+	}
+}
+
+func TestWalletHandler_RenameWallet(t *testing.T) {
+	testCases := []struct {
+		Title                     string
+		GivenUserIDWithContext    int
+		GivenRenameRequest        string
+		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
+		ExpectedStatus            int
+		ExpectedResponse          string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			GivenRenameRequest:     `{"name":" Holiday "}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				RenameWallet: []interface{}{nil},
+			},
+			ExpectedStatus: http.StatusNoContent,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingName",
+			GivenUserIDWithContext: 1,
+			GivenRenameRequest:     `{}`,
+			ExpectedStatus:         http.StatusBadRequest,
+			ExpectedResponse:       `{"status":400,"message":"Key: 'RenameWalletRequest.Name' Error:Field validation for 'Name' failed on the 'required' tag"}`,
+		},
+		{
+			Title:                  "ReturnsError_WalletClosed",
+			GivenUserIDWithContext: 1,
+			GivenRenameRequest:     `{"name":"Holiday"}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				RenameWallet: []interface{}{exception.ErrWalletClosed},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Wallet is closed."}`,
+		},
+		{
+			Title:                  "ReturnsError_NoWalletFound",
+			GivenUserIDWithContext: 1,
+			GivenRenameRequest:     `{"name":"Holiday"}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				RenameWallet: []interface{}{exception.ErrNoWalletFound},
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedResponse: `{"status":404,"message":"Wallet not found."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletUsecase := &mocks.WalletUsecase{}
+
+			// the usecase only ever sees a trimmed name
+			walletUsecase.On("RenameWallet", mock.Anything, mock.Anything, 1, dto.RenameWalletRequest{Name: "Holiday"}).
+				Return(tc.WalletUsecaseReturnValues.RenameWallet...)
+
+			walletHandler := handlers.NewWalletHandler(walletUsecase)
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/wallet/1/name", strings.NewReader(tc.GivenRenameRequest))
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			walletHandler.RenameWallet(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+		})
+	}
+}
+
+func TestWalletHandler_ArchiveAndRestoreWallet(t *testing.T) {
+	testCases := []struct {
+		Title                     string
+		GivenOperation            string
+		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
+		ExpectedStatus            int
+		ExpectedResponse          string
+	}{
+		{
+			Title:          "ReturnsSuccessfully_ArchiveWallet",
+			GivenOperation: "archive",
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				ArchiveWallet: []interface{}{nil},
+			},
+			ExpectedStatus: http.StatusNoContent,
+		},
+		{
+			Title:          "ReturnsSuccessfully_RestoreWallet",
+			GivenOperation: "restore",
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				RestoreWallet: []interface{}{nil},
+			},
+			ExpectedStatus: http.StatusNoContent,
+		},
+		{
+			Title:          "ReturnsError_ArchiveWallet_WalletArchived",
+			GivenOperation: "archive",
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				ArchiveWallet: []interface{}{exception.ErrWalletArchived},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Wallet is archived. Please restore the wallet before using it."}`,
+		},
+		{
+			Title:          "ReturnsError_RestoreWallet_WalletNotArchived",
+			GivenOperation: "restore",
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				RestoreWallet: []interface{}{exception.ErrWalletNotArchived},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Wallet is not archived."}`,
+		},
+		{
+			Title:          "ReturnsError_RestoreWallet_InternalServerError",
+			GivenOperation: "restore",
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				RestoreWallet: []interface{}{errors.New("internal server error")},
+			},
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedResponse: `{"status":500,"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletUsecase := &mocks.WalletUsecase{}
+
+			walletUsecase.On("ArchiveWallet", mock.Anything, 1, 1).
+				Return(tc.WalletUsecaseReturnValues.ArchiveWallet...)
+			walletUsecase.On("RestoreWallet", mock.Anything, 1, 1).
+				Return(tc.WalletUsecaseReturnValues.RestoreWallet...)
+
+			walletHandler := handlers.NewWalletHandler(walletUsecase)
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/wallet/1/"+tc.GivenOperation, nil)
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), 1)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			if tc.GivenOperation == "archive" {
+				walletHandler.ArchiveWallet(rr, req)
+			} else {
+				walletHandler.RestoreWallet(rr, req)
+			}
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+		})
+	}
+}
+
+func TestWalletHandler_CloseWallet(t *testing.T) {
+	testCases := []struct {
+		Title                     string
+		GivenUserIDWithContext    int
+		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
+		ExpectedStatus            int
+		ExpectedResponse          string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				CloseWallet: []interface{}{&dto.CloseWalletResponse{
+					WalletID:    1,
+					Status:      domain.WalletStatusClosed,
+					SweptAmount: []dto.CurrencyAmount{{Amount: 10000, Currency: "SGD"}},
+				}, nil},
+			},
+			ExpectedStatus:   http.StatusOK,
+			ExpectedResponse: `{"wallet_id":1,"status":"closed","swept_amount":[{"amount":100.00,"currency":"SGD"}]}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_NoWalletFound",
+			GivenUserIDWithContext: 1,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				CloseWallet: []interface{}{nil, exception.ErrNoWalletFound},
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedResponse: `{"status":404,"message":"Wallet not found."}`,
+		},
+		{
+			Title:                  "ReturnsError_WalletClosed",
+			GivenUserIDWithContext: 1,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				CloseWallet: []interface{}{nil, exception.ErrWalletClosed},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Wallet is closed."}`,
+		},
+		{
+			Title:                  "ReturnsError_InternalServerError",
+			GivenUserIDWithContext: 1,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				CloseWallet: []interface{}{nil, errors.New("internal server error")},
+			},
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedResponse: `{"status":500,"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletUsecase := &mocks.WalletUsecase{}
+
+			walletUsecase.On("CloseWallet", mock.Anything, 1, 1).
+				Return(tc.WalletUsecaseReturnValues.CloseWallet...)
+
+			walletHandler := handlers.NewWalletHandler(walletUsecase)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/wallet/1/close", nil)
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			walletHandler.CloseWallet(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+		})
+	}
+}
+
+func TestWalletHandler_SetWalletGoal(t *testing.T) {
+	testCases := []struct {
+		Title                     string
+		GivenUserIDWithContext    int
+		GivenRequest              string
+		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
+		ExpectedStatus            int
+		ExpectedResponse          string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"target_amount":400,"currency":"sgd"}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				SetWalletGoal: []interface{}{&domain.WalletGoal{
+					ID:           1,
+					WalletID:     1,
+					UserID:       1,
+					TargetAmount: 40000,
+					Currency:     "SGD",
+					CreatedAt:    "2024-06-15T13:45:00Z",
+					Progress: &domain.WalletGoalProgress{
+						SavedAmount:     10000,
+						RemainingAmount: 30000,
+						Percentage:      2500000000,
+					},
+				}, nil},
+			},
+			ExpectedStatus:   http.StatusOK,
+			ExpectedResponse: `{"id":1,"walletID":1,"userID":1,"targetAmount":400.00,"currency":"SGD","createdAt":"2024-06-15T13:45:00Z","progress":{"savedAmount":100.00,"remainingAmount":300.00,"percentage":25,"reached":false}}`,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			GivenRequest:           `{"target_amount":400,"currency":"SGD"}`,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_InvalidDeadline",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"target_amount":400,"currency":"SGD","deadline":"31/12/2026"}`,
+			ExpectedStatus:         http.StatusBadRequest,
+		},
+		{
+			Title:                  "ReturnsError_DeadlinePassed",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"target_amount":400,"currency":"SGD","deadline":"2020-01-01"}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				SetWalletGoal: []interface{}{nil, exception.ErrWalletGoalDeadlinePassed},
+			},
+			ExpectedStatus:   http.StatusBadRequest,
+			ExpectedResponse: `{"status":400,"message":"Savings goal deadline has passed. Please choose a later date."}`,
+		},
+		{
+			Title:                  "ReturnsError_NoWalletFound",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"target_amount":400,"currency":"SGD"}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				SetWalletGoal: []interface{}{nil, exception.ErrNoWalletFound},
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedResponse: `{"status":404,"message":"Wallet not found."}`,
+		},
+		{
+			Title:                  "ReturnsError_InternalServerError",
+			GivenUserIDWithContext: 1,
+			GivenRequest:           `{"target_amount":400,"currency":"SGD"}`,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				SetWalletGoal: []interface{}{nil, errors.New("internal server error")},
+			},
+			ExpectedStatus:   http.StatusInternalServerError,
+			ExpectedResponse: `{"status":500,"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletUsecase := &mocks.WalletUsecase{}
+
+			walletUsecase.On("SetWalletGoal", mock.Anything, 1, 1, mock.MatchedBy(func(req dto.SetWalletGoalRequest) bool {
+				return req.Currency == "SGD"
+			})).
+				Return(tc.WalletUsecaseReturnValues.SetWalletGoal...)
+
+			walletHandler := handlers.NewWalletHandler(walletUsecase)
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/wallet/1/goal", strings.NewReader(tc.GivenRequest))
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			walletHandler.SetWalletGoal(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			if tc.ExpectedResponse != "" {
+				require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+			}
+		})
+	}
+}
+
+func TestWalletHandler_DeleteWalletGoal(t *testing.T) {
+	testCases := []struct {
+		Title                     string
+		GivenUserIDWithContext    int
+		WalletUsecaseReturnValues mocks.WalletUsecaseReturnValues
+		ExpectedStatus            int
+		ExpectedResponse          string
+	}{
+		{
+			Title:                  "ReturnsSuccessfully",
+			GivenUserIDWithContext: 1,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				DeleteWalletGoal: []interface{}{nil},
+			},
+			ExpectedStatus: http.StatusNoContent,
+		},
+		{
+			Title:                  "ReturnsError_MissingUserIDFromContext",
+			GivenUserIDWithContext: 0,
+			ExpectedStatus:         http.StatusUnauthorized,
+			ExpectedResponse:       `{"status":401,"message":"Unauthorized"}`,
+		},
+		{
+			Title:                  "ReturnsError_WalletGoalNotFound",
+			GivenUserIDWithContext: 1,
+			WalletUsecaseReturnValues: mocks.WalletUsecaseReturnValues{
+				DeleteWalletGoal: []interface{}{exception.ErrWalletGoalNotFound},
+			},
+			ExpectedStatus:   http.StatusNotFound,
+			ExpectedResponse: `{"status":404,"message":"Savings goal not found."}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletUsecase := &mocks.WalletUsecase{}
+
+			walletUsecase.On("DeleteWalletGoal", mock.Anything, 1, 1).
+				Return(tc.WalletUsecaseReturnValues.DeleteWalletGoal...)
+
+			walletHandler := handlers.NewWalletHandler(walletUsecase)
+
+			req, err := http.NewRequest(http.MethodDelete, "/api/v1/wallet/1/goal", nil)
+			require.NoError(t, err)
+			req = mux.SetURLVars(req, map[string]string{"id": "1"})
+
+			ctx := testdata.InjectUserIDIntoContext(req.Context(), tc.GivenUserIDWithContext)
+			req = req.WithContext(ctx)
+
+			rr := httptest.NewRecorder()
+
+			walletHandler.DeleteWalletGoal(rr, req)
+
+			require.Equal(t, tc.ExpectedStatus, rr.Code)
+			require.Equal(t, tc.ExpectedResponse, strings.TrimSpace(rr.Body.String()))
+		})
+	}
+}
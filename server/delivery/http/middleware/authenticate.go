@@ -26,6 +26,7 @@ var SkippedAuthenticationEndpoints = map[string]struct{}{
 	"/api/v1/health":               {},
 	"/api/v1/configure-mfa":        {},
 	"/api/v1/verify-mfa":           {},
+	"/api/v1/currencies":           {},
 }
 
 type AuthenticationMiddleware struct {
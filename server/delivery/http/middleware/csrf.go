@@ -19,6 +19,7 @@ var SkippedCSRFEndpoints = map[string]struct{}{
 	"/api/v1/health":               {},
 	"/api/v1/configure-mfa":        {},
 	"/api/v1/verify-mfa":           {},
+	"/api/v1/currencies":           {},
 }
 
 type CSRFMiddleware struct {
@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	apiErr "github.com/LeonLow97/go-clean-architecture/exception/response"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants/headers"
+	"github.com/LeonLow97/go-clean-architecture/utils/contextstore"
+	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotentEndpoints is a set of money-moving route templates that honour the Idempotency-Key header
+var IdempotentEndpoints = map[string]struct{}{
+	"/api/v1/transaction":                               {},
+	"/api/v1/transaction/{id:[0-9]+}/refund":            {},
+	"/api/v1/transaction/refund/{id:[0-9]+}/{decision}": {},
+	"/api/v1/transaction/scheduled":                     {},
+	"/api/v1/transaction/request/{id:[0-9]+}/pay":       {},
+	"/api/v1/balances/deposit":                          {},
+	"/api/v1/balances/withdraw":                         {},
+	"/api/v1/balances/currency-exchange":                {},
+	"/api/v1/wallet":                                    {},
+	"/api/v1/wallet/transfer":                           {},
+	"/api/v1/wallet/{id:[0-9]+}/exchange":               {},
+	"/api/v1/wallet/update/{id:[0-9]+}/{operation}":     {},
+	"/api/v1/wallet/{id:[0-9]+}/close":                  {},
+}
+
+const maxIdempotencyKeyLength = 255
+
+const (
+	idempotencyStatusProcessing = "processing"
+	idempotencyStatusCompleted  = "completed"
+)
+
+// idempotencyRecord is what gets stored in redis against an Idempotency-Key
+type idempotencyRecord struct {
+	Status      string `json:"status"`
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type IdempotencyMiddleware struct {
+	cfg         infrastructure.Config
+	redisClient infrastructure.RedisClient
+}
+
+func NewIdempotencyMiddleware(cfg infrastructure.Config, redisClient infrastructure.RedisClient) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		cfg:         cfg,
+		redisClient: redisClient,
+	}
+}
+
+func (m IdempotencyMiddleware) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || !isIdempotentEndpoint(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// the header is opt-in, requests without it behave as before
+		idempotencyKey := r.Header.Get(headers.IdempotencyKey)
+		if len(idempotencyKey) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			jsonutil.ErrorJSON(w, apiErr.ErrIdempotencyKeyInvalid, http.StatusBadRequest)
+			return
+		}
+
+		ctx := r.Context()
+
+		// keys are scoped per user so that two users cannot collide on the same key
+		userID, err := contextstore.UserIDFromContext(ctx)
+		if err != nil {
+			jsonutil.ErrorJSON(w, apiErr.ErrUnauthorized, http.StatusUnauthorized)
+			return
+		}
+		redisKey := fmt.Sprintf("idempotency:%d:%s", userID, idempotencyKey)
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Println("failed to read request body for idempotency fingerprint", err)
+			jsonutil.ErrorJSON(w, apiErr.ErrBadRequest, http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := requestFingerprint(r, body)
+
+		// claim the key, only one request can hold it at a time
+		pending, _ := json.Marshal(idempotencyRecord{Status: idempotencyStatusProcessing, Fingerprint: fingerprint})
+		acquired, err := m.redisClient.SetNX(ctx, redisKey, pending, constants.IDEMPOTENCY_KEY_EXPIRY)
+		if err != nil {
+			log.Println("failed to claim idempotency key with SetNX redis client", err)
+			jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+			return
+		}
+
+		if !acquired {
+			m.replay(w, r, redisKey, fingerprint)
+			return
+		}
+
+		// a panicking handler would otherwise leave the key processing until it expires
+		defer func() {
+			if p := recover(); p != nil {
+				m.release(ctx, redisKey)
+				panic(p)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// server errors are not cached so that the client can retry with the same key
+		if recorder.statusCode >= http.StatusInternalServerError {
+			m.release(ctx, redisKey)
+			return
+		}
+
+		completed, _ := json.Marshal(idempotencyRecord{
+			Status:      idempotencyStatusCompleted,
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get(headers.ContentType),
+			Body:        recorder.body.Bytes(),
+		})
+		if err := m.redisClient.SetEx(ctx, redisKey, completed, constants.IDEMPOTENCY_KEY_EXPIRY); err != nil {
+			log.Println("failed to store idempotent response with SetEx redis client", err)
+		}
+	})
+}
+
+// release deletes a claimed key so that the request can be retried with it
+func (m IdempotencyMiddleware) release(ctx context.Context, redisKey string) {
+	if err := m.redisClient.Del(ctx, redisKey); err != nil {
+		log.Println("failed to release idempotency key with Del redis client", err)
+	}
+}
+
+// replay writes the stored response for a key that has been seen before
+func (m IdempotencyMiddleware) replay(w http.ResponseWriter, r *http.Request, redisKey, fingerprint string) {
+	stored, err := m.redisClient.Get(r.Context(), redisKey)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// the key expired or was released between SetNX and Get
+			jsonutil.ErrorJSON(w, apiErr.ErrIdempotencyKeyInProgress, http.StatusConflict)
+			return
+		}
+		log.Println("failed to retrieve idempotent response with Get redis client", err)
+		jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(stored), &record); err != nil {
+		log.Println("failed to unmarshal idempotent response", err)
+		jsonutil.ErrorJSON(w, apiErr.ErrInternalServerError, http.StatusInternalServerError)
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		jsonutil.ErrorJSON(w, apiErr.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity)
+		return
+	}
+
+	if record.Status != idempotencyStatusCompleted {
+		jsonutil.ErrorJSON(w, apiErr.ErrIdempotencyKeyInProgress, http.StatusConflict)
+		return
+	}
+
+	if record.ContentType != "" {
+		w.Header().Set(headers.ContentType, record.ContentType)
+	}
+	w.Header().Set(headers.IdempotentReplayed, "true")
+	w.WriteHeader(record.StatusCode)
+	w.Write(record.Body)
+}
+
+func isIdempotentEndpoint(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+
+	pathTemplate, err := route.GetPathTemplate()
+	if err != nil {
+		return false
+	}
+
+	_, exists := IdempotentEndpoints[pathTemplate]
+	return exists
+}
+
+// requestFingerprint identifies a request by its method, path and body
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(r.URL.Path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(statusCode int) {
+	if !rr.wroteHeader {
+		rr.statusCode = statusCode
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
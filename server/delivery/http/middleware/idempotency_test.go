@@ -0,0 +1,213 @@
+package middleware_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/delivery/http/middleware"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants/headers"
+	"github.com/LeonLow97/go-clean-architecture/utils/contextstore"
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedisClient is an in-memory RedisClient that only supports plain string keys
+type fakeRedisClient struct {
+	infrastructure.RedisClient
+
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{values: make(map[string]string)}
+}
+
+func (f *fakeRedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.values[key]; exists {
+		return false, nil
+	}
+	f.values[key] = string(value.([]byte))
+	return true, nil
+}
+
+func (f *fakeRedisClient) SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.values[key] = string(value.([]byte))
+	return nil
+}
+
+func (f *fakeRedisClient) Get(ctx context.Context, key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, exists := f.values[key]
+	if !exists {
+		return "", redis.Nil
+	}
+	return v, nil
+}
+
+func (f *fakeRedisClient) Del(ctx context.Context, keys ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, key := range keys {
+		delete(f.values, key)
+	}
+	return nil
+}
+
+// newIdempotentRouter counts how many times the wrapped handler actually runs
+func newIdempotentRouter(redisClient infrastructure.RedisClient, status int, calls *int) *mux.Router {
+	router := mux.NewRouter().PathPrefix("/api/v1").Subrouter()
+	router.Use(
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(contextstore.UserIDWithContext(r.Context(), 1)))
+			})
+		},
+		middleware.NewIdempotencyMiddleware(infrastructure.Config{}, redisClient).Middleware,
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set(headers.ContentType, "application/json")
+		w.WriteHeader(status)
+		w.Write(body)
+	}
+	router.HandleFunc("/balances/deposit", handler).Methods(http.MethodPost)
+	router.HandleFunc("/beneficiary", handler).Methods(http.MethodPost)
+
+	return router
+}
+
+func sendRequest(router http.Handler, path, idempotencyKey, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if idempotencyKey != "" {
+		req.Header.Set(headers.IdempotencyKey, idempotencyKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyMiddleware_ReplaysStoredResponse(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(newFakeRedisClient(), http.StatusCreated, &calls)
+
+	first := sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+	second := sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+
+	require.Equal(t, 1, calls, "handler should only run once for the same key")
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Equal(t, http.StatusCreated, second.Code)
+	require.Equal(t, first.Body.String(), second.Body.String())
+	require.Equal(t, "application/json", second.Header().Get(headers.ContentType))
+	require.Equal(t, "true", second.Header().Get(headers.IdempotentReplayed))
+	require.Empty(t, first.Header().Get(headers.IdempotentReplayed))
+}
+
+func TestIdempotencyMiddleware_RejectsReusedKeyWithDifferentBody(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(newFakeRedisClient(), http.StatusCreated, &calls)
+
+	sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+	w := sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":20}`)
+
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestIdempotencyMiddleware_RejectsKeyStillProcessing(t *testing.T) {
+	redisClient := newFakeRedisClient()
+	var calls int
+	router := newIdempotentRouter(redisClient, http.StatusCreated, &calls)
+
+	// simulate a first request that claimed the key and has not finished yet
+	first := sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	for key, value := range redisClient.values {
+		redisClient.values[key] = strings.Replace(value, `"completed"`, `"processing"`, 1)
+	}
+
+	w := sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+	require.Equal(t, 1, calls)
+	require.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestIdempotencyMiddleware_ReleasesKeyOnServerError(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(newFakeRedisClient(), http.StatusInternalServerError, &calls)
+
+	sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+	sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+
+	require.Equal(t, 2, calls, "a failed request should be retryable with the same key")
+}
+
+func TestIdempotencyMiddleware_ReleasesKeyOnPanic(t *testing.T) {
+	var calls int
+	router := mux.NewRouter().PathPrefix("/api/v1").Subrouter()
+	router.Use(
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				next.ServeHTTP(w, r.WithContext(contextstore.UserIDWithContext(r.Context(), 1)))
+			})
+		},
+		middleware.NewIdempotencyMiddleware(infrastructure.Config{}, newFakeRedisClient()).Middleware,
+	)
+	router.HandleFunc("/balances/deposit", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("handler panicked")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}).Methods(http.MethodPost)
+
+	require.PanicsWithValue(t, "handler panicked", func() {
+		sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+	}, "the panic should still reach the recovery middleware")
+
+	w := sendRequest(router, "/api/v1/balances/deposit", "key-1", `{"amount":10}`)
+	require.Equal(t, 2, calls, "a panicked request should be retryable with the same key")
+	require.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestIdempotencyMiddleware_PassesThrough(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(newFakeRedisClient(), http.StatusOK, &calls)
+
+	// without a key
+	sendRequest(router, "/api/v1/balances/deposit", "", `{"amount":10}`)
+	sendRequest(router, "/api/v1/balances/deposit", "", `{"amount":10}`)
+	require.Equal(t, 2, calls)
+
+	// on an endpoint that does not move money
+	sendRequest(router, "/api/v1/beneficiary", "key-1", `{}`)
+	sendRequest(router, "/api/v1/beneficiary", "key-1", `{}`)
+	require.Equal(t, 4, calls)
+}
+
+func TestIdempotencyMiddleware_RejectsLongKey(t *testing.T) {
+	var calls int
+	router := newIdempotentRouter(newFakeRedisClient(), http.StatusOK, &calls)
+
+	w := sendRequest(router, "/api/v1/balances/deposit", strings.Repeat("k", 256), `{}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, 0, calls)
+}
@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job is work the scheduler runs on every tick. Run is given the time of the tick so that
+// everything a tick does agrees on what is due.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context, now time.Time) error
+}
+
+// Scheduler runs its jobs one after the other every interval, in the order they were given.
+// A job that fails is logged and run again on the next tick, it does not stop the others.
+type Scheduler struct {
+	interval time.Duration
+	jobs     []Job
+}
+
+func New(interval time.Duration, jobs ...Job) *Scheduler {
+	return &Scheduler{
+		interval: interval,
+		jobs:     jobs,
+	}
+}
+
+// Start runs the jobs straight away and then on every tick until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.tick(ctx, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.tick(ctx, now)
+		}
+	}
+}
+
+func (s *Scheduler) tick(ctx context.Context, now time.Time) {
+	for _, job := range s.jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := job.Run(ctx, now); err != nil {
+			log.Printf("scheduled job %s failed with error: %v\n", job.Name, err)
+		}
+	}
+}
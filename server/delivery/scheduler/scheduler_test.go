@@ -0,0 +1,48 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/delivery/scheduler"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_Start(t *testing.T) {
+	t.Run("ReturnsSuccessfully_RunsEveryJobUntilStopped", func(t *testing.T) {
+		var failingRuns, runs int32
+		ctx, cancel := context.WithCancel(context.Background())
+
+		s := scheduler.New(5*time.Millisecond,
+			scheduler.Job{Name: "failing", Run: func(ctx context.Context, now time.Time) error {
+				atomic.AddInt32(&failingRuns, 1)
+				return errors.New("job failed")
+			}},
+			scheduler.Job{Name: "counting", Run: func(ctx context.Context, now time.Time) error {
+				if atomic.AddInt32(&runs, 1) == 3 {
+					cancel()
+				}
+				return nil
+			}},
+		)
+
+		done := make(chan struct{})
+		go func() {
+			s.Start(ctx)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not stop once its context was done")
+		}
+
+		// a failing job does not keep the jobs after it from running
+		require.Equal(t, int32(3), atomic.LoadInt32(&runs))
+		require.Equal(t, int32(3), atomic.LoadInt32(&failingRuns))
+	})
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// Auto-save rule types
+const (
+	// AutoSaveTypeSchedule moves a fixed amount into the wallet on every run
+	AutoSaveTypeSchedule = "schedule"
+	// AutoSaveTypeRoundUp moves the round ups of the transfers sent since the last run
+	AutoSaveTypeRoundUp = "round_up"
+)
+
+// Auto-save rule statuses. A paused rule is skipped by the scheduler until the user resumes it.
+const (
+	AutoSaveStatusActive = "active"
+	AutoSaveStatusPaused = "paused"
+)
+
+// Auto-save run statuses
+const (
+	AutoSaveRunSucceeded = "succeeded"
+	AutoSaveRunFailed    = "failed"
+	// AutoSaveRunSkipped is a round up run with no transfers to round up
+	AutoSaveRunSkipped = "skipped"
+)
+
+// AutoSaveRule moves money from the main balance into a wallet on a schedule. LastTransactionID
+// is how far round up rules have got through the user's transfers, the next run starts after it.
+type AutoSaveRule struct {
+	ID                  int          `json:"id" db:"id"`
+	UserID              int          `json:"user_id" db:"user_id"`
+	WalletID            int          `json:"wallet_id" db:"wallet_id"`
+	Type                string       `json:"type" db:"type"`
+	Amount              money.Amount `json:"amount" db:"amount"`
+	Currency            string       `json:"currency" db:"currency"`
+	Frequency           string       `json:"frequency" db:"frequency"`
+	StartAt             time.Time    `json:"start_at" db:"start_at"`
+	NextRunAt           time.Time    `json:"next_run_at" db:"next_run_at"`
+	Status              string       `json:"status" db:"status"`
+	ConsecutiveFailures int          `json:"consecutive_failures" db:"consecutive_failures"`
+	LastTransactionID   int          `json:"-" db:"last_transaction_id"`
+	CreatedAt           time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt           string       `json:"updated_at" db:"updated_at"`
+}
+
+// AutoSaveRun is the outcome of one run of a rule. FailureReason is one of the transaction
+// reason codes, recorded when the run failed.
+type AutoSaveRun struct {
+	ID            int          `json:"id" db:"id"`
+	RuleID        int          `json:"rule_id" db:"rule_id"`
+	UserID        int          `json:"user_id" db:"user_id"`
+	WalletID      int          `json:"wallet_id" db:"wallet_id"`
+	Amount        money.Amount `json:"amount" db:"amount"`
+	Currency      string       `json:"currency" db:"currency"`
+	Status        string       `json:"status" db:"status"`
+	FailureReason string       `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt     string       `json:"created_at" db:"created_at"`
+}
+
+// NextRun returns the first run of the rule after now, runs missed while the rule was paused
+// or the scheduler was down are skipped rather than caught up on
+func (r AutoSaveRule) NextRun(now time.Time) time.Time {
+	return nextRun(r.Frequency, r.StartAt, now)
+}
+
+// RoundUp returns what takes amount up to the next whole unit of its currency, zero when it is
+// already a whole amount
+func RoundUp(amount money.Amount) money.Amount {
+	unit := money.FromInt(1)
+	if remainder := amount % unit; remainder.IsPositive() {
+		return unit - remainder
+	}
+	return 0
+}
+
+type AutoSaveUsecase interface {
+	CreateAutoSaveRule(ctx context.Context, userID int, req dto.CreateAutoSaveRuleRequest) (*AutoSaveRule, error)
+	GetAutoSaveRules(ctx context.Context, userID int) ([]AutoSaveRule, error)
+	GetAutoSaveRuns(ctx context.Context, userID, ruleID int) ([]AutoSaveRun, error)
+	PauseAutoSaveRule(ctx context.Context, userID, ruleID int) (*AutoSaveRule, error)
+	ResumeAutoSaveRule(ctx context.Context, userID, ruleID int) (*AutoSaveRule, error)
+	DeleteAutoSaveRule(ctx context.Context, userID, ruleID int) error
+
+	// RunDueAutoSaveRules runs the active rules that are due at now, it is called by the scheduler
+	RunDueAutoSaveRules(ctx context.Context, now time.Time) error
+}
+
+type AutoSaveRepository interface {
+	CreateAutoSaveRule(ctx context.Context, rule *AutoSaveRule) error
+	GetAutoSaveRules(ctx context.Context, userID int) ([]AutoSaveRule, error)
+	GetAutoSaveRule(ctx context.Context, tx *sqlx.Tx, userID, ruleID int) (*AutoSaveRule, error)
+	GetDueAutoSaveRules(ctx context.Context, now time.Time, limit int) ([]AutoSaveRule, error)
+	LockDueAutoSaveRule(ctx context.Context, tx *sqlx.Tx, ruleID int, now time.Time) (*AutoSaveRule, error)
+	UpdateAutoSaveRule(ctx context.Context, tx *sqlx.Tx, rule AutoSaveRule) error
+	DeleteAutoSaveRule(ctx context.Context, userID, ruleID int) error
+
+	CreateAutoSaveRun(ctx context.Context, tx *sqlx.Tx, run *AutoSaveRun) error
+	GetAutoSaveRuns(ctx context.Context, userID, ruleID int) ([]AutoSaveRun, error)
+}
@@ -0,0 +1,91 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoSaveRule_NextRun(t *testing.T) {
+	// a Monday
+	startAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Title           string
+		GivenFrequency  string
+		GivenStartAt    time.Time
+		GivenNow        time.Time
+		ExpectedNextRun time.Time
+	}{
+		{
+			Title:           "ReturnsSuccessfully_BeforeStart",
+			GivenFrequency:  domain.FrequencyWeekly,
+			GivenStartAt:    startAt,
+			GivenNow:        startAt.Add(-time.Hour),
+			ExpectedNextRun: startAt,
+		},
+		{
+			Title:           "ReturnsSuccessfully_Daily",
+			GivenFrequency:  domain.FrequencyDaily,
+			GivenStartAt:    startAt,
+			GivenNow:        startAt,
+			ExpectedNextRun: time.Date(2026, time.January, 6, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:           "ReturnsSuccessfully_WeeklyStaysOnTheSameDay",
+			GivenFrequency:  domain.FrequencyWeekly,
+			GivenStartAt:    startAt,
+			GivenNow:        time.Date(2026, time.January, 14, 12, 0, 0, 0, time.UTC),
+			ExpectedNextRun: time.Date(2026, time.January, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:           "ReturnsSuccessfully_SkipsMissedRuns",
+			GivenFrequency:  domain.FrequencyDaily,
+			GivenStartAt:    startAt,
+			GivenNow:        time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC),
+			ExpectedNextRun: time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:           "ReturnsSuccessfully_MonthlyOnTheLastDayOfShorterMonths",
+			GivenFrequency:  domain.FrequencyMonthly,
+			GivenStartAt:    time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC),
+			GivenNow:        time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC),
+			ExpectedNextRun: time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			Title:           "ReturnsSuccessfully_MonthlyBackOnItsDay",
+			GivenFrequency:  domain.FrequencyMonthly,
+			GivenStartAt:    time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC),
+			GivenNow:        time.Date(2026, time.February, 28, 9, 0, 0, 0, time.UTC),
+			ExpectedNextRun: time.Date(2026, time.March, 31, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			rule := domain.AutoSaveRule{Frequency: tc.GivenFrequency, StartAt: tc.GivenStartAt}
+			require.Equal(t, tc.ExpectedNextRun, rule.NextRun(tc.GivenNow))
+		})
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	testCases := []struct {
+		Title    string
+		Amount   money.Amount
+		Expected money.Amount
+	}{
+		{Title: "ReturnsSuccessfully_Cents", Amount: money.MustParse("12.30"), Expected: money.MustParse("0.70")},
+		{Title: "ReturnsSuccessfully_OneCent", Amount: money.MustParse("4.99"), Expected: money.MustParse("0.01")},
+		{Title: "ReturnsSuccessfully_WholeAmount", Amount: money.MustParse("20.00"), Expected: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			require.Equal(t, tc.Expected, domain.RoundUp(tc.Amount))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+)
+
+// Currency is an ISO 4217 currency the platform knows about. Disabled currencies are kept
+// so that existing balances still display, but no new money can move in them.
+type Currency struct {
+	Code     string `json:"code" db:"code"`
+	Name     string `json:"name" db:"name"`
+	Symbol   string `json:"symbol" db:"symbol"`
+	Exponent int    `json:"exponent" db:"exponent"`
+	Enabled  bool   `json:"enabled" db:"enabled"`
+}
+
+// CountryCurrency is the home currency of users registered with a mobile country code
+type CountryCurrency struct {
+	MobileCountryCode string `json:"mobile_country_code" db:"mobile_country_code"`
+	Currency          string `json:"currency" db:"currency"`
+}
+
+type CurrencyUsecase interface {
+	GetCurrencies(ctx context.Context) (*dto.GetCurrenciesResponse, error)
+}
+
+type CurrencyRepository interface {
+	GetCurrencies(ctx context.Context) ([]Currency, error)
+	GetCountryCurrencies(ctx context.Context) ([]CountryCurrency, error)
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// ExchangeQuote locks an exchange rate, the fees and the amounts worked out with them until
+// ExpiresAt, so that a user is charged what they were shown in the preview. A quote can only be
+// used once, for the product it was previewed for.
+type ExchangeQuote struct {
+	ID           string       `json:"id"`
+	UserID       int          `json:"user_id"`
+	Product      string       `json:"product"`
+	FromCurrency string       `json:"from_currency"`
+	ToCurrency   string       `json:"to_currency"`
+	Rate         money.Rate   `json:"rate"`
+	Spread       money.Rate   `json:"spread"`
+	Path         []string     `json:"path"`
+	EffectiveAt  time.Time    `json:"effective_at"`
+	FromAmount   money.Amount `json:"from_amount"`
+	ToAmount     money.Amount `json:"to_amount"`
+	Profit       money.Amount `json:"profit"`
+	Fees         []FeeCharge  `json:"fees"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+}
+
+// ExchangeRate returns the rate locked in by the quote
+func (q ExchangeQuote) ExchangeRate() ExchangeRate {
+	return ExchangeRate{
+		FromCurrency: q.FromCurrency,
+		ToCurrency:   q.ToCurrency,
+		Rate:         q.Rate,
+		Spread:       q.Spread,
+		Path:         q.Path,
+		EffectiveAt:  q.EffectiveAt,
+	}
+}
+
+type ExchangeQuoteRepository interface {
+	CreateExchangeQuote(ctx context.Context, quote ExchangeQuote, ttl time.Duration) error
+	GetExchangeQuote(ctx context.Context, quoteID string) (*ExchangeQuote, error)
+	ClaimExchangeQuote(ctx context.Context, quoteID string, ttl time.Duration) error
+	ReleaseExchangeQuote(ctx context.Context, quoteID string) error
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// ExchangeRate is what one unit of FromCurrency converts into in ToCurrency, and the spread
+// the platform takes on the pair. It applies from EffectiveAt until a newer rate takes over.
+// Path lists the currencies the conversion goes through, from FromCurrency to ToCurrency.
+type ExchangeRate struct {
+	FromCurrency string     `json:"from_currency" db:"from_currency"`
+	ToCurrency   string     `json:"to_currency" db:"to_currency"`
+	Rate         money.Rate `json:"rate" db:"rate"`
+	Spread       money.Rate `json:"spread" db:"spread"`
+	EffectiveAt  time.Time  `json:"effective_at" db:"effective_at"`
+	Path         []string   `json:"path,omitempty" db:"-"`
+}
+
+// ConversionPath returns the currencies the conversion goes through, a direct rate goes straight across
+func (r ExchangeRate) ConversionPath() []string {
+	if len(r.Path) == 0 {
+		return []string{r.FromCurrency, r.ToCurrency}
+	}
+	return r.Path
+}
+
+// RateSource returns whether the rate is stored for the pair or derived through another currency
+func (r ExchangeRate) RateSource() string {
+	if len(r.ConversionPath()) > 2 {
+		return FXRateSourceCross
+	}
+	return FXRateSourceDirect
+}
+
+type ExchangeRateUsecase interface {
+	GetExchangeRateHistory(ctx context.Context, req dto.GetExchangeRateHistoryRequest) (*dto.GetExchangeRateHistoryResponse, error)
+}
+
+// ExchangeRateProvider looks up the rate currently in effect for a currency pair
+type ExchangeRateProvider interface {
+	GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (*ExchangeRate, error)
+}
+
+type ExchangeRateRepository interface {
+	ExchangeRateProvider
+	GetExchangeRateHistory(ctx context.Context, fromCurrency, toCurrency string, since time.Time) ([]ExchangeRate, error)
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// Products a fee rule can price
+const (
+	FeeProductExchange   = "exchange"
+	FeeProductTransfer   = "transfer"
+	FeeProductWithdrawal = "withdrawal"
+)
+
+// Fee line item types, a single charge can be made up of several of them
+const (
+	// FeeTypeSpread is the share of the exchange rate spread kept on a conversion
+	FeeTypeSpread = "spread"
+	// FeeTypePercentage is a percentage of the amount
+	FeeTypePercentage = "percentage"
+	// FeeTypeFixed is a flat amount per charge
+	FeeTypeFixed = "fixed"
+	// FeeTypeMinimum tops the percentage and fixed fees up to the minimum fee of the rule
+	FeeTypeMinimum = "minimum"
+)
+
+// FeeCorridorAny matches every currency on that side of a rule's corridor
+const FeeCorridorAny = "*"
+
+// FeeSchedule is one version of the fees charged across all products. Schedules are never
+// edited, a change is published as the next version and applies from its EffectiveAt.
+type FeeSchedule struct {
+	ID          int       `json:"id" db:"id"`
+	Version     int       `json:"version" db:"version"`
+	Note        string    `json:"note" db:"note"`
+	EffectiveAt time.Time `json:"effective_at" db:"effective_at"`
+	CreatedBy   int       `json:"created_by" db:"created_by"`
+	CreatedAt   string    `json:"created_at" db:"created_at"`
+	Rules       []FeeRule `json:"rules" db:"-"`
+}
+
+// FeeRule prices one product in a corridor. Fixed, minimum, maximum and tier amounts are in
+// FromCurrency, so rules that use them must name the source currency. A zero MaxFee means no cap.
+type FeeRule struct {
+	ID            int          `json:"id" db:"id"`
+	FeeScheduleID int          `json:"fee_schedule_id" db:"fee_schedule_id"`
+	Product       string       `json:"product" db:"product"`
+	FromCurrency  string       `json:"from_currency" db:"from_currency"`
+	ToCurrency    string       `json:"to_currency" db:"to_currency"`
+	SpreadShare   money.Rate   `json:"spread_share" db:"spread_share"`
+	Percentage    money.Rate   `json:"percentage" db:"percentage"`
+	FixedAmount   money.Amount `json:"fixed_amount" db:"fixed_amount"`
+	MinFee        money.Amount `json:"min_fee" db:"min_fee"`
+	MaxFee        money.Amount `json:"max_fee" db:"max_fee"`
+	Tiers         []FeeTier    `json:"tiers,omitempty" db:"-"`
+}
+
+// FeeTier replaces the percentage and fixed fee of its rule for amounts of at least MinAmount
+type FeeTier struct {
+	ID          int          `json:"id" db:"id"`
+	FeeRuleID   int          `json:"fee_rule_id" db:"fee_rule_id"`
+	MinAmount   money.Amount `json:"min_amount" db:"min_amount"`
+	Percentage  money.Rate   `json:"percentage" db:"percentage"`
+	FixedAmount money.Amount `json:"fixed_amount" db:"fixed_amount"`
+}
+
+// FeeCharge is a single line item of a fee taken from a user, recorded with the journal
+// entry that moved it to revenue and the schedule version and rule that priced it
+type FeeCharge struct {
+	ID             int          `json:"id" db:"id"`
+	JournalEntryID int          `json:"journal_entry_id" db:"journal_entry_id"`
+	TransactionID  int          `json:"transaction_id,omitempty" db:"transaction_id"`
+	UserID         int          `json:"user_id" db:"user_id"`
+	FeeScheduleID  int          `json:"fee_schedule_id" db:"fee_schedule_id"`
+	FeeRuleID      int          `json:"fee_rule_id" db:"fee_rule_id"`
+	Product        string       `json:"product" db:"product"`
+	Type           string       `json:"type" db:"fee_type"`
+	Currency       string       `json:"currency" db:"currency"`
+	Amount         money.Amount `json:"amount" db:"amount"`
+	CreatedAt      string       `json:"created_at,omitempty" db:"created_at"`
+}
+
+// Rule returns the rule of the schedule that prices product from fromCurrency to toCurrency.
+// A rule naming a currency beats a wildcard, and the source currency counts for more than the
+// destination. nil is returned when no rule matches, the product is then free.
+func (s FeeSchedule) Rule(product, fromCurrency, toCurrency string) *FeeRule {
+	var match *FeeRule
+	bestScore := -1
+	for idx, rule := range s.Rules {
+		if rule.Product != product {
+			continue
+		}
+		if rule.FromCurrency != FeeCorridorAny && rule.FromCurrency != fromCurrency {
+			continue
+		}
+		if rule.ToCurrency != FeeCorridorAny && rule.ToCurrency != toCurrency {
+			continue
+		}
+
+		score := 0
+		if rule.FromCurrency != FeeCorridorAny {
+			score += 2
+		}
+		if rule.ToCurrency != FeeCorridorAny {
+			score++
+		}
+		if score > bestScore {
+			match, bestScore = &s.Rules[idx], score
+		}
+	}
+	return match
+}
+
+// Tier returns the tier of the rule that prices amount, the one with the highest MinAmount
+// amount reaches. nil is returned when amount is below every tier.
+func (r FeeRule) Tier(amount money.Amount) *FeeTier {
+	var match *FeeTier
+	for idx, tier := range r.Tiers {
+		if tier.MinAmount <= amount && (match == nil || tier.MinAmount > match.MinAmount) {
+			match = &r.Tiers[idx]
+		}
+	}
+	return match
+}
+
+// Validate checks that the rules of the schedule can be applied unambiguously
+func (s FeeSchedule) Validate() error {
+	corridors := make(map[[3]string]bool)
+	for _, rule := range s.Rules {
+		if err := rule.Validate(); err != nil {
+			return err
+		}
+
+		corridor := [3]string{rule.Product, rule.FromCurrency, rule.ToCurrency}
+		if corridors[corridor] {
+			return exception.ErrFeeRuleDuplicated
+		}
+		corridors[corridor] = true
+	}
+	return nil
+}
+
+// Validate checks that the amounts of the rule are consistent
+func (r FeeRule) Validate() error {
+	if r.SpreadShare < 0 || r.SpreadShare > money.RateOne || r.Percentage < 0 || r.Percentage >= money.RateOne ||
+		r.FixedAmount.IsNegative() || r.MinFee.IsNegative() || r.MaxFee.IsNegative() {
+		return exception.ErrFeeRuleInvalid
+	}
+	if r.MaxFee.IsPositive() && r.MaxFee < r.MinFee {
+		return exception.ErrFeeRuleInvalid
+	}
+
+	usesAmounts := r.FixedAmount.IsPositive() || r.MinFee.IsPositive() || r.MaxFee.IsPositive()
+	tierAmounts := make(map[money.Amount]bool)
+	for _, tier := range r.Tiers {
+		if tier.MinAmount.IsNegative() || tier.Percentage < 0 || tier.Percentage >= money.RateOne || tier.FixedAmount.IsNegative() {
+			return exception.ErrFeeRuleInvalid
+		}
+		if tierAmounts[tier.MinAmount] {
+			return exception.ErrFeeRuleInvalid
+		}
+		tierAmounts[tier.MinAmount] = true
+		usesAmounts = true
+	}
+
+	// amounts are in the source currency, a wildcard source would charge them in every currency alike
+	if usesAmounts && r.FromCurrency == FeeCorridorAny {
+		return exception.ErrFeeRuleCurrencyRequired
+	}
+
+	return nil
+}
+
+// TotalFees adds up the line items of a charge
+func TotalFees(fees []FeeCharge) money.Amount {
+	var total money.Amount
+	for _, fee := range fees {
+		total += fee.Amount
+	}
+	return total
+}
+
+type FeeUsecase interface {
+	GetFeeSchedules(ctx context.Context, userID int) (*dto.GetFeeSchedulesResponse, error)
+	CreateFeeSchedule(ctx context.Context, userID int, req dto.CreateFeeScheduleRequest) (*dto.FeeSchedule, error)
+}
+
+type FeeRepository interface {
+	GetActiveFeeSchedule(ctx context.Context) (*FeeSchedule, error)
+	GetFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	CreateFeeSchedule(ctx context.Context, tx *sqlx.Tx, schedule *FeeSchedule) error
+	CreateFeeCharges(ctx context.Context, tx *sqlx.Tx, charges []FeeCharge) error
+}
@@ -0,0 +1,47 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeSchedule_Rule(t *testing.T) {
+	schedule := domain.FeeSchedule{
+		Rules: []domain.FeeRule{
+			{ID: 1, Product: domain.FeeProductTransfer, FromCurrency: domain.FeeCorridorAny, ToCurrency: domain.FeeCorridorAny},
+			{ID: 2, Product: domain.FeeProductTransfer, FromCurrency: domain.FeeCorridorAny, ToCurrency: "USD"},
+			{ID: 3, Product: domain.FeeProductTransfer, FromCurrency: "SGD", ToCurrency: domain.FeeCorridorAny},
+			{ID: 4, Product: domain.FeeProductTransfer, FromCurrency: "SGD", ToCurrency: "USD"},
+			{ID: 5, Product: domain.FeeProductExchange, FromCurrency: "SGD", ToCurrency: "USD"},
+		},
+	}
+
+	testCases := []struct {
+		Title          string
+		Product        string
+		FromCurrency   string
+		ToCurrency     string
+		ExpectedRuleID int
+	}{
+		{Title: "ReturnsSuccessfully_ExactCorridor", Product: domain.FeeProductTransfer, FromCurrency: "SGD", ToCurrency: "USD", ExpectedRuleID: 4},
+		{Title: "ReturnsSuccessfully_SourceBeatsDestination", Product: domain.FeeProductTransfer, FromCurrency: "SGD", ToCurrency: "AUD", ExpectedRuleID: 3},
+		{Title: "ReturnsSuccessfully_DestinationOnly", Product: domain.FeeProductTransfer, FromCurrency: "MYR", ToCurrency: "USD", ExpectedRuleID: 2},
+		{Title: "ReturnsSuccessfully_Wildcard", Product: domain.FeeProductTransfer, FromCurrency: "MYR", ToCurrency: "AUD", ExpectedRuleID: 1},
+		{Title: "ReturnsSuccessfully_OtherProduct", Product: domain.FeeProductExchange, FromCurrency: "SGD", ToCurrency: "USD", ExpectedRuleID: 5},
+		{Title: "ReturnsSuccessfully_NoRule", Product: domain.FeeProductWithdrawal, FromCurrency: "SGD", ToCurrency: "SGD"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			rule := schedule.Rule(tc.Product, tc.FromCurrency, tc.ToCurrency)
+			if tc.ExpectedRuleID == 0 {
+				require.Nil(t, rule)
+				return
+			}
+			require.NotNil(t, rule)
+			require.Equal(t, tc.ExpectedRuleID, rule.ID)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// Where the rate applied to a conversion came from
+const (
+	// FXRateSourceDirect is a rate stored for the pair
+	FXRateSourceDirect = "direct"
+	// FXRateSourceCross is a rate derived through the base currency
+	FXRateSourceCross = "cross"
+	// FXRateSourceQuote is a rate locked in by a preview quote
+	FXRateSourceQuote = "quote"
+	// FXRateSourceOriginal is the rate of the transfer a refund reverses
+	FXRateSourceOriginal = "original"
+)
+
+// FXConversion is the audit record of a single conversion: the mid rate and spread that were
+// applied, the profit kept and where the rate came from. Records are never updated.
+type FXConversion struct {
+	ID              int          `json:"id" db:"id"`
+	JournalEntryID  int          `json:"journal_entry_id" db:"journal_entry_id"`
+	TransactionID   int          `json:"transaction_id,omitempty" db:"transaction_id"`
+	UserID          int          `json:"user_id" db:"user_id"`
+	FromCurrency    string       `json:"from_currency" db:"from_currency"`
+	ToCurrency      string       `json:"to_currency" db:"to_currency"`
+	FromAmount      money.Amount `json:"from_amount" db:"from_amount"`
+	ToAmount        money.Amount `json:"to_amount" db:"to_amount"`
+	MidRate         money.Rate   `json:"mid_rate" db:"mid_rate"`
+	Spread          money.Rate   `json:"spread" db:"spread"`
+	Profit          money.Amount `json:"profit" db:"profit"`
+	RateSource      string       `json:"rate_source" db:"rate_source"`
+	ConversionPath  string       `json:"conversion_path" db:"conversion_path"`
+	RateEffectiveAt *time.Time   `json:"rate_effective_at,omitempty" db:"rate_effective_at"`
+	CreatedAt       string       `json:"created_at" db:"created_at"`
+}
+
+// NewFXConversion records fromAmount being converted into toAmount at rate, with profit kept
+func NewFXConversion(userID int, rate ExchangeRate, rateSource string, fromAmount, toAmount, profit money.Amount) *FXConversion {
+	conversion := &FXConversion{
+		UserID:         userID,
+		FromCurrency:   rate.FromCurrency,
+		ToCurrency:     rate.ToCurrency,
+		FromAmount:     fromAmount,
+		ToAmount:       toAmount,
+		MidRate:        rate.Rate,
+		Spread:         rate.Spread,
+		Profit:         profit,
+		RateSource:     rateSource,
+		ConversionPath: strings.Join(rate.ConversionPath(), ">"),
+	}
+	if !rate.EffectiveAt.IsZero() {
+		effectiveAt := rate.EffectiveAt
+		conversion.RateEffectiveAt = &effectiveAt
+	}
+	return conversion
+}
+
+type FXConversionRepository interface {
+	CreateFXConversion(ctx context.Context, tx *sqlx.Tx, conversion *FXConversion) error
+	GetFXConversionByTransactionID(ctx context.Context, transactionID int) (*FXConversion, error)
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// Posting directions. User accounts are liabilities of the platform, so a CREDIT
+// increases what the platform owes the user and a DEBIT decreases it.
+const (
+	Debit  = "DEBIT"
+	Credit = "CREDIT"
+)
+
+// Journal entry types, one per money-moving usecase
+const (
+	JournalDeposit        = "deposit"
+	JournalWithdraw       = "withdraw"
+	JournalExchange       = "exchange"
+	JournalCreateWallet   = "create_wallet"
+	JournalTopUpWallet    = "top_up_wallet"
+	JournalCashOutWallet  = "cash_out_wallet"
+	JournalWalletTransfer = "wallet_transfer"
+	JournalWalletExchange = "wallet_exchange"
+	JournalCloseWallet    = "close_wallet"
+	JournalTransfer       = "transfer"
+	JournalRefund         = "refund"
+)
+
+// System accounts on the platform side of the ledger
+const (
+	// AccountExternal is the counterpart for money entering or leaving the platform (cards, banks)
+	AccountExternal = "system:external"
+	// AccountFXClearing takes one currency in and pays another out so that each currency nets to zero
+	AccountFXClearing = "system:fx"
+	// AccountRevenue collects the spread kept on conversions
+	AccountRevenue = "system:revenue"
+)
+
+// UserBalanceAccount returns the ledger account for a user's main balance
+func UserBalanceAccount(userID int) string {
+	return fmt.Sprintf("user:%d:balance", userID)
+}
+
+// UserWalletAccount returns the ledger account for one of a user's wallets
+func UserWalletAccount(userID, walletID int) string {
+	return fmt.Sprintf("user:%d:wallet:%d", userID, walletID)
+}
+
+// JournalEntry groups the postings of a single money movement. Postings are
+// immutable once written, corrections are made with a new entry.
+type JournalEntry struct {
+	ID        int       `db:"id"`
+	Type      string    `db:"type"`
+	Reference string    `db:"reference"`
+	CreatedAt string    `db:"created_at"`
+	Postings  []Posting `db:"-"`
+}
+
+type Posting struct {
+	ID        int          `db:"id"`
+	EntryID   int          `db:"entry_id"`
+	Account   string       `db:"account"`
+	Direction string       `db:"direction"`
+	Currency  string       `db:"currency"`
+	Amount    money.Amount `db:"amount"`
+	CreatedAt string       `db:"created_at"`
+}
+
+// Debit adds a posting that takes amount out of account
+func (e *JournalEntry) Debit(account, currency string, amount money.Amount) *JournalEntry {
+	e.Postings = append(e.Postings, Posting{Account: account, Direction: Debit, Currency: currency, Amount: amount})
+	return e
+}
+
+// Credit adds a posting that puts amount into account
+func (e *JournalEntry) Credit(account, currency string, amount money.Amount) *JournalEntry {
+	e.Postings = append(e.Postings, Posting{Account: account, Direction: Credit, Currency: currency, Amount: amount})
+	return e
+}
+
+// Transfer moves amount from one account to another in the same currency
+func (e *JournalEntry) Transfer(from, to, currency string, amount money.Amount) *JournalEntry {
+	return e.Debit(from, currency, amount).Credit(to, currency, amount)
+}
+
+// Exchange moves fromAmount out of one account and toAmount into another across
+// currencies. The profit is kept in the source currency and the rest goes
+// through the FX clearing account, so each currency still nets to zero.
+func (e *JournalEntry) Exchange(from, to, fromCurrency, toCurrency string, fromAmount, profit, toAmount money.Amount) *JournalEntry {
+	return e.Debit(from, fromCurrency, fromAmount).
+		Credit(AccountRevenue, fromCurrency, profit).
+		Credit(AccountFXClearing, fromCurrency, fromAmount-profit).
+		Debit(AccountFXClearing, toCurrency, toAmount).
+		Credit(to, toCurrency, toAmount)
+}
+
+// CheckBalanced enforces the double-entry invariant: for every currency in the
+// entry, debits and credits sum to the same amount. Zero postings are dropped
+// since they carry no movement.
+func (e *JournalEntry) CheckBalanced() error {
+	postings := e.Postings[:0]
+	totals := make(map[string]money.Amount)
+
+	for _, p := range e.Postings {
+		if p.Amount.IsNegative() {
+			return exception.ErrNegativePostingAmount
+		}
+		if p.Amount.IsZero() {
+			continue
+		}
+
+		switch p.Direction {
+		case Debit:
+			totals[p.Currency] += p.Amount
+		case Credit:
+			totals[p.Currency] -= p.Amount
+		default:
+			return exception.ErrInvalidPostingDirection
+		}
+		postings = append(postings, p)
+	}
+	e.Postings = postings
+
+	if len(e.Postings) == 0 {
+		return exception.ErrEmptyJournalEntry
+	}
+
+	for _, total := range totals {
+		if !total.IsZero() {
+			return exception.ErrUnbalancedJournalEntry
+		}
+	}
+
+	return nil
+}
+
+type LedgerRepository interface {
+	CreateJournalEntry(ctx context.Context, tx *sqlx.Tx, entry *JournalEntry) error
+	GetAccountBalance(ctx context.Context, tx *sqlx.Tx, account, currency string) (money.Amount, error)
+}
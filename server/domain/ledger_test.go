@@ -0,0 +1,89 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalEntry_CheckBalanced(t *testing.T) {
+	user := domain.UserBalanceAccount(1)
+	wallet := domain.UserWalletAccount(1, 2)
+
+	testCases := []struct {
+		Title         string
+		GivenEntry    func() *domain.JournalEntry
+		ExpectedError error
+	}{
+		{
+			Title: "ReturnsSuccessfully_Transfer",
+			GivenEntry: func() *domain.JournalEntry {
+				entry := &domain.JournalEntry{Type: domain.JournalTopUpWallet}
+				return entry.Transfer(user, wallet, "SGD", money.MustParse("10.50"))
+			},
+		},
+		{
+			Title: "ReturnsSuccessfully_ExchangeNetsToZeroPerCurrency",
+			GivenEntry: func() *domain.JournalEntry {
+				entry := &domain.JournalEntry{Type: domain.JournalExchange}
+				return entry.Exchange(user, user, "SGD", "USD", money.MustParse("100.00"), money.MustParse("0.01"), money.MustParse("75.99"))
+			},
+		},
+		{
+			Title: "ReturnsSuccessfully_ZeroPostingsDropped",
+			GivenEntry: func() *domain.JournalEntry {
+				entry := &domain.JournalEntry{Type: domain.JournalExchange}
+				return entry.Exchange(user, user, "SGD", "USD", money.MustParse("0.01"), 0, 0)
+			},
+		},
+		{
+			Title: "ReturnsError_Unbalanced",
+			GivenEntry: func() *domain.JournalEntry {
+				entry := &domain.JournalEntry{Type: domain.JournalDeposit}
+				return entry.Debit(domain.AccountExternal, "SGD", money.FromInt(10)).Credit(user, "SGD", money.MustParse("10.01"))
+			},
+			ExpectedError: exception.ErrUnbalancedJournalEntry,
+		},
+		{
+			Title: "ReturnsError_BalancedAcrossCurrenciesOnly",
+			GivenEntry: func() *domain.JournalEntry {
+				entry := &domain.JournalEntry{Type: domain.JournalExchange}
+				return entry.Debit(user, "SGD", money.FromInt(10)).Credit(user, "USD", money.FromInt(10))
+			},
+			ExpectedError: exception.ErrUnbalancedJournalEntry,
+		},
+		{
+			Title: "ReturnsError_NegativeAmount",
+			GivenEntry: func() *domain.JournalEntry {
+				entry := &domain.JournalEntry{Type: domain.JournalDeposit}
+				return entry.Transfer(domain.AccountExternal, user, "SGD", money.FromInt(-1))
+			},
+			ExpectedError: exception.ErrNegativePostingAmount,
+		},
+		{
+			Title: "ReturnsError_InvalidDirection",
+			GivenEntry: func() *domain.JournalEntry {
+				return &domain.JournalEntry{Postings: []domain.Posting{{Account: user, Direction: "SIDEWAYS", Currency: "SGD", Amount: money.FromInt(1)}}}
+			},
+			ExpectedError: exception.ErrInvalidPostingDirection,
+		},
+		{
+			Title: "ReturnsError_Empty",
+			GivenEntry: func() *domain.JournalEntry {
+				entry := &domain.JournalEntry{Type: domain.JournalDeposit}
+				return entry.Transfer(domain.AccountExternal, user, "SGD", 0)
+			},
+			ExpectedError: exception.ErrEmptyJournalEntry,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			err := tc.GivenEntry().CheckBalanced()
+			require.Equal(t, tc.ExpectedError, err)
+		})
+	}
+}
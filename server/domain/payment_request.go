@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// Payment request statuses. A request is processing while the payer's transfer is being made,
+// paid, declined, cancelled and expired are final.
+const (
+	PaymentRequestStatusPending    = "pending"
+	PaymentRequestStatusProcessing = "processing"
+	PaymentRequestStatusPaid       = "paid"
+	PaymentRequestStatusDeclined   = "declined"
+	PaymentRequestStatusCancelled  = "cancelled"
+	PaymentRequestStatusExpired    = "expired"
+)
+
+// PaymentRequest is a request from a user to one of their beneficiaries for money. Amount is what
+// the payer sends in Currency, fees come out of what the requester receives as with any transfer.
+// FailureReason is the reason code of the last attempt to pay the request that failed, the payer
+// can try again for as long as the request is pending.
+type PaymentRequest struct {
+	ID                   int          `json:"id" db:"id"`
+	RequesterID          int          `json:"requester_id" db:"requester_id"`
+	RequesterUsername    string       `json:"requester_username" db:"requester_username"`
+	PayerID              int          `json:"payer_id" db:"payer_id"`
+	PayerUsername        string       `json:"payer_username" db:"payer_username"`
+	Amount               money.Amount `json:"amount" db:"amount"`
+	Currency             string       `json:"currency" db:"currency"`
+	Note                 string       `json:"note" db:"note"`
+	Status               string       `json:"status" db:"status"`
+	FailureReason        string       `json:"failure_reason,omitempty" db:"failure_reason"`
+	TransactionID        int          `json:"-" db:"transaction_id"`
+	TransactionReference string       `json:"transaction_reference,omitempty" db:"transaction_reference"`
+	ExpiresAt            time.Time    `json:"expires_at" db:"expires_at"`
+	CreatedAt            string       `json:"created_at" db:"created_at"`
+	UpdatedAt            string       `json:"updated_at" db:"updated_at"`
+}
+
+// paymentRequestStatusTransitions lists the statuses a payment request can move to from each status.
+// A request goes back to pending when the transfer paying it fails.
+var paymentRequestStatusTransitions = map[string][]string{
+	PaymentRequestStatusPending: {
+		PaymentRequestStatusProcessing,
+		PaymentRequestStatusDeclined,
+		PaymentRequestStatusCancelled,
+		PaymentRequestStatusExpired,
+	},
+	PaymentRequestStatusProcessing: {PaymentRequestStatusPaid, PaymentRequestStatusPending},
+}
+
+// CheckPaymentRequestStatusTransition returns an error if a payment request cannot move from one status to the other
+func CheckPaymentRequestStatusTransition(from, to string) error {
+	for _, allowed := range paymentRequestStatusTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return exception.ErrPaymentRequestNotPending
+}
+
+// Expired reports whether a pending request has passed its expiry at now and can no longer be paid
+func (p PaymentRequest) Expired(now time.Time) bool {
+	return p.Status == PaymentRequestStatusPending && !now.Before(p.ExpiresAt)
+}
@@ -0,0 +1,62 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPaymentRequestStatusTransition(t *testing.T) {
+	testCases := []struct {
+		Title         string
+		GivenFrom     string
+		GivenTo       string
+		ExpectedError error
+	}{
+		{Title: "ReturnsSuccessfully_PendingToProcessing", GivenFrom: domain.PaymentRequestStatusPending, GivenTo: domain.PaymentRequestStatusProcessing},
+		{Title: "ReturnsSuccessfully_PendingToExpired", GivenFrom: domain.PaymentRequestStatusPending, GivenTo: domain.PaymentRequestStatusExpired},
+		{Title: "ReturnsSuccessfully_ProcessingToPaid", GivenFrom: domain.PaymentRequestStatusProcessing, GivenTo: domain.PaymentRequestStatusPaid},
+		{Title: "ReturnsSuccessfully_ProcessingBackToPending", GivenFrom: domain.PaymentRequestStatusProcessing, GivenTo: domain.PaymentRequestStatusPending},
+		{Title: "ReturnsError_PendingToPaid", GivenFrom: domain.PaymentRequestStatusPending, GivenTo: domain.PaymentRequestStatusPaid, ExpectedError: exception.ErrPaymentRequestNotPending},
+		{Title: "ReturnsError_ProcessingToDeclined", GivenFrom: domain.PaymentRequestStatusProcessing, GivenTo: domain.PaymentRequestStatusDeclined, ExpectedError: exception.ErrPaymentRequestNotPending},
+		{Title: "ReturnsError_PaidToCancelled", GivenFrom: domain.PaymentRequestStatusPaid, GivenTo: domain.PaymentRequestStatusCancelled, ExpectedError: exception.ErrPaymentRequestNotPending},
+		{Title: "ReturnsError_DeclinedToProcessing", GivenFrom: domain.PaymentRequestStatusDeclined, GivenTo: domain.PaymentRequestStatusProcessing, ExpectedError: exception.ErrPaymentRequestNotPending},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			err := domain.CheckPaymentRequestStatusTransition(tc.GivenFrom, tc.GivenTo)
+			if tc.ExpectedError == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestPaymentRequest_Expired(t *testing.T) {
+	expiresAt := time.Date(2026, time.November, 9, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Title          string
+		GivenStatus    string
+		GivenNow       time.Time
+		ExpectedResult bool
+	}{
+		{Title: "ReturnsSuccessfully_PendingBeforeExpiry", GivenStatus: domain.PaymentRequestStatusPending, GivenNow: expiresAt.Add(-time.Second)},
+		{Title: "ReturnsSuccessfully_PendingAtExpiry", GivenStatus: domain.PaymentRequestStatusPending, GivenNow: expiresAt, ExpectedResult: true},
+		{Title: "ReturnsSuccessfully_PaidAfterExpiry", GivenStatus: domain.PaymentRequestStatusPaid, GivenNow: expiresAt.Add(time.Hour)},
+		{Title: "ReturnsSuccessfully_ProcessingAfterExpiry", GivenStatus: domain.PaymentRequestStatusProcessing, GivenNow: expiresAt.Add(time.Hour)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			paymentRequest := domain.PaymentRequest{Status: tc.GivenStatus, ExpiresAt: expiresAt}
+			require.Equal(t, tc.ExpectedResult, paymentRequest.Expired(tc.GivenNow))
+		})
+	}
+}
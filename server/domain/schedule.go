@@ -0,0 +1,47 @@
+package domain
+
+import "time"
+
+// Frequencies a schedule runs at, counted from its start. A schedule that runs once only runs at its start.
+const (
+	FrequencyOnce    = "once"
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+// nextRun returns the first run of a schedule after now, zero when a schedule that runs once
+// has already run. Runs missed while a schedule was paused or the scheduler was down are
+// skipped rather than caught up on.
+func nextRun(frequency string, startAt, now time.Time) time.Time {
+	if frequency == FrequencyOnce {
+		if startAt.After(now) {
+			return startAt
+		}
+		return time.Time{}
+	}
+
+	for n := 0; ; n++ {
+		if run := scheduledRun(frequency, startAt, n); run.After(now) {
+			return run
+		}
+	}
+}
+
+// scheduledRun returns the nth run of a recurring schedule. Monthly runs that fall on a day the
+// month does not have are moved to the last day of the month, instead of spilling into the next.
+func scheduledRun(frequency string, startAt time.Time, n int) time.Time {
+	switch frequency {
+	case FrequencyWeekly:
+		return startAt.AddDate(0, 0, 7*n)
+	case FrequencyMonthly:
+		run := startAt.AddDate(0, n, 0)
+		if run.Day() != startAt.Day() {
+			// AddDate overflowed into the next month, step back to the end of the one before
+			run = run.AddDate(0, 0, -run.Day())
+		}
+		return run
+	default:
+		return startAt.AddDate(0, 0, n)
+	}
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// Scheduled transfer statuses. Only active transfers are run, cancelled and completed are final.
+const (
+	ScheduledTransferStatusActive    = "active"
+	ScheduledTransferStatusPaused    = "paused"
+	ScheduledTransferStatusCancelled = "cancelled"
+	ScheduledTransferStatusCompleted = "completed"
+)
+
+// Scheduled transfer run statuses. A run is pending from when it is claimed until its transfer has gone through or failed.
+const (
+	ScheduledTransferRunPending   = "pending"
+	ScheduledTransferRunSucceeded = "succeeded"
+	ScheduledTransferRunFailed    = "failed"
+)
+
+// ScheduledTransfer is a transfer to a beneficiary made at StartAt, and again at every run of its
+// Frequency for recurring transfers. A recurring transfer completes after its last run on or before
+// EndAt, or after MaxRuns runs, whichever comes first. Neither is set for a transfer that runs until
+// it is cancelled.
+type ScheduledTransfer struct {
+	ID                           int          `json:"id" db:"id"`
+	UserID                       int          `json:"user_id" db:"user_id"`
+	SenderWalletID               int          `json:"sender_wallet_id" db:"sender_wallet_id"`
+	SourceAmount                 money.Amount `json:"source_amount" db:"source_amount"`
+	SourceCurrency               string       `json:"source_currency" db:"source_currency"`
+	BeneficiaryMobileCountryCode string       `json:"beneficiary_mobile_country_code" db:"beneficiary_mobile_country_code"`
+	BeneficiaryMobileNumber      string       `json:"beneficiary_mobile_number" db:"beneficiary_mobile_number"`
+	AutoConvert                  bool         `json:"auto_convert" db:"auto_convert"`
+	Frequency                    string       `json:"frequency" db:"frequency"`
+	StartAt                      time.Time    `json:"start_at" db:"start_at"`
+	EndAt                        *time.Time   `json:"end_at,omitempty" db:"end_at"`
+	MaxRuns                      int          `json:"max_runs,omitempty" db:"max_runs"`
+	RunCount                     int          `json:"run_count" db:"run_count"`
+	NextRunAt                    time.Time    `json:"next_run_at" db:"next_run_at"`
+	Status                       string       `json:"status" db:"status"`
+	CreatedAt                    string       `json:"created_at" db:"created_at"`
+	UpdatedAt                    string       `json:"updated_at" db:"updated_at"`
+}
+
+// ScheduledTransferRun is one run of a scheduled transfer. TransactionReference points at the
+// transaction the run made, which is recorded when the run is claimed. FailureReason is one of
+// the transaction reason codes.
+type ScheduledTransferRun struct {
+	ID                   int    `json:"id" db:"id"`
+	ScheduledTransferID  int    `json:"scheduled_transfer_id" db:"scheduled_transfer_id"`
+	UserID               int    `json:"user_id" db:"user_id"`
+	TransactionID        int    `json:"-" db:"transaction_id"`
+	TransactionReference string `json:"transaction_reference,omitempty" db:"transaction_reference"`
+	Status               string `json:"status" db:"status"`
+	FailureReason        string `json:"failure_reason,omitempty" db:"failure_reason"`
+	CreatedAt            string `json:"created_at" db:"created_at"`
+	UpdatedAt            string `json:"updated_at" db:"updated_at"`
+}
+
+// TransactionRequest returns the request a run of the scheduled transfer is made with
+func (s ScheduledTransfer) TransactionRequest() dto.CreateTransactionRequest {
+	return dto.CreateTransactionRequest{
+		SenderWalletID:               s.SenderWalletID,
+		SourceCurrency:               s.SourceCurrency,
+		SourceAmount:                 s.SourceAmount,
+		BeneficiaryMobileCountryCode: s.BeneficiaryMobileCountryCode,
+		BeneficiaryMobileNumber:      s.BeneficiaryMobileNumber,
+		AutoConvert:                  s.AutoConvert,
+	}
+}
+
+// Advance counts a run made at now and moves the transfer on to its next run, completing it when
+// that was its last run. A run counts whether or not its transfer went through.
+func (s *ScheduledTransfer) Advance(now time.Time) {
+	s.RunCount++
+	if s.Frequency == FrequencyOnce || (s.MaxRuns > 0 && s.RunCount >= s.MaxRuns) {
+		s.Status = ScheduledTransferStatusCompleted
+		return
+	}
+	s.scheduleNextRun(now)
+}
+
+// Resume makes a paused transfer active again. Runs missed while it was paused are skipped, except
+// for a transfer that runs once, which is made straight away if its date has passed.
+func (s *ScheduledTransfer) Resume(now time.Time) {
+	s.Status = ScheduledTransferStatusActive
+	if s.Frequency == FrequencyOnce {
+		if s.NextRunAt.Before(now) {
+			s.NextRunAt = now
+		}
+		return
+	}
+	s.scheduleNextRun(now)
+}
+
+func (s *ScheduledTransfer) scheduleNextRun(now time.Time) {
+	next := nextRun(s.Frequency, s.StartAt, now)
+	if s.EndAt != nil && next.After(*s.EndAt) {
+		s.Status = ScheduledTransferStatusCompleted
+		return
+	}
+	s.NextRunAt = next
+}
@@ -0,0 +1,108 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledTransfer_Advance(t *testing.T) {
+	startAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+	endAt := startAt.AddDate(0, 0, 10)
+
+	testCases := []struct {
+		Title             string
+		GivenTransfer     domain.ScheduledTransfer
+		GivenNow          time.Time
+		ExpectedStatus    string
+		ExpectedNextRunAt time.Time
+	}{
+		{
+			Title:             "ReturnsSuccessfully_MovesOnToTheNextRun",
+			GivenTransfer:     domain.ScheduledTransfer{Frequency: domain.FrequencyDaily, StartAt: startAt, NextRunAt: startAt},
+			GivenNow:          startAt.Add(time.Minute),
+			ExpectedStatus:    domain.ScheduledTransferStatusActive,
+			ExpectedNextRunAt: startAt.AddDate(0, 0, 1),
+		},
+		{
+			Title:             "ReturnsSuccessfully_OnceCompletes",
+			GivenTransfer:     domain.ScheduledTransfer{Frequency: domain.FrequencyOnce, StartAt: startAt, NextRunAt: startAt},
+			GivenNow:          startAt.Add(time.Minute),
+			ExpectedStatus:    domain.ScheduledTransferStatusCompleted,
+			ExpectedNextRunAt: startAt,
+		},
+		{
+			Title:             "ReturnsSuccessfully_CompletesAfterMaxRuns",
+			GivenTransfer:     domain.ScheduledTransfer{Frequency: domain.FrequencyDaily, StartAt: startAt, NextRunAt: startAt, MaxRuns: 3, RunCount: 2},
+			GivenNow:          startAt.Add(time.Minute),
+			ExpectedStatus:    domain.ScheduledTransferStatusCompleted,
+			ExpectedNextRunAt: startAt,
+		},
+		{
+			Title:             "ReturnsSuccessfully_CompletesPastEndAt",
+			GivenTransfer:     domain.ScheduledTransfer{Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: startAt.AddDate(0, 0, 7), EndAt: &endAt},
+			GivenNow:          startAt.AddDate(0, 0, 7).Add(time.Minute),
+			ExpectedStatus:    domain.ScheduledTransferStatusCompleted,
+			ExpectedNextRunAt: startAt.AddDate(0, 0, 7),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			transfer := tc.GivenTransfer
+			transfer.Status = domain.ScheduledTransferStatusActive
+
+			transfer.Advance(tc.GivenNow)
+
+			require.Equal(t, tc.GivenTransfer.RunCount+1, transfer.RunCount)
+			require.Equal(t, tc.ExpectedStatus, transfer.Status)
+			require.Equal(t, tc.ExpectedNextRunAt, transfer.NextRunAt)
+		})
+	}
+}
+
+func TestScheduledTransfer_Resume(t *testing.T) {
+	startAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2026, time.January, 20, 12, 0, 0, 0, time.UTC)
+	endAt := time.Date(2026, time.January, 25, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Title             string
+		GivenTransfer     domain.ScheduledTransfer
+		ExpectedStatus    string
+		ExpectedNextRunAt time.Time
+	}{
+		{
+			Title:             "ReturnsSuccessfully_SkipsMissedRuns",
+			GivenTransfer:     domain.ScheduledTransfer{Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: startAt.AddDate(0, 0, 7)},
+			ExpectedStatus:    domain.ScheduledTransferStatusActive,
+			ExpectedNextRunAt: startAt.AddDate(0, 0, 21),
+		},
+		{
+			Title:             "ReturnsSuccessfully_OnceRunsStraightAway",
+			GivenTransfer:     domain.ScheduledTransfer{Frequency: domain.FrequencyOnce, StartAt: startAt, NextRunAt: startAt},
+			ExpectedStatus:    domain.ScheduledTransferStatusActive,
+			ExpectedNextRunAt: now,
+		},
+		{
+			Title:             "ReturnsSuccessfully_CompletesPastEndAt",
+			GivenTransfer:     domain.ScheduledTransfer{Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: startAt.AddDate(0, 0, 7), EndAt: &endAt},
+			ExpectedStatus:    domain.ScheduledTransferStatusCompleted,
+			ExpectedNextRunAt: startAt.AddDate(0, 0, 7),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			transfer := tc.GivenTransfer
+			transfer.Status = domain.ScheduledTransferStatusPaused
+
+			transfer.Resume(now)
+
+			require.Equal(t, tc.ExpectedStatus, transfer.Status)
+			require.Equal(t, tc.ExpectedNextRunAt, transfer.NextRunAt)
+		})
+	}
+}
@@ -2,39 +2,148 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 	"github.com/jmoiron/sqlx"
 )
 
 type Transaction struct {
-	SenderID                int     `db:"sender_id"`
-	BeneficiaryID           int     `db:"beneficiary_id"`
-	SenderUsername          string  `json:"sender_username" db:"sender_username"`
-	SenderMobileNumber      string  `json:"sender_mobile_number" db:"sender_mobile_number"`
-	BeneficiaryUsername     string  `json:"beneficiary_username" db:"beneficiary_username"`
-	BeneficiaryMobileNumber string  `json:"beneficiary_mobile_number" db:"beneficiary_mobile_number"`
-	SourceAmount            float64 `json:"source_amount" db:"source_amount"`
-	SourceCurrency          string  `json:"source_currency" db:"source_currency"`
-	DestinationAmount       float64 `json:"destination_amount" db:"destination_amount"`
-	DestinationCurrency     string  `json:"destination_currency" db:"destination_currency"`
-	SourceOfTransfer        string  `json:"source_of_transfer" db:"source_of_transfer"`
-	Status                  string  `json:"status" db:"status"`
-	CreatedAt               string  `json:"created_at" db:"created_at"`
+	ID                      int           `json:"id" db:"id"`
+	Reference               string        `json:"reference" db:"reference"`
+	SenderID                int           `db:"sender_id"`
+	BeneficiaryID           int           `db:"beneficiary_id"`
+	SenderUsername          string        `json:"sender_username" db:"sender_username"`
+	SenderMobileNumber      string        `json:"sender_mobile_number" db:"sender_mobile_number"`
+	BeneficiaryUsername     string        `json:"beneficiary_username" db:"beneficiary_username"`
+	BeneficiaryMobileNumber string        `json:"beneficiary_mobile_number" db:"beneficiary_mobile_number"`
+	SourceAmount            money.Amount  `json:"source_amount" db:"source_amount"`
+	SourceCurrency          string        `json:"source_currency" db:"source_currency"`
+	DestinationAmount       money.Amount  `json:"destination_amount" db:"destination_amount"`
+	DestinationCurrency     string        `json:"destination_currency" db:"destination_currency"`
+	FXRate                  money.Rate    `json:"fx_rate" db:"fx_rate"`
+	Profit                  money.Amount  `json:"profit" db:"profit"`
+	SourceOfTransfer        string        `json:"source_of_transfer" db:"source_of_transfer"`
+	SenderWalletID          int           `json:"sender_wallet_id,omitempty" db:"sender_wallet_id"`
+	OriginalTransactionID   int           `json:"original_transaction_id,omitempty" db:"original_transaction_id"`
+	Status                  string        `json:"status" db:"status"`
+	FailureReason           string        `json:"failure_reason,omitempty" db:"failure_reason"`
+	FXConversion            *FXConversion `json:"fx_conversion,omitempty" db:"-"`
+	CreatedAt               string        `json:"created_at" db:"created_at"`
+	UpdatedAt               string        `json:"updated_at" db:"updated_at"`
+}
+
+// transactionStatusTransitions lists the statuses a transaction can move to from each
+// status. SUCCESS is only left through a reversal, FAILED and REVERSED are final.
+var transactionStatusTransitions = map[string][]string{
+	constants.CREATED: {constants.PENDING, constants.FAILED},
+	constants.PENDING: {constants.SUCCESS, constants.FAILED},
+	constants.SUCCESS: {constants.REVERSED},
+}
+
+// CheckTransactionStatusTransition returns an error if a transaction cannot move from one status to the other
+func CheckTransactionStatusTransition(from, to string) error {
+	for _, allowed := range transactionStatusTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return exception.ErrInvalidTransactionStatusTransition
+}
+
+// Refund is a request from the sender of a transaction to have some or all of it
+// sent back. Amount is taken from the beneficiary in the currency they received,
+// RefundAmount is what the sender's wallet gets back once the beneficiary approves.
+type Refund struct {
+	ID                    int          `json:"id" db:"id"`
+	TransactionID         int          `json:"transaction_id" db:"transaction_id"`
+	ReversalTransactionID int          `json:"reversal_transaction_id,omitempty" db:"reversal_transaction_id"`
+	SenderID              int          `json:"sender_id" db:"sender_id"`
+	BeneficiaryID         int          `json:"beneficiary_id" db:"beneficiary_id"`
+	Amount                money.Amount `json:"amount" db:"amount"`
+	Currency              string       `json:"currency" db:"currency"`
+	RefundAmount          money.Amount `json:"refund_amount" db:"refund_amount"`
+	RefundCurrency        string       `json:"refund_currency" db:"refund_currency"`
+	RateType              string       `json:"rate_type" db:"rate_type"`
+	Reason                string       `json:"reason" db:"reason"`
+	Status                string       `json:"status" db:"status"`
+	CreatedAt             string       `json:"created_at" db:"created_at"`
+	UpdatedAt             string       `json:"updated_at" db:"updated_at"`
 }
 
 type TransactionUsecase interface {
 	CreateTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID int) error
-	GetTransactions(ctx context.Context, userID int, paginator *pagination.Paginator) (*[]Transaction, error)
+	GetTransactions(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) (*[]Transaction, error)
+	GetTransaction(ctx context.Context, userID int, reference string) (*Transaction, error)
+
+	RequestRefund(ctx context.Context, userID, transactionID int, req dto.RefundTransactionRequest) (*Refund, error)
+	RespondToRefund(ctx context.Context, userID, refundID int, decision string) (*Refund, error)
+	GetRefunds(ctx context.Context, userID int) (*[]Refund, error)
+
+	CreateScheduledTransfer(ctx context.Context, userID int, req dto.CreateScheduledTransferRequest) (*ScheduledTransfer, error)
+	GetScheduledTransfers(ctx context.Context, userID int) ([]ScheduledTransfer, error)
+	GetScheduledTransferRuns(ctx context.Context, userID, scheduledTransferID int) ([]ScheduledTransferRun, error)
+	PauseScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*ScheduledTransfer, error)
+	ResumeScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*ScheduledTransfer, error)
+	CancelScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*ScheduledTransfer, error)
+
+	RequestPayment(ctx context.Context, userID int, req dto.RequestPaymentRequest) (*PaymentRequest, error)
+	GetPaymentRequests(ctx context.Context, userID int) ([]PaymentRequest, error)
+	PayPaymentRequest(ctx context.Context, userID, paymentRequestID int, req dto.PayPaymentRequest) (*PaymentRequest, error)
+	DeclinePaymentRequest(ctx context.Context, userID, paymentRequestID int) (*PaymentRequest, error)
+	CancelPaymentRequest(ctx context.Context, userID, paymentRequestID int) (*PaymentRequest, error)
+
+	// RunDueScheduledTransfers makes the active scheduled transfers that are due at now, it is called by the scheduler
+	RunDueScheduledTransfers(ctx context.Context, now time.Time) error
+	// RecoverScheduledTransferRuns settles the scheduled transfer runs that stopped halfway, it is called by the scheduler
+	RecoverScheduledTransferRuns(ctx context.Context, now time.Time) error
+	// ExpirePaymentRequests expires the pending payment requests that have passed their expiry at now, it is called by the scheduler
+	ExpirePaymentRequests(ctx context.Context, now time.Time) error
+	// RecoverPaymentRequests settles the payment requests whose payment stopped halfway, it is called by the scheduler
+	RecoverPaymentRequests(ctx context.Context, now time.Time) error
 }
 
 type TransactionRepository interface {
 	CheckLinkageOfSenderAndBeneficiaryByMobileNumber(ctx context.Context, userID int, mobileCountryCode, mobileNumber string) (int, bool, bool, error)
 	CheckValidityOfSenderIDAndWalletID(ctx context.Context, userID, walletID int) (bool, string, error) // TODO: move to wallet repository?
 
-	InsertTransaction(ctx context.Context, tx *sqlx.Tx, userID int, transaction Transaction) error
+	InsertTransaction(ctx context.Context, tx *sqlx.Tx, userID int, transaction Transaction) (int, error)
+	UpdateTransaction(ctx context.Context, tx *sqlx.Tx, fromStatus string, transaction Transaction) error
+	UpdateTransactionStatusByReference(ctx context.Context, tx *sqlx.Tx, reference, fromStatus, status string) error
+
+	GetTotalTransactionsCount(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) error
+	GetTransactions(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) (*[]Transaction, error)
+	GetTransactionByID(ctx context.Context, tx *sqlx.Tx, userID, transactionID int) (*Transaction, error)
+	GetTransactionByReference(ctx context.Context, userID int, reference string) (*Transaction, error)
+	GetSentTransactions(ctx context.Context, userID int, currency string, afterID int, since time.Time) ([]Transaction, error)
+
+	InsertRefund(ctx context.Context, tx *sqlx.Tx, refund *Refund) error
+	UpdateRefund(ctx context.Context, tx *sqlx.Tx, refund Refund) error
+	GetRefundByID(ctx context.Context, tx *sqlx.Tx, refundID int) (*Refund, error)
+	GetRefundsByTransactionID(ctx context.Context, tx *sqlx.Tx, transactionID int) ([]Refund, error)
+	GetRefundsByUserID(ctx context.Context, userID int) (*[]Refund, error)
+
+	InsertScheduledTransfer(ctx context.Context, transfer *ScheduledTransfer) error
+	UpdateScheduledTransfer(ctx context.Context, tx *sqlx.Tx, transfer ScheduledTransfer) error
+	GetScheduledTransfers(ctx context.Context, userID int) ([]ScheduledTransfer, error)
+	GetScheduledTransferByID(ctx context.Context, tx *sqlx.Tx, userID, scheduledTransferID int) (*ScheduledTransfer, error)
+	GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]ScheduledTransfer, error)
+	LockDueScheduledTransfer(ctx context.Context, tx *sqlx.Tx, scheduledTransferID int, now time.Time) (*ScheduledTransfer, error)
+
+	InsertScheduledTransferRun(ctx context.Context, tx *sqlx.Tx, run *ScheduledTransferRun) error
+	UpdateScheduledTransferRun(ctx context.Context, tx *sqlx.Tx, run ScheduledTransferRun) error
+	GetScheduledTransferRuns(ctx context.Context, userID, scheduledTransferID int) ([]ScheduledTransferRun, error)
+	GetScheduledTransferRunByID(ctx context.Context, tx *sqlx.Tx, runID int) (*ScheduledTransferRun, error)
+	GetPendingScheduledTransferRuns(ctx context.Context, updatedBefore time.Time) ([]ScheduledTransferRun, error)
 
-	GetTotalTransactionsCount(ctx context.Context, userID int, paginator *pagination.Paginator) error
-	GetTransactions(ctx context.Context, userID int, paginator *pagination.Paginator) (*[]Transaction, error)
+	InsertPaymentRequest(ctx context.Context, paymentRequest *PaymentRequest) error
+	UpdatePaymentRequest(ctx context.Context, tx *sqlx.Tx, paymentRequest PaymentRequest) error
+	GetPaymentRequestByID(ctx context.Context, tx *sqlx.Tx, paymentRequestID int) (*PaymentRequest, error)
+	GetPaymentRequestsByUserID(ctx context.Context, userID int) ([]PaymentRequest, error)
+	ExpirePaymentRequests(ctx context.Context, now time.Time) (int64, error)
+	GetProcessingPaymentRequests(ctx context.Context, updatedBefore time.Time) ([]PaymentRequest, error)
 }
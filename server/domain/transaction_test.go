@@ -0,0 +1,37 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTransactionStatusTransition(t *testing.T) {
+	testCases := []struct {
+		Title         string
+		From          string
+		To            string
+		ExpectedError error
+	}{
+		{Title: "ReturnsSuccessfully_CreatedToPending", From: constants.CREATED, To: constants.PENDING},
+		{Title: "ReturnsSuccessfully_CreatedToFailed", From: constants.CREATED, To: constants.FAILED},
+		{Title: "ReturnsSuccessfully_PendingToSuccess", From: constants.PENDING, To: constants.SUCCESS},
+		{Title: "ReturnsSuccessfully_PendingToFailed", From: constants.PENDING, To: constants.FAILED},
+		{Title: "ReturnsSuccessfully_SuccessToReversed", From: constants.SUCCESS, To: constants.REVERSED},
+		{Title: "ReturnsError_CreatedToSuccess", From: constants.CREATED, To: constants.SUCCESS, ExpectedError: exception.ErrInvalidTransactionStatusTransition},
+		{Title: "ReturnsError_SuccessToFailed", From: constants.SUCCESS, To: constants.FAILED, ExpectedError: exception.ErrInvalidTransactionStatusTransition},
+		{Title: "ReturnsError_FailedIsFinal", From: constants.FAILED, To: constants.PENDING, ExpectedError: exception.ErrInvalidTransactionStatusTransition},
+		{Title: "ReturnsError_ReversedIsFinal", From: constants.REVERSED, To: constants.SUCCESS, ExpectedError: exception.ErrInvalidTransactionStatusTransition},
+		{Title: "ReturnsError_SameStatus", From: constants.PENDING, To: constants.PENDING, ExpectedError: exception.ErrInvalidTransactionStatusTransition},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			err := domain.CheckTransactionStatusTransition(tc.From, tc.To)
+			require.Equal(t, tc.ExpectedError, err)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// TxManager runs a unit of work inside a SQL transaction
+type TxManager interface {
+	// WithTx begins a transaction, runs fn and commits if fn returns nil, otherwise
+	// rolls back. Calling WithTx again with the ctx handed to fn runs the inner fn
+	// in a savepoint of the same transaction instead of starting a new one.
+	WithTx(ctx context.Context, fn func(ctx context.Context, tx *sqlx.Tx) error) error
+}
@@ -4,50 +4,120 @@ import (
 	"context"
 
 	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/jmoiron/sqlx"
 )
 
+// Wallet statuses. An archived wallet keeps its balances but no money moves in or out of it
+// until it is restored, a closed wallet has been emptied into the main balance for good.
+const (
+	WalletStatusActive   = "active"
+	WalletStatusArchived = "archived"
+	WalletStatusClosed   = "closed"
+)
+
 type Wallet struct {
 	ID             int                    `json:"id" db:"id"`
+	Name           string                 `json:"name" db:"name"`
 	WalletType     string                 `json:"walletType" db:"wallet_type"`
 	WalletTypeID   int                    `json:"walletTypeID" db:"wallet_type_id"`
 	UserID         int                    `json:"userID" db:"user_id"`
+	Status         string                 `json:"status" db:"status"`
 	CreatedAt      string                 `json:"createdAt" db:"created_at"`
+	ClosedAt       *string                `json:"closedAt,omitempty" db:"closed_at"`
 	CurrencyAmount []WalletCurrencyAmount `json:"currencyAmount"`
+	Goal           *WalletGoal            `json:"goal,omitempty" db:"-"`
+}
+
+// CheckActive returns why money cannot move in or out of the wallet, nil when it can
+func (w Wallet) CheckActive() error {
+	switch w.Status {
+	case WalletStatusArchived:
+		return exception.ErrWalletArchived
+	case WalletStatusClosed:
+		return exception.ErrWalletClosed
+	}
+	return nil
 }
 
 type WalletCurrencyAmount struct {
-	WalletID  int     `json:"wallet_id" db:"wallet_id"`
-	Amount    float64 `json:"amount" db:"amount"`
-	Currency  string  `json:"currency" db:"currency"`
-	CreatedAt string  `json:"createdAt" db:"created_at"`
-	UpdatedAt string  `json:"updatedAt" db:"updated_at"`
+	WalletID  int          `json:"wallet_id" db:"wallet_id"`
+	Amount    money.Amount `json:"amount" db:"amount"`
+	Currency  string       `json:"currency" db:"currency"`
+	CreatedAt string       `json:"createdAt" db:"created_at"`
+	UpdatedAt string       `json:"updatedAt" db:"updated_at"`
+}
+
+// Wallet history types, recorded once per currency on each wallet a movement touches
+const (
+	WalletHistoryTransferIn  = "transfer_in"
+	WalletHistoryTransferOut = "transfer_out"
+	WalletHistoryExchangeIn  = "exchange_in"
+	WalletHistoryExchangeOut = "exchange_out"
+	WalletHistoryCloseOut    = "close_out"
+)
+
+// WalletHistory is one currency line of a movement in or out of a wallet. CounterpartyWalletID
+// is the other wallet of the movement, zero when the money came from or went to the main balance.
+type WalletHistory struct {
+	ID                   int          `json:"id" db:"id"`
+	WalletID             int          `json:"walletID" db:"wallet_id"`
+	UserID               int          `json:"userID" db:"user_id"`
+	Type                 string       `json:"type" db:"type"`
+	CounterpartyWalletID int          `json:"counterpartyWalletID" db:"counterparty_wallet_id"`
+	JournalEntryID       int          `json:"journalEntryID" db:"journal_entry_id"`
+	Currency             string       `json:"currency" db:"currency"`
+	Amount               money.Amount `json:"amount" db:"amount"`
+	CreatedAt            string       `json:"createdAt" db:"created_at"`
 }
 
 type WalletUsecase interface {
 	GetWallet(ctx context.Context, userID, walletID int) (*Wallet, error)
-	GetWallets(ctx context.Context, userID int) (*[]Wallet, error)
+	GetWallets(ctx context.Context, userID int, req dto.GetWalletsRequest) (*[]Wallet, error)
 	GetWalletTypes(ctx context.Context) (*[]dto.GetWalletTypesResponse, error)
 
 	CreateWallet(ctx context.Context, userID int, req dto.CreateWalletRequest) error
+	RenameWallet(ctx context.Context, userID, walletID int, req dto.RenameWalletRequest) error
+	ArchiveWallet(ctx context.Context, userID, walletID int) error
+	RestoreWallet(ctx context.Context, userID, walletID int) error
+	CloseWallet(ctx context.Context, userID, walletID int) (*dto.CloseWalletResponse, error)
+
+	SetWalletGoal(ctx context.Context, userID, walletID int, req dto.SetWalletGoalRequest) (*WalletGoal, error)
+	DeleteWalletGoal(ctx context.Context, userID, walletID int) error
 
 	TopUpWallet(ctx context.Context, userID, walletID int, req dto.UpdateWalletRequest) error
 	CashOutWallet(ctx context.Context, userID, walletID int, req dto.UpdateWalletRequest) error
+	TransferBetweenWallets(ctx context.Context, userID int, req dto.TransferWalletRequest) (*dto.TransferWalletResponse, error)
+	ExchangeWalletCurrency(ctx context.Context, userID, walletID int, req dto.WalletExchangeRequest) (*dto.WalletExchangeResponse, error)
 }
 
 type WalletRepository interface {
-	GetWalletByWalletID(ctx context.Context, userID, walletID int) (*Wallet, error)
-	GetWallets(ctx context.Context, userID int) ([]Wallet, error)
+	GetWalletByWalletID(ctx context.Context, tx *sqlx.Tx, userID, walletID int) (*Wallet, error)
+	GetWallets(ctx context.Context, userID int, includeClosed bool) ([]Wallet, error)
 	GetWalletTypes(ctx context.Context) (*[]dto.GetWalletTypesResponse, error)
 	GetWalletBalancesByUserID(ctx context.Context, userID int) ([]WalletCurrencyAmount, error)
 	GetWalletBalancesByUserIDAndWalletID(ctx context.Context, tx *sqlx.Tx, userID, walletID int) ([]WalletCurrencyAmount, error)
+	GetWalletBalancesByWalletIDs(ctx context.Context, tx *sqlx.Tx, userID int, walletIDs ...int) ([]WalletCurrencyAmount, error)
 
-	CheckWalletExistsByWalletTypeID(ctx context.Context, userID, walletTypeID int) (bool, error)
+	CountWallets(ctx context.Context, tx *sqlx.Tx, userID int) (int, error)
+	CheckWalletExistsByName(ctx context.Context, userID int, name string) (bool, error)
 	CheckWalletTypeExists(ctx context.Context, WalletTypeID int) (bool, error)
 
 	CreateWallet(ctx context.Context, tx *sqlx.Tx, wallet *Wallet) (int, error)
+	UpdateWalletName(ctx context.Context, userID, walletID int, name string) error
+	UpdateWalletStatus(ctx context.Context, tx *sqlx.Tx, userID, walletID int, status string) error
 	InsertWalletCurrencyAmount(ctx context.Context, tx *sqlx.Tx, walletID, userID int, currencyAmount []WalletCurrencyAmount) error
 
-	TopUpWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]float64) error
-	CashOutWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]float64) error
+	TopUpWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]money.Amount) error
+	CashOutWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]money.Amount) error
+
+	CreateWalletHistory(ctx context.Context, tx *sqlx.Tx, history []WalletHistory) error
+
+	GetWalletGoal(ctx context.Context, tx *sqlx.Tx, userID, walletID int) (*WalletGoal, error)
+	GetWalletGoals(ctx context.Context, userID int) ([]WalletGoal, error)
+	UpsertWalletGoal(ctx context.Context, goal *WalletGoal) error
+	DeleteWalletGoal(ctx context.Context, userID, walletID int) error
+	CompleteWalletGoal(ctx context.Context, tx *sqlx.Tx, goalID int) error
+	CreateWalletGoalEvent(ctx context.Context, tx *sqlx.Tx, event *WalletGoalEvent) error
 }
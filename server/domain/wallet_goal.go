@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// WalletGoal is a savings target set on a wallet. A wallet has at most one goal, and its
+// progress is worked out from the wallet balances whenever the wallet is read.
+type WalletGoal struct {
+	ID           int                 `json:"id" db:"id"`
+	WalletID     int                 `json:"walletID" db:"wallet_id"`
+	UserID       int                 `json:"userID" db:"user_id"`
+	TargetAmount money.Amount        `json:"targetAmount" db:"target_amount"`
+	Currency     string              `json:"currency" db:"currency"`
+	Deadline     *string             `json:"deadline,omitempty" db:"deadline"`
+	CompletedAt  *string             `json:"completedAt,omitempty" db:"completed_at"`
+	CreatedAt    string              `json:"createdAt" db:"created_at"`
+	Progress     *WalletGoalProgress `json:"progress,omitempty" db:"-"`
+}
+
+// WalletGoalProgress is how far a wallet is towards its goal, with every currency the wallet
+// holds converted into the goal currency. Percentage stops at 100 once the target is reached.
+type WalletGoalProgress struct {
+	SavedAmount     money.Amount `json:"savedAmount"`
+	RemainingAmount money.Amount `json:"remainingAmount"`
+	Percentage      money.Rate   `json:"percentage"`
+	Reached         bool         `json:"reached"`
+}
+
+// Wallet goal event types
+const (
+	WalletGoalEventCompleted = "completed"
+)
+
+// WalletGoalEvent is written in the same transaction as the top up that reached a goal, so
+// that whatever notifies the user reads it only once the money has actually moved.
+type WalletGoalEvent struct {
+	ID          int          `json:"id" db:"id"`
+	GoalID      int          `json:"goalID" db:"goal_id"`
+	WalletID    int          `json:"walletID" db:"wallet_id"`
+	UserID      int          `json:"userID" db:"user_id"`
+	Type        string       `json:"type" db:"type"`
+	Currency    string       `json:"currency" db:"currency"`
+	SavedAmount money.Amount `json:"savedAmount" db:"saved_amount"`
+	CreatedAt   string       `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// CreateAutoSaveRuleRequest sets up a rule that moves money from the main balance into a
+// wallet. Amount is what a schedule rule moves on every run, round up rules work it out from
+// the transfers sent in Currency. StartAt is optional, the first run is straight away when it
+// is not given.
+type CreateAutoSaveRuleRequest struct {
+	WalletID  int          `json:"wallet_id" validate:"required,gt=0"`
+	Type      string       `json:"type" validate:"required,oneof=schedule round_up"`
+	Amount    money.Amount `json:"amount" validate:"gte=0"`
+	Currency  string       `json:"currency" validate:"required,len=3"`
+	Frequency string       `json:"frequency" validate:"required,oneof=daily weekly monthly"`
+	StartAt   *time.Time   `json:"start_at"`
+}
+
+func (req *CreateAutoSaveRuleRequest) Sanitize() {
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+}
@@ -1,19 +1,24 @@
 package dto
 
-import "strings"
+import (
+	"strings"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
 
 type DepositRequest struct {
-	Balance  float64 `json:"amount" validate:"required,gt=0"`
-	Currency string  `json:"currency" validate:"required"`
-	UserID   int     `json:"-"`
+	Balance  money.Amount `json:"amount" validate:"required,gt=0"`
+	Currency string       `json:"currency" validate:"required"`
+	UserID   int          `json:"-"`
 }
 
 type GetBalanceResponse struct {
-	ID        int     `json:"id"`
-	Balance   float64 `json:"balance"`
-	Currency  string  `json:"currency" validate:"required"`
-	CreatedAt string  `json:"createdAt"`
-	UpdatedAt string  `json:"updatedAt"`
+	ID        int          `json:"id"`
+	Balance   money.Amount `json:"balance"`
+	Currency  string       `json:"currency" validate:"required"`
+	CreatedAt string       `json:"createdAt"`
+	UpdatedAt string       `json:"updatedAt"`
 }
 
 type GetBalanceHistory struct {
@@ -21,13 +26,13 @@ type GetBalanceHistory struct {
 }
 
 type BalanceHistory struct {
-	ID        int     `db:"id" json:"-"`
-	UserID    int     `db:"user_id" json:"-"`
-	BalanceID int     `db:"balance_id" json:"-"`
-	Amount    float64 `db:"amount" json:"amount"`
-	Currency  string  `db:"currency" json:"currency" validate:"required"`
-	Type      string  `db:"type" json:"type"`
-	CreatedAt string  `db:"created_at" json:"createdAt"`
+	ID        int          `db:"id" json:"-"`
+	UserID    int          `db:"user_id" json:"-"`
+	BalanceID int          `db:"balance_id" json:"-"`
+	Amount    money.Amount `db:"amount" json:"amount"`
+	Currency  string       `db:"currency" json:"currency" validate:"required"`
+	Type      string       `db:"type" json:"type"`
+	CreatedAt string       `db:"created_at" json:"createdAt"`
 }
 
 type GetBalancesResponse struct {
@@ -39,9 +44,9 @@ type GetUserBalanceCurrenciesResponse struct {
 }
 
 type WithdrawRequest struct {
-	Balance  float64 `json:"amount" validate:"required,gt=0"`
-	Currency string  `json:"currency" validate:"required"`
-	UserID   int     `json:"-"`
+	Balance  money.Amount `json:"amount" validate:"required,gt=0"`
+	Currency string       `json:"currency" validate:"required"`
+	UserID   int          `json:"-"`
 }
 
 func (req *DepositRequest) DepositSanitize() {
@@ -53,31 +58,45 @@ func (req *WithdrawRequest) WithdrawSanitize() {
 }
 
 type CurrencyExchangeRequest struct {
-	FromAmount float64 `json:"from_amount" validate:"required,gt=0"`
-	ToCurrency string  `json:"to_currency" validate:"required,len=3"`
+	FromAmount money.Amount `json:"from_amount" validate:"required,gt=0"`
+	ToCurrency string       `json:"to_currency" validate:"required,len=3"`
+	// QuoteID is optional, when given the exchange goes through at the rate locked in by the preview
+	QuoteID string `json:"quote_id" validate:"omitempty,max=255"`
 }
 
 func (req *CurrencyExchangeRequest) CurrencyExchangeSanitize() {
 	req.ToCurrency = strings.TrimSpace(req.ToCurrency)
+	req.QuoteID = strings.TrimSpace(req.QuoteID)
 }
 
 type PreviewExchangeRequest struct {
-	ActionType   string  `json:"action_type" validate:"oneof=amountToSend amountToReceive"`
-	FromAmount   float64 `json:"from_amount" validate:"omitempty,gt=0"`
-	FromCurrency string  `json:"from_currency" validate:"omitempty,len=3"`
-	ToAmount     float64 `json:"to_amount" validate:"omitempty,gt=0"`
-	ToCurrency   string  `json:"to_currency" validate:"omitempty,len=3"`
+	ActionType   string       `json:"action_type" validate:"oneof=amountToSend amountToReceive"`
+	FromAmount   money.Amount `json:"from_amount" validate:"omitempty,gt=0"`
+	FromCurrency string       `json:"from_currency" validate:"omitempty,len=3"`
+	ToAmount     money.Amount `json:"to_amount" validate:"omitempty,gt=0"`
+	ToCurrency   string       `json:"to_currency" validate:"omitempty,len=3"`
+	// Product decides the fees the quote is priced with, an exchange between the user's own balances by default
+	Product string `json:"product" validate:"omitempty,oneof=exchange transfer"`
 }
 
 type PreviewExchangeResponse struct {
-	ActionType   string  `json:"actionType"`
-	FromAmount   float64 `json:"fromAmount"`
-	FromCurrency string  `json:"fromCurrency"`
-	ToAmount     float64 `json:"toAmount"`
-	ToCurrency   string  `json:"toCurrency"`
+	ActionType   string       `json:"actionType"`
+	FromAmount   money.Amount `json:"fromAmount"`
+	FromCurrency string       `json:"fromCurrency"`
+	ToAmount     money.Amount `json:"toAmount"`
+	ToCurrency   string       `json:"toCurrency"`
+	QuoteID      string       `json:"quoteId"`
+	Fee          money.Amount `json:"fee"`
+	Rate         money.Rate   `json:"rate"`
+	Spread       money.Rate   `json:"spread"`
+	Path         []string     `json:"path"`
+	ExpiresAt    time.Time    `json:"expiresAt"`
 }
 
 func (req *PreviewExchangeRequest) PreviewExchangeSanitize() {
 	req.FromCurrency = strings.TrimSpace(req.FromCurrency)
 	req.ToCurrency = strings.TrimSpace(req.ToCurrency)
+	if req.Product == "" {
+		req.Product = "exchange"
+	}
 }
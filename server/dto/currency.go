@@ -0,0 +1,13 @@
+package dto
+
+type GetCurrencyResponse struct {
+	Code               string   `json:"code"`
+	Name               string   `json:"name"`
+	Symbol             string   `json:"symbol"`
+	Exponent           int      `json:"exponent"`
+	MobileCountryCodes []string `json:"mobile_country_codes"`
+}
+
+type GetCurrenciesResponse struct {
+	Currencies []GetCurrencyResponse `json:"currencies"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+type GetExchangeRateHistoryRequest struct {
+	From  string `form:"from" validate:"required,len=3,alpha"`
+	To    string `form:"to" validate:"required,len=3,alpha,nefield=From"`
+	Range string `form:"range" validate:"omitempty,oneof=1d 7d 30d 90d 1y"`
+}
+
+func (req *GetExchangeRateHistoryRequest) Sanitize() {
+	req.From = strings.ToUpper(strings.TrimSpace(req.From))
+	req.To = strings.ToUpper(strings.TrimSpace(req.To))
+	if req.Range == "" {
+		req.Range = constants.DEFAULT_EXCHANGE_RATE_HISTORY_RANGE
+	}
+}
+
+type ExchangeRateHistoryPoint struct {
+	Rate        money.Rate `json:"rate"`
+	Spread      money.Rate `json:"spread"`
+	EffectiveAt time.Time  `json:"effective_at"`
+}
+
+type GetExchangeRateHistoryResponse struct {
+	From  string                     `json:"from"`
+	To    string                     `json:"to"`
+	Range string                     `json:"range"`
+	Rates []ExchangeRateHistoryPoint `json:"rates"`
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+type FeeTier struct {
+	MinAmount   money.Amount `json:"min_amount" validate:"gte=0"`
+	Percentage  money.Rate   `json:"percentage" validate:"gte=0"`
+	FixedAmount money.Amount `json:"fixed_amount" validate:"gte=0"`
+}
+
+type FeeRule struct {
+	ID           int          `json:"id,omitempty"`
+	Product      string       `json:"product" validate:"required,oneof=exchange transfer withdrawal"`
+	FromCurrency string       `json:"from_currency" validate:"required,max=3"`
+	ToCurrency   string       `json:"to_currency" validate:"required,max=3"`
+	SpreadShare  money.Rate   `json:"spread_share" validate:"gte=0"`
+	Percentage   money.Rate   `json:"percentage" validate:"gte=0"`
+	FixedAmount  money.Amount `json:"fixed_amount" validate:"gte=0"`
+	MinFee       money.Amount `json:"min_fee" validate:"gte=0"`
+	MaxFee       money.Amount `json:"max_fee" validate:"gte=0"`
+	Tiers        []FeeTier    `json:"tiers,omitempty" validate:"omitempty,dive"`
+}
+
+type CreateFeeScheduleRequest struct {
+	Note string `json:"note" validate:"max=255"`
+	// EffectiveAt is optional, the schedule applies straight away when it is not given
+	EffectiveAt *time.Time `json:"effective_at"`
+	Rules       []FeeRule  `json:"rules" validate:"required,min=1,dive"`
+}
+
+func (req *CreateFeeScheduleRequest) Sanitize() {
+	req.Note = strings.TrimSpace(req.Note)
+	for idx := range req.Rules {
+		req.Rules[idx].FromCurrency = strings.ToUpper(strings.TrimSpace(req.Rules[idx].FromCurrency))
+		req.Rules[idx].ToCurrency = strings.ToUpper(strings.TrimSpace(req.Rules[idx].ToCurrency))
+	}
+}
+
+type FeeSchedule struct {
+	ID          int       `json:"id"`
+	Version     int       `json:"version"`
+	Note        string    `json:"note"`
+	EffectiveAt time.Time `json:"effective_at"`
+	CreatedBy   int       `json:"created_by"`
+	Active      bool      `json:"active"`
+	Rules       []FeeRule `json:"rules"`
+}
+
+type GetFeeSchedulesResponse struct {
+	Schedules []FeeSchedule `json:"schedules"`
+}
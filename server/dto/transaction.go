@@ -1,17 +1,104 @@
 package dto
 
-import "strings"
+import (
+	"strings"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
 
 type CreateTransactionRequest struct {
-	SenderWalletID               int     `json:"sender_wallet_id" validate:"required,min=1"`
-	SourceCurrency               string  `json:"source_currency" validate:"required,min=3,max=3"`
-	SourceAmount                 float64 `json:"source_amount" validate:"required,gt=0"`
-	BeneficiaryMobileCountryCode string  `json:"beneficiary_mobile_country_code" validate:"required,min=1,max=5"`
-	BeneficiaryMobileNumber      string  `json:"beneficiary_mobile_number" validate:"required,min=5,max=255"`
+	SenderWalletID               int          `json:"sender_wallet_id" validate:"required,min=1"`
+	SourceCurrency               string       `json:"source_currency" validate:"required,min=3,max=3"`
+	SourceAmount                 money.Amount `json:"source_amount" validate:"required,gt=0"`
+	BeneficiaryMobileCountryCode string       `json:"beneficiary_mobile_country_code" validate:"required,min=1,max=5"`
+	BeneficiaryMobileNumber      string       `json:"beneficiary_mobile_number" validate:"required,min=5,max=255"`
+	// QuoteID is optional, when given a transfer that needs converting goes through at the rate locked in by the preview
+	QuoteID string `json:"quote_id" validate:"omitempty,max=255"`
+	// AutoConvert tops up the source currency from the other currencies of the wallet when it is short
+	AutoConvert bool `json:"auto_convert"`
 }
 
 func (req *CreateTransactionRequest) Sanitize() {
+	req.SourceCurrency = strings.TrimSpace(req.SourceCurrency)
+	req.QuoteID = strings.TrimSpace(req.QuoteID)
+	req.BeneficiaryMobileNumber = strings.TrimSpace(req.BeneficiaryMobileNumber)
+	req.BeneficiaryMobileCountryCode = strings.TrimSpace(req.BeneficiaryMobileCountryCode)
+}
+
+// CreateScheduledTransferRequest schedules a transfer to a beneficiary for StartAt. A recurring
+// transfer is made again at every run of its Frequency, until EndAt or until it has run MaxRuns
+// times when either is given. Both are ignored for a transfer that runs once.
+type CreateScheduledTransferRequest struct {
+	SenderWalletID               int          `json:"sender_wallet_id" validate:"required,min=1"`
+	SourceCurrency               string       `json:"source_currency" validate:"required,min=3,max=3"`
+	SourceAmount                 money.Amount `json:"source_amount" validate:"required,gt=0"`
+	BeneficiaryMobileCountryCode string       `json:"beneficiary_mobile_country_code" validate:"required,min=1,max=5"`
+	BeneficiaryMobileNumber      string       `json:"beneficiary_mobile_number" validate:"required,min=5,max=255"`
+	AutoConvert                  bool         `json:"auto_convert"`
+	Frequency                    string       `json:"frequency" validate:"required,oneof=once daily weekly monthly"`
+	StartAt                      time.Time    `json:"start_at" validate:"required"`
+	EndAt                        *time.Time   `json:"end_at"`
+	MaxRuns                      int          `json:"max_runs" validate:"gte=0"`
+}
+
+func (req *CreateScheduledTransferRequest) Sanitize() {
 	req.SourceCurrency = strings.TrimSpace(req.SourceCurrency)
 	req.BeneficiaryMobileNumber = strings.TrimSpace(req.BeneficiaryMobileNumber)
 	req.BeneficiaryMobileCountryCode = strings.TrimSpace(req.BeneficiaryMobileCountryCode)
 }
+
+// RequestPaymentRequest asks a linked beneficiary for Amount in Currency, Note tells them what it is for
+type RequestPaymentRequest struct {
+	BeneficiaryMobileCountryCode string       `json:"beneficiary_mobile_country_code" validate:"required,min=1,max=5"`
+	BeneficiaryMobileNumber      string       `json:"beneficiary_mobile_number" validate:"required,min=5,max=255"`
+	Amount                       money.Amount `json:"amount" validate:"required,gt=0"`
+	Currency                     string       `json:"currency" validate:"required,len=3"`
+	Note                         string       `json:"note" validate:"max=255"`
+}
+
+func (req *RequestPaymentRequest) Sanitize() {
+	req.BeneficiaryMobileCountryCode = strings.TrimSpace(req.BeneficiaryMobileCountryCode)
+	req.BeneficiaryMobileNumber = strings.TrimSpace(req.BeneficiaryMobileNumber)
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	req.Note = strings.TrimSpace(req.Note)
+}
+
+// PayPaymentRequest pays a payment request from one of the payer's wallets
+type PayPaymentRequest struct {
+	SenderWalletID int `json:"sender_wallet_id" validate:"required,min=1"`
+	// AutoConvert tops up the requested currency from the other currencies of the wallet when it is short
+	AutoConvert bool `json:"auto_convert"`
+}
+
+type RefundTransactionRequest struct {
+	// Amount is in the currency the beneficiary received, zero refunds everything not refunded yet
+	Amount   money.Amount `json:"amount" validate:"gte=0"`
+	RateType string       `json:"rate_type" validate:"required,oneof=original current"`
+	Reason   string       `json:"reason" validate:"max=255"`
+}
+
+func (req *RefundTransactionRequest) Sanitize() {
+	req.RateType = strings.ToLower(strings.TrimSpace(req.RateType))
+	req.Reason = strings.TrimSpace(req.Reason)
+}
+
+// TransactionFilter narrows down and orders a user's transaction history, every field is optional.
+// Transactions from From up to but not including To are returned, and amounts are compared against
+// what the user sent or received in the currency they sent or received it in.
+type TransactionFilter struct {
+	From         time.Time    `form:"from"`
+	To           time.Time    `form:"to" validate:"omitempty,gtfield=From"`
+	Currency     string       `form:"currency" validate:"omitempty,len=3,alpha"`
+	Direction    string       `form:"direction" validate:"omitempty,oneof=sent received"`
+	Status       string       `form:"status" validate:"omitempty,oneof=CREATED PENDING SUCCESS FAILED REVERSED"`
+	Counterparty string       `form:"counterparty" validate:"max=255"`
+	MinAmount    money.Amount `form:"min_amount" validate:"gte=0"`
+	MaxAmount    money.Amount `form:"max_amount" validate:"omitempty,gtefield=MinAmount"`
+	Sort         string       `form:"sort" validate:"omitempty,oneof=date_desc date_asc amount_desc amount_asc"`
+}
+
+func (f *TransactionFilter) Sanitize() {
+	f.Currency = strings.ToUpper(strings.TrimSpace(f.Currency))
+	f.Counterparty = strings.TrimSpace(f.Counterparty)
+}
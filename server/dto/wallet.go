@@ -2,22 +2,25 @@ package dto
 
 import (
 	"strings"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 )
 
 type UpdateWalletResponse struct {
-	WalletID int     `json:"wallet_id"`
-	Type     string  `json:"type"`
-	Balance  float64 `json:"balance"`
+	WalletID int          `json:"wallet_id"`
+	Type     string       `json:"type"`
+	Balance  money.Amount `json:"balance"`
 }
 
 type CreateWalletRequest struct {
 	WalletTypeID   int              `json:"wallet_type_id" validate:"required,gt=0"`
+	Name           string           `json:"name" validate:"max=50"`
 	CurrencyAmount []CurrencyAmount `json:"currency_amount" validate:"required"`
 }
 
 type CurrencyAmount struct {
-	Amount   float64 `json:"amount" db:"amount" validate:"required,gt=0"`
-	Currency string  `json:"currency" db:"currency" validate:"required,min=0,max=3"`
+	Amount   money.Amount `json:"amount" db:"amount" validate:"required,gt=0"`
+	Currency string       `json:"currency" db:"currency" validate:"required,min=0,max=3"`
 }
 
 type GetWalletsResponse struct {
@@ -33,19 +36,53 @@ type GetWalletResponse struct {
 }
 
 type WalletCurrencyAmount struct {
-	WalletID  int     `json:"wallet_id" db:"id"`
-	Amount    float64 `json:"amount" db:"amount"`
-	Currency  string  `json:"currency" db:"currency"`
-	CreatedAt string  `json:"createdAt" db:"created_at"`
-	UpdatedAt string  `json:"updatedAt" db:"updated_at"`
+	WalletID  int          `json:"wallet_id" db:"id"`
+	Amount    money.Amount `json:"amount" db:"amount"`
+	Currency  string       `json:"currency" db:"currency"`
+	CreatedAt string       `json:"createdAt" db:"created_at"`
+	UpdatedAt string       `json:"updatedAt" db:"updated_at"`
 }
 
 func (req *CreateWalletRequest) CreateWalletSanitize() {
+	req.Name = strings.TrimSpace(req.Name)
 	for idx, c := range req.CurrencyAmount {
 		req.CurrencyAmount[idx].Currency = strings.TrimSpace(c.Currency)
 	}
 }
 
+// GetWalletsRequest filters the wallets listed, closed wallets are left out unless asked for
+type GetWalletsRequest struct {
+	IncludeClosed bool `form:"include_closed"`
+}
+
+type RenameWalletRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+func (req *RenameWalletRequest) Sanitize() {
+	req.Name = strings.TrimSpace(req.Name)
+}
+
+// SetWalletGoalRequest sets the savings goal of a wallet, replacing the goal it had. Deadline
+// is an optional date formatted as 2006-01-02.
+type SetWalletGoalRequest struct {
+	TargetAmount money.Amount `json:"target_amount" validate:"required,gt=0"`
+	Currency     string       `json:"currency" validate:"required,len=3"`
+	Deadline     string       `json:"deadline" validate:"omitempty,datetime=2006-01-02"`
+}
+
+func (req *SetWalletGoalRequest) Sanitize() {
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	req.Deadline = strings.TrimSpace(req.Deadline)
+}
+
+// CloseWalletResponse lists what was left in the wallet and moved back to the main balance
+type CloseWalletResponse struct {
+	WalletID    int              `json:"wallet_id"`
+	Status      string           `json:"status"`
+	SweptAmount []CurrencyAmount `json:"swept_amount"`
+}
+
 type GetWalletTypesResponse struct {
 	ID         int    `json:"id" db:"id"`
 	WalletType string `json:"walletType" db:"type"`
@@ -65,3 +102,48 @@ func (req *UpdateWalletRequest) TopUpWalletSanitize() {
 		req.CurrencyAmount[idx].Currency = strings.TrimSpace(ca.Currency)
 	}
 }
+
+type TransferWalletRequest struct {
+	FromWalletID   int              `json:"from_wallet_id" validate:"required,gt=0"`
+	ToWalletID     int              `json:"to_wallet_id" validate:"required,gt=0"`
+	CurrencyAmount []CurrencyAmount `json:"currency_amount" validate:"required,min=1,dive"`
+}
+
+func (req *TransferWalletRequest) Sanitize() {
+	for idx, ca := range req.CurrencyAmount {
+		req.CurrencyAmount[idx].Currency = strings.ToUpper(strings.TrimSpace(ca.Currency))
+	}
+}
+
+// WalletBalances are the balances a wallet holds in every currency, ordered by currency
+type WalletBalances struct {
+	WalletID       int              `json:"wallet_id"`
+	CurrencyAmount []CurrencyAmount `json:"currency_amount"`
+}
+
+type TransferWalletResponse struct {
+	FromWallet WalletBalances `json:"from_wallet"`
+	ToWallet   WalletBalances `json:"to_wallet"`
+}
+
+type WalletExchangeRequest struct {
+	FromCurrency string       `json:"from_currency" validate:"required,len=3"`
+	ToCurrency   string       `json:"to_currency" validate:"required,len=3"`
+	FromAmount   money.Amount `json:"from_amount" validate:"required,gt=0"`
+}
+
+func (req *WalletExchangeRequest) Sanitize() {
+	req.FromCurrency = strings.ToUpper(strings.TrimSpace(req.FromCurrency))
+	req.ToCurrency = strings.ToUpper(strings.TrimSpace(req.ToCurrency))
+}
+
+type WalletExchangeResponse struct {
+	WalletID       int              `json:"wallet_id"`
+	FromAmount     money.Amount     `json:"from_amount"`
+	FromCurrency   string           `json:"from_currency"`
+	ToAmount       money.Amount     `json:"to_amount"`
+	ToCurrency     string           `json:"to_currency"`
+	Fee            money.Amount     `json:"fee"`
+	Rate           money.Rate       `json:"rate"`
+	CurrencyAmount []CurrencyAmount `json:"currency_amount"`
+}
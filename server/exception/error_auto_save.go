@@ -0,0 +1,11 @@
+package exception
+
+import "errors"
+
+var (
+	ErrAutoSaveRuleNotFound   = errors.New("auto-save rule not found")
+	ErrNoAutoSaveRulesFound   = errors.New("no auto-save rules found")
+	ErrAutoSaveAmountRequired = errors.New("scheduled auto-save rule requires an amount")
+	ErrAutoSaveRulePaused     = errors.New("auto-save rule is already paused")
+	ErrAutoSaveRuleNotPaused  = errors.New("auto-save rule is not paused")
+)
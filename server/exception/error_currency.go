@@ -0,0 +1,9 @@
+package exception
+
+import "errors"
+
+var (
+	ErrCurrencyNotSupported    = errors.New("currency is not supported")
+	ErrCurrencyDisabled        = errors.New("currency is disabled")
+	ErrCountryCurrencyNotFound = errors.New("no home currency for mobile country code")
+)
@@ -0,0 +1,35 @@
+package exception
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	ErrFXConversionNotFound = errors.New("fx conversion not found")
+
+	ErrExchangeQuoteInvalid  = errors.New("exchange quote is invalid")
+	ErrExchangeQuoteExpired  = errors.New("exchange quote has expired")
+	ErrExchangeQuoteUsed     = errors.New("exchange quote has already been used")
+	ErrExchangeQuoteMismatch = errors.New("exchange quote does not match the request")
+)
+
+// NoExchangeRouteError is returned when a currency pair has no direct rate and cannot be
+// derived through the base currency either. It matches ErrExchangeRateNotFound.
+type NoExchangeRouteError struct {
+	FromCurrency string
+	ToCurrency   string
+	BaseCurrency string
+}
+
+func (e *NoExchangeRouteError) Error() string {
+	if e.BaseCurrency == "" {
+		return fmt.Sprintf("no exchange route from %s to %s", e.FromCurrency, e.ToCurrency)
+	}
+	return fmt.Sprintf("no exchange route from %s to %s, directly or through %s", e.FromCurrency, e.ToCurrency, e.BaseCurrency)
+}
+
+func (e *NoExchangeRouteError) Unwrap() error {
+	return ErrExchangeRateNotFound
+}
@@ -0,0 +1,13 @@
+package exception
+
+import "errors"
+
+var (
+	ErrFeeScheduleNotFound     = errors.New("fee schedule not found")
+	ErrFeeRuleInvalid          = errors.New("fee rule is invalid")
+	ErrFeeRuleDuplicated       = errors.New("fee rule is duplicated for the same product and corridor")
+	ErrFeeRuleCurrencyRequired = errors.New("fee rule with fixed, minimum, maximum or tiered fees must name the source currency")
+
+	ErrFeesExceedAmount = errors.New("fees exceed the amount")
+	ErrAmountTooLarge   = errors.New("amount is too large to convert")
+)
@@ -0,0 +1,10 @@
+package exception
+
+import "errors"
+
+var (
+	ErrEmptyJournalEntry       = errors.New("journal entry has no postings")
+	ErrUnbalancedJournalEntry  = errors.New("journal entry does not sum to zero per currency")
+	ErrNegativePostingAmount   = errors.New("posting amount cannot be negative")
+	ErrInvalidPostingDirection = errors.New("posting direction must be DEBIT or CREDIT")
+)
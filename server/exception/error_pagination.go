@@ -0,0 +1,8 @@
+package exception
+
+import "errors"
+
+var (
+	ErrInvalidCursor          = errors.New("invalid pagination cursor")
+	ErrCursorSortNotSupported = errors.New("cursor pagination only supports sorting by date")
+)
@@ -15,4 +15,33 @@ var (
 	ErrInsufficientFundsInWallet               = errors.New("insufficient funds for transfer in wallet")
 
 	ErrNoTransactionsFound = errors.New("no transactions found")
+	ErrTransactionNotFound = errors.New("transaction not found")
+
+	// transaction lifecycle
+	ErrInvalidTransactionStatusTransition = errors.New("invalid transaction status transition")
+	ErrTransactionStatusConflict          = errors.New("transaction is no longer in the expected status")
+
+	// refunds
+	ErrTransactionNotRefundable   = errors.New("transaction cannot be refunded")
+	ErrRefundWindowExpired        = errors.New("refund window has expired")
+	ErrRefundAmountExceeded       = errors.New("refund amount exceeds what is left to refund")
+	ErrRefundNotFound             = errors.New("refund not found")
+	ErrNoRefundsFound             = errors.New("no refunds found")
+	ErrRefundAlreadyResolved      = errors.New("refund has already been approved or declined")
+	ErrInsufficientFundsForRefund = errors.New("insufficient funds in beneficiary balance for refund")
+
+	// scheduled transfers
+	ErrScheduledTransferNotFound       = errors.New("scheduled transfer not found")
+	ErrNoScheduledTransfersFound       = errors.New("no scheduled transfers found")
+	ErrScheduledTransferStartPassed    = errors.New("scheduled transfer start has passed")
+	ErrScheduledTransferEndBeforeStart = errors.New("scheduled transfer ends before it starts")
+	ErrScheduledTransferNotActive      = errors.New("scheduled transfer is not active")
+	ErrScheduledTransferNotPaused      = errors.New("scheduled transfer is not paused")
+	ErrScheduledTransferFinished       = errors.New("scheduled transfer has already completed or been cancelled")
+
+	// payment requests
+	ErrPaymentRequestNotFound   = errors.New("payment request not found")
+	ErrNoPaymentRequestsFound   = errors.New("no payment requests found")
+	ErrPaymentRequestExpired    = errors.New("payment request has expired")
+	ErrPaymentRequestNotPending = errors.New("payment request is no longer pending")
 )
@@ -13,6 +13,7 @@ var (
 var (
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrInactiveUser       = errors.New("user is inactive")
+	ErrUserNotAdmin       = errors.New("user is not an admin")
 )
 
 var (
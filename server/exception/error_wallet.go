@@ -4,7 +4,8 @@ import "errors"
 
 var (
 	ErrWalletTypeInvalid   = errors.New("wallet type invalid")
-	ErrWalletAlreadyExists = errors.New("wallet already exists for this user")
+	ErrWalletAlreadyExists = errors.New("wallet with this name already exists for this user")
+	ErrWalletLimitReached  = errors.New("user has reached the maximum number of wallets")
 
 	ErrNoWalletsFound = errors.New("no wallets found for this user")
 	ErrNoWalletFound  = errors.New("no wallet found for this user")
@@ -16,4 +17,14 @@ var (
 	ErrWalletBalancesNotFound = errors.New("wallet balances not found")
 
 	ErrWalletTypesNotFound = errors.New("wallet types not found")
+
+	ErrWalletTransferSameWallet = errors.New("cannot transfer between the same wallet")
+
+	ErrWalletArchived    = errors.New("wallet is archived")
+	ErrWalletClosed      = errors.New("wallet is closed")
+	ErrWalletNotArchived = errors.New("wallet is not archived")
+	ErrWalletNameInvalid = errors.New("wallet name is invalid")
+
+	ErrWalletGoalNotFound       = errors.New("wallet goal not found")
+	ErrWalletGoalDeadlinePassed = errors.New("wallet goal deadline has passed")
 )
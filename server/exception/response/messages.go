@@ -8,6 +8,12 @@ var (
 	ErrForbidden           = "Forbidden"
 )
 
+// Pagination
+var (
+	ErrInvalidCursor          = "Invalid cursor. Please start again from the first page."
+	ErrCursorSortNotSupported = "Cursor pagination can only be sorted by date."
+)
+
 // User
 var (
 	ErrInvalidCredentials = "Invalid Credentials. Please try again."
@@ -28,7 +34,8 @@ var (
 // Wallet
 var (
 	ErrWalletTypeInvalid   = "Wallet type is invalid. Please try another wallet type."
-	ErrWalletAlreadyExists = "The wallet you are trying to create already exist. Please try again."
+	ErrWalletAlreadyExists = "A wallet with this name already exists. Please choose another name."
+	ErrWalletLimitReached  = "You have reached the maximum number of wallets. Please close a wallet before creating another."
 
 	ErrNoWalletsFound = "No wallets found."
 	ErrNoWalletFound  = "Wallet not found."
@@ -40,6 +47,16 @@ var (
 
 	ErrNoWalletBalancesFound = "No wallet balances found. Please top up."
 	ErrWalletTypesNotFound   = "No wallet types found."
+
+	ErrWalletTransferSameWallet = "Unable to transfer as both wallets are the same. Please choose another wallet."
+
+	ErrWalletArchived    = "Wallet is archived. Please restore the wallet before using it."
+	ErrWalletClosed      = "Wallet is closed."
+	ErrWalletNotArchived = "Wallet is not archived."
+	ErrWalletNameInvalid = "Wallet name cannot be empty. Please enter a name."
+
+	ErrWalletGoalNotFound       = "Savings goal not found."
+	ErrWalletGoalDeadlinePassed = "Savings goal deadline has passed. Please choose a later date."
 )
 
 // Balance
@@ -54,6 +71,9 @@ var (
 
 	ErrDepositCurrencyNotAllowed  = "Deposit Currency is not allowed."
 	ErrWithdrawCurrencyNotAllowed = "Withdraw Currency is not allowed."
+	ErrToCurrencyNotAllowed       = "Currency Exchange to the specified currency is not allowed."
+
+	ErrFromCurrencyEqualToCurrency = "Unable to exchange a currency into itself. Please choose another currency."
 
 	ErrUserCurrenciesNotFound = "User currencies not found. Please inform system administrator."
 )
@@ -71,9 +91,83 @@ var (
 var (
 	ErrUserAndWalletAssociationNotFound = "User is not associated with the specified wallet."
 	ErrNoTransactionsFound              = "No transactions found."
+	ErrTransactionNotFound              = "Transaction not found."
 
 	ErrBeneficiaryAccountNotRegistered = "Beneficiary is currently not a registered user. Please try again later or contact the System Administrator."
 	ErrInsufficientFundsInWallet       = "Insufficient funds in the specified wallet. Please top up."
 
 	ErrSenderWalletInvalid = "No wallet found with the specified Wallet ID. Please try again."
 )
+
+// Currency
+var (
+	ErrCurrencyNotSupported    = "Currency is not supported."
+	ErrCurrencyDisabled        = "Currency is currently unavailable. Please try again later."
+	ErrCountryCurrencyNotFound = "Mobile country code is not supported."
+)
+
+// Exchange Rate
+var (
+	ErrExchangeRateNotFound = "Currency exchange between the specified currencies is not supported."
+
+	ErrExchangeQuoteInvalid  = "Quote is invalid. Please preview the exchange again."
+	ErrExchangeQuoteExpired  = "Quote has expired. Please preview the exchange again."
+	ErrExchangeQuoteUsed     = "Quote has already been used. Please preview the exchange again."
+	ErrExchangeQuoteMismatch = "Quote does not match the amount or currencies of this request."
+)
+
+// Fee
+var (
+	ErrFeeScheduleNotFound     = "No fee schedule found."
+	ErrFeeRuleInvalid          = "Fee rule is invalid. Percentages must be below 100% and amounts cannot be negative or have a maximum below the minimum."
+	ErrFeeRuleDuplicated       = "More than one fee rule was given for the same product and currencies."
+	ErrFeeRuleCurrencyRequired = "Fee rules with fixed, minimum, maximum or tiered fees must specify the from currency."
+	ErrFeesExceedAmount        = "The amount is too small to cover the fees. Please increase the amount."
+	ErrAmountTooLarge          = "The amount is too large to convert. Please reduce the amount."
+)
+
+// Refund
+var (
+	ErrTransactionNotRefundable   = "This transaction cannot be refunded."
+	ErrRefundWindowExpired        = "The time allowed to refund this transaction has passed."
+	ErrRefundAmountExceeded       = "Refund amount is more than what is left to refund on this transaction."
+	ErrRefundNotFound             = "Refund not found."
+	ErrNoRefundsFound             = "No refunds found."
+	ErrRefundAlreadyResolved      = "This refund has already been approved or declined."
+	ErrInsufficientFundsForRefund = "Insufficient funds in your balance to approve this refund. Please top up."
+)
+
+// Scheduled transfer
+var (
+	ErrScheduledTransferNotFound       = "Scheduled transfer not found."
+	ErrNoScheduledTransfersFound       = "No scheduled transfers found."
+	ErrScheduledTransferStartPassed    = "Scheduled transfers must start in the future."
+	ErrScheduledTransferEndBeforeStart = "Scheduled transfers must end after they start."
+	ErrScheduledTransferNotActive      = "Only active scheduled transfers can be paused."
+	ErrScheduledTransferNotPaused      = "Scheduled transfer is not paused."
+	ErrScheduledTransferFinished       = "This scheduled transfer has already completed or been cancelled."
+)
+
+// Payment request
+var (
+	ErrPaymentRequestNotFound   = "Payment request not found."
+	ErrNoPaymentRequestsFound   = "No payment requests found."
+	ErrPaymentRequestExpired    = "This payment request has expired."
+	ErrPaymentRequestNotPending = "This payment request has already been paid, declined or cancelled, or is being paid."
+)
+
+// Auto-save
+var (
+	ErrAutoSaveRuleNotFound   = "Auto-save rule not found."
+	ErrNoAutoSaveRulesFound   = "No auto-save rules found."
+	ErrAutoSaveAmountRequired = "Scheduled auto-save rules need an amount greater than 0."
+	ErrAutoSaveRulePaused     = "Auto-save rule is already paused."
+	ErrAutoSaveRuleNotPaused  = "Auto-save rule is not paused."
+)
+
+// Idempotency
+var (
+	ErrIdempotencyKeyInvalid    = "Idempotency-Key must be between 1 and 255 characters."
+	ErrIdempotencyKeyReused     = "Idempotency-Key has already been used for a different request. Please use a new key."
+	ErrIdempotencyKeyInProgress = "A request with this Idempotency-Key is still being processed. Please retry later."
+)
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -53,6 +54,30 @@ type Config struct {
 		RedisHost string `mapstructure:"redis_host"`
 		RedisPort int    `mapstructure:"redis_port"`
 	} `mapstructure:"redis"`
+	Transaction struct {
+		RefundWindow               time.Duration `mapstructure:"refund_window"`
+		ScheduledTransferBatchSize int           `mapstructure:"scheduled_transfer_batch_size"`
+		PaymentRequestExpiry       time.Duration `mapstructure:"payment_request_expiry"`
+	} `mapstructure:"transaction"`
+	ExchangeRate struct {
+		CacheTTL     time.Duration `mapstructure:"cache_ttl"`
+		QuoteTTL     time.Duration `mapstructure:"quote_ttl"`
+		QuoteSecret  string        `mapstructure:"quote_secret"`
+		BaseCurrency string        `mapstructure:"base_currency"`
+	} `mapstructure:"exchange_rate"`
+	Currency struct {
+		CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	} `mapstructure:"currency"`
+	Wallet struct {
+		MaxWalletsPerUser int `mapstructure:"max_wallets_per_user"`
+	} `mapstructure:"wallet"`
+	Scheduler struct {
+		Interval time.Duration `mapstructure:"interval"`
+	} `mapstructure:"scheduler"`
+	AutoSave struct {
+		MaxFailures int `mapstructure:"max_failures"`
+		BatchSize   int `mapstructure:"batch_size"`
+	} `mapstructure:"auto_save"`
 }
 
 func LoadConfig() (*Config, error) {
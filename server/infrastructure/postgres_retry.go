@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Postgres error codes for transactions that can safely be run again
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgUniqueViolation      = "23505"
+)
+
+// retryBackoff is the base wait between attempts, multiplied by the attempt number
+var retryBackoff = 20 * time.Millisecond
+
+// IsRetryableTxError reports whether err is a serialization failure or a deadlock,
+// in which case Postgres has already rolled the transaction back and it can be retried.
+func IsRetryableTxError(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgSerializationFailure || pgErr.Code == pgDeadlockDetected
+}
+
+// IsUniqueViolation reports whether err was raised by a unique constraint or index,
+// which is never retried since running the statement again hits the same row.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == pgUniqueViolation
+}
+
+// RetryTx runs fn up to maxAttempts times for as long as it fails with a retryable
+// error. fn must begin and complete its own transaction so that every attempt
+// starts from a clean state.
+func RetryTx(ctx context.Context, maxAttempts int, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = fn()
+		if err == nil || !IsRetryableTxError(err) {
+			return err
+		}
+
+		log.Printf("retrying sql transaction after attempt %d failed with error: %v\n", attempt, err)
+		if attempt == maxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * retryBackoff):
+		}
+	}
+	return err
+}
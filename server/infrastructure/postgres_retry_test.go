@@ -0,0 +1,101 @@
+package infrastructure_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"SerializationFailure", &pgconn.PgError{Code: "40001"}, true},
+		{"DeadlockDetected", &pgconn.PgError{Code: "40P01"}, true},
+		{"WrappedDeadlock", fmt.Errorf("update balances: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{"UniqueViolation", &pgconn.PgError{Code: "23505"}, false},
+		{"NotAPostgresError", errors.New("insufficient funds"), false},
+		{"Nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, infrastructure.IsRetryableTxError(tt.err))
+		})
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"UniqueViolation", &pgconn.PgError{Code: "23505"}, true},
+		{"WrappedUniqueViolation", fmt.Errorf("create wallet: %w", &pgconn.PgError{Code: "23505"}), true},
+		{"DeadlockDetected", &pgconn.PgError{Code: "40P01"}, false},
+		{"NotAPostgresError", errors.New("insufficient funds"), false},
+		{"Nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, infrastructure.IsUniqueViolation(tt.err))
+		})
+	}
+}
+
+func TestRetryTx(t *testing.T) {
+	t.Run("RetriesUntilSuccess", func(t *testing.T) {
+		calls := 0
+		err := infrastructure.RetryTx(context.Background(), 3, func() error {
+			calls++
+			if calls < 3 {
+				return &pgconn.PgError{Code: "40001"}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		calls := 0
+		err := infrastructure.RetryTx(context.Background(), 2, func() error {
+			calls++
+			return &pgconn.PgError{Code: "40P01"}
+		})
+		require.True(t, infrastructure.IsRetryableTxError(err))
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("DoesNotRetryOtherErrors", func(t *testing.T) {
+		calls := 0
+		expected := errors.New("insufficient funds")
+		err := infrastructure.RetryTx(context.Background(), 3, func() error {
+			calls++
+			return expected
+		})
+		require.Equal(t, expected, err)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("StopsWhenContextIsCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		err := infrastructure.RetryTx(ctx, 3, func() error {
+			calls++
+			cancel()
+			return &pgconn.PgError{Code: "40001"}
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1, calls)
+	})
+}
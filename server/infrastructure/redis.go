@@ -19,6 +19,7 @@ type RedisClient interface {
 
 	Set(ctx context.Context, key string, value interface{}) error
 	SetEx(ctx context.Context, key string, member interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
 	Get(ctx context.Context, key string) (string, error)
 	GetEx(ctx context.Context, key string, expiration time.Duration) (string, error)
 
@@ -79,6 +80,11 @@ func (rc *RedisClientImpl) SetEx(ctx context.Context, key string, member interfa
 	return rc.client.SetEx(ctx, key, member, expiration).Err()
 }
 
+// SetNX sets the key only if it does not exist yet, returns true if the key was set
+func (rc *RedisClientImpl) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return rc.client.SetNX(ctx, key, value, expiration).Result()
+}
+
 func (rc *RedisClientImpl) Get(ctx context.Context, key string) (string, error) {
 	return rc.client.Get(ctx, key).Result()
 }
@@ -0,0 +1,86 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/mock"
+)
+
+type AutoSaveRepository struct {
+	mock.Mock
+}
+
+type AutoSaveRepositoryReturnValues struct {
+	CreateAutoSaveRule  []interface{}
+	GetAutoSaveRules    []interface{}
+	GetAutoSaveRule     []interface{}
+	GetDueAutoSaveRules []interface{}
+	LockDueAutoSaveRule []interface{}
+	UpdateAutoSaveRule  []interface{}
+	DeleteAutoSaveRule  []interface{}
+	CreateAutoSaveRun   []interface{}
+	GetAutoSaveRuns     []interface{}
+}
+
+func (m *AutoSaveRepository) CreateAutoSaveRule(ctx context.Context, rule *domain.AutoSaveRule) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *AutoSaveRepository) GetAutoSaveRules(ctx context.Context, userID int) ([]domain.AutoSaveRule, error) {
+	args := m.Called(ctx, userID)
+	if v, ok := args.Get(0).([]domain.AutoSaveRule); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AutoSaveRepository) GetAutoSaveRule(ctx context.Context, tx *sqlx.Tx, userID, ruleID int) (*domain.AutoSaveRule, error) {
+	args := m.Called(ctx, tx, userID, ruleID)
+	if v, ok := args.Get(0).(*domain.AutoSaveRule); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AutoSaveRepository) GetDueAutoSaveRules(ctx context.Context, now time.Time, limit int) ([]domain.AutoSaveRule, error) {
+	args := m.Called(ctx, now, limit)
+	if v, ok := args.Get(0).([]domain.AutoSaveRule); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AutoSaveRepository) LockDueAutoSaveRule(ctx context.Context, tx *sqlx.Tx, ruleID int, now time.Time) (*domain.AutoSaveRule, error) {
+	args := m.Called(ctx, tx, ruleID, now)
+	if v, ok := args.Get(0).(*domain.AutoSaveRule); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *AutoSaveRepository) UpdateAutoSaveRule(ctx context.Context, tx *sqlx.Tx, rule domain.AutoSaveRule) error {
+	args := m.Called(ctx, tx, rule)
+	return args.Error(0)
+}
+
+func (m *AutoSaveRepository) DeleteAutoSaveRule(ctx context.Context, userID, ruleID int) error {
+	args := m.Called(ctx, userID, ruleID)
+	return args.Error(0)
+}
+
+func (m *AutoSaveRepository) CreateAutoSaveRun(ctx context.Context, tx *sqlx.Tx, run *domain.AutoSaveRun) error {
+	args := m.Called(ctx, tx, run)
+	return args.Error(0)
+}
+
+func (m *AutoSaveRepository) GetAutoSaveRuns(ctx context.Context, userID, ruleID int) ([]domain.AutoSaveRun, error) {
+	args := m.Called(ctx, userID, ruleID)
+	if v, ok := args.Get(0).([]domain.AutoSaveRun); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
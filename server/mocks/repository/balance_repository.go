@@ -5,6 +5,8 @@ import (
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/mock"
 )
@@ -14,6 +16,7 @@ type BalanceRepository struct {
 }
 
 type BalanceRepositoryReturnValues struct {
+	GetBalanceHistoryCount   []interface{}
 	GetBalanceHistory        []interface{}
 	GetBalances              []interface{}
 	GetBalance               []interface{}
@@ -23,11 +26,15 @@ type BalanceRepositoryReturnValues struct {
 	CreateBalance            []interface{}
 	UpdateBalance            []interface{}
 	UpdateBalances           []interface{}
-	LogCreatorProfit         []interface{}
 }
 
-func (m *BalanceRepository) GetBalanceHistory(ctx context.Context, userID, balanceID int) (*[]dto.BalanceHistory, error) {
-	args := m.Called(ctx, userID, balanceID)
+func (m *BalanceRepository) GetBalanceHistoryCount(ctx context.Context, userID, balanceID int, paginator *pagination.Paginator) error {
+	args := m.Called(ctx, userID, balanceID, paginator)
+	return args.Error(0)
+}
+
+func (m *BalanceRepository) GetBalanceHistory(ctx context.Context, userID, balanceID int, paginator *pagination.Paginator) (*[]dto.BalanceHistory, error) {
+	args := m.Called(ctx, userID, balanceID, paginator)
 
 	var balanceHistory *[]dto.BalanceHistory
 	if v, ok := args.Get(0).(*[]dto.BalanceHistory); ok {
@@ -81,7 +88,7 @@ func (m *BalanceRepository) GetBalanceByID(ctx context.Context, userID int, bala
 	return balance, args.Error(1)
 }
 
-func (m *BalanceRepository) CreateBalanceHistory(ctx context.Context, tx *sqlx.Tx, balance *domain.Balance, depositedBalance float64, balanceType string) error {
+func (m *BalanceRepository) CreateBalanceHistory(ctx context.Context, tx *sqlx.Tx, balance *domain.Balance, depositedBalance money.Amount, balanceType string) error {
 	args := m.Called(ctx, tx, balance, depositedBalance, balanceType)
 	return args.Error(0)
 }
@@ -96,12 +103,7 @@ func (m *BalanceRepository) UpdateBalance(ctx context.Context, tx *sqlx.Tx, bala
 	return args.Error(0)
 }
 
-func (m *BalanceRepository) UpdateBalances(ctx context.Context, tx *sqlx.Tx, userID int, finalBalancesMap map[string]float64) error {
+func (m *BalanceRepository) UpdateBalances(ctx context.Context, tx *sqlx.Tx, userID int, finalBalancesMap map[string]money.Amount) error {
 	args := m.Called(ctx, tx, userID, finalBalancesMap)
 	return args.Error(0)
 }
-
-func (m *BalanceRepository) LogCreatorProfit(ctx context.Context, tx *sqlx.Tx, profit float64, currency string) error {
-	args := m.Called(ctx, tx, profit, currency)
-	return args.Error(0)
-}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type CurrencyRepository struct {
+	mock.Mock
+}
+
+type CurrencyRepositoryReturnValues struct {
+	GetCurrencies        []interface{}
+	GetCountryCurrencies []interface{}
+}
+
+func (m *CurrencyRepository) GetCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	args := m.Called(ctx)
+	if v, ok := args.Get(0).([]domain.Currency); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *CurrencyRepository) GetCountryCurrencies(ctx context.Context) ([]domain.CountryCurrency, error) {
+	args := m.Called(ctx)
+	if v, ok := args.Get(0).([]domain.CountryCurrency); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
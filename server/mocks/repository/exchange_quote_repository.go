@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type ExchangeQuoteRepository struct {
+	mock.Mock
+}
+
+type ExchangeQuoteRepositoryReturnValues struct {
+	CreateExchangeQuote  []interface{}
+	GetExchangeQuote     []interface{}
+	ClaimExchangeQuote   []interface{}
+	ReleaseExchangeQuote []interface{}
+}
+
+func (m *ExchangeQuoteRepository) CreateExchangeQuote(ctx context.Context, quote domain.ExchangeQuote, ttl time.Duration) error {
+	args := m.Called(ctx, quote, ttl)
+	return args.Error(0)
+}
+
+func (m *ExchangeQuoteRepository) GetExchangeQuote(ctx context.Context, quoteID string) (*domain.ExchangeQuote, error) {
+	args := m.Called(ctx, quoteID)
+	if v, ok := args.Get(0).(*domain.ExchangeQuote); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ExchangeQuoteRepository) ClaimExchangeQuote(ctx context.Context, quoteID string, ttl time.Duration) error {
+	args := m.Called(ctx, quoteID, ttl)
+	return args.Error(0)
+}
+
+func (m *ExchangeQuoteRepository) ReleaseExchangeQuote(ctx context.Context, quoteID string) error {
+	args := m.Called(ctx, quoteID)
+	return args.Error(0)
+}
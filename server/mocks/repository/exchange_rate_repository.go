@@ -0,0 +1,34 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/stretchr/testify/mock"
+)
+
+type ExchangeRateRepository struct {
+	mock.Mock
+}
+
+type ExchangeRateRepositoryReturnValues struct {
+	GetExchangeRate        []interface{}
+	GetExchangeRateHistory []interface{}
+}
+
+func (m *ExchangeRateRepository) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (*domain.ExchangeRate, error) {
+	args := m.Called(ctx, fromCurrency, toCurrency)
+	if v, ok := args.Get(0).(*domain.ExchangeRate); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *ExchangeRateRepository) GetExchangeRateHistory(ctx context.Context, fromCurrency, toCurrency string, since time.Time) ([]domain.ExchangeRate, error) {
+	args := m.Called(ctx, fromCurrency, toCurrency, since)
+	if v, ok := args.Get(0).([]domain.ExchangeRate); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
@@ -0,0 +1,46 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/mock"
+)
+
+type FeeRepository struct {
+	mock.Mock
+}
+
+type FeeRepositoryReturnValues struct {
+	GetActiveFeeSchedule []interface{}
+	GetFeeSchedules      []interface{}
+	CreateFeeSchedule    []interface{}
+	CreateFeeCharges     []interface{}
+}
+
+func (m *FeeRepository) GetActiveFeeSchedule(ctx context.Context) (*domain.FeeSchedule, error) {
+	args := m.Called(ctx)
+	if v, ok := args.Get(0).(*domain.FeeSchedule); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *FeeRepository) GetFeeSchedules(ctx context.Context) ([]domain.FeeSchedule, error) {
+	args := m.Called(ctx)
+	if v, ok := args.Get(0).([]domain.FeeSchedule); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *FeeRepository) CreateFeeSchedule(ctx context.Context, tx *sqlx.Tx, schedule *domain.FeeSchedule) error {
+	args := m.Called(ctx, tx, schedule)
+	return args.Error(0)
+}
+
+func (m *FeeRepository) CreateFeeCharges(ctx context.Context, tx *sqlx.Tx, charges []domain.FeeCharge) error {
+	args := m.Called(ctx, tx, charges)
+	return args.Error(0)
+}
@@ -0,0 +1,31 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/mock"
+)
+
+type FXConversionRepository struct {
+	mock.Mock
+}
+
+type FXConversionRepositoryReturnValues struct {
+	CreateFXConversion             []interface{}
+	GetFXConversionByTransactionID []interface{}
+}
+
+func (m *FXConversionRepository) CreateFXConversion(ctx context.Context, tx *sqlx.Tx, conversion *domain.FXConversion) error {
+	args := m.Called(ctx, tx, conversion)
+	return args.Error(0)
+}
+
+func (m *FXConversionRepository) GetFXConversionByTransactionID(ctx context.Context, transactionID int) (*domain.FXConversion, error) {
+	args := m.Called(ctx, transactionID)
+	if v, ok := args.Get(0).(*domain.FXConversion); ok {
+		return v, args.Error(1)
+	}
+	return nil, args.Error(1)
+}
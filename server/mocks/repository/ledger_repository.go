@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/mock"
+)
+
+type LedgerRepository struct {
+	mock.Mock
+}
+
+type LedgerRepositoryReturnValues struct {
+	CreateJournalEntry []interface{}
+	GetAccountBalance  []interface{}
+}
+
+func (m *LedgerRepository) CreateJournalEntry(ctx context.Context, tx *sqlx.Tx, entry *domain.JournalEntry) error {
+	args := m.Called(ctx, tx, entry)
+	return args.Error(0)
+}
+
+func (m *LedgerRepository) GetAccountBalance(ctx context.Context, tx *sqlx.Tx, account, currency string) (money.Amount, error) {
+	args := m.Called(ctx, tx, account, currency)
+
+	var balance money.Amount
+	if v, ok := args.Get(0).(money.Amount); ok {
+		balance = v
+	}
+
+	return balance, args.Error(1)
+}
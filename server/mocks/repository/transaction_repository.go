@@ -2,8 +2,10 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/mock"
@@ -25,18 +27,28 @@ func (m *TransactionRepository) CheckValidityOfSenderIDAndWalletID(ctx context.C
 	return args.Bool(0), args.String(1), args.Error(2)
 }
 
-func (m *TransactionRepository) InsertTransaction(ctx context.Context, tx *sqlx.Tx, userID int, transaction domain.Transaction) error {
+func (m *TransactionRepository) InsertTransaction(ctx context.Context, tx *sqlx.Tx, userID int, transaction domain.Transaction) (int, error) {
 	args := m.Called(ctx, tx, userID, transaction)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *TransactionRepository) UpdateTransaction(ctx context.Context, tx *sqlx.Tx, fromStatus string, transaction domain.Transaction) error {
+	args := m.Called(ctx, tx, fromStatus, transaction)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) UpdateTransactionStatusByReference(ctx context.Context, tx *sqlx.Tx, reference, fromStatus, status string) error {
+	args := m.Called(ctx, tx, reference, fromStatus, status)
 	return args.Error(0)
 }
 
-func (m *TransactionRepository) GetTotalTransactionsCount(ctx context.Context, userID int, paginator *pagination.Paginator) error {
-	args := m.Called(ctx, userID, paginator)
+func (m *TransactionRepository) GetTotalTransactionsCount(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) error {
+	args := m.Called(ctx, userID, filter, paginator)
 	return args.Error(0)
 }
 
-func (m *TransactionRepository) GetTransactions(ctx context.Context, userID int, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
-	args := m.Called(ctx, userID, paginator)
+func (m *TransactionRepository) GetTransactions(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
+	args := m.Called(ctx, userID, filter, paginator)
 
 	var transactions *[]domain.Transaction
 	if v, ok := args.Get(0).(*[]domain.Transaction); ok {
@@ -45,3 +57,230 @@ func (m *TransactionRepository) GetTransactions(ctx context.Context, userID int,
 
 	return transactions, args.Error(1)
 }
+
+func (m *TransactionRepository) GetTransactionByID(ctx context.Context, tx *sqlx.Tx, userID, transactionID int) (*domain.Transaction, error) {
+	args := m.Called(ctx, tx, userID, transactionID)
+
+	var transaction *domain.Transaction
+	if v, ok := args.Get(0).(*domain.Transaction); ok {
+		transaction = v
+	}
+
+	return transaction, args.Error(1)
+}
+
+func (m *TransactionRepository) GetTransactionByReference(ctx context.Context, userID int, reference string) (*domain.Transaction, error) {
+	args := m.Called(ctx, userID, reference)
+
+	var transaction *domain.Transaction
+	if v, ok := args.Get(0).(*domain.Transaction); ok {
+		transaction = v
+	}
+
+	return transaction, args.Error(1)
+}
+
+func (m *TransactionRepository) GetSentTransactions(ctx context.Context, userID int, currency string, afterID int, since time.Time) ([]domain.Transaction, error) {
+	args := m.Called(ctx, userID, currency, afterID, since)
+
+	var transactions []domain.Transaction
+	if v, ok := args.Get(0).([]domain.Transaction); ok {
+		transactions = v
+	}
+
+	return transactions, args.Error(1)
+}
+
+func (m *TransactionRepository) InsertRefund(ctx context.Context, tx *sqlx.Tx, refund *domain.Refund) error {
+	args := m.Called(ctx, tx, refund)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) UpdateRefund(ctx context.Context, tx *sqlx.Tx, refund domain.Refund) error {
+	args := m.Called(ctx, tx, refund)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) GetRefundByID(ctx context.Context, tx *sqlx.Tx, refundID int) (*domain.Refund, error) {
+	args := m.Called(ctx, tx, refundID)
+
+	var refund *domain.Refund
+	if v, ok := args.Get(0).(*domain.Refund); ok {
+		refund = v
+	}
+
+	return refund, args.Error(1)
+}
+
+func (m *TransactionRepository) GetRefundsByTransactionID(ctx context.Context, tx *sqlx.Tx, transactionID int) ([]domain.Refund, error) {
+	args := m.Called(ctx, tx, transactionID)
+
+	var refunds []domain.Refund
+	if v, ok := args.Get(0).([]domain.Refund); ok {
+		refunds = v
+	}
+
+	return refunds, args.Error(1)
+}
+
+func (m *TransactionRepository) GetRefundsByUserID(ctx context.Context, userID int) (*[]domain.Refund, error) {
+	args := m.Called(ctx, userID)
+
+	var refunds *[]domain.Refund
+	if v, ok := args.Get(0).(*[]domain.Refund); ok {
+		refunds = v
+	}
+
+	return refunds, args.Error(1)
+}
+
+func (m *TransactionRepository) InsertScheduledTransfer(ctx context.Context, transfer *domain.ScheduledTransfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) UpdateScheduledTransfer(ctx context.Context, tx *sqlx.Tx, transfer domain.ScheduledTransfer) error {
+	args := m.Called(ctx, tx, transfer)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) GetScheduledTransfers(ctx context.Context, userID int) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, userID)
+
+	var transfers []domain.ScheduledTransfer
+	if v, ok := args.Get(0).([]domain.ScheduledTransfer); ok {
+		transfers = v
+	}
+
+	return transfers, args.Error(1)
+}
+
+func (m *TransactionRepository) GetScheduledTransferByID(ctx context.Context, tx *sqlx.Tx, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, userID, scheduledTransferID)
+
+	var transfer *domain.ScheduledTransfer
+	if v, ok := args.Get(0).(*domain.ScheduledTransfer); ok {
+		transfer = v
+	}
+
+	return transfer, args.Error(1)
+}
+
+func (m *TransactionRepository) GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, now, limit)
+
+	var transfers []domain.ScheduledTransfer
+	if v, ok := args.Get(0).([]domain.ScheduledTransfer); ok {
+		transfers = v
+	}
+
+	return transfers, args.Error(1)
+}
+
+func (m *TransactionRepository) LockDueScheduledTransfer(ctx context.Context, tx *sqlx.Tx, scheduledTransferID int, now time.Time) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, tx, scheduledTransferID, now)
+
+	var transfer *domain.ScheduledTransfer
+	if v, ok := args.Get(0).(*domain.ScheduledTransfer); ok {
+		transfer = v
+	}
+
+	return transfer, args.Error(1)
+}
+
+func (m *TransactionRepository) InsertScheduledTransferRun(ctx context.Context, tx *sqlx.Tx, run *domain.ScheduledTransferRun) error {
+	args := m.Called(ctx, tx, run)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) UpdateScheduledTransferRun(ctx context.Context, tx *sqlx.Tx, run domain.ScheduledTransferRun) error {
+	args := m.Called(ctx, tx, run)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) GetScheduledTransferRuns(ctx context.Context, userID, scheduledTransferID int) ([]domain.ScheduledTransferRun, error) {
+	args := m.Called(ctx, userID, scheduledTransferID)
+
+	var runs []domain.ScheduledTransferRun
+	if v, ok := args.Get(0).([]domain.ScheduledTransferRun); ok {
+		runs = v
+	}
+
+	return runs, args.Error(1)
+}
+
+func (m *TransactionRepository) GetScheduledTransferRunByID(ctx context.Context, tx *sqlx.Tx, runID int) (*domain.ScheduledTransferRun, error) {
+	args := m.Called(ctx, tx, runID)
+
+	var run *domain.ScheduledTransferRun
+	if v, ok := args.Get(0).(*domain.ScheduledTransferRun); ok {
+		run = v
+	}
+
+	return run, args.Error(1)
+}
+
+func (m *TransactionRepository) GetPendingScheduledTransferRuns(ctx context.Context, updatedBefore time.Time) ([]domain.ScheduledTransferRun, error) {
+	args := m.Called(ctx, updatedBefore)
+
+	var runs []domain.ScheduledTransferRun
+	if v, ok := args.Get(0).([]domain.ScheduledTransferRun); ok {
+		runs = v
+	}
+
+	return runs, args.Error(1)
+}
+
+func (m *TransactionRepository) InsertPaymentRequest(ctx context.Context, paymentRequest *domain.PaymentRequest) error {
+	args := m.Called(ctx, paymentRequest)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) UpdatePaymentRequest(ctx context.Context, tx *sqlx.Tx, paymentRequest domain.PaymentRequest) error {
+	args := m.Called(ctx, tx, paymentRequest)
+	return args.Error(0)
+}
+
+func (m *TransactionRepository) GetPaymentRequestByID(ctx context.Context, tx *sqlx.Tx, paymentRequestID int) (*domain.PaymentRequest, error) {
+	args := m.Called(ctx, tx, paymentRequestID)
+
+	var paymentRequest *domain.PaymentRequest
+	if v, ok := args.Get(0).(*domain.PaymentRequest); ok {
+		paymentRequest = v
+	}
+
+	return paymentRequest, args.Error(1)
+}
+
+func (m *TransactionRepository) GetPaymentRequestsByUserID(ctx context.Context, userID int) ([]domain.PaymentRequest, error) {
+	args := m.Called(ctx, userID)
+
+	var paymentRequests []domain.PaymentRequest
+	if v, ok := args.Get(0).([]domain.PaymentRequest); ok {
+		paymentRequests = v
+	}
+
+	return paymentRequests, args.Error(1)
+}
+
+func (m *TransactionRepository) ExpirePaymentRequests(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+
+	var expired int64
+	if v, ok := args.Get(0).(int64); ok {
+		expired = v
+	}
+
+	return expired, args.Error(1)
+}
+
+func (m *TransactionRepository) GetProcessingPaymentRequests(ctx context.Context, updatedBefore time.Time) ([]domain.PaymentRequest, error) {
+	args := m.Called(ctx, updatedBefore)
+
+	var paymentRequests []domain.PaymentRequest
+	if v, ok := args.Get(0).([]domain.PaymentRequest); ok {
+		paymentRequests = v
+	}
+
+	return paymentRequests, args.Error(1)
+}
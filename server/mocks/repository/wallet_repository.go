@@ -5,6 +5,7 @@ import (
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/mock"
 )
@@ -19,16 +20,27 @@ type WalletRepositoryReturnValues struct {
 	GetWalletTypes                       []interface{}
 	GetWalletBalancesByUserID            []interface{}
 	GetWalletBalancesByUserIDAndWalletID []interface{}
-	CheckWalletExistsByWalletTypeID      []interface{}
+	GetWalletBalancesByWalletIDs         []interface{}
+	CountWallets                         []interface{}
+	CheckWalletExistsByName              []interface{}
 	CheckWalletTypeExists                []interface{}
 	CreateWallet                         []interface{}
+	UpdateWalletName                     []interface{}
+	UpdateWalletStatus                   []interface{}
 	InsertWalletCurrencyAmount           []interface{}
 	TopUpWalletBalances                  []interface{}
 	CashOutWalletBalances                []interface{}
+	CreateWalletHistory                  []interface{}
+	GetWalletGoal                        []interface{}
+	GetWalletGoals                       []interface{}
+	UpsertWalletGoal                     []interface{}
+	DeleteWalletGoal                     []interface{}
+	CompleteWalletGoal                   []interface{}
+	CreateWalletGoalEvent                []interface{}
 }
 
-func (m *WalletRepository) GetWalletByWalletID(ctx context.Context, userID, walletID int) (*domain.Wallet, error) {
-	args := m.Called(ctx, userID, walletID)
+func (m *WalletRepository) GetWalletByWalletID(ctx context.Context, tx *sqlx.Tx, userID, walletID int) (*domain.Wallet, error) {
+	args := m.Called(ctx, tx, userID, walletID)
 
 	var wallet *domain.Wallet
 	if v, ok := args.Get(0).(*domain.Wallet); ok {
@@ -38,8 +50,8 @@ func (m *WalletRepository) GetWalletByWalletID(ctx context.Context, userID, wall
 	return wallet, args.Error(1)
 }
 
-func (m *WalletRepository) GetWallets(ctx context.Context, userID int) ([]domain.Wallet, error) {
-	args := m.Called(ctx, userID)
+func (m *WalletRepository) GetWallets(ctx context.Context, userID int, includeClosed bool) ([]domain.Wallet, error) {
+	args := m.Called(ctx, userID, includeClosed)
 
 	var wallets []domain.Wallet
 	if v, ok := args.Get(0).([]domain.Wallet); ok {
@@ -82,8 +94,24 @@ func (m *WalletRepository) GetWalletBalancesByUserIDAndWalletID(ctx context.Cont
 	return walletCurrencyAmount, args.Error(1)
 }
 
-func (m *WalletRepository) CheckWalletExistsByWalletTypeID(ctx context.Context, userID, walletTypeID int) (bool, error) {
-	args := m.Called(ctx, userID, walletTypeID)
+func (m *WalletRepository) GetWalletBalancesByWalletIDs(ctx context.Context, tx *sqlx.Tx, userID int, walletIDs ...int) ([]domain.WalletCurrencyAmount, error) {
+	args := m.Called(ctx, tx, userID, walletIDs)
+
+	var walletCurrencyAmount []domain.WalletCurrencyAmount
+	if v, ok := args.Get(0).([]domain.WalletCurrencyAmount); ok {
+		walletCurrencyAmount = v
+	}
+
+	return walletCurrencyAmount, args.Error(1)
+}
+
+func (m *WalletRepository) CountWallets(ctx context.Context, tx *sqlx.Tx, userID int) (int, error) {
+	args := m.Called(ctx, tx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *WalletRepository) CheckWalletExistsByName(ctx context.Context, userID int, name string) (bool, error) {
+	args := m.Called(ctx, userID, name)
 	return args.Bool(0), args.Error(1)
 }
 
@@ -97,17 +125,74 @@ func (m *WalletRepository) CreateWallet(ctx context.Context, tx *sqlx.Tx, wallet
 	return args.Int(0), args.Error(1)
 }
 
+func (m *WalletRepository) UpdateWalletName(ctx context.Context, userID, walletID int, name string) error {
+	args := m.Called(ctx, userID, walletID, name)
+	return args.Error(0)
+}
+
+func (m *WalletRepository) UpdateWalletStatus(ctx context.Context, tx *sqlx.Tx, userID, walletID int, status string) error {
+	args := m.Called(ctx, tx, userID, walletID, status)
+	return args.Error(0)
+}
+
 func (m *WalletRepository) InsertWalletCurrencyAmount(ctx context.Context, tx *sqlx.Tx, walletID, userID int, currencyAmount []domain.WalletCurrencyAmount) error {
 	args := m.Called(ctx, tx, walletID, userID, currencyAmount)
 	return args.Error(0)
 }
 
-func (m *WalletRepository) TopUpWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]float64) error {
+func (m *WalletRepository) TopUpWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]money.Amount) error {
 	args := m.Called(ctx, tx, userID, walletID, finalWalletBalancesMap)
 	return args.Error(0)
 }
 
-func (m *WalletRepository) CashOutWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]float64) error {
+func (m *WalletRepository) CashOutWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]money.Amount) error {
 	args := m.Called(ctx, tx, userID, walletID, finalWalletBalancesMap)
 	return args.Error(0)
 }
+
+func (m *WalletRepository) CreateWalletHistory(ctx context.Context, tx *sqlx.Tx, history []domain.WalletHistory) error {
+	args := m.Called(ctx, tx, history)
+	return args.Error(0)
+}
+
+func (m *WalletRepository) GetWalletGoal(ctx context.Context, tx *sqlx.Tx, userID, walletID int) (*domain.WalletGoal, error) {
+	args := m.Called(ctx, tx, userID, walletID)
+
+	var goal *domain.WalletGoal
+	if v, ok := args.Get(0).(*domain.WalletGoal); ok {
+		goal = v
+	}
+
+	return goal, args.Error(1)
+}
+
+func (m *WalletRepository) GetWalletGoals(ctx context.Context, userID int) ([]domain.WalletGoal, error) {
+	args := m.Called(ctx, userID)
+
+	var goals []domain.WalletGoal
+	if v, ok := args.Get(0).([]domain.WalletGoal); ok {
+		goals = v
+	}
+
+	return goals, args.Error(1)
+}
+
+func (m *WalletRepository) UpsertWalletGoal(ctx context.Context, goal *domain.WalletGoal) error {
+	args := m.Called(ctx, goal)
+	return args.Error(0)
+}
+
+func (m *WalletRepository) DeleteWalletGoal(ctx context.Context, userID, walletID int) error {
+	args := m.Called(ctx, userID, walletID)
+	return args.Error(0)
+}
+
+func (m *WalletRepository) CompleteWalletGoal(ctx context.Context, tx *sqlx.Tx, goalID int) error {
+	args := m.Called(ctx, tx, goalID)
+	return args.Error(0)
+}
+
+func (m *WalletRepository) CreateWalletGoalEvent(ctx context.Context, tx *sqlx.Tx, event *domain.WalletGoalEvent) error {
+	args := m.Called(ctx, tx, event)
+	return args.Error(0)
+}
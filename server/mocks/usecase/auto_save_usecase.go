@@ -0,0 +1,89 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type AutoSaveUsecase struct {
+	mock.Mock
+}
+
+type AutoSaveUsecaseReturnValues struct {
+	CreateAutoSaveRule  []interface{}
+	GetAutoSaveRules    []interface{}
+	GetAutoSaveRuns     []interface{}
+	PauseAutoSaveRule   []interface{}
+	ResumeAutoSaveRule  []interface{}
+	DeleteAutoSaveRule  []interface{}
+	RunDueAutoSaveRules []interface{}
+}
+
+func (m *AutoSaveUsecase) CreateAutoSaveRule(ctx context.Context, userID int, req dto.CreateAutoSaveRuleRequest) (*domain.AutoSaveRule, error) {
+	args := m.Called(ctx, userID, req)
+
+	var rule *domain.AutoSaveRule
+	if v, ok := args.Get(0).(*domain.AutoSaveRule); ok {
+		rule = v
+	}
+
+	return rule, args.Error(1)
+}
+
+func (m *AutoSaveUsecase) GetAutoSaveRules(ctx context.Context, userID int) ([]domain.AutoSaveRule, error) {
+	args := m.Called(ctx, userID)
+
+	var rules []domain.AutoSaveRule
+	if v, ok := args.Get(0).([]domain.AutoSaveRule); ok {
+		rules = v
+	}
+
+	return rules, args.Error(1)
+}
+
+func (m *AutoSaveUsecase) GetAutoSaveRuns(ctx context.Context, userID, ruleID int) ([]domain.AutoSaveRun, error) {
+	args := m.Called(ctx, userID, ruleID)
+
+	var runs []domain.AutoSaveRun
+	if v, ok := args.Get(0).([]domain.AutoSaveRun); ok {
+		runs = v
+	}
+
+	return runs, args.Error(1)
+}
+
+func (m *AutoSaveUsecase) PauseAutoSaveRule(ctx context.Context, userID, ruleID int) (*domain.AutoSaveRule, error) {
+	args := m.Called(ctx, userID, ruleID)
+
+	var rule *domain.AutoSaveRule
+	if v, ok := args.Get(0).(*domain.AutoSaveRule); ok {
+		rule = v
+	}
+
+	return rule, args.Error(1)
+}
+
+func (m *AutoSaveUsecase) ResumeAutoSaveRule(ctx context.Context, userID, ruleID int) (*domain.AutoSaveRule, error) {
+	args := m.Called(ctx, userID, ruleID)
+
+	var rule *domain.AutoSaveRule
+	if v, ok := args.Get(0).(*domain.AutoSaveRule); ok {
+		rule = v
+	}
+
+	return rule, args.Error(1)
+}
+
+func (m *AutoSaveUsecase) DeleteAutoSaveRule(ctx context.Context, userID, ruleID int) error {
+	args := m.Called(ctx, userID, ruleID)
+	return args.Error(0)
+}
+
+func (m *AutoSaveUsecase) RunDueAutoSaveRules(ctx context.Context, now time.Time) error {
+	args := m.Called(ctx, now)
+	return args.Error(0)
+}
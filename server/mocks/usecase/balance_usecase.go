@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -19,11 +20,11 @@ type BalanceUsecaseReturnValues struct {
 	Deposit                  []interface{}
 	Withdraw                 []interface{}
 	CurrencyExchange         []interface{}
-	PreviewExchange          interface{}
+	PreviewExchange          []interface{}
 }
 
-func (m *BalanceUsecase) GetBalanceHistory(ctx context.Context, userID int, balanceID int) (*dto.GetBalanceHistory, error) {
-	args := m.Called(ctx, userID, balanceID)
+func (m *BalanceUsecase) GetBalanceHistory(ctx context.Context, userID int, balanceID int, paginator *pagination.Paginator) (*dto.GetBalanceHistory, error) {
+	args := m.Called(ctx, userID, balanceID, paginator)
 
 	var getBalanceHistory *dto.GetBalanceHistory
 	if v, ok := args.Get(0).(*dto.GetBalanceHistory); ok {
@@ -77,13 +78,13 @@ func (m *BalanceUsecase) CurrencyExchange(ctx context.Context, userID int, req d
 	return args.Error(0)
 }
 
-func (m *BalanceUsecase) PreviewExchange(ctx context.Context, req dto.PreviewExchangeRequest) dto.PreviewExchangeResponse {
-	args := m.Called(ctx, req)
+func (m *BalanceUsecase) PreviewExchange(ctx context.Context, userID int, req dto.PreviewExchangeRequest) (dto.PreviewExchangeResponse, error) {
+	args := m.Called(ctx, userID, req)
 
 	var previewExchangeResponse dto.PreviewExchangeResponse
 	if v, ok := args.Get(0).(dto.PreviewExchangeResponse); ok {
 		previewExchangeResponse = v
 	}
 
-	return previewExchangeResponse
+	return previewExchangeResponse, args.Error(1)
 }
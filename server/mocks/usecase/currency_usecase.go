@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type CurrencyUsecase struct {
+	mock.Mock
+}
+
+type CurrencyUsecaseReturnValues struct {
+	GetCurrencies []interface{}
+}
+
+func (m *CurrencyUsecase) GetCurrencies(ctx context.Context) (*dto.GetCurrenciesResponse, error) {
+	args := m.Called(ctx)
+
+	var currencies *dto.GetCurrenciesResponse
+	if v, ok := args.Get(0).(*dto.GetCurrenciesResponse); ok {
+		currencies = v
+	}
+
+	return currencies, args.Error(1)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type ExchangeRateUsecase struct {
+	mock.Mock
+}
+
+type ExchangeRateUsecaseReturnValues struct {
+	GetExchangeRateHistory []interface{}
+}
+
+func (m *ExchangeRateUsecase) GetExchangeRateHistory(ctx context.Context, req dto.GetExchangeRateHistoryRequest) (*dto.GetExchangeRateHistoryResponse, error) {
+	args := m.Called(ctx, req)
+
+	var history *dto.GetExchangeRateHistoryResponse
+	if v, ok := args.Get(0).(*dto.GetExchangeRateHistoryResponse); ok {
+		history = v
+	}
+
+	return history, args.Error(1)
+}
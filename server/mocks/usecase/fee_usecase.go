@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/stretchr/testify/mock"
+)
+
+type FeeUsecase struct {
+	mock.Mock
+}
+
+type FeeUsecaseReturnValues struct {
+	GetFeeSchedules   []interface{}
+	CreateFeeSchedule []interface{}
+}
+
+func (m *FeeUsecase) GetFeeSchedules(ctx context.Context, userID int) (*dto.GetFeeSchedulesResponse, error) {
+	args := m.Called(ctx, userID)
+
+	var schedules *dto.GetFeeSchedulesResponse
+	if v, ok := args.Get(0).(*dto.GetFeeSchedulesResponse); ok {
+		schedules = v
+	}
+
+	return schedules, args.Error(1)
+}
+
+func (m *FeeUsecase) CreateFeeSchedule(ctx context.Context, userID int, req dto.CreateFeeScheduleRequest) (*dto.FeeSchedule, error) {
+	args := m.Called(ctx, userID, req)
+
+	var schedule *dto.FeeSchedule
+	if v, ok := args.Get(0).(*dto.FeeSchedule); ok {
+		schedule = v
+	}
+
+	return schedule, args.Error(1)
+}
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
@@ -16,6 +17,19 @@ type TransactionUsecase struct {
 type TransactionUsecaseReturnValues struct {
 	CreateTransaction []interface{}
 	GetTransactions   []interface{}
+	GetTransaction    []interface{}
+
+	RequestRefund   []interface{}
+	RespondToRefund []interface{}
+	GetRefunds      []interface{}
+
+	CreateScheduledTransfer  []interface{}
+	GetScheduledTransfers    []interface{}
+	GetScheduledTransferRuns []interface{}
+	PauseScheduledTransfer   []interface{}
+	ResumeScheduledTransfer  []interface{}
+	CancelScheduledTransfer  []interface{}
+	RunDueScheduledTransfers []interface{}
 }
 
 func (m *TransactionUsecase) CreateTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID int) error {
@@ -23,8 +37,8 @@ func (m *TransactionUsecase) CreateTransaction(ctx context.Context, req dto.Crea
 	return args.Error(0)
 }
 
-func (m *TransactionUsecase) GetTransactions(ctx context.Context, userID int, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
-	args := m.Called(ctx, userID, paginator)
+func (m *TransactionUsecase) GetTransactions(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
+	args := m.Called(ctx, userID, filter, paginator)
 
 	var transactions *[]domain.Transaction
 	if v, ok := args.Get(0).(*[]domain.Transaction); ok {
@@ -33,3 +47,188 @@ func (m *TransactionUsecase) GetTransactions(ctx context.Context, userID int, pa
 
 	return transactions, args.Error(1)
 }
+
+func (m *TransactionUsecase) GetTransaction(ctx context.Context, userID int, reference string) (*domain.Transaction, error) {
+	args := m.Called(ctx, userID, reference)
+
+	var transaction *domain.Transaction
+	if v, ok := args.Get(0).(*domain.Transaction); ok {
+		transaction = v
+	}
+
+	return transaction, args.Error(1)
+}
+
+func (m *TransactionUsecase) RequestRefund(ctx context.Context, userID, transactionID int, req dto.RefundTransactionRequest) (*domain.Refund, error) {
+	args := m.Called(ctx, userID, transactionID, req)
+
+	var refund *domain.Refund
+	if v, ok := args.Get(0).(*domain.Refund); ok {
+		refund = v
+	}
+
+	return refund, args.Error(1)
+}
+
+func (m *TransactionUsecase) RespondToRefund(ctx context.Context, userID, refundID int, decision string) (*domain.Refund, error) {
+	args := m.Called(ctx, userID, refundID, decision)
+
+	var refund *domain.Refund
+	if v, ok := args.Get(0).(*domain.Refund); ok {
+		refund = v
+	}
+
+	return refund, args.Error(1)
+}
+
+func (m *TransactionUsecase) GetRefunds(ctx context.Context, userID int) (*[]domain.Refund, error) {
+	args := m.Called(ctx, userID)
+
+	var refunds *[]domain.Refund
+	if v, ok := args.Get(0).(*[]domain.Refund); ok {
+		refunds = v
+	}
+
+	return refunds, args.Error(1)
+}
+
+func (m *TransactionUsecase) CreateScheduledTransfer(ctx context.Context, userID int, req dto.CreateScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, userID, req)
+
+	var transfer *domain.ScheduledTransfer
+	if v, ok := args.Get(0).(*domain.ScheduledTransfer); ok {
+		transfer = v
+	}
+
+	return transfer, args.Error(1)
+}
+
+func (m *TransactionUsecase) GetScheduledTransfers(ctx context.Context, userID int) ([]domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, userID)
+
+	var transfers []domain.ScheduledTransfer
+	if v, ok := args.Get(0).([]domain.ScheduledTransfer); ok {
+		transfers = v
+	}
+
+	return transfers, args.Error(1)
+}
+
+func (m *TransactionUsecase) GetScheduledTransferRuns(ctx context.Context, userID, scheduledTransferID int) ([]domain.ScheduledTransferRun, error) {
+	args := m.Called(ctx, userID, scheduledTransferID)
+
+	var runs []domain.ScheduledTransferRun
+	if v, ok := args.Get(0).([]domain.ScheduledTransferRun); ok {
+		runs = v
+	}
+
+	return runs, args.Error(1)
+}
+
+func (m *TransactionUsecase) PauseScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, userID, scheduledTransferID)
+
+	var transfer *domain.ScheduledTransfer
+	if v, ok := args.Get(0).(*domain.ScheduledTransfer); ok {
+		transfer = v
+	}
+
+	return transfer, args.Error(1)
+}
+
+func (m *TransactionUsecase) ResumeScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, userID, scheduledTransferID)
+
+	var transfer *domain.ScheduledTransfer
+	if v, ok := args.Get(0).(*domain.ScheduledTransfer); ok {
+		transfer = v
+	}
+
+	return transfer, args.Error(1)
+}
+
+func (m *TransactionUsecase) CancelScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	args := m.Called(ctx, userID, scheduledTransferID)
+
+	var transfer *domain.ScheduledTransfer
+	if v, ok := args.Get(0).(*domain.ScheduledTransfer); ok {
+		transfer = v
+	}
+
+	return transfer, args.Error(1)
+}
+
+func (m *TransactionUsecase) RunDueScheduledTransfers(ctx context.Context, now time.Time) error {
+	args := m.Called(ctx, now)
+	return args.Error(0)
+}
+
+func (m *TransactionUsecase) RecoverScheduledTransferRuns(ctx context.Context, now time.Time) error {
+	args := m.Called(ctx, now)
+	return args.Error(0)
+}
+
+func (m *TransactionUsecase) RequestPayment(ctx context.Context, userID int, req dto.RequestPaymentRequest) (*domain.PaymentRequest, error) {
+	args := m.Called(ctx, userID, req)
+
+	var paymentRequest *domain.PaymentRequest
+	if v, ok := args.Get(0).(*domain.PaymentRequest); ok {
+		paymentRequest = v
+	}
+
+	return paymentRequest, args.Error(1)
+}
+
+func (m *TransactionUsecase) GetPaymentRequests(ctx context.Context, userID int) ([]domain.PaymentRequest, error) {
+	args := m.Called(ctx, userID)
+
+	var paymentRequests []domain.PaymentRequest
+	if v, ok := args.Get(0).([]domain.PaymentRequest); ok {
+		paymentRequests = v
+	}
+
+	return paymentRequests, args.Error(1)
+}
+
+func (m *TransactionUsecase) PayPaymentRequest(ctx context.Context, userID, paymentRequestID int, req dto.PayPaymentRequest) (*domain.PaymentRequest, error) {
+	args := m.Called(ctx, userID, paymentRequestID, req)
+
+	var paymentRequest *domain.PaymentRequest
+	if v, ok := args.Get(0).(*domain.PaymentRequest); ok {
+		paymentRequest = v
+	}
+
+	return paymentRequest, args.Error(1)
+}
+
+func (m *TransactionUsecase) DeclinePaymentRequest(ctx context.Context, userID, paymentRequestID int) (*domain.PaymentRequest, error) {
+	args := m.Called(ctx, userID, paymentRequestID)
+
+	var paymentRequest *domain.PaymentRequest
+	if v, ok := args.Get(0).(*domain.PaymentRequest); ok {
+		paymentRequest = v
+	}
+
+	return paymentRequest, args.Error(1)
+}
+
+func (m *TransactionUsecase) CancelPaymentRequest(ctx context.Context, userID, paymentRequestID int) (*domain.PaymentRequest, error) {
+	args := m.Called(ctx, userID, paymentRequestID)
+
+	var paymentRequest *domain.PaymentRequest
+	if v, ok := args.Get(0).(*domain.PaymentRequest); ok {
+		paymentRequest = v
+	}
+
+	return paymentRequest, args.Error(1)
+}
+
+func (m *TransactionUsecase) ExpirePaymentRequests(ctx context.Context, now time.Time) error {
+	args := m.Called(ctx, now)
+	return args.Error(0)
+}
+
+func (m *TransactionUsecase) RecoverPaymentRequests(ctx context.Context, now time.Time) error {
+	args := m.Called(ctx, now)
+	return args.Error(0)
+}
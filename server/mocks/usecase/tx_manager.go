@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/mock"
+)
+
+type TxManager struct {
+	mock.Mock
+}
+
+// WithTx returns the configured error without running fn, otherwise it runs fn
+// with a nil transaction since the repositories it calls are mocked as well
+func (m *TxManager) WithTx(ctx context.Context, fn func(ctx context.Context, tx *sqlx.Tx) error) error {
+	args := m.Called(ctx, fn)
+
+	if err := args.Error(0); err != nil {
+		return err
+	}
+
+	return fn(ctx, nil)
+}
@@ -18,8 +18,18 @@ type WalletUsecaseReturnValues struct {
 	GetWalletTypes []interface{}
 
 	CreateWallet  []interface{}
+	RenameWallet  []interface{}
+	ArchiveWallet []interface{}
+	RestoreWallet []interface{}
+	CloseWallet   []interface{}
 	TopUpWallet   []interface{}
 	CashOutWallet []interface{}
+
+	TransferBetweenWallets []interface{}
+	ExchangeWalletCurrency []interface{}
+
+	SetWalletGoal    []interface{}
+	DeleteWalletGoal []interface{}
 }
 
 func (m *WalletUsecase) GetWallet(ctx context.Context, userID, walletID int) (*domain.Wallet, error) {
@@ -33,8 +43,8 @@ func (m *WalletUsecase) GetWallet(ctx context.Context, userID, walletID int) (*d
 	return wallet, args.Error(1)
 }
 
-func (m *WalletUsecase) GetWallets(ctx context.Context, userID int) (*[]domain.Wallet, error) {
-	args := m.Called(ctx, userID)
+func (m *WalletUsecase) GetWallets(ctx context.Context, userID int, req dto.GetWalletsRequest) (*[]domain.Wallet, error) {
+	args := m.Called(ctx, userID, req)
 
 	var wallets *[]domain.Wallet
 	if v, ok := args.Get(0).(*[]domain.Wallet); ok {
@@ -60,6 +70,48 @@ func (m *WalletUsecase) CreateWallet(ctx context.Context, userID int, req dto.Cr
 	return args.Error(0)
 }
 
+func (m *WalletUsecase) RenameWallet(ctx context.Context, userID, walletID int, req dto.RenameWalletRequest) error {
+	args := m.Called(ctx, userID, walletID, req)
+	return args.Error(0)
+}
+
+func (m *WalletUsecase) ArchiveWallet(ctx context.Context, userID, walletID int) error {
+	args := m.Called(ctx, userID, walletID)
+	return args.Error(0)
+}
+
+func (m *WalletUsecase) RestoreWallet(ctx context.Context, userID, walletID int) error {
+	args := m.Called(ctx, userID, walletID)
+	return args.Error(0)
+}
+
+func (m *WalletUsecase) CloseWallet(ctx context.Context, userID, walletID int) (*dto.CloseWalletResponse, error) {
+	args := m.Called(ctx, userID, walletID)
+
+	var resp *dto.CloseWalletResponse
+	if v, ok := args.Get(0).(*dto.CloseWalletResponse); ok {
+		resp = v
+	}
+
+	return resp, args.Error(1)
+}
+
+func (m *WalletUsecase) SetWalletGoal(ctx context.Context, userID, walletID int, req dto.SetWalletGoalRequest) (*domain.WalletGoal, error) {
+	args := m.Called(ctx, userID, walletID, req)
+
+	var goal *domain.WalletGoal
+	if v, ok := args.Get(0).(*domain.WalletGoal); ok {
+		goal = v
+	}
+
+	return goal, args.Error(1)
+}
+
+func (m *WalletUsecase) DeleteWalletGoal(ctx context.Context, userID, walletID int) error {
+	args := m.Called(ctx, userID, walletID)
+	return args.Error(0)
+}
+
 func (m *WalletUsecase) TopUpWallet(ctx context.Context, userID, walletID int, req dto.UpdateWalletRequest) error {
 	args := m.Called(ctx, userID, walletID, req)
 	return args.Error(0)
@@ -69,3 +121,25 @@ func (m *WalletUsecase) CashOutWallet(ctx context.Context, userID, walletID int,
 	args := m.Called(ctx, userID, walletID, req)
 	return args.Error(0)
 }
+
+func (m *WalletUsecase) TransferBetweenWallets(ctx context.Context, userID int, req dto.TransferWalletRequest) (*dto.TransferWalletResponse, error) {
+	args := m.Called(ctx, userID, req)
+
+	var resp *dto.TransferWalletResponse
+	if v, ok := args.Get(0).(*dto.TransferWalletResponse); ok {
+		resp = v
+	}
+
+	return resp, args.Error(1)
+}
+
+func (m *WalletUsecase) ExchangeWalletCurrency(ctx context.Context, userID, walletID int, req dto.WalletExchangeRequest) (*dto.WalletExchangeResponse, error) {
+	args := m.Called(ctx, userID, walletID, req)
+
+	var resp *dto.WalletExchangeResponse
+	if v, ok := args.Get(0).(*dto.WalletExchangeResponse); ok {
+		resp = v
+	}
+
+	return resp, args.Error(1)
+}
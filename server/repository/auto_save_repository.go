@@ -0,0 +1,242 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/jmoiron/sqlx"
+)
+
+type autoSaveRepository struct {
+	db *sqlx.DB
+}
+
+func NewAutoSaveRepository(db *sqlx.DB) domain.AutoSaveRepository {
+	return &autoSaveRepository{
+		db: db,
+	}
+}
+
+const autoSaveRuleColumns = `
+			id,
+			user_id,
+			wallet_id,
+			type,
+			amount,
+			currency,
+			frequency,
+			start_at,
+			next_run_at,
+			status,
+			consecutive_failures,
+			last_transaction_id,
+			created_at,
+			updated_at`
+
+func (r *autoSaveRepository) CreateAutoSaveRule(ctx context.Context, rule *domain.AutoSaveRule) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO auto_save_rules (user_id, wallet_id, type, amount, currency, frequency, start_at, next_run_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at;
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		rule.UserID,
+		rule.WalletID,
+		rule.Type,
+		rule.Amount,
+		rule.Currency,
+		rule.Frequency,
+		rule.StartAt,
+		rule.NextRunAt,
+		rule.Status,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+}
+
+func (r *autoSaveRepository) GetAutoSaveRules(ctx context.Context, userID int) ([]domain.AutoSaveRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + autoSaveRuleColumns + `
+		FROM auto_save_rules
+		WHERE user_id = $1
+		ORDER BY id;
+	`
+
+	var rules []domain.AutoSaveRule
+	if err := r.db.SelectContext(ctx, &rules, query, userID); err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, exception.ErrNoAutoSaveRulesFound
+	}
+
+	return rules, nil
+}
+
+func (r *autoSaveRepository) GetAutoSaveRule(ctx context.Context, tx *sqlx.Tx, userID, ruleID int) (*domain.AutoSaveRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + autoSaveRuleColumns + `
+		FROM auto_save_rules
+		WHERE user_id = $1 AND id = $2
+	`
+
+	var rule domain.AutoSaveRule
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &rule, query, userID, ruleID)
+	} else {
+		// lock the rule so that a run of the scheduler does not overwrite the change
+		err = tx.GetContext(ctx, &rule, query+" FOR UPDATE", userID, ruleID)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrAutoSaveRuleNotFound
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetDueAutoSaveRules returns up to limit active rules whose next run is at or before now, the longest overdue first
+func (r *autoSaveRepository) GetDueAutoSaveRules(ctx context.Context, now time.Time, limit int) ([]domain.AutoSaveRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + autoSaveRuleColumns + `
+		FROM auto_save_rules
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at, id
+		LIMIT $3;
+	`
+
+	var rules []domain.AutoSaveRule
+	if err := r.db.SelectContext(ctx, &rules, query, domain.AutoSaveStatusActive, now, limit); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// LockDueAutoSaveRule locks the rule for a run if it is still active and due at now. A rule another
+// scheduler is running is skipped rather than waited for, and ErrAutoSaveRuleNotFound is returned.
+func (r *autoSaveRepository) LockDueAutoSaveRule(ctx context.Context, tx *sqlx.Tx, ruleID int, now time.Time) (*domain.AutoSaveRule, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + autoSaveRuleColumns + `
+		FROM auto_save_rules
+		WHERE id = $1 AND status = $2 AND next_run_at <= $3
+		FOR UPDATE SKIP LOCKED;
+	`
+
+	var rule domain.AutoSaveRule
+	if err := tx.GetContext(ctx, &rule, query, ruleID, domain.AutoSaveStatusActive, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrAutoSaveRuleNotFound
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// UpdateAutoSaveRule saves the schedule, status and progress of a rule locked in tx
+func (r *autoSaveRepository) UpdateAutoSaveRule(ctx context.Context, tx *sqlx.Tx, rule domain.AutoSaveRule) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE auto_save_rules
+		SET next_run_at = $1, status = $2, consecutive_failures = $3, last_transaction_id = $4, updated_at = NOW()
+		WHERE id = $5;
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		rule.NextRunAt,
+		rule.Status,
+		rule.ConsecutiveFailures,
+		rule.LastTransactionID,
+		rule.ID,
+	)
+	return err
+}
+
+func (r *autoSaveRepository) DeleteAutoSaveRule(ctx context.Context, userID, ruleID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		DELETE FROM auto_save_rules
+		WHERE user_id = $1 AND id = $2;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, ruleID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return exception.ErrAutoSaveRuleNotFound
+	}
+
+	return nil
+}
+
+func (r *autoSaveRepository) CreateAutoSaveRun(ctx context.Context, tx *sqlx.Tx, run *domain.AutoSaveRun) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO auto_save_runs (rule_id, user_id, wallet_id, amount, currency, status, failure_reason)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+		RETURNING id;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		run.RuleID,
+		run.UserID,
+		run.WalletID,
+		run.Amount,
+		run.Currency,
+		run.Status,
+		run.FailureReason,
+	).Scan(&run.ID)
+}
+
+// GetAutoSaveRuns returns the runs of a rule, latest first
+func (r *autoSaveRepository) GetAutoSaveRuns(ctx context.Context, userID, ruleID int) ([]domain.AutoSaveRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT id, rule_id, user_id, wallet_id, amount, currency, status, COALESCE(failure_reason, '') AS failure_reason, created_at
+		FROM auto_save_runs
+		WHERE user_id = $1 AND rule_id = $2
+		ORDER BY id DESC;
+	`
+
+	var runs []domain.AutoSaveRun
+	if err := r.db.SelectContext(ctx, &runs, query, userID, ruleID); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -21,18 +23,48 @@ func NewBalanceRepository(db *sqlx.DB) domain.BalanceRepository {
 	}
 }
 
-func (r *balanceRepository) GetBalanceHistory(ctx context.Context, userID, balanceID int) (*[]dto.BalanceHistory, error) {
+func (r *balanceRepository) GetBalanceHistoryCount(ctx context.Context, userID, balanceID int, paginator *pagination.Paginator) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	query := `
-		SELECT id, amount, currency, type, user_id, balance_id, created_at
+		SELECT COUNT(1)
 		FROM balances_history
 		WHERE user_id = $1 AND balance_id = $2;
 	`
 
+	return r.db.QueryRowContext(ctx, query, userID, balanceID).Scan(&paginator.TotalRecords)
+}
+
+func (r *balanceRepository) GetBalanceHistory(ctx context.Context, userID, balanceID int, paginator *pagination.Paginator) (*[]dto.BalanceHistory, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	where := "WHERE user_id = ? AND balance_id = ?"
+	args := []interface{}{userID, balanceID}
+
+	// in keyset mode the page starts right after the last row of the previous one
+	after, err := paginator.After()
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		where += " AND (created_at, id) < (?, ?)"
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	query := `
+		SELECT id, amount, currency, type, user_id, balance_id, created_at
+		FROM balances_history
+		` + where + `
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+		OFFSET ?;
+	`
+	args = append(args, paginator.Limit(), paginator.Offset())
+
 	var balanceHistory []dto.BalanceHistory
-	if err := r.db.SelectContext(ctx, &balanceHistory, query, userID, balanceID); err != nil {
+	if err := r.db.SelectContext(ctx, &balanceHistory, r.db.Rebind(query), args...); err != nil {
 		return nil, err
 	}
 
@@ -40,6 +72,15 @@ func (r *balanceRepository) GetBalanceHistory(ctx context.Context, userID, balan
 		return nil, exception.ErrBalanceHistoryNotFound
 	}
 
+	n, hasNextPage := paginator.Trim(len(balanceHistory))
+	balanceHistory = balanceHistory[:n]
+	if hasNextPage {
+		last := balanceHistory[n-1]
+		if err := paginator.SetNextCursor(last.CreatedAt, last.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	return &balanceHistory, nil
 }
 
@@ -50,7 +91,9 @@ func (r *balanceRepository) GetBalances(ctx context.Context, tx *sqlx.Tx, userID
 	query := `
 		SELECT id, balance, currency, created_at, updated_at
 		FROM balances
-		WHERE user_id = $1;
+		WHERE user_id = $1
+		ORDER BY currency
+		FOR UPDATE;
 	`
 
 	var balances []domain.Balance
@@ -72,7 +115,7 @@ func (r *balanceRepository) GetBalance(ctx context.Context, tx *sqlx.Tx, userID
 	query := `
 		SELECT id, balance, currency, user_id, created_at, updated_at
 		FROM balances
-		WHERE user_id = $1 AND currency = $2;
+		WHERE user_id = $1 AND currency = $2
 	`
 
 	var balance domain.Balance
@@ -80,7 +123,8 @@ func (r *balanceRepository) GetBalance(ctx context.Context, tx *sqlx.Tx, userID
 	if tx == nil {
 		err = r.db.GetContext(ctx, &balance, query, userID, currency)
 	} else {
-		err = tx.GetContext(ctx, &balance, query, userID, currency)
+		// lock the row until the transaction completes
+		err = tx.GetContext(ctx, &balance, query+" FOR UPDATE", userID, currency)
 	}
 
 	if err != nil {
@@ -185,11 +229,12 @@ func (r *balanceRepository) UpdateBalance(ctx context.Context, tx *sqlx.Tx, bala
 	return nil
 }
 
-func (r *balanceRepository) UpdateBalances(ctx context.Context, tx *sqlx.Tx, userID int, finalBalancesMap map[string]float64) error {
+func (r *balanceRepository) UpdateBalances(ctx context.Context, tx *sqlx.Tx, userID int, finalBalancesMap map[string]money.Amount) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
-	for currency, newBalance := range finalBalancesMap {
+	for _, currency := range sortedCurrencies(finalBalancesMap) {
+		newBalance := finalBalancesMap[currency]
 		query := `
 			INSERT INTO balances (user_id, currency, balance)
 			VALUES ($1, $2, $3)
@@ -205,7 +250,7 @@ func (r *balanceRepository) UpdateBalances(ctx context.Context, tx *sqlx.Tx, use
 	return nil
 }
 
-func (r *balanceRepository) CreateBalanceHistory(ctx context.Context, tx *sqlx.Tx, balance *domain.Balance, depositedBalance float64, balanceType string) error {
+func (r *balanceRepository) CreateBalanceHistory(ctx context.Context, tx *sqlx.Tx, balance *domain.Balance, depositedBalance money.Amount, balanceType string) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
@@ -226,21 +271,3 @@ func (r *balanceRepository) CreateBalanceHistory(ctx context.Context, tx *sqlx.T
 
 	return nil
 }
-
-func (uc *balanceRepository) LogCreatorProfit(ctx context.Context, tx *sqlx.Tx, profit float64, currency string) error {
-	ctx, cancel := context.WithTimeout(ctx, time.Minute)
-	defer cancel()
-
-	query := `
-		INSERT INTO creator_profit (amount, currency)
-		VALUES ($1, $2)
-		ON CONFLICT (currency) DO
-		UPDATE SET
-			amount = creator_profit.amount + EXCLUDED.amount,
-			currency = EXCLUDED.currency,
-			updated_at = NOW();
-	`
-
-	_, err := tx.ExecContext(ctx, query, profit, currency)
-	return err
-}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+type currencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewCurrencyRepository(db *sqlx.DB) domain.CurrencyRepository {
+	return &currencyRepository{
+		db: db,
+	}
+}
+
+func (r *currencyRepository) GetCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT code, name, symbol, exponent, enabled
+		FROM currencies
+		ORDER BY code;
+	`
+
+	var currencies []domain.Currency
+	if err := r.db.SelectContext(ctx, &currencies, query); err != nil {
+		return nil, err
+	}
+
+	return currencies, nil
+}
+
+func (r *currencyRepository) GetCountryCurrencies(ctx context.Context) ([]domain.CountryCurrency, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT mobile_country_code, currency
+		FROM country_currencies
+		ORDER BY mobile_country_code;
+	`
+
+	var countryCurrencies []domain.CountryCurrency
+	if err := r.db.SelectContext(ctx, &countryCurrencies, query); err != nil {
+		return nil, err
+	}
+
+	return countryCurrencies, nil
+}
+
+type cachedCurrencyRepository struct {
+	redisClient infrastructure.RedisClient
+	repository  domain.CurrencyRepository
+	ttl         time.Duration
+}
+
+// NewCachedCurrencyRepository keeps the currency catalogue in redis for ttl, it is read on
+// every money movement but hardly ever changes. Redis being unavailable is not fatal,
+// lookups go straight to repository instead.
+func NewCachedCurrencyRepository(redisClient infrastructure.RedisClient, repository domain.CurrencyRepository, ttl time.Duration) domain.CurrencyRepository {
+	if ttl <= 0 {
+		ttl = constants.DEFAULT_CURRENCY_CACHE_TTL
+	}
+	return &cachedCurrencyRepository{
+		redisClient: redisClient,
+		repository:  repository,
+		ttl:         ttl,
+	}
+}
+
+func (r *cachedCurrencyRepository) GetCurrencies(ctx context.Context) ([]domain.Currency, error) {
+	var currencies []domain.Currency
+	if r.fromCache(ctx, "currencies", &currencies) {
+		return currencies, nil
+	}
+
+	currencies, err := r.repository.GetCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.toCache(ctx, "currencies", currencies)
+
+	return currencies, nil
+}
+
+func (r *cachedCurrencyRepository) GetCountryCurrencies(ctx context.Context) ([]domain.CountryCurrency, error) {
+	var countryCurrencies []domain.CountryCurrency
+	if r.fromCache(ctx, "country_currencies", &countryCurrencies) {
+		return countryCurrencies, nil
+	}
+
+	countryCurrencies, err := r.repository.GetCountryCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.toCache(ctx, "country_currencies", countryCurrencies)
+
+	return countryCurrencies, nil
+}
+
+// fromCache reports whether redisKey was found and decoded into dest
+func (r *cachedCurrencyRepository) fromCache(ctx context.Context, redisKey string, dest interface{}) bool {
+	cached, err := r.redisClient.Get(ctx, redisKey)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("failed to retrieve %s with Get redis client: %v\n", redisKey, err)
+		}
+		return false
+	}
+
+	if err := json.Unmarshal([]byte(cached), dest); err != nil {
+		log.Printf("failed to unmarshal cached %s: %v\n", redisKey, err)
+		return false
+	}
+	return true
+}
+
+func (r *cachedCurrencyRepository) toCache(ctx context.Context, redisKey string, value interface{}) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("failed to marshal %s for caching: %v\n", redisKey, err)
+		return
+	}
+	if err := r.redisClient.SetEx(ctx, redisKey, encoded, r.ttl); err != nil {
+		log.Printf("failed to cache %s with SetEx redis client: %v\n", redisKey, err)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/redis/go-redis/v9"
+)
+
+type exchangeQuoteRepository struct {
+	redisClient infrastructure.RedisClient
+}
+
+// NewExchangeQuoteRepository keeps quotes in redis, they expire on their own once their ttl is up
+func NewExchangeQuoteRepository(redisClient infrastructure.RedisClient) domain.ExchangeQuoteRepository {
+	return &exchangeQuoteRepository{
+		redisClient: redisClient,
+	}
+}
+
+func (r *exchangeQuoteRepository) CreateExchangeQuote(ctx context.Context, quote domain.ExchangeQuote, ttl time.Duration) error {
+	encoded, err := json.Marshal(quote)
+	if err != nil {
+		return err
+	}
+	return r.redisClient.SetEx(ctx, exchangeQuoteKey(quote.ID), encoded, ttl)
+}
+
+func (r *exchangeQuoteRepository) GetExchangeQuote(ctx context.Context, quoteID string) (*domain.ExchangeQuote, error) {
+	stored, err := r.redisClient.Get(ctx, exchangeQuoteKey(quoteID))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, exception.ErrExchangeQuoteExpired
+		}
+		return nil, err
+	}
+
+	var quote domain.ExchangeQuote
+	if err := json.Unmarshal([]byte(stored), &quote); err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
+// ClaimExchangeQuote marks a quote as used, only the first claim on a quote succeeds
+func (r *exchangeQuoteRepository) ClaimExchangeQuote(ctx context.Context, quoteID string, ttl time.Duration) error {
+	claimed, err := r.redisClient.SetNX(ctx, exchangeQuoteKey(quoteID)+":claimed", time.Now().UTC().Format(time.RFC3339), ttl)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return exception.ErrExchangeQuoteUsed
+	}
+	return nil
+}
+
+// ReleaseExchangeQuote lets a claimed quote be used again, for when the exchange it was claimed for did not go through
+func (r *exchangeQuoteRepository) ReleaseExchangeQuote(ctx context.Context, quoteID string) error {
+	return r.redisClient.Del(ctx, exchangeQuoteKey(quoteID)+":claimed")
+}
+
+func exchangeQuoteKey(quoteID string) string {
+	return fmt.Sprintf("exchange_quote:%s", quoteID)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+type staticExchangeRateProvider struct {
+	rates map[string]domain.ExchangeRate
+}
+
+// NewStaticExchangeRateProvider serves a fixed set of rates from memory, for tests and local runs.
+// When the same pair is given more than once, the one with the latest EffectiveAt wins.
+func NewStaticExchangeRateProvider(rates []domain.ExchangeRate) domain.ExchangeRateProvider {
+	provider := &staticExchangeRateProvider{
+		rates: make(map[string]domain.ExchangeRate, len(rates)),
+	}
+	for _, rate := range rates {
+		key := exchangeRateKey(rate.FromCurrency, rate.ToCurrency)
+		if existing, exists := provider.rates[key]; exists && existing.EffectiveAt.After(rate.EffectiveAt) {
+			continue
+		}
+		provider.rates[key] = rate
+	}
+	return provider
+}
+
+func (p *staticExchangeRateProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (*domain.ExchangeRate, error) {
+	rate, exists := p.rates[exchangeRateKey(fromCurrency, toCurrency)]
+	if !exists {
+		return nil, exception.ErrExchangeRateNotFound
+	}
+	return &rate, nil
+}
+
+type cachedExchangeRateProvider struct {
+	redisClient infrastructure.RedisClient
+	provider    domain.ExchangeRateProvider
+	ttl         time.Duration
+}
+
+// NewCachedExchangeRateProvider keeps rates looked up from provider in redis for ttl.
+// Redis being unavailable is not fatal, lookups go straight to provider instead.
+func NewCachedExchangeRateProvider(redisClient infrastructure.RedisClient, provider domain.ExchangeRateProvider, ttl time.Duration) domain.ExchangeRateProvider {
+	if ttl <= 0 {
+		ttl = constants.DEFAULT_EXCHANGE_RATE_CACHE_TTL
+	}
+	return &cachedExchangeRateProvider{
+		redisClient: redisClient,
+		provider:    provider,
+		ttl:         ttl,
+	}
+}
+
+func (p *cachedExchangeRateProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (*domain.ExchangeRate, error) {
+	redisKey := "exchange_rate:" + exchangeRateKey(fromCurrency, toCurrency)
+
+	cached, err := p.redisClient.Get(ctx, redisKey)
+	switch {
+	case err == nil:
+		var rate domain.ExchangeRate
+		if err := json.Unmarshal([]byte(cached), &rate); err == nil {
+			return &rate, nil
+		}
+		log.Println("failed to unmarshal cached exchange rate", err)
+	case !errors.Is(err, redis.Nil):
+		log.Println("failed to retrieve exchange rate with Get redis client", err)
+	}
+
+	rate, err := p.provider.GetExchangeRate(ctx, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(rate)
+	if err != nil {
+		log.Println("failed to marshal exchange rate for caching", err)
+		return rate, nil
+	}
+	if err := p.redisClient.SetEx(ctx, redisKey, encoded, p.ttl); err != nil {
+		log.Println("failed to cache exchange rate with SetEx redis client", err)
+	}
+
+	return rate, nil
+}
+
+func exchangeRateKey(fromCurrency, toCurrency string) string {
+	return fmt.Sprintf("%s:%s", fromCurrency, toCurrency)
+}
@@ -0,0 +1,155 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedisClient is an in-memory RedisClient that only supports Get and SetEx
+type fakeRedisClient struct {
+	infrastructure.RedisClient
+
+	values map[string]string
+	err    error
+}
+
+func (f *fakeRedisClient) Get(ctx context.Context, key string) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	v, exists := f.values[key]
+	if !exists {
+		return "", redis.Nil
+	}
+	return v, nil
+}
+
+func (f *fakeRedisClient) SetEx(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.values[key] = string(value.([]byte))
+	return nil
+}
+
+// countingExchangeRateProvider counts how many lookups reach the underlying provider
+type countingExchangeRateProvider struct {
+	domain.ExchangeRateProvider
+	calls int
+}
+
+func (p *countingExchangeRateProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (*domain.ExchangeRate, error) {
+	p.calls++
+	return p.ExchangeRateProvider.GetExchangeRate(ctx, fromCurrency, toCurrency)
+}
+
+func TestStaticExchangeRateProvider_GetExchangeRate(t *testing.T) {
+	older := testdata.MockExchangeRate("SGD", "USD")
+	newer := older
+	newer.Rate = money.MustParseRate("0.80")
+	newer.EffectiveAt = older.EffectiveAt.Add(time.Hour)
+
+	tests := []struct {
+		Name         string
+		Rates        []domain.ExchangeRate
+		FromCurrency string
+		ToCurrency   string
+		ExpectedRate money.Rate
+		ExpectedErr  error
+	}{
+		{
+			Name:         "ReturnsSuccessfully",
+			Rates:        testdata.MockExchangeRates(),
+			FromCurrency: "SGD",
+			ToCurrency:   "USD",
+			ExpectedRate: money.MustParseRate("0.76"),
+		},
+		{
+			Name:         "ReturnsSuccessfully_LatestEffectiveRate",
+			Rates:        []domain.ExchangeRate{newer, older},
+			FromCurrency: "SGD",
+			ToCurrency:   "USD",
+			ExpectedRate: money.MustParseRate("0.80"),
+		},
+		{
+			Name:         "ReturnsError_ExchangeRateNotFound",
+			Rates:        testdata.MockExchangeRates(),
+			FromCurrency: "SGD",
+			ToCurrency:   "XYZ",
+			ExpectedErr:  exception.ErrExchangeRateNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			provider := repository.NewStaticExchangeRateProvider(tc.Rates)
+
+			rate, err := provider.GetExchangeRate(context.Background(), tc.FromCurrency, tc.ToCurrency)
+			if tc.ExpectedErr != nil {
+				require.ErrorIs(t, err, tc.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedRate, rate.Rate)
+		})
+	}
+}
+
+func TestCachedExchangeRateProvider_GetExchangeRate(t *testing.T) {
+	tests := []struct {
+		Name          string
+		RedisErr      error
+		ToCurrency    string
+		ExpectedCalls int
+		ExpectedErr   error
+	}{
+		{
+			Name:          "ReturnsSuccessfully_ServedFromCache",
+			ToCurrency:    "USD",
+			ExpectedCalls: 1,
+		},
+		{
+			Name:          "ReturnsSuccessfully_RedisUnavailable",
+			RedisErr:      errors.New("connection refused"),
+			ToCurrency:    "USD",
+			ExpectedCalls: 2,
+		},
+		{
+			Name:          "ReturnsError_ExchangeRateNotFound",
+			ToCurrency:    "XYZ",
+			ExpectedCalls: 2,
+			ExpectedErr:   exception.ErrExchangeRateNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.Name, func(t *testing.T) {
+			redisClient := &fakeRedisClient{values: make(map[string]string), err: tc.RedisErr}
+			underlying := &countingExchangeRateProvider{ExchangeRateProvider: repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates())}
+			provider := repository.NewCachedExchangeRateProvider(redisClient, underlying, time.Minute)
+
+			// look the same pair up twice, the second lookup should not reach the database when cached
+			for i := 0; i < 2; i++ {
+				rate, err := provider.GetExchangeRate(context.Background(), "SGD", tc.ToCurrency)
+				if tc.ExpectedErr != nil {
+					require.ErrorIs(t, err, tc.ExpectedErr)
+					continue
+				}
+				require.NoError(t, err)
+				require.Equal(t, testdata.MockExchangeRate("SGD", tc.ToCurrency), *rate)
+			}
+			require.Equal(t, tc.ExpectedCalls, underlying.calls)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/jmoiron/sqlx"
+)
+
+type exchangeRateRepository struct {
+	db *sqlx.DB
+}
+
+func NewExchangeRateRepository(db *sqlx.DB) domain.ExchangeRateRepository {
+	return &exchangeRateRepository{
+		db: db,
+	}
+}
+
+func (r *exchangeRateRepository) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (*domain.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	// rates can be scheduled ahead of time, only the latest one already in effect applies
+	query := `
+		SELECT from_currency, to_currency, rate, spread, effective_at
+		FROM exchange_rates
+		WHERE from_currency = $1 AND to_currency = $2 AND effective_at <= NOW()
+		ORDER BY effective_at DESC
+		LIMIT 1;
+	`
+
+	var exchangeRate domain.ExchangeRate
+	if err := r.db.GetContext(ctx, &exchangeRate, query, fromCurrency, toCurrency); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, exception.ErrExchangeRateNotFound
+		}
+		return nil, err
+	}
+
+	return &exchangeRate, nil
+}
+
+func (r *exchangeRateRepository) GetExchangeRateHistory(ctx context.Context, fromCurrency, toCurrency string, since time.Time) ([]domain.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	// the rate that was already in effect at since is included so that a chart has a starting point
+	query := `
+		SELECT from_currency, to_currency, rate, spread, effective_at
+		FROM exchange_rates
+		WHERE from_currency = $1 AND to_currency = $2 AND effective_at <= NOW()
+			AND effective_at >= COALESCE((
+				SELECT MAX(effective_at)
+				FROM exchange_rates
+				WHERE from_currency = $1 AND to_currency = $2 AND effective_at <= $3
+			), $3)
+		ORDER BY effective_at;
+	`
+
+	var exchangeRates []domain.ExchangeRate
+	if err := r.db.SelectContext(ctx, &exchangeRates, query, fromCurrency, toCurrency, since); err != nil {
+		return nil, err
+	}
+	if len(exchangeRates) == 0 {
+		return nil, exception.ErrExchangeRateNotFound
+	}
+
+	return exchangeRates, nil
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/jmoiron/sqlx"
+)
+
+type feeRepository struct {
+	db *sqlx.DB
+}
+
+func NewFeeRepository(db *sqlx.DB) domain.FeeRepository {
+	return &feeRepository{
+		db: db,
+	}
+}
+
+// GetActiveFeeSchedule returns the latest version of the fee schedule that has taken effect, with its rules
+func (r *feeRepository) GetActiveFeeSchedule(ctx context.Context) (*domain.FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT id, version, note, effective_at, COALESCE(created_by, 0) AS created_by, created_at
+		FROM fee_schedules
+		WHERE effective_at <= NOW()
+		ORDER BY version DESC
+		LIMIT 1;
+	`
+
+	var schedule domain.FeeSchedule
+	if err := r.db.GetContext(ctx, &schedule, query); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, exception.ErrFeeScheduleNotFound
+		}
+		return nil, err
+	}
+
+	if err := r.getFeeRules(ctx, &schedule); err != nil {
+		return nil, err
+	}
+
+	return &schedule, nil
+}
+
+// GetFeeSchedules returns every version of the fee schedule, latest first, with their rules
+func (r *feeRepository) GetFeeSchedules(ctx context.Context) ([]domain.FeeSchedule, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT id, version, note, effective_at, COALESCE(created_by, 0) AS created_by, created_at
+		FROM fee_schedules
+		ORDER BY version DESC;
+	`
+
+	var schedules []domain.FeeSchedule
+	if err := r.db.SelectContext(ctx, &schedules, query); err != nil {
+		return nil, err
+	}
+	if len(schedules) == 0 {
+		return nil, exception.ErrFeeScheduleNotFound
+	}
+
+	for idx := range schedules {
+		if err := r.getFeeRules(ctx, &schedules[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedules, nil
+}
+
+func (r *feeRepository) getFeeRules(ctx context.Context, schedule *domain.FeeSchedule) error {
+	queryRules := `
+		SELECT id, fee_schedule_id, product, from_currency, to_currency, spread_share, percentage,
+			fixed_amount, min_fee, max_fee
+		FROM fee_rules
+		WHERE fee_schedule_id = $1
+		ORDER BY id;
+	`
+
+	if err := r.db.SelectContext(ctx, &schedule.Rules, queryRules, schedule.ID); err != nil {
+		return err
+	}
+
+	queryTiers := `
+		SELECT t.id, t.fee_rule_id, t.min_amount, t.percentage, t.fixed_amount
+		FROM fee_rule_tiers t
+		JOIN fee_rules r ON r.id = t.fee_rule_id
+		WHERE r.fee_schedule_id = $1
+		ORDER BY t.fee_rule_id, t.min_amount;
+	`
+
+	var tiers []domain.FeeTier
+	if err := r.db.SelectContext(ctx, &tiers, queryTiers, schedule.ID); err != nil {
+		return err
+	}
+
+	ruleIndex := make(map[int]int, len(schedule.Rules))
+	for idx, rule := range schedule.Rules {
+		ruleIndex[rule.ID] = idx
+	}
+	for _, tier := range tiers {
+		idx := ruleIndex[tier.FeeRuleID]
+		schedule.Rules[idx].Tiers = append(schedule.Rules[idx].Tiers, tier)
+	}
+
+	return nil
+}
+
+// CreateFeeSchedule publishes schedule as the next version. The table is locked so that two
+// schedules published at the same time cannot be given the same version.
+func (r *feeRepository) CreateFeeSchedule(ctx context.Context, tx *sqlx.Tx, schedule *domain.FeeSchedule) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE fee_schedules IN SHARE ROW EXCLUSIVE MODE;`); err != nil {
+		return err
+	}
+
+	querySchedule := `
+		INSERT INTO fee_schedules (version, note, effective_at, created_by)
+		SELECT COALESCE(MAX(version), 0) + 1, $1, $2, NULLIF($3, 0)
+		FROM fee_schedules
+		RETURNING id, version, created_at;
+	`
+
+	if err := tx.QueryRowContext(ctx, querySchedule, schedule.Note, schedule.EffectiveAt, schedule.CreatedBy).
+		Scan(&schedule.ID, &schedule.Version, &schedule.CreatedAt); err != nil {
+		return err
+	}
+
+	queryRule := `
+		INSERT INTO fee_rules (fee_schedule_id, product, from_currency, to_currency, spread_share, percentage,
+			fixed_amount, min_fee, max_fee)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;
+	`
+	queryTier := `
+		INSERT INTO fee_rule_tiers (fee_rule_id, min_amount, percentage, fixed_amount)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;
+	`
+
+	for idx := range schedule.Rules {
+		rule := &schedule.Rules[idx]
+		rule.FeeScheduleID = schedule.ID
+		if err := tx.QueryRowContext(ctx, queryRule,
+			rule.FeeScheduleID,
+			rule.Product,
+			rule.FromCurrency,
+			rule.ToCurrency,
+			rule.SpreadShare,
+			rule.Percentage,
+			rule.FixedAmount,
+			rule.MinFee,
+			rule.MaxFee,
+		).Scan(&rule.ID); err != nil {
+			return err
+		}
+
+		for tierIdx := range rule.Tiers {
+			tier := &rule.Tiers[tierIdx]
+			tier.FeeRuleID = rule.ID
+			if err := tx.QueryRowContext(ctx, queryTier, tier.FeeRuleID, tier.MinAmount, tier.Percentage, tier.FixedAmount).Scan(&tier.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreateFeeCharges records each line item of a fee in the caller's transaction
+func (r *feeRepository) CreateFeeCharges(ctx context.Context, tx *sqlx.Tx, charges []domain.FeeCharge) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO fee_charges (journal_entry_id, transaction_id, user_id, fee_schedule_id, fee_rule_id, product,
+			fee_type, currency, amount)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id;
+	`
+
+	for idx := range charges {
+		charge := &charges[idx]
+		if err := tx.QueryRowContext(ctx, query,
+			charge.JournalEntryID,
+			charge.TransactionID,
+			charge.UserID,
+			charge.FeeScheduleID,
+			charge.FeeRuleID,
+			charge.Product,
+			charge.Type,
+			charge.Currency,
+			charge.Amount,
+		).Scan(&charge.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
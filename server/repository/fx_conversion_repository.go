@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/jmoiron/sqlx"
+)
+
+type fxConversionRepository struct {
+	db *sqlx.DB
+}
+
+func NewFXConversionRepository(db *sqlx.DB) domain.FXConversionRepository {
+	return &fxConversionRepository{
+		db: db,
+	}
+}
+
+func (r *fxConversionRepository) CreateFXConversion(ctx context.Context, tx *sqlx.Tx, conversion *domain.FXConversion) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO fx_conversions (journal_entry_id, transaction_id, user_id, from_currency, to_currency, from_amount, to_amount,
+			mid_rate, spread, profit, rate_source, conversion_path, rate_effective_at)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id;
+	`
+
+	if err := tx.QueryRowContext(ctx, query,
+		conversion.JournalEntryID,
+		conversion.TransactionID,
+		conversion.UserID,
+		conversion.FromCurrency,
+		conversion.ToCurrency,
+		conversion.FromAmount,
+		conversion.ToAmount,
+		conversion.MidRate,
+		conversion.Spread,
+		conversion.Profit,
+		conversion.RateSource,
+		conversion.ConversionPath,
+		conversion.RateEffectiveAt,
+	).Scan(&conversion.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *fxConversionRepository) GetFXConversionByTransactionID(ctx context.Context, transactionID int) (*domain.FXConversion, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT id, journal_entry_id, COALESCE(transaction_id, 0) AS transaction_id, user_id, from_currency, to_currency,
+			from_amount, to_amount, mid_rate, spread, profit, rate_source, conversion_path, rate_effective_at, created_at
+		FROM fx_conversions
+		WHERE transaction_id = $1;
+	`
+
+	var conversion domain.FXConversion
+	if err := r.db.GetContext(ctx, &conversion, query, transactionID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, exception.ErrFXConversionNotFound
+		}
+		return nil, err
+	}
+
+	return &conversion, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+)
+
+type ledgerRepository struct {
+	db *sqlx.DB
+}
+
+func NewLedgerRepository(db *sqlx.DB) domain.LedgerRepository {
+	return &ledgerRepository{
+		db: db,
+	}
+}
+
+// CreateJournalEntry writes the entry and its postings in the caller's transaction.
+// The entry is rejected before anything is written if it does not balance, the
+// deferred trigger on ledger_postings checks the same invariant again at commit.
+func (r *ledgerRepository) CreateJournalEntry(ctx context.Context, tx *sqlx.Tx, entry *domain.JournalEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	if err := entry.CheckBalanced(); err != nil {
+		return err
+	}
+
+	queryEntry := `
+		INSERT INTO ledger_journal_entries (type, reference)
+		VALUES ($1, $2)
+		RETURNING id;
+	`
+
+	if err := tx.QueryRowContext(ctx, queryEntry, entry.Type, entry.Reference).Scan(&entry.ID); err != nil {
+		return err
+	}
+
+	queryPosting := `
+		INSERT INTO ledger_postings (entry_id, account, direction, currency, amount)
+		VALUES ($1, $2, $3, $4, $5);
+	`
+
+	for idx, p := range entry.Postings {
+		if _, err := tx.ExecContext(ctx, queryPosting, entry.ID, p.Account, p.Direction, p.Currency, p.Amount); err != nil {
+			return err
+		}
+		entry.Postings[idx].EntryID = entry.ID
+	}
+
+	return nil
+}
+
+// GetAccountBalance derives the balance of an account from its postings, credits minus debits
+func (r *ledgerRepository) GetAccountBalance(ctx context.Context, tx *sqlx.Tx, account, currency string) (money.Amount, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(SUM(CASE WHEN direction = 'CREDIT' THEN amount ELSE -amount END), 0)
+		FROM ledger_postings
+		WHERE account = $1 AND currency = $2;
+	`
+
+	var balance money.Amount
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &balance, query, account, currency)
+	} else {
+		err = tx.GetContext(ctx, &balance, query, account, currency)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return balance, nil
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"sort"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// Lock ordering used by every money-moving flow so that concurrent requests
+// cannot deadlock on each other:
+//   - the users row is locked before anything else when a wallet is created
+//   - an auto_save_rules row is locked before anything its run moves
+//   - a scheduled_transfers row is only locked to claim a run, in a transaction of its own
+//   - a payment_requests row is only locked to claim or resolve the request, in a transaction of its own
+//   - a scheduled_transfer_runs or payment_requests row being recovered is locked before the transactions row of its transfer
+//   - transaction_refunds and transactions rows are locked before any balance rows
+//   - wallets rows are locked before their wallet_balances rows, in id order
+//   - wallet_goals rows are locked after their wallets row
+//   - wallet_balances rows are locked before balances rows
+//   - within a table, rows are locked in (user_id, currency) order
+//
+// Reads done inside a transaction take the locks with ORDER BY ... FOR UPDATE,
+// and writes driven by a map iterate over its currencies in sorted order since
+// Go map iteration order is random.
+
+// sortedCurrencies returns the keys of a currency to amount map in a stable order
+func sortedCurrencies(amounts map[string]money.Amount) []string {
+	currencies := make([]string, 0, len(amounts))
+	for currency := range amounts {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
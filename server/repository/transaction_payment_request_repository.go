@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/jmoiron/sqlx"
+)
+
+const paymentRequestSelect = `
+		SELECT
+			pr.id,
+			pr.requester_id,
+			requester.username AS requester_username,
+			pr.payer_id,
+			payer.username AS payer_username,
+			pr.amount,
+			pr.currency,
+			pr.note,
+			pr.status,
+			COALESCE(pr.failure_reason, '') AS failure_reason,
+			COALESCE(pr.transaction_id, 0) AS transaction_id,
+			COALESCE(t.reference, '') AS transaction_reference,
+			pr.expires_at,
+			pr.created_at,
+			pr.updated_at
+		FROM payment_requests pr
+		JOIN users requester
+			ON requester.id = pr.requester_id
+		JOIN users payer
+			ON payer.id = pr.payer_id
+		LEFT JOIN transactions t
+			ON t.id = pr.transaction_id`
+
+func (r *transactionRepository) InsertPaymentRequest(ctx context.Context, paymentRequest *domain.PaymentRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO payment_requests (requester_id, payer_id, amount, currency, note, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at;
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		paymentRequest.RequesterID,
+		paymentRequest.PayerID,
+		paymentRequest.Amount,
+		paymentRequest.Currency,
+		paymentRequest.Note,
+		paymentRequest.Status,
+		paymentRequest.ExpiresAt,
+	).Scan(&paymentRequest.ID, &paymentRequest.CreatedAt, &paymentRequest.UpdatedAt)
+}
+
+// UpdatePaymentRequest saves the status of a request locked in tx, along with the transfer that paid it or the reason it failed
+func (r *transactionRepository) UpdatePaymentRequest(ctx context.Context, tx *sqlx.Tx, paymentRequest domain.PaymentRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE payment_requests
+		SET status = $1, failure_reason = NULLIF($2, ''), transaction_id = NULLIF($3, 0), updated_at = NOW()
+		WHERE id = $4;
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		paymentRequest.Status,
+		paymentRequest.FailureReason,
+		paymentRequest.TransactionID,
+		paymentRequest.ID,
+	)
+	return err
+}
+
+func (r *transactionRepository) GetPaymentRequestByID(ctx context.Context, tx *sqlx.Tx, paymentRequestID int) (*domain.PaymentRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := paymentRequestSelect + `
+		WHERE pr.id = $1
+	`
+
+	var paymentRequest domain.PaymentRequest
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &paymentRequest, query, paymentRequestID)
+	} else {
+		// only the request is locked, not the users or transaction joined to it
+		err = tx.GetContext(ctx, &paymentRequest, query+" FOR UPDATE OF pr", paymentRequestID)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrPaymentRequestNotFound
+		}
+		return nil, err
+	}
+	return &paymentRequest, nil
+}
+
+// GetPaymentRequestsByUserID returns the requests the user made or was asked to pay, latest first
+func (r *transactionRepository) GetPaymentRequestsByUserID(ctx context.Context, userID int) ([]domain.PaymentRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := paymentRequestSelect + `
+		WHERE pr.requester_id = $1 OR pr.payer_id = $1
+		ORDER BY pr.id DESC;
+	`
+
+	var paymentRequests []domain.PaymentRequest
+	if err := r.db.SelectContext(ctx, &paymentRequests, query, userID); err != nil {
+		return nil, err
+	}
+	if len(paymentRequests) == 0 {
+		return nil, exception.ErrNoPaymentRequestsFound
+	}
+
+	return paymentRequests, nil
+}
+
+// ExpirePaymentRequests moves the pending requests that have passed their expiry at now to expired,
+// returning how many were expired. Requests being paid are left alone.
+func (r *transactionRepository) ExpirePaymentRequests(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE payment_requests
+		SET status = $1, updated_at = NOW()
+		WHERE status = $2 AND expires_at <= $3;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, domain.PaymentRequestStatusExpired, domain.PaymentRequestStatusPending, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// GetProcessingPaymentRequests returns the requests that have been processing since before updatedBefore, oldest first
+func (r *transactionRepository) GetProcessingPaymentRequests(ctx context.Context, updatedBefore time.Time) ([]domain.PaymentRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := paymentRequestSelect + `
+		WHERE pr.status = $1 AND pr.updated_at <= $2
+		ORDER BY pr.updated_at;
+	`
+
+	var paymentRequests []domain.PaymentRequest
+	if err := r.db.SelectContext(ctx, &paymentRequests, query, domain.PaymentRequestStatusProcessing, updatedBefore); err != nil {
+		return nil, err
+	}
+
+	return paymentRequests, nil
+}
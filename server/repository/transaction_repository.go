@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
 	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 	"github.com/jmoiron/sqlx"
 )
@@ -48,45 +51,42 @@ func (r *transactionRepository) CheckLinkageOfSenderAndBeneficiaryByMobileNumber
 	return beneficiaryID, isBeneficiaryActive, isMFAConfigured, nil
 }
 
+// CheckValidityOfSenderIDAndWalletID reports whether the wallet is an active wallet of the user, along with
+// its name. A user can hold several wallets of a type, so transfers are recorded against the wallet's name.
 func (r *transactionRepository) CheckValidityOfSenderIDAndWalletID(ctx context.Context, userID, walletID int) (bool, string, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	query := `
-		SELECT EXISTS (
-			SELECT 1
-			FROM wallets
-			WHERE
-				user_id = $1 AND
-				id = $2
-		),
-		wt.type
+		SELECT w.status = 'active', w.name
 		FROM wallets w
-		JOIN wallet_types wt
-			ON w.wallet_type_id = wt.id
 		WHERE w.user_id = $1 AND w.id = $2;
 	`
 
 	var validSenderWallet bool
-	var walletType string
-	if err := r.db.QueryRowContext(ctx, query, userID, walletID).Scan(&validSenderWallet, &walletType); err != nil {
+	var walletName string
+	if err := r.db.QueryRowContext(ctx, query, userID, walletID).Scan(&validSenderWallet, &walletName); err != nil {
 		return false, "", err
 	}
-	return validSenderWallet, walletType, nil
+	return validSenderWallet, walletName, nil
 }
 
-func (r *transactionRepository) InsertTransaction(ctx context.Context, tx *sqlx.Tx, userID int, transaction domain.Transaction) error {
+func (r *transactionRepository) InsertTransaction(ctx context.Context, tx *sqlx.Tx, userID int, transaction domain.Transaction) (int, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
+	// beneficiary and destination are not known yet when an attempt is first recorded
 	query := `
 		INSERT INTO transactions 
 			(user_id, sender_id, beneficiary_id, source_of_transfer, source_amount,
-			source_currency, destination_amount, destination_currency, status, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10);
+			source_currency, destination_amount, destination_currency, status, failure_reason,
+			sender_wallet_id, original_transaction_id, reference, fx_rate, profit, created_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6, $7, NULLIF($8, ''), $9, NULLIF($10, ''), NULLIF($11, 0), NULLIF($12, 0), $13, NULLIF($14::NUMERIC, 0), $15, $16)
+		RETURNING id;
 	`
 
-	if _, err := tx.ExecContext(ctx, query,
+	var transactionID int
+	if err := tx.QueryRowContext(ctx, query,
 		userID,
 		transaction.SenderID,
 		transaction.BeneficiaryID,
@@ -96,43 +96,224 @@ func (r *transactionRepository) InsertTransaction(ctx context.Context, tx *sqlx.
 		transaction.DestinationAmount,
 		transaction.DestinationCurrency,
 		transaction.Status,
+		transaction.FailureReason,
+		transaction.SenderWalletID,
+		transaction.OriginalTransactionID,
+		transaction.Reference,
+		transaction.FXRate,
+		transaction.Profit,
 		time.Now(),
-	); err != nil {
+	).Scan(&transactionID); err != nil {
+		return 0, err
+	}
+
+	return transactionID, nil
+}
+
+// UpdateTransaction writes the details and status of a transaction, but only if it is
+// still in fromStatus so that two concurrent transitions cannot both succeed
+func (r *transactionRepository) UpdateTransaction(ctx context.Context, tx *sqlx.Tx, fromStatus string, transaction domain.Transaction) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE transactions
+		SET
+			beneficiary_id = NULLIF($1, 0),
+			source_of_transfer = $2,
+			destination_amount = $3,
+			destination_currency = NULLIF($4, ''),
+			status = $5,
+			failure_reason = NULLIF($6, ''),
+			fx_rate = NULLIF($7::NUMERIC, 0),
+			profit = $8,
+			updated_at = NOW()
+		WHERE id = $9 AND status = $10;
+	`
+
+	result, err := tx.ExecContext(ctx, query,
+		transaction.BeneficiaryID,
+		transaction.SourceOfTransfer,
+		transaction.DestinationAmount,
+		transaction.DestinationCurrency,
+		transaction.Status,
+		transaction.FailureReason,
+		transaction.FXRate,
+		transaction.Profit,
+		transaction.ID,
+		fromStatus,
+	)
+	if err != nil {
 		return err
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return exception.ErrTransactionStatusConflict
+	}
+
 	return nil
 }
 
-func (r *transactionRepository) GetTransactions(ctx context.Context, userID int, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
+// UpdateTransactionStatusByReference moves every copy of a transaction, the sender's and the
+// beneficiary's, from fromStatus to status
+func (r *transactionRepository) UpdateTransactionStatusByReference(ctx context.Context, tx *sqlx.Tx, reference, fromStatus, status string) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	query := `
-		SELECT
+		UPDATE transactions
+		SET status = $1, updated_at = NOW()
+		WHERE reference = $2 AND status = $3;
+	`
+
+	result, err := tx.ExecContext(ctx, query, status, reference, fromStatus)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return exception.ErrTransactionStatusConflict
+	}
+
+	return nil
+}
+
+// transactionColumns selects a transaction along with the usernames and mobile numbers of both parties,
+// nullable columns are coalesced to the zero value of their field
+const transactionColumns = `
+			t.id,
+			t.reference,
+			t.sender_id,
+			COALESCE(t.beneficiary_id, 0) 		AS beneficiary_id,
 			sender.username 			AS sender_username,
 			sender.mobile_number 		AS sender_mobile_number,
-			beneficiary.username 		AS beneficiary_username,
-			beneficiary.mobile_number 	AS beneficiary_mobile_number,
+			COALESCE(beneficiary.username, '') 		AS beneficiary_username,
+			COALESCE(beneficiary.mobile_number, '') 	AS beneficiary_mobile_number,
 			t.source_amount,
 			t.source_currency,
 			t.destination_amount,
-			t.destination_currency,
+			COALESCE(t.destination_currency, '') AS destination_currency,
+			COALESCE(t.fx_rate, 0) AS fx_rate,
+			t.profit,
 			t.source_of_transfer,
+			COALESCE(t.sender_wallet_id, 0) AS sender_wallet_id,
+			COALESCE(t.original_transaction_id, 0) AS original_transaction_id,
 			t.status,
-			t.created_at
-		FROM transactions t
+			COALESCE(t.failure_reason, '') AS failure_reason,
+			t.created_at,
+			t.updated_at`
+
+const transactionJoins = `
 		JOIN users AS sender
 			ON t.sender_id = sender.id
-		JOIN users AS beneficiary
-			ON t.beneficiary_id = beneficiary.id
-		WHERE t.user_id = ?
-		ORDER BY created_at DESC
+		LEFT JOIN users AS beneficiary
+			ON t.beneficiary_id = beneficiary.id`
+
+// transactionAmount is what the user sent or received, in the currency they sent or received it in
+const transactionAmount = `CASE WHEN t.sender_id = t.user_id THEN t.source_amount ELSE t.destination_amount END`
+
+// transactionSortOrders are the only ORDER BY clauses the transaction history can be sorted by,
+// the id breaks ties so that pages never overlap
+var transactionSortOrders = map[string]string{
+	"":                         "t.created_at DESC, t.id DESC",
+	constants.SORT_DATE_DESC:   "t.created_at DESC, t.id DESC",
+	constants.SORT_DATE_ASC:    "t.created_at ASC, t.id ASC",
+	constants.SORT_AMOUNT_DESC: transactionAmount + " DESC, t.id DESC",
+	constants.SORT_AMOUNT_ASC:  transactionAmount + " ASC, t.id ASC",
+}
+
+// transactionFilterConditions builds the WHERE clause shared by the transaction history and its count.
+// Filter values are always bound as arguments, only fixed sql is written into the query.
+func transactionFilterConditions(userID int, filter dto.TransactionFilter) (string, []interface{}) {
+	conditions := []string{"t.user_id = ?"}
+	args := []interface{}{userID}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "t.created_at >= ?")
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "t.created_at < ?")
+		args = append(args, filter.To)
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "(t.source_currency = ? OR t.destination_currency = ?)")
+		args = append(args, filter.Currency, filter.Currency)
+	}
+
+	// a user's copy of a transaction they sent has them as the sender
+	switch filter.Direction {
+	case constants.DIRECTION_SENT:
+		conditions = append(conditions, "t.sender_id = t.user_id")
+	case constants.DIRECTION_RECEIVED:
+		conditions = append(conditions, "t.sender_id <> t.user_id")
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "t.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Counterparty != "" {
+		conditions = append(conditions, `(
+			CASE WHEN t.sender_id = t.user_id THEN beneficiary.username ELSE sender.username END ILIKE ? OR
+			CASE WHEN t.sender_id = t.user_id THEN beneficiary.mobile_number ELSE sender.mobile_number END LIKE ?
+		)`)
+		pattern := "%" + likeEscaper.Replace(filter.Counterparty) + "%"
+		args = append(args, pattern, pattern)
+	}
+	if filter.MinAmount.IsPositive() {
+		conditions = append(conditions, transactionAmount+" >= ?")
+		args = append(args, filter.MinAmount)
+	}
+	if filter.MaxAmount.IsPositive() {
+		conditions = append(conditions, transactionAmount+" <= ?")
+		args = append(args, filter.MaxAmount)
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// likeEscaper escapes the LIKE wildcards so that a search term only ever matches itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *transactionRepository) GetTransactions(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	where, args := transactionFilterConditions(userID, filter)
+
+	// in keyset mode the page starts right after the last row of the previous one
+	after, err := paginator.After()
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		comparison := "<"
+		if filter.Sort == constants.SORT_DATE_ASC {
+			comparison = ">"
+		}
+		where += " AND (t.created_at, t.id) " + comparison + " (?, ?)"
+		args = append(args, after.CreatedAt, after.ID)
+	}
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t ` + transactionJoins + `
+		` + where + `
+		ORDER BY ` + transactionSortOrders[filter.Sort] + `
 		LIMIT ?
 		OFFSET ?;
 	`
 
-	args := []interface{}{userID, paginator.Limit(), paginator.Offset()}
+	args = append(args, paginator.Limit(), paginator.Offset())
 
 	var transactions []domain.Transaction
 	if err := r.db.SelectContext(ctx, &transactions, r.db.Rebind(query), args...); err != nil {
@@ -143,22 +324,225 @@ func (r *transactionRepository) GetTransactions(ctx context.Context, userID int,
 		return nil, exception.ErrNoTransactionsFound
 	}
 
+	n, hasNextPage := paginator.Trim(len(transactions))
+	transactions = transactions[:n]
+	if hasNextPage {
+		last := transactions[n-1]
+		if err := paginator.SetNextCursor(last.CreatedAt, last.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	return &transactions, nil
 }
 
-func (r *transactionRepository) GetTotalTransactionsCount(ctx context.Context, userID int, paginator *pagination.Paginator) error {
+func (r *transactionRepository) GetTotalTransactionsCount(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
+	where, args := transactionFilterConditions(userID, filter)
 	query := `
 		SELECT COUNT(1)
-		FROM transactions t
-		JOIN users AS sender
-			ON t.sender_id = sender.id
-		JOIN users AS beneficiary
-			ON t.beneficiary_id = beneficiary.id
-		WHERE t.user_id = $1
+		FROM transactions t ` + transactionJoins + `
+		` + where + `
+	`
+
+	return r.db.QueryRowContext(ctx, r.db.Rebind(query), args...).Scan(&paginator.TotalRecords)
+}
+
+// GetTransactionByID returns one of the user's transactions, locking it when called inside a sql transaction
+func (r *transactionRepository) GetTransactionByID(ctx context.Context, tx *sqlx.Tx, userID, transactionID int) (*domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t ` + transactionJoins + `
+		WHERE t.user_id = $1 AND t.id = $2
+	`
+
+	var transaction domain.Transaction
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &transaction, query, userID, transactionID)
+	} else {
+		err = tx.GetContext(ctx, &transaction, query+" FOR UPDATE OF t", userID, transactionID)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrTransactionNotFound
+		}
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// GetTransactionByReference returns the user's copy of the transfer with the given public reference
+func (r *transactionRepository) GetTransactionByReference(ctx context.Context, userID int, reference string) (*domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t ` + transactionJoins + `
+		WHERE t.user_id = $1 AND t.reference = $2
+	`
+
+	var transaction domain.Transaction
+	if err := r.db.GetContext(ctx, &transaction, query, userID, reference); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrTransactionNotFound
+		}
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// GetSentTransactions returns the successful transfers the user sent in currency after the
+// transaction with id afterID and since the given time, oldest first. Reversals are left out.
+func (r *transactionRepository) GetSentTransactions(ctx context.Context, userID int, currency string, afterID int, since time.Time) ([]domain.Transaction, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions t ` + transactionJoins + `
+		WHERE t.user_id = $1 AND t.sender_id = t.user_id AND t.status = $2
+			AND t.original_transaction_id IS NULL
+			AND t.source_currency = $3 AND t.id > $4 AND t.created_at >= $5
+		ORDER BY t.id
+	`
+
+	var transactions []domain.Transaction
+	if err := r.db.SelectContext(ctx, &transactions, query, userID, constants.SUCCESS, currency, afterID, since); err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
+func (r *transactionRepository) InsertRefund(ctx context.Context, tx *sqlx.Tx, refund *domain.Refund) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO transaction_refunds
+			(transaction_id, sender_id, beneficiary_id, amount, currency, refund_currency, rate_type, reason, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at, updated_at;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		refund.TransactionID,
+		refund.SenderID,
+		refund.BeneficiaryID,
+		refund.Amount,
+		refund.Currency,
+		refund.RefundCurrency,
+		refund.RateType,
+		refund.Reason,
+		refund.Status,
+	).Scan(&refund.ID, &refund.CreatedAt, &refund.UpdatedAt)
+}
+
+func (r *transactionRepository) UpdateRefund(ctx context.Context, tx *sqlx.Tx, refund domain.Refund) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE transaction_refunds
+		SET
+			refund_amount = $1,
+			reversal_transaction_id = NULLIF($2, 0),
+			status = $3,
+			updated_at = NOW()
+		WHERE id = $4;
+	`
+
+	if _, err := tx.ExecContext(ctx, query, refund.RefundAmount, refund.ReversalTransactionID, refund.Status, refund.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+const refundColumns = `
+	id,
+	transaction_id,
+	COALESCE(reversal_transaction_id, 0) AS reversal_transaction_id,
+	sender_id,
+	beneficiary_id,
+	amount,
+	currency,
+	refund_amount,
+	refund_currency,
+	rate_type,
+	reason,
+	status,
+	created_at,
+	updated_at
+`
+
+// GetRefundByID returns a refund, locking it when called inside a sql transaction
+func (r *transactionRepository) GetRefundByID(ctx context.Context, tx *sqlx.Tx, refundID int) (*domain.Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `SELECT ` + refundColumns + ` FROM transaction_refunds WHERE id = $1`
+
+	var refund domain.Refund
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &refund, query, refundID)
+	} else {
+		err = tx.GetContext(ctx, &refund, query+" FOR UPDATE", refundID)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrRefundNotFound
+		}
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+func (r *transactionRepository) GetRefundsByTransactionID(ctx context.Context, tx *sqlx.Tx, transactionID int) ([]domain.Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `SELECT ` + refundColumns + ` FROM transaction_refunds WHERE transaction_id = $1 ORDER BY id;`
+
+	var refunds []domain.Refund
+	if err := tx.SelectContext(ctx, &refunds, query, transactionID); err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// GetRefundsByUserID returns the refunds a user asked for or has been asked to approve
+func (r *transactionRepository) GetRefundsByUserID(ctx context.Context, userID int) (*[]domain.Refund, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + refundColumns + `
+		FROM transaction_refunds
+		WHERE sender_id = $1 OR beneficiary_id = $1
+		ORDER BY created_at DESC;
 	`
 
-	return r.db.QueryRowContext(ctx, query, userID).Scan(&paginator.TotalRecords)
+	var refunds []domain.Refund
+	if err := r.db.SelectContext(ctx, &refunds, query, userID); err != nil {
+		return nil, err
+	}
+
+	if len(refunds) == 0 {
+		return nil, exception.ErrNoRefundsFound
+	}
+
+	return &refunds, nil
 }
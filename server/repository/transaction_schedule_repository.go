@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/jmoiron/sqlx"
+)
+
+const scheduledTransferColumns = `
+			id,
+			user_id,
+			sender_wallet_id,
+			source_amount,
+			source_currency,
+			beneficiary_mobile_country_code,
+			beneficiary_mobile_number,
+			auto_convert,
+			frequency,
+			start_at,
+			end_at,
+			max_runs,
+			run_count,
+			next_run_at,
+			status,
+			created_at,
+			updated_at`
+
+func (r *transactionRepository) InsertScheduledTransfer(ctx context.Context, transfer *domain.ScheduledTransfer) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO scheduled_transfers
+			(user_id, sender_wallet_id, source_amount, source_currency, beneficiary_mobile_country_code, beneficiary_mobile_number,
+			auto_convert, frequency, start_at, end_at, max_runs, next_run_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at;
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		transfer.UserID,
+		transfer.SenderWalletID,
+		transfer.SourceAmount,
+		transfer.SourceCurrency,
+		transfer.BeneficiaryMobileCountryCode,
+		transfer.BeneficiaryMobileNumber,
+		transfer.AutoConvert,
+		transfer.Frequency,
+		transfer.StartAt,
+		transfer.EndAt,
+		transfer.MaxRuns,
+		transfer.NextRunAt,
+		transfer.Status,
+	).Scan(&transfer.ID, &transfer.CreatedAt, &transfer.UpdatedAt)
+}
+
+// UpdateScheduledTransfer saves the schedule, status and run count of a transfer locked in tx
+func (r *transactionRepository) UpdateScheduledTransfer(ctx context.Context, tx *sqlx.Tx, transfer domain.ScheduledTransfer) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE scheduled_transfers
+		SET next_run_at = $1, status = $2, run_count = $3, updated_at = NOW()
+		WHERE id = $4;
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		transfer.NextRunAt,
+		transfer.Status,
+		transfer.RunCount,
+		transfer.ID,
+	)
+	return err
+}
+
+func (r *transactionRepository) GetScheduledTransfers(ctx context.Context, userID int) ([]domain.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE user_id = $1
+		ORDER BY id;
+	`
+
+	var transfers []domain.ScheduledTransfer
+	if err := r.db.SelectContext(ctx, &transfers, query, userID); err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return nil, exception.ErrNoScheduledTransfersFound
+	}
+
+	return transfers, nil
+}
+
+func (r *transactionRepository) GetScheduledTransferByID(ctx context.Context, tx *sqlx.Tx, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE user_id = $1 AND id = $2
+	`
+
+	var transfer domain.ScheduledTransfer
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &transfer, query, userID, scheduledTransferID)
+	} else {
+		// lock the transfer so that a run of the scheduler does not overwrite the change
+		err = tx.GetContext(ctx, &transfer, query+" FOR UPDATE", userID, scheduledTransferID)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrScheduledTransferNotFound
+		}
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// GetDueScheduledTransfers returns up to limit active transfers whose next run is at or before now, the longest overdue first
+func (r *transactionRepository) GetDueScheduledTransfers(ctx context.Context, now time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at, id
+		LIMIT $3;
+	`
+
+	var transfers []domain.ScheduledTransfer
+	if err := r.db.SelectContext(ctx, &transfers, query, domain.ScheduledTransferStatusActive, now, limit); err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+// LockDueScheduledTransfer locks the transfer for a run if it is still active and due at now. A transfer another
+// scheduler is running is skipped rather than waited for, and ErrScheduledTransferNotFound is returned.
+func (r *transactionRepository) LockDueScheduledTransfer(ctx context.Context, tx *sqlx.Tx, scheduledTransferID int, now time.Time) (*domain.ScheduledTransfer, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT ` + scheduledTransferColumns + `
+		FROM scheduled_transfers
+		WHERE id = $1 AND status = $2 AND next_run_at <= $3
+		FOR UPDATE SKIP LOCKED;
+	`
+
+	var transfer domain.ScheduledTransfer
+	if err := tx.GetContext(ctx, &transfer, query, scheduledTransferID, domain.ScheduledTransferStatusActive, now); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrScheduledTransferNotFound
+		}
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+const scheduledTransferRunSelect = `
+		SELECT
+			str.id,
+			str.scheduled_transfer_id,
+			str.user_id,
+			COALESCE(str.transaction_id, 0) AS transaction_id,
+			COALESCE(t.reference, '') AS transaction_reference,
+			str.status,
+			COALESCE(str.failure_reason, '') AS failure_reason,
+			str.created_at,
+			str.updated_at
+		FROM scheduled_transfer_runs str
+		LEFT JOIN transactions t ON t.id = str.transaction_id`
+
+func (r *transactionRepository) InsertScheduledTransferRun(ctx context.Context, tx *sqlx.Tx, run *domain.ScheduledTransferRun) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO scheduled_transfer_runs (scheduled_transfer_id, user_id, transaction_id, status)
+		VALUES ($1, $2, NULLIF($3, 0), $4)
+		RETURNING id, created_at, updated_at;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		run.ScheduledTransferID,
+		run.UserID,
+		run.TransactionID,
+		run.Status,
+	).Scan(&run.ID, &run.CreatedAt, &run.UpdatedAt)
+}
+
+// UpdateScheduledTransferRun records the outcome of a run and the transaction it made
+func (r *transactionRepository) UpdateScheduledTransferRun(ctx context.Context, tx *sqlx.Tx, run domain.ScheduledTransferRun) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE scheduled_transfer_runs
+		SET transaction_id = NULLIF($1, 0), status = $2, failure_reason = NULLIF($3, ''), updated_at = NOW()
+		WHERE id = $4;
+	`
+
+	_, err := tx.ExecContext(ctx, query,
+		run.TransactionID,
+		run.Status,
+		run.FailureReason,
+		run.ID,
+	)
+	return err
+}
+
+// GetScheduledTransferRuns returns the runs of a scheduled transfer, latest first
+func (r *transactionRepository) GetScheduledTransferRuns(ctx context.Context, userID, scheduledTransferID int) ([]domain.ScheduledTransferRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := scheduledTransferRunSelect + `
+		WHERE str.user_id = $1 AND str.scheduled_transfer_id = $2
+		ORDER BY str.id DESC;
+	`
+
+	var runs []domain.ScheduledTransferRun
+	if err := r.db.SelectContext(ctx, &runs, query, userID, scheduledTransferID); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+// GetScheduledTransferRunByID returns a run locked in tx, only the run is locked and not the transaction joined to it
+func (r *transactionRepository) GetScheduledTransferRunByID(ctx context.Context, tx *sqlx.Tx, runID int) (*domain.ScheduledTransferRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := scheduledTransferRunSelect + `
+		WHERE str.id = $1
+		FOR UPDATE OF str;
+	`
+
+	var run domain.ScheduledTransferRun
+	if err := tx.GetContext(ctx, &run, query, runID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrScheduledTransferNotFound
+		}
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// GetPendingScheduledTransferRuns returns the runs that have been pending since before updatedBefore, oldest first
+func (r *transactionRepository) GetPendingScheduledTransferRuns(ctx context.Context, updatedBefore time.Time) ([]domain.ScheduledTransferRun, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := scheduledTransferRunSelect + `
+		WHERE str.status = $1 AND str.updated_at <= $2
+		ORDER BY str.updated_at;
+	`
+
+	var runs []domain.ScheduledTransferRun
+	if err := r.db.SelectContext(ctx, &runs, query, domain.ScheduledTransferRunPending, updatedBefore); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -23,25 +25,38 @@ func NewWalletRepository(db *sqlx.DB) domain.WalletRepository {
 	}
 }
 
-func (r *walletRepository) GetWalletByWalletID(ctx context.Context, userID, walletID int) (*domain.Wallet, error) {
+// GetWalletByWalletID returns a wallet of the user in any status. Inside a transaction the wallet
+// row is locked so that its status cannot change while money moves in or out of it.
+func (r *walletRepository) GetWalletByWalletID(ctx context.Context, tx *sqlx.Tx, userID, walletID int) (*domain.Wallet, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	query := `
 		SELECT
 			w.id AS id,
+			w.name,
 			w.user_id AS user_id,
 			wt.type AS wallet_type,
 			wt.id AS wallet_type_id,
-			w.created_at
+			w.status,
+			w.created_at,
+			w.closed_at
 		FROM wallets w
 		JOIN wallet_types wt
 			ON w.wallet_type_id = wt.id
-		WHERE w.user_id = $1 AND w.id = $2;
+		WHERE w.user_id = $1 AND w.id = $2
 	`
 
 	var wallet domain.Wallet
-	if err := r.db.GetContext(ctx, &wallet, query, userID, walletID); err != nil {
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &wallet, query, userID, walletID)
+	} else {
+		// lock the wallet until the transaction completes
+		err = tx.GetContext(ctx, &wallet, query+" FOR UPDATE OF w", userID, walletID)
+	}
+
+	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, exception.ErrNoWalletFound
 		}
@@ -50,24 +65,29 @@ func (r *walletRepository) GetWalletByWalletID(ctx context.Context, userID, wall
 	return &wallet, nil
 }
 
-func (r *walletRepository) GetWallets(ctx context.Context, userID int) ([]domain.Wallet, error) {
+// GetWallets returns the wallets of the user, closed wallets only when includeClosed is set
+func (r *walletRepository) GetWallets(ctx context.Context, userID int, includeClosed bool) ([]domain.Wallet, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	query := `
 		SELECT
 			w.id AS id,
+			w.name,
 			wt.type AS wallet_type,
 			wt.id AS wallet_type_id,
-			w.created_at
+			w.status,
+			w.created_at,
+			w.closed_at
 		FROM wallets w
 		JOIN wallet_types wt
 			ON w.wallet_type_id = wt.id
-		WHERE w.user_id = $1;
+		WHERE w.user_id = $1 AND ($2 OR w.status <> 'closed')
+		ORDER BY w.id;
 	`
 
 	var wallets []domain.Wallet
-	if err := r.db.SelectContext(ctx, &wallets, query, userID); err != nil {
+	if err := r.db.SelectContext(ctx, &wallets, query, userID, includeClosed); err != nil {
 		return nil, err
 	}
 
@@ -107,7 +127,8 @@ func (r *walletRepository) GetWalletBalancesByUserIDAndWalletID(ctx context.Cont
 	query := `
 		SELECT wallet_id, amount, currency, created_at, updated_at
 		FROM wallet_balances
-		WHERE user_id = $1 AND wallet_id = $2;
+		WHERE user_id = $1 AND wallet_id = $2
+		ORDER BY currency
 	`
 
 	var walletBalances []domain.WalletCurrencyAmount
@@ -115,7 +136,8 @@ func (r *walletRepository) GetWalletBalancesByUserIDAndWalletID(ctx context.Cont
 	if tx == nil {
 		err = r.db.SelectContext(ctx, &walletBalances, query, userID, walletID)
 	} else {
-		err = tx.SelectContext(ctx, &walletBalances, query, userID, walletID)
+		// lock the wallet balances until the transaction completes
+		err = tx.SelectContext(ctx, &walletBalances, query+" FOR UPDATE", userID, walletID)
 	}
 
 	if err != nil {
@@ -129,6 +151,32 @@ func (r *walletRepository) GetWalletBalancesByUserIDAndWalletID(ctx context.Cont
 	return walletBalances, nil
 }
 
+// GetWalletBalancesByWalletIDs locks the balances of several wallets of a user at once, in
+// (currency, wallet_id) order so that movements between the same wallets in opposite
+// directions wait on each other instead of deadlocking. Wallets without balances return no rows.
+func (r *walletRepository) GetWalletBalancesByWalletIDs(ctx context.Context, tx *sqlx.Tx, userID int, walletIDs ...int) ([]domain.WalletCurrencyAmount, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query, args, err := sqlx.In(`
+		SELECT wallet_id, amount, currency, created_at, updated_at
+		FROM wallet_balances
+		WHERE user_id = ? AND wallet_id IN (?)
+		ORDER BY currency, wallet_id
+		FOR UPDATE;
+	`, userID, walletIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var walletBalances []domain.WalletCurrencyAmount
+	if err := tx.SelectContext(ctx, &walletBalances, r.db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+
+	return walletBalances, nil
+}
+
 func (r *walletRepository) GetWalletTypes(ctx context.Context) (*[]dto.GetWalletTypesResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
@@ -149,7 +197,40 @@ func (r *walletRepository) GetWalletTypes(ctx context.Context) (*[]dto.GetWallet
 	return &walletTypes, nil
 }
 
-func (r *walletRepository) CheckWalletExistsByWalletTypeID(ctx context.Context, userID, walletTypeID int) (bool, error) {
+// CountWallets counts the wallets a user has that are not closed. The user row is locked
+// first so that wallets created at the same time by the same user are counted one after another.
+func (r *walletRepository) CountWallets(ctx context.Context, tx *sqlx.Tx, userID int) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	lockQuery := `
+		SELECT 1
+		FROM users
+		WHERE id = $1
+		FOR NO KEY UPDATE;
+	`
+
+	if _, err := tx.ExecContext(ctx, lockQuery, userID); err != nil {
+		return 0, err
+	}
+
+	query := `
+		SELECT COUNT(*)
+		FROM wallets
+		WHERE 
+			user_id = $1 AND
+			status <> 'closed';
+	`
+
+	var count int
+	if err := tx.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *walletRepository) CheckWalletExistsByName(ctx context.Context, userID int, name string) (bool, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
@@ -159,12 +240,13 @@ func (r *walletRepository) CheckWalletExistsByWalletTypeID(ctx context.Context,
 			FROM wallets
 			WHERE 
 				user_id = $1 AND
-				wallet_type_id = $2
+				LOWER(name) = LOWER($2) AND
+				status <> 'closed'
 		)
 	`
 
 	var walletExists bool
-	if err := r.db.QueryRowContext(ctx, query, userID, walletTypeID).Scan(&walletExists); err != nil {
+	if err := r.db.QueryRowContext(ctx, query, userID, name).Scan(&walletExists); err != nil {
 		return false, err
 	}
 
@@ -196,19 +278,64 @@ func (r *walletRepository) CreateWallet(ctx context.Context, tx *sqlx.Tx, wallet
 	defer cancel()
 
 	query := `
-		INSERT INTO wallets (wallet_type_id, user_id, created_at)
-		VALUES ($1, $2, $3)
+		INSERT INTO wallets (wallet_type_id, user_id, name, created_at)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id;
 	`
 
 	var walletID int
-	if err := tx.QueryRowContext(ctx, query, wallet.WalletTypeID, wallet.UserID, time.Now()).Scan(&walletID); err != nil {
+	if err := tx.QueryRowContext(ctx, query, wallet.WalletTypeID, wallet.UserID, wallet.Name, time.Now()).Scan(&walletID); err != nil {
+		// another wallet took the name after it was checked
+		if infrastructure.IsUniqueViolation(err) {
+			return 0, exception.ErrWalletAlreadyExists
+		}
 		return 0, err
 	}
 
 	return walletID, nil
 }
 
+func (r *walletRepository) UpdateWalletName(ctx context.Context, userID, walletID int, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE wallets
+		SET name = $1, updated_at = NOW()
+		WHERE user_id = $2 AND id = $3;
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, name, userID, walletID); err != nil {
+		if infrastructure.IsUniqueViolation(err) {
+			return exception.ErrWalletAlreadyExists
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UpdateWalletStatus moves a wallet to status in the caller's transaction, stamping when it was closed
+func (r *walletRepository) UpdateWalletStatus(ctx context.Context, tx *sqlx.Tx, userID, walletID int, status string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE wallets
+		SET
+			status = $1,
+			closed_at = CASE WHEN $1 = 'closed' THEN NOW() ELSE closed_at END,
+			updated_at = NOW()
+		WHERE user_id = $2 AND id = $3;
+	`
+
+	if _, err := tx.ExecContext(ctx, query, status, userID, walletID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (r *walletRepository) InsertWalletCurrencyAmount(ctx context.Context, tx *sqlx.Tx, walletID, userID int, currencyAmount []domain.WalletCurrencyAmount) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
@@ -227,14 +354,15 @@ func (r *walletRepository) InsertWalletCurrencyAmount(ctx context.Context, tx *s
 	return nil
 }
 
-func (r *walletRepository) TopUpWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]float64) error {
+func (r *walletRepository) TopUpWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]money.Amount) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
 	placeholders := make([]string, 0)
 	args := make([]interface{}, 0)
 
-	for currency, amount := range finalWalletBalancesMap {
+	for _, currency := range sortedCurrencies(finalWalletBalancesMap) {
+		amount := finalWalletBalancesMap[currency]
 		placeholder := "( ?, ?, ?, ?, ?, ? )"
 		placeholders = append(placeholders, placeholder)
 		args = append(args, amount, currency, walletID, userID, time.Now(), time.Now())
@@ -260,11 +388,12 @@ func (r *walletRepository) TopUpWalletBalances(ctx context.Context, tx *sqlx.Tx,
 	return nil
 }
 
-func (r *walletRepository) CashOutWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]float64) error {
+func (r *walletRepository) CashOutWalletBalances(ctx context.Context, tx *sqlx.Tx, userID, walletID int, finalWalletBalancesMap map[string]money.Amount) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Minute)
 	defer cancel()
 
-	for currency, amount := range finalWalletBalancesMap {
+	for _, currency := range sortedCurrencies(finalWalletBalancesMap) {
+		amount := finalWalletBalancesMap[currency]
 		querySelect := `
 			SELECT * FROM wallet_balances
 			WHERE
@@ -297,3 +426,168 @@ func (r *walletRepository) CashOutWalletBalances(ctx context.Context, tx *sqlx.T
 
 	return nil
 }
+
+// CreateWalletHistory records each currency line of a wallet movement in the caller's transaction
+func (r *walletRepository) CreateWalletHistory(ctx context.Context, tx *sqlx.Tx, history []domain.WalletHistory) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO wallet_history (wallet_id, user_id, type, counterparty_wallet_id, journal_entry_id, currency, amount)
+		VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, 0), $6, $7)
+		RETURNING id;
+	`
+
+	for idx := range history {
+		h := &history[idx]
+		if err := tx.QueryRowContext(ctx, query,
+			h.WalletID,
+			h.UserID,
+			h.Type,
+			h.CounterpartyWalletID,
+			h.JournalEntryID,
+			h.Currency,
+			h.Amount,
+		).Scan(&h.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *walletRepository) GetWalletGoal(ctx context.Context, tx *sqlx.Tx, userID, walletID int) (*domain.WalletGoal, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT id, wallet_id, user_id, target_amount, currency, TO_CHAR(deadline, 'YYYY-MM-DD') AS deadline, completed_at, created_at
+		FROM wallet_goals
+		WHERE user_id = $1 AND wallet_id = $2
+	`
+
+	var goal domain.WalletGoal
+	var err error
+	if tx == nil {
+		err = r.db.GetContext(ctx, &goal, query, userID, walletID)
+	} else {
+		// lock the goal so that it is completed once, locked after its wallet
+		err = tx.GetContext(ctx, &goal, query+" FOR UPDATE", userID, walletID)
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, exception.ErrWalletGoalNotFound
+		}
+		return nil, err
+	}
+	return &goal, nil
+}
+
+func (r *walletRepository) GetWalletGoals(ctx context.Context, userID int) ([]domain.WalletGoal, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		SELECT id, wallet_id, user_id, target_amount, currency, TO_CHAR(deadline, 'YYYY-MM-DD') AS deadline, completed_at, created_at
+		FROM wallet_goals
+		WHERE user_id = $1
+		ORDER BY wallet_id;
+	`
+
+	var goals []domain.WalletGoal
+	if err := r.db.SelectContext(ctx, &goals, query, userID); err != nil {
+		return nil, err
+	}
+
+	return goals, nil
+}
+
+// UpsertWalletGoal sets the goal of a wallet. A goal that is replaced starts over, so it can be
+// completed again.
+func (r *walletRepository) UpsertWalletGoal(ctx context.Context, goal *domain.WalletGoal) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO wallet_goals (wallet_id, user_id, target_amount, currency, deadline)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (wallet_id) DO UPDATE SET
+			target_amount = EXCLUDED.target_amount,
+			currency = EXCLUDED.currency,
+			deadline = EXCLUDED.deadline,
+			completed_at = NULL,
+			updated_at = NOW()
+		RETURNING id, created_at;
+	`
+
+	return r.db.QueryRowContext(ctx, query,
+		goal.WalletID,
+		goal.UserID,
+		goal.TargetAmount,
+		goal.Currency,
+		goal.Deadline,
+	).Scan(&goal.ID, &goal.CreatedAt)
+}
+
+func (r *walletRepository) DeleteWalletGoal(ctx context.Context, userID, walletID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		DELETE FROM wallet_goals
+		WHERE user_id = $1 AND wallet_id = $2;
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, walletID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return exception.ErrWalletGoalNotFound
+	}
+
+	return nil
+}
+
+func (r *walletRepository) CompleteWalletGoal(ctx context.Context, tx *sqlx.Tx, goalID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		UPDATE wallet_goals
+		SET completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1;
+	`
+
+	if _, err := tx.ExecContext(ctx, query, goalID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *walletRepository) CreateWalletGoalEvent(ctx context.Context, tx *sqlx.Tx, event *domain.WalletGoalEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	query := `
+		INSERT INTO wallet_goal_events (goal_id, wallet_id, user_id, type, currency, saved_amount)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;
+	`
+
+	return tx.QueryRowContext(ctx, query,
+		event.GoalID,
+		event.WalletID,
+		event.UserID,
+		event.Type,
+		event.Currency,
+		event.SavedAmount,
+	).Scan(&event.ID)
+}
@@ -4,13 +4,14 @@ import (
 	"time"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 )
 
 // NewBalance returns a new instance of Balance with sample data.
 func NewBalance() domain.Balance {
 	return domain.Balance{
 		ID:        1,
-		Balance:   100.0,
+		Balance:   money.FromInt(100),
 		Currency:  "USD",
 		UserID:    1,
 		CreatedAt: time.Now().Format(time.RFC3339),
@@ -22,7 +23,7 @@ func NewBalance() domain.Balance {
 func NewBalanceWithID(id int) domain.Balance {
 	return domain.Balance{
 		ID:        id,
-		Balance:   150.0,
+		Balance:   money.FromInt(150),
 		Currency:  "EUR",
 		UserID:    2,
 		CreatedAt: time.Now().Format(time.RFC3339),
@@ -35,7 +36,7 @@ func NewBalances() []domain.Balance {
 	return []domain.Balance{
 		{
 			ID:        1,
-			Balance:   100.0,
+			Balance:   money.FromInt(100),
 			Currency:  "USD",
 			UserID:    1,
 			CreatedAt: time.Now().Format(time.RFC3339),
@@ -43,7 +44,7 @@ func NewBalances() []domain.Balance {
 		},
 		{
 			ID:        2,
-			Balance:   50.0,
+			Balance:   money.FromInt(50),
 			Currency:  "EUR",
 			UserID:    2,
 			CreatedAt: time.Now().Format(time.RFC3339),
@@ -51,7 +52,7 @@ func NewBalances() []domain.Balance {
 		},
 		{
 			ID:        3,
-			Balance:   200.0,
+			Balance:   money.FromInt(200),
 			Currency:  "SGD",
 			UserID:    1,
 			CreatedAt: time.Now().Format(time.RFC3339),
@@ -64,21 +65,21 @@ func NewWalletCurrencyAmount() []domain.WalletCurrencyAmount {
 	return []domain.WalletCurrencyAmount{
 		{
 			WalletID:  1,
-			Amount:    100.50,
+			Amount:    money.MustParse("100.50"),
 			Currency:  "SGD",
 			CreatedAt: "2024-06-16T12:00:00Z",
 			UpdatedAt: "2024-06-16T12:00:00Z",
 		},
 		{
 			WalletID:  2,
-			Amount:    200.25,
+			Amount:    money.MustParse("200.25"),
 			Currency:  "USD",
 			CreatedAt: "2024-06-15T12:00:00Z",
 			UpdatedAt: "2024-06-15T12:00:00Z",
 		},
 		{
 			WalletID:  3,
-			Amount:    50.00,
+			Amount:    money.FromInt(50),
 			Currency:  "EUR",
 			CreatedAt: "2024-06-14T12:00:00Z",
 			UpdatedAt: "2024-06-14T12:00:00Z",
@@ -0,0 +1,23 @@
+package testdata
+
+import "github.com/LeonLow97/go-clean-architecture/domain"
+
+// MockCurrencies returns the currencies seeded in the currencies table.
+func MockCurrencies() []domain.Currency {
+	return []domain.Currency{
+		{Code: "AUD", Name: "Australian Dollar", Symbol: "A$", Exponent: 2, Enabled: true},
+		{Code: "MYR", Name: "Malaysian Ringgit", Symbol: "RM", Exponent: 2, Enabled: true},
+		{Code: "SGD", Name: "Singapore Dollar", Symbol: "S$", Exponent: 2, Enabled: true},
+		{Code: "USD", Name: "US Dollar", Symbol: "$", Exponent: 2, Enabled: true},
+	}
+}
+
+// MockCountryCurrencies returns the home currencies seeded in the country_currencies table.
+func MockCountryCurrencies() []domain.CountryCurrency {
+	return []domain.CountryCurrency{
+		{MobileCountryCode: "+1", Currency: "USD"},
+		{MobileCountryCode: "+60", Currency: "MYR"},
+		{MobileCountryCode: "+61", Currency: "AUD"},
+		{MobileCountryCode: "+65", Currency: "SGD"},
+	}
+}
@@ -0,0 +1,45 @@
+package testdata
+
+import (
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// mockExchangeRates is the rate and spread for every pair, as seeded in the exchange_rates table.
+var mockExchangeRates = map[string]map[string][2]string{
+	"SGD": {"USD": {"0.76", "0.005"}, "AUD": {"1.03", "0.004"}, "MYR": {"3.29", "0.007"}},
+	"USD": {"SGD": {"1.32", "0.006"}, "AUD": {"1.41", "0.005"}, "MYR": {"4.43", "0.008"}},
+	"AUD": {"SGD": {"0.97", "0.003"}, "USD": {"0.71", "0.004"}, "MYR": {"3.35", "0.006"}},
+	"MYR": {"SGD": {"0.30", "0.008"}, "USD": {"0.22", "0.007"}, "AUD": {"0.30", "0.009"}},
+}
+
+// MockExchangeRate returns the seeded exchange rate for a currency pair.
+// Unknown pairs come back with a zero rate.
+func MockExchangeRate(fromCurrency, toCurrency string) domain.ExchangeRate {
+	rate := domain.ExchangeRate{
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+		EffectiveAt:  time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+	}
+	if seeded, exists := mockExchangeRates[fromCurrency][toCurrency]; exists {
+		rate.Rate = money.MustParseRate(seeded[0])
+		rate.Spread = money.MustParseRate(seeded[1])
+	}
+	return rate
+}
+
+// MockExchangeRates returns the seeded exchange rates for every currency pair.
+func MockExchangeRates() []domain.ExchangeRate {
+	var rates []domain.ExchangeRate
+	for _, from := range []string{"SGD", "USD", "AUD", "MYR"} {
+		for _, to := range []string{"SGD", "USD", "AUD", "MYR"} {
+			if from == to {
+				continue
+			}
+			rates = append(rates, MockExchangeRate(from, to))
+		}
+	}
+	return rates
+}
@@ -0,0 +1,32 @@
+package testdata
+
+import (
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// MockFeeRule returns the rule seeded for product in the first fee schedule,
+// which keeps 2% of the spread on every corridor and charges nothing else.
+func MockFeeRule(product string) *domain.FeeRule {
+	return &domain.FeeRule{
+		ID:            1,
+		FeeScheduleID: 1,
+		Product:       product,
+		FromCurrency:  domain.FeeCorridorAny,
+		ToCurrency:    domain.FeeCorridorAny,
+		SpreadShare:   money.MustParseRate("0.02"),
+	}
+}
+
+// MockFeeSchedule returns the first fee schedule as seeded in the fee_schedules table
+func MockFeeSchedule() *domain.FeeSchedule {
+	return &domain.FeeSchedule{
+		ID:      1,
+		Version: 1,
+		Note:    "Initial fee schedule",
+		Rules: []domain.FeeRule{
+			*MockFeeRule(domain.FeeProductExchange),
+			*MockFeeRule(domain.FeeProductTransfer),
+		},
+	}
+}
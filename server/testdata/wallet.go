@@ -3,6 +3,7 @@ package testdata
 import (
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 )
 
 var fixedTime = "2024-06-15T13:45:00Z"
@@ -14,6 +15,7 @@ func MockWallet() *domain.Wallet {
 		WalletType:   "Personal",
 		WalletTypeID: 1,
 		UserID:       1,
+		Status:       domain.WalletStatusActive,
 		CreatedAt:    fixedTime,
 	}
 }
@@ -23,14 +25,14 @@ func MockWalletCurrencyAmounts() []domain.WalletCurrencyAmount {
 	return []domain.WalletCurrencyAmount{
 		{
 			WalletID:  1,
-			Amount:    100.0,
+			Amount:    money.FromInt(100),
 			Currency:  "SGD",
 			CreatedAt: fixedTime,
 			UpdatedAt: fixedTime,
 		},
 		{
 			WalletID:  1,
-			Amount:    50.0,
+			Amount:    money.FromInt(50),
 			Currency:  "USD",
 			CreatedAt: fixedTime,
 			UpdatedAt: fixedTime,
@@ -45,6 +47,7 @@ func MockWalletWithCurrencyAmounts() *domain.Wallet {
 		WalletType:     "Personal",
 		WalletTypeID:   1,
 		UserID:         1,
+		Status:         domain.WalletStatusActive,
 		CreatedAt:      fixedTime,
 		CurrencyAmount: MockWalletCurrencyAmounts(),
 	}
@@ -71,14 +74,14 @@ func MockWalletCurrencyAmountsByWalletID(walletID int) []domain.WalletCurrencyAm
 	return []domain.WalletCurrencyAmount{
 		{
 			WalletID:  walletID,
-			Amount:    float64(walletID * 100),
+			Amount:    money.FromInt(int64(walletID * 100)),
 			Currency:  "SGD",
 			CreatedAt: fixedTime,
 			UpdatedAt: fixedTime,
 		},
 		{
 			WalletID:  walletID,
-			Amount:    float64(walletID * 50),
+			Amount:    money.FromInt(int64(walletID * 50)),
 			Currency:  "USD",
 			CreatedAt: fixedTime,
 			UpdatedAt: fixedTime,
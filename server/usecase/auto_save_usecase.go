@@ -0,0 +1,320 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/jmoiron/sqlx"
+)
+
+type autoSaveUsecase struct {
+	txManager             domain.TxManager
+	autoSaveRepository    domain.AutoSaveRepository
+	walletRepository      domain.WalletRepository
+	transactionRepository domain.TransactionRepository
+	walletUsecase         domain.WalletUsecase
+	currencies            currencyCatalogue
+	maxFailures           int
+	batchSize             int
+}
+
+func NewAutoSaveUsecase(cfg infrastructure.Config, txManager domain.TxManager, autoSaveRepository domain.AutoSaveRepository, walletRepository domain.WalletRepository, transactionRepository domain.TransactionRepository, walletUsecase domain.WalletUsecase, currencyRepository domain.CurrencyRepository) domain.AutoSaveUsecase {
+	maxFailures := cfg.AutoSave.MaxFailures
+	if maxFailures <= 0 {
+		maxFailures = constants.DEFAULT_AUTO_SAVE_MAX_FAILURES
+	}
+	batchSize := cfg.AutoSave.BatchSize
+	if batchSize <= 0 {
+		batchSize = constants.DEFAULT_AUTO_SAVE_BATCH_SIZE
+	}
+
+	return &autoSaveUsecase{
+		txManager:             txManager,
+		autoSaveRepository:    autoSaveRepository,
+		walletRepository:      walletRepository,
+		transactionRepository: transactionRepository,
+		walletUsecase:         walletUsecase,
+		currencies:            currencyCatalogue{repository: currencyRepository},
+		maxFailures:           maxFailures,
+		batchSize:             batchSize,
+	}
+}
+
+// CreateAutoSaveRule sets up a rule that moves money from the main balance into one of the user's
+// wallets. A rule starting in the past first runs at its next occurrence after now.
+func (uc *autoSaveUsecase) CreateAutoSaveRule(ctx context.Context, userID int, req dto.CreateAutoSaveRuleRequest) (*domain.AutoSaveRule, error) {
+	amount := req.Amount
+	switch req.Type {
+	case domain.AutoSaveTypeSchedule:
+		if !amount.IsPositive() {
+			return nil, exception.ErrAutoSaveAmountRequired
+		}
+	case domain.AutoSaveTypeRoundUp:
+		// what a round up rule moves is worked out from the transfers on every run
+		amount = 0
+	}
+
+	wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, nil, userID, req.WalletID)
+	if err != nil {
+		log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+	if err = wallet.CheckActive(); err != nil {
+		return nil, err
+	}
+
+	if _, err = uc.currencies.currency(ctx, req.Currency); err != nil {
+		log.Printf("user %d cannot auto-save in currency %s with error: %v\n", userID, req.Currency, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &domain.AutoSaveRule{
+		UserID:    userID,
+		WalletID:  req.WalletID,
+		Type:      req.Type,
+		Amount:    amount,
+		Currency:  req.Currency,
+		Frequency: req.Frequency,
+		StartAt:   now,
+		NextRunAt: now,
+		Status:    domain.AutoSaveStatusActive,
+	}
+	if req.StartAt != nil {
+		rule.StartAt = *req.StartAt
+		rule.NextRunAt = rule.StartAt
+		if rule.StartAt.Before(now) {
+			rule.NextRunAt = rule.NextRun(now)
+		}
+	}
+
+	if err = uc.autoSaveRepository.CreateAutoSaveRule(ctx, rule); err != nil {
+		log.Printf("failed to create auto-save rule for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (uc *autoSaveUsecase) GetAutoSaveRules(ctx context.Context, userID int) ([]domain.AutoSaveRule, error) {
+	rules, err := uc.autoSaveRepository.GetAutoSaveRules(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get auto-save rules for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetAutoSaveRuns lists the runs of one of the user's rules, latest first
+func (uc *autoSaveUsecase) GetAutoSaveRuns(ctx context.Context, userID, ruleID int) ([]domain.AutoSaveRun, error) {
+	if _, err := uc.autoSaveRepository.GetAutoSaveRule(ctx, nil, userID, ruleID); err != nil {
+		log.Printf("failed to get auto-save rule id %d for user id %d with error: %v\n", ruleID, userID, err)
+		return nil, err
+	}
+
+	runs, err := uc.autoSaveRepository.GetAutoSaveRuns(ctx, userID, ruleID)
+	if err != nil {
+		log.Printf("failed to get runs of auto-save rule id %d for user id %d with error: %v\n", ruleID, userID, err)
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (uc *autoSaveUsecase) PauseAutoSaveRule(ctx context.Context, userID, ruleID int) (*domain.AutoSaveRule, error) {
+	var rule *domain.AutoSaveRule
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		if rule, err = uc.autoSaveRepository.GetAutoSaveRule(ctx, tx, userID, ruleID); err != nil {
+			log.Printf("failed to get auto-save rule id %d for user id %d with error: %v\n", ruleID, userID, err)
+			return err
+		}
+		if rule.Status == domain.AutoSaveStatusPaused {
+			return exception.ErrAutoSaveRulePaused
+		}
+
+		rule.Status = domain.AutoSaveStatusPaused
+		if err = uc.autoSaveRepository.UpdateAutoSaveRule(ctx, tx, *rule); err != nil {
+			log.Printf("failed to pause auto-save rule id %d for user id %d with error: %v\n", ruleID, userID, err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+// ResumeAutoSaveRule activates a paused rule from its next occurrence, the runs it missed while
+// paused are not made up for and its failures start counting again from zero
+func (uc *autoSaveUsecase) ResumeAutoSaveRule(ctx context.Context, userID, ruleID int) (*domain.AutoSaveRule, error) {
+	var rule *domain.AutoSaveRule
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		if rule, err = uc.autoSaveRepository.GetAutoSaveRule(ctx, tx, userID, ruleID); err != nil {
+			log.Printf("failed to get auto-save rule id %d for user id %d with error: %v\n", ruleID, userID, err)
+			return err
+		}
+		if rule.Status != domain.AutoSaveStatusPaused {
+			return exception.ErrAutoSaveRuleNotPaused
+		}
+
+		rule.Status = domain.AutoSaveStatusActive
+		rule.ConsecutiveFailures = 0
+		rule.NextRunAt = rule.NextRun(time.Now())
+		if err = uc.autoSaveRepository.UpdateAutoSaveRule(ctx, tx, *rule); err != nil {
+			log.Printf("failed to resume auto-save rule id %d for user id %d with error: %v\n", ruleID, userID, err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (uc *autoSaveUsecase) DeleteAutoSaveRule(ctx context.Context, userID, ruleID int) error {
+	if err := uc.autoSaveRepository.DeleteAutoSaveRule(ctx, userID, ruleID); err != nil {
+		log.Printf("failed to delete auto-save rule id %d for user id %d with error: %v\n", ruleID, userID, err)
+		return err
+	}
+
+	return nil
+}
+
+// RunDueAutoSaveRules runs a batch of the rules due at now, each in its own transaction. A rule
+// that cannot be run is logged and left due, it is picked up again on the next call.
+func (uc *autoSaveUsecase) RunDueAutoSaveRules(ctx context.Context, now time.Time) error {
+	rules, err := uc.autoSaveRepository.GetDueAutoSaveRules(ctx, now, uc.batchSize)
+	if err != nil {
+		log.Printf("failed to get due auto-save rules with error: %v\n", err)
+		return err
+	}
+
+	for _, rule := range rules {
+		if err := uc.runAutoSaveRule(ctx, rule.ID, now); err != nil {
+			log.Printf("failed to run auto-save rule id %d for user id %d with error: %v\n", rule.ID, rule.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// autoSaveFailureReasons maps the errors a top up can fail with to the reason code stored against the run
+var autoSaveFailureReasons = map[error]string{
+	exception.ErrInsufficientFunds:    constants.REASON_INSUFFICIENT_FUNDS,
+	exception.ErrBalanceNotFound:      constants.REASON_INSUFFICIENT_FUNDS,
+	exception.ErrWalletArchived:       constants.REASON_WALLET_ARCHIVED,
+	exception.ErrWalletClosed:         constants.REASON_WALLET_CLOSED,
+	exception.ErrCurrencyNotSupported: constants.REASON_CURRENCY_NOT_SUPPORTED,
+	exception.ErrCurrencyDisabled:     constants.REASON_CURRENCY_DISABLED,
+}
+
+func autoSaveFailureReason(err error) string {
+	for target, reason := range autoSaveFailureReasons {
+		if errors.Is(err, target) {
+			return reason
+		}
+	}
+	return constants.REASON_INTERNAL_ERROR
+}
+
+// runAutoSaveRule tops up the wallet of the rule and records the run, moving the rule on to its
+// next occurrence. The top up runs in a savepoint, so when it fails only its own work is undone
+// and the failed run is still recorded with the rule.
+func (uc *autoSaveUsecase) runAutoSaveRule(ctx context.Context, ruleID int, now time.Time) error {
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// a rule that another scheduler is running, or that was paused since, is left alone
+		rule, err := uc.autoSaveRepository.LockDueAutoSaveRule(ctx, tx, ruleID, now)
+		if err != nil {
+			if errors.Is(err, exception.ErrAutoSaveRuleNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		amount := rule.Amount
+		lastTransactionID := rule.LastTransactionID
+		if rule.Type == domain.AutoSaveTypeRoundUp {
+			transactions, err := uc.transactionRepository.GetSentTransactions(ctx, rule.UserID, rule.Currency, rule.LastTransactionID, rule.CreatedAt)
+			if err != nil {
+				log.Printf("failed to get sent transactions for user id %d with error: %v\n", rule.UserID, err)
+				return err
+			}
+
+			amount = 0
+			for _, t := range transactions {
+				amount += domain.RoundUp(t.SourceAmount)
+				lastTransactionID = t.ID
+			}
+		}
+
+		run := &domain.AutoSaveRun{
+			RuleID:   rule.ID,
+			UserID:   rule.UserID,
+			WalletID: rule.WalletID,
+			Amount:   amount,
+			Currency: rule.Currency,
+			Status:   domain.AutoSaveRunSucceeded,
+		}
+		if !amount.IsPositive() {
+			run.Status = domain.AutoSaveRunSkipped
+			rule.LastTransactionID = lastTransactionID
+		} else {
+			uc.topUp(ctx, rule, run, lastTransactionID)
+		}
+		rule.NextRunAt = rule.NextRun(now)
+
+		if err = uc.autoSaveRepository.CreateAutoSaveRun(ctx, tx, run); err != nil {
+			log.Printf("failed to create run of auto-save rule id %d with error: %v\n", rule.ID, err)
+			return err
+		}
+		if err = uc.autoSaveRepository.UpdateAutoSaveRule(ctx, tx, *rule); err != nil {
+			log.Printf("failed to update auto-save rule id %d after its run with error: %v\n", rule.ID, err)
+			return err
+		}
+		return nil
+	})
+}
+
+// topUp moves the amount of run into the wallet of rule, recording the outcome on both. Round up
+// rules only move past the transfers they rounded up once the money has moved, and a rule that
+// keeps running out of funds is paused.
+func (uc *autoSaveUsecase) topUp(ctx context.Context, rule *domain.AutoSaveRule, run *domain.AutoSaveRun, lastTransactionID int) {
+	req := dto.UpdateWalletRequest{
+		CurrencyAmount: []dto.CurrencyAmount{{Amount: run.Amount, Currency: run.Currency}},
+	}
+	err := uc.walletUsecase.TopUpWallet(ctx, rule.UserID, rule.WalletID, req)
+	if err == nil {
+		rule.ConsecutiveFailures = 0
+		rule.LastTransactionID = lastTransactionID
+		return
+	}
+
+	log.Printf("auto-save rule id %d failed to top up wallet id %d with %s %s with error: %v\n", rule.ID, rule.WalletID, run.Amount, run.Currency, err)
+	run.Status = domain.AutoSaveRunFailed
+	run.FailureReason = autoSaveFailureReason(err)
+
+	switch run.FailureReason {
+	case constants.REASON_INSUFFICIENT_FUNDS:
+		rule.ConsecutiveFailures++
+		if rule.ConsecutiveFailures >= uc.maxFailures {
+			rule.Status = domain.AutoSaveStatusPaused
+		}
+	case constants.REASON_WALLET_CLOSED:
+		// a closed wallet never takes money again
+		rule.Status = domain.AutoSaveStatusPaused
+	}
+}
@@ -0,0 +1,319 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoSaveUsecase_CreateAutoSaveRule(t *testing.T) {
+	past := time.Now().Add(-36 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+
+	testCases := []struct {
+		Title             string
+		GivenRequest      dto.CreateAutoSaveRuleRequest
+		GivenWallet       *domain.Wallet
+		GetWalletError    error
+		CreateError       error
+		ExpectedAmount    money.Amount
+		ExpectedNextRunAt time.Time
+		ExpectedError     error
+		ExpectNotCreating bool
+	}{
+		{
+			Title:             "ReturnsSuccessfully_Schedule",
+			GivenRequest:      dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: &future},
+			GivenWallet:       &domain.Wallet{ID: 1, Status: domain.WalletStatusActive},
+			ExpectedAmount:    money.FromInt(50),
+			ExpectedNextRunAt: future,
+		},
+		{
+			Title:             "ReturnsSuccessfully_RoundUpIgnoresAmount",
+			GivenRequest:      dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeRoundUp, Amount: money.FromInt(5), Currency: "SGD", Frequency: domain.FrequencyDaily, StartAt: &future},
+			GivenWallet:       &domain.Wallet{ID: 1, Status: domain.WalletStatusActive},
+			ExpectedNextRunAt: future,
+		},
+		{
+			Title:             "ReturnsSuccessfully_StartInThePast",
+			GivenRequest:      dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyDaily, StartAt: &past},
+			GivenWallet:       &domain.Wallet{ID: 1, Status: domain.WalletStatusActive},
+			ExpectedAmount:    money.FromInt(50),
+			ExpectedNextRunAt: past.AddDate(0, 0, 2),
+		},
+		{
+			Title:             "ReturnsError_AmountRequired",
+			GivenRequest:      dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeSchedule, Currency: "SGD", Frequency: domain.FrequencyWeekly},
+			ExpectedError:     exception.ErrAutoSaveAmountRequired,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:             "ReturnsError_WalletNotFound",
+			GivenRequest:      dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly},
+			GetWalletError:    exception.ErrNoWalletFound,
+			ExpectedError:     exception.ErrNoWalletFound,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:             "ReturnsError_WalletArchived",
+			GivenRequest:      dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly},
+			GivenWallet:       &domain.Wallet{ID: 1, Status: domain.WalletStatusArchived},
+			ExpectedError:     exception.ErrWalletArchived,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:             "ReturnsError_CurrencyNotSupported",
+			GivenRequest:      dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "XYZ", Frequency: domain.FrequencyWeekly},
+			GivenWallet:       &domain.Wallet{ID: 1, Status: domain.WalletStatusActive},
+			ExpectedError:     exception.ErrCurrencyNotSupported,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:         "ReturnsError_CreateAutoSaveRule",
+			GivenRequest:  dto.CreateAutoSaveRuleRequest{WalletID: 1, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly},
+			GivenWallet:   &domain.Wallet{ID: 1, Status: domain.WalletStatusActive},
+			CreateError:   errors.New("insert failed"),
+			ExpectedError: errors.New("insert failed"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			autoSaveRepo := new(mocks.AutoSaveRepository)
+			walletRepo := new(mocks.WalletRepository)
+			autoSaveUsecase := usecase.NewAutoSaveUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), autoSaveRepo, walletRepo, new(mocks.TransactionRepository), new(usecaseMocks.WalletUsecase), newCurrencyRepository())
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, tc.GivenRequest.WalletID).Return(tc.GivenWallet, tc.GetWalletError)
+			autoSaveRepo.On("CreateAutoSaveRule", mock.Anything, mock.Anything).Return(tc.CreateError)
+
+			rule, err := autoSaveUsecase.CreateAutoSaveRule(context.Background(), 1, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedAmount, rule.Amount)
+				require.Equal(t, domain.AutoSaveStatusActive, rule.Status)
+				require.True(t, tc.ExpectedNextRunAt.Equal(rule.NextRunAt), "next run at %v, expected %v", rule.NextRunAt, tc.ExpectedNextRunAt)
+			} else {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				require.Nil(t, rule)
+			}
+			if tc.ExpectNotCreating {
+				autoSaveRepo.AssertNotCalled(t, "CreateAutoSaveRule", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAutoSaveUsecase_ResumeAutoSaveRule(t *testing.T) {
+	startAt := time.Now().Add(-30 * time.Minute)
+
+	testCases := []struct {
+		Title         string
+		GivenRule     *domain.AutoSaveRule
+		ExpectedError error
+	}{
+		{
+			Title:     "ReturnsSuccessfully",
+			GivenRule: &domain.AutoSaveRule{ID: 1, UserID: 1, Frequency: domain.FrequencyDaily, StartAt: startAt, Status: domain.AutoSaveStatusPaused, ConsecutiveFailures: 3},
+		},
+		{
+			Title:         "ReturnsError_AutoSaveRuleNotPaused",
+			GivenRule:     &domain.AutoSaveRule{ID: 1, UserID: 1, Frequency: domain.FrequencyDaily, StartAt: startAt, Status: domain.AutoSaveStatusActive},
+			ExpectedError: exception.ErrAutoSaveRuleNotPaused,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			autoSaveRepo := new(mocks.AutoSaveRepository)
+			autoSaveUsecase := usecase.NewAutoSaveUsecase(infrastructure.Config{}, txManager, autoSaveRepo, new(mocks.WalletRepository), new(mocks.TransactionRepository), new(usecaseMocks.WalletUsecase), newCurrencyRepository())
+
+			autoSaveRepo.On("GetAutoSaveRule", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenRule, nil)
+			autoSaveRepo.On("UpdateAutoSaveRule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			rule, err := autoSaveUsecase.ResumeAutoSaveRule(context.Background(), 1, 1)
+
+			if tc.ExpectedError == nil {
+				require.NoError(t, err)
+				require.Equal(t, domain.AutoSaveStatusActive, rule.Status)
+				require.Zero(t, rule.ConsecutiveFailures)
+				// missed runs are skipped, the rule picks up from its next daily run
+				require.True(t, startAt.AddDate(0, 0, 1).Equal(rule.NextRunAt))
+				autoSaveRepo.AssertCalled(t, "UpdateAutoSaveRule", mock.Anything, mock.Anything, *rule)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				autoSaveRepo.AssertNotCalled(t, "UpdateAutoSaveRule", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestAutoSaveUsecase_RunDueAutoSaveRules(t *testing.T) {
+	now := time.Date(2026, time.March, 2, 9, 0, 30, 0, time.UTC)
+	startAt := time.Date(2026, time.January, 5, 9, 0, 0, 0, time.UTC)
+	nextRunAt := time.Date(2026, time.March, 9, 9, 0, 0, 0, time.UTC)
+
+	scheduleRule := domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: now, Status: domain.AutoSaveStatusActive, CreatedAt: startAt}
+	roundUpRule := domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeRoundUp, Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: now, Status: domain.AutoSaveStatusActive, LastTransactionID: 4, CreatedAt: startAt}
+	withFailures := func(rule domain.AutoSaveRule, failures int) domain.AutoSaveRule {
+		rule.ConsecutiveFailures = failures
+		return rule
+	}
+
+	testCases := []struct {
+		Title             string
+		GivenRule         domain.AutoSaveRule
+		GivenTransactions []domain.Transaction
+		GetDueError       error
+		LockError         error
+		TopUpError        error
+		ExpectedTopUp     money.Amount
+		ExpectedRun       *domain.AutoSaveRun
+		ExpectedRule      domain.AutoSaveRule
+		ExpectedError     error
+	}{
+		{
+			Title:         "ReturnsSuccessfully_Schedule",
+			GivenRule:     withFailures(scheduleRule, 2),
+			ExpectedTopUp: money.FromInt(50),
+			ExpectedRun:   &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Amount: money.FromInt(50), Currency: "SGD", Status: domain.AutoSaveRunSucceeded},
+			ExpectedRule:  domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusActive, CreatedAt: startAt},
+		},
+		{
+			Title:     "ReturnsSuccessfully_RoundUp",
+			GivenRule: roundUpRule,
+			GivenTransactions: []domain.Transaction{
+				{ID: 5, SourceAmount: money.MustParse("12.30")},
+				{ID: 6, SourceAmount: money.MustParse("4.00")},
+				{ID: 8, SourceAmount: money.MustParse("7.99")},
+			},
+			ExpectedTopUp: money.MustParse("0.71"),
+			ExpectedRun:   &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Amount: money.MustParse("0.71"), Currency: "SGD", Status: domain.AutoSaveRunSucceeded},
+			ExpectedRule:  domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeRoundUp, Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusActive, LastTransactionID: 8, CreatedAt: startAt},
+		},
+		{
+			Title:             "ReturnsSuccessfully_RoundUpNothingToSave",
+			GivenRule:         roundUpRule,
+			GivenTransactions: []domain.Transaction{{ID: 9, SourceAmount: money.FromInt(5)}},
+			ExpectedRun:       &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Currency: "SGD", Status: domain.AutoSaveRunSkipped},
+			ExpectedRule:      domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeRoundUp, Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusActive, LastTransactionID: 9, CreatedAt: startAt},
+		},
+		{
+			Title:         "ReturnsSuccessfully_InsufficientFunds",
+			GivenRule:     withFailures(scheduleRule, 1),
+			TopUpError:    exception.ErrInsufficientFunds,
+			ExpectedTopUp: money.FromInt(50),
+			ExpectedRun:   &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Amount: money.FromInt(50), Currency: "SGD", Status: domain.AutoSaveRunFailed, FailureReason: constants.REASON_INSUFFICIENT_FUNDS},
+			ExpectedRule:  domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusActive, ConsecutiveFailures: 2, CreatedAt: startAt},
+		},
+		{
+			Title:         "ReturnsSuccessfully_PausedAfterRepeatedInsufficientFunds",
+			GivenRule:     withFailures(scheduleRule, 2),
+			TopUpError:    exception.ErrBalanceNotFound,
+			ExpectedTopUp: money.FromInt(50),
+			ExpectedRun:   &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Amount: money.FromInt(50), Currency: "SGD", Status: domain.AutoSaveRunFailed, FailureReason: constants.REASON_INSUFFICIENT_FUNDS},
+			ExpectedRule:  domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusPaused, ConsecutiveFailures: 3, CreatedAt: startAt},
+		},
+		{
+			Title:             "ReturnsSuccessfully_RoundUpInsufficientFundsKeepsTransfers",
+			GivenRule:         roundUpRule,
+			GivenTransactions: []domain.Transaction{{ID: 5, SourceAmount: money.MustParse("12.30")}},
+			TopUpError:        exception.ErrInsufficientFunds,
+			ExpectedTopUp:     money.MustParse("0.70"),
+			ExpectedRun:       &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Amount: money.MustParse("0.70"), Currency: "SGD", Status: domain.AutoSaveRunFailed, FailureReason: constants.REASON_INSUFFICIENT_FUNDS},
+			ExpectedRule:      domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeRoundUp, Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusActive, ConsecutiveFailures: 1, LastTransactionID: 4, CreatedAt: startAt},
+		},
+		{
+			Title:         "ReturnsSuccessfully_WalletArchived",
+			GivenRule:     withFailures(scheduleRule, 2),
+			TopUpError:    exception.ErrWalletArchived,
+			ExpectedTopUp: money.FromInt(50),
+			ExpectedRun:   &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Amount: money.FromInt(50), Currency: "SGD", Status: domain.AutoSaveRunFailed, FailureReason: constants.REASON_WALLET_ARCHIVED},
+			ExpectedRule:  domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusActive, ConsecutiveFailures: 2, CreatedAt: startAt},
+		},
+		{
+			Title:         "ReturnsSuccessfully_PausedWhenWalletClosed",
+			GivenRule:     scheduleRule,
+			TopUpError:    exception.ErrWalletClosed,
+			ExpectedTopUp: money.FromInt(50),
+			ExpectedRun:   &domain.AutoSaveRun{RuleID: 1, UserID: 1, WalletID: 2, Amount: money.FromInt(50), Currency: "SGD", Status: domain.AutoSaveRunFailed, FailureReason: constants.REASON_WALLET_CLOSED},
+			ExpectedRule:  domain.AutoSaveRule{ID: 1, UserID: 1, WalletID: 2, Type: domain.AutoSaveTypeSchedule, Amount: money.FromInt(50), Currency: "SGD", Frequency: domain.FrequencyWeekly, StartAt: startAt, NextRunAt: nextRunAt, Status: domain.AutoSaveStatusPaused, CreatedAt: startAt},
+		},
+		{
+			Title:     "ReturnsSuccessfully_RuleNoLongerDue",
+			GivenRule: scheduleRule,
+			LockError: exception.ErrAutoSaveRuleNotFound,
+		},
+		{
+			Title:         "ReturnsError_GetDueAutoSaveRules",
+			GivenRule:     scheduleRule,
+			GetDueError:   errors.New("select failed"),
+			ExpectedError: errors.New("select failed"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			autoSaveRepo := new(mocks.AutoSaveRepository)
+			transactionRepo := new(mocks.TransactionRepository)
+			walletUsecase := new(usecaseMocks.WalletUsecase)
+			autoSaveUsecase := usecase.NewAutoSaveUsecase(infrastructure.Config{}, txManager, autoSaveRepo, new(mocks.WalletRepository), transactionRepo, walletUsecase, newCurrencyRepository())
+
+			lockedRule := tc.GivenRule
+			autoSaveRepo.On("GetDueAutoSaveRules", mock.Anything, now, constants.DEFAULT_AUTO_SAVE_BATCH_SIZE).Return([]domain.AutoSaveRule{tc.GivenRule}, tc.GetDueError)
+			if tc.LockError != nil {
+				autoSaveRepo.On("LockDueAutoSaveRule", mock.Anything, mock.Anything, tc.GivenRule.ID, now).Return(nil, tc.LockError)
+			} else {
+				autoSaveRepo.On("LockDueAutoSaveRule", mock.Anything, mock.Anything, tc.GivenRule.ID, now).Return(&lockedRule, nil)
+			}
+			transactionRepo.On("GetSentTransactions", mock.Anything, 1, "SGD", tc.GivenRule.LastTransactionID, startAt).Return(tc.GivenTransactions, nil)
+			walletUsecase.On("TopUpWallet", mock.Anything, 1, 2, mock.Anything).Return(tc.TopUpError)
+			autoSaveRepo.On("CreateAutoSaveRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			autoSaveRepo.On("UpdateAutoSaveRule", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			err := autoSaveUsecase.RunDueAutoSaveRules(context.Background(), now)
+
+			if tc.ExpectedError != nil {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				autoSaveRepo.AssertNotCalled(t, "LockDueAutoSaveRule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+
+			if tc.ExpectedRun == nil {
+				walletUsecase.AssertNotCalled(t, "TopUpWallet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				autoSaveRepo.AssertNotCalled(t, "CreateAutoSaveRun", mock.Anything, mock.Anything, mock.Anything)
+				autoSaveRepo.AssertNotCalled(t, "UpdateAutoSaveRule", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			if tc.ExpectedTopUp.IsPositive() {
+				walletUsecase.AssertCalled(t, "TopUpWallet", mock.Anything, 1, 2, dto.UpdateWalletRequest{
+					CurrencyAmount: []dto.CurrencyAmount{{Amount: tc.ExpectedTopUp, Currency: "SGD"}},
+				})
+			} else {
+				walletUsecase.AssertNotCalled(t, "TopUpWallet", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			autoSaveRepo.AssertCalled(t, "CreateAutoSaveRun", mock.Anything, mock.Anything, tc.ExpectedRun)
+			autoSaveRepo.AssertCalled(t, "UpdateAutoSaveRule", mock.Anything, mock.Anything, tc.ExpectedRule)
+		})
+	}
+}
@@ -2,32 +2,63 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"log"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
 	"github.com/LeonLow97/go-clean-architecture/utils"
 	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
 	"github.com/jmoiron/sqlx"
 )
 
 type balanceUsecase struct {
-	dbConn            *sqlx.DB
-	userRepository    domain.UserRepository
-	balanceRepository domain.BalanceRepository
+	txManager              domain.TxManager
+	userRepository         domain.UserRepository
+	balanceRepository      domain.BalanceRepository
+	ledgerRepository       domain.LedgerRepository
+	exchangeRateProvider   domain.ExchangeRateProvider
+	fxConversionRepository domain.FXConversionRepository
+	exchangeQuotes         exchangeQuotes
+	currencies             currencyCatalogue
+	fees                   feeSchedules
+	baseCurrency           string
 }
 
-func NewBalanceUsecase(dbConn *sqlx.DB, userRepository domain.UserRepository, balanceRepository domain.BalanceRepository) domain.BalanceUsecase {
+func NewBalanceUsecase(cfg infrastructure.Config, txManager domain.TxManager, userRepository domain.UserRepository, balanceRepository domain.BalanceRepository, ledgerRepository domain.LedgerRepository, exchangeRateProvider domain.ExchangeRateProvider, exchangeQuoteRepository domain.ExchangeQuoteRepository, currencyRepository domain.CurrencyRepository, fxConversionRepository domain.FXConversionRepository, feeRepository domain.FeeRepository) domain.BalanceUsecase {
+	baseCurrency := cfg.ExchangeRate.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = constants.DEFAULT_EXCHANGE_BASE_CURRENCY
+	}
+
 	return &balanceUsecase{
-		dbConn:            dbConn,
-		userRepository:    userRepository,
-		balanceRepository: balanceRepository,
+		txManager:              txManager,
+		userRepository:         userRepository,
+		balanceRepository:      balanceRepository,
+		ledgerRepository:       ledgerRepository,
+		exchangeRateProvider:   exchangeRateProvider,
+		fxConversionRepository: fxConversionRepository,
+		exchangeQuotes:         newExchangeQuotes(cfg, exchangeQuoteRepository),
+		currencies:             currencyCatalogue{repository: currencyRepository},
+		fees:                   feeSchedules{repository: feeRepository},
+		baseCurrency:           baseCurrency,
 	}
 }
 
-func (uc *balanceUsecase) GetBalanceHistory(ctx context.Context, userID int, balanceID int) (*dto.GetBalanceHistory, error) {
-	balanceHistory, err := uc.balanceRepository.GetBalanceHistory(ctx, userID, balanceID)
+func (uc *balanceUsecase) GetBalanceHistory(ctx context.Context, userID int, balanceID int, paginator *pagination.Paginator) (*dto.GetBalanceHistory, error) {
+	// keyset pages are not counted, that is what keeps them fast on a long history
+	if !paginator.Keyset() {
+		if err := uc.balanceRepository.GetBalanceHistoryCount(ctx, userID, balanceID, paginator); err != nil {
+			log.Printf("failed to get balance history count for user id: %d, balance id: %d with error: %v\n", userID, balanceID, err)
+			return nil, err
+		}
+	}
+
+	balanceHistory, err := uc.balanceRepository.GetBalanceHistory(ctx, userID, balanceID, paginator)
 	if err != nil {
 		log.Printf("failed to get balance history for user id: %d, balance id: %d with error: %v\n", userID, balanceID, err)
 		return nil, err
@@ -59,47 +90,32 @@ func (uc *balanceUsecase) GetBalance(ctx context.Context, userID int, balanceID
 }
 
 func (uc *balanceUsecase) GetBalances(ctx context.Context, userID int) (*dto.GetBalancesResponse, error) {
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
-		return nil, err
-	}
+	// lock balances in case use POSTMAN and frontend to update balance at the same time
+	var resp dto.GetBalancesResponse
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		balances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+		if err != nil {
+			log.Printf("failed to get balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
+		for _, b := range balances {
+			balance := dto.GetBalanceResponse{
+				ID:        b.ID,
+				Balance:   b.Balance,
+				Currency:  b.Currency,
+				CreatedAt: b.CreatedAt,
+				UpdatedAt: b.UpdatedAt,
 			}
+			resp.Balances = append(resp.Balances, balance)
 		}
-	}()
 
-	balances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+		return nil
+	})
 	if err != nil {
-		log.Printf("failed to get balances for user id %d with error: %v\n", userID, err)
 		return nil, err
 	}
 
-	var resp dto.GetBalancesResponse
-	for _, b := range balances {
-		balance := dto.GetBalanceResponse{
-			ID:        b.ID,
-			Balance:   b.Balance,
-			Currency:  b.Currency,
-			CreatedAt: b.CreatedAt,
-			UpdatedAt: b.UpdatedAt,
-		}
-		resp.Balances = append(resp.Balances, balance)
-	}
-
 	return &resp, nil
 }
 
@@ -118,79 +134,66 @@ func (uc *balanceUsecase) Deposit(ctx context.Context, req dto.DepositRequest) e
 	// to retrieve the deposited amount. For the purpose of this project, we assume
 	// a successful retrieval, and req.Balance represents the received amount.
 
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
-		return err
-	}
-
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
-			}
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// retrieve user mobile country code (assumption: to determine the currency the user can deposit)
+		user, err := uc.userRepository.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			log.Printf("failed to get user with error: %v\n", err)
+			return err
+		}
+		homeCurrency, err := uc.currencies.homeCurrency(ctx, user.MobileCountryCode)
+		if err != nil {
+			log.Printf("failed to get home currency of mobile country code %s with error: %v\n", user.MobileCountryCode, err)
+			return err
+		}
+		if homeCurrency.Code != req.Currency {
+			return exception.ErrDepositCurrencyNotAllowed
 		}
-	}()
 
-	// retrieve user mobile country code (assumption: to determine the currency the user can deposit)
-	user, err := uc.userRepository.GetUserByID(ctx, req.UserID)
-	if err != nil {
-		log.Printf("failed to get user with error: %v\n", err)
-		return err
-	}
-	if constants.CountryCodeToCurrencyMap[user.MobileCountryCode] != req.Currency {
-		err = exception.ErrDepositCurrencyNotAllowed
-		return err
-	}
+		currentBalance, err := uc.balanceRepository.GetBalance(ctx, tx, req.UserID, req.Currency)
+		if err != nil {
+			log.Printf("failed to get one balance for user id %d with error: %v\n", req.UserID, err)
+			return err
+		}
 
-	currentBalance, err := uc.balanceRepository.GetBalance(ctx, tx, req.UserID, req.Currency)
-	if err != nil {
-		log.Printf("failed to get one balance for user id %d with error: %v\n", req.UserID, err)
-		return err
-	}
+		var updatedBalance *domain.Balance
 
-	var updatedBalance *domain.Balance
+		// Update the balance if it exists
+		if currentBalance != nil {
+			currentBalance.Balance += req.Balance
 
-	// Update the balance if it exists
-	if currentBalance != nil {
-		currentBalance.Balance += req.Balance
+			if err = uc.balanceRepository.UpdateBalance(ctx, tx, currentBalance); err != nil {
+				return err
+			}
+			updatedBalance = currentBalance
+		} else {
+			// Create a new balance if it does not exist
+			updatedBalance = &domain.Balance{
+				Balance:  req.Balance,
+				Currency: req.Currency,
+				UserID:   req.UserID,
+			}
+			// user does not have this balance, insert the balance
+			if err = uc.balanceRepository.CreateBalance(ctx, tx, updatedBalance); err != nil {
+				return err
+			}
+		}
 
-		if err = uc.balanceRepository.UpdateBalance(ctx, tx, currentBalance); err != nil {
+		// money enters the platform from the user's card
+		entry := &domain.JournalEntry{Type: domain.JournalDeposit}
+		entry.Transfer(domain.AccountExternal, domain.UserBalanceAccount(req.UserID), req.Currency, req.Balance)
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for deposit for user id %d with error: %v\n", req.UserID, err)
 			return err
 		}
-		updatedBalance = currentBalance
-	} else {
-		// Create a new balance if it does not exist
-		updatedBalance = &domain.Balance{
-			Balance:  req.Balance,
-			Currency: req.Currency,
-			UserID:   req.UserID,
-		}
-		// user does not have this balance, insert the balance
-		if err = uc.balanceRepository.CreateBalance(ctx, tx, updatedBalance); err != nil {
+
+		if err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, updatedBalance, req.Balance, "deposit"); err != nil {
+			log.Printf("failed to create balance history with error: %v\n", err)
 			return err
 		}
-	}
-
-	defer func() {
-		err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, updatedBalance, req.Balance, "deposit")
-	}()
-
-	if err != nil {
-		log.Printf("failed to create balance history with error: %v\n", err)
-		return err
-	}
 
-	return nil
+		return nil
+	})
 }
 
 func (uc *balanceUsecase) Withdraw(ctx context.Context, req dto.WithdrawRequest) error {
@@ -200,213 +203,285 @@ func (uc *balanceUsecase) Withdraw(ctx context.Context, req dto.WithdrawRequest)
 	// receive a success message from the credit card API. Subsequently,
 	// update the user's balance via Apache Kafka to mitigate potential failures.
 
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
-		return err
-	}
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// retrieve user mobile country code (assumption: to determine the currency the user can deposit)
+		user, err := uc.userRepository.GetUserByID(ctx, req.UserID)
+		if err != nil {
+			log.Printf("failed to get user with error: %v\n", err)
+			return err
+		}
+		homeCurrency, err := uc.currencies.homeCurrency(ctx, user.MobileCountryCode)
+		if err != nil {
+			log.Printf("failed to get home currency of mobile country code %s with error: %v\n", user.MobileCountryCode, err)
+			return err
+		}
+		if homeCurrency.Code != req.Currency {
+			return exception.ErrWithdrawCurrencyNotAllowed
+		}
 
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
-			}
+		currentBalance, err := uc.balanceRepository.GetBalance(ctx, tx, req.UserID, req.Currency)
+		if err != nil {
+			log.Printf("failed to get one balance for user id %d with error: %v\n", req.UserID, err)
+			return err
 		}
-	}()
 
-	// retrieve user mobile country code (assumption: to determine the currency the user can deposit)
-	user, err := uc.userRepository.GetUserByID(ctx, req.UserID)
-	if err != nil {
-		log.Printf("failed to get user with error: %v\n", err)
-		return err
-	}
-	if constants.CountryCodeToCurrencyMap[user.MobileCountryCode] != req.Currency {
-		err = exception.ErrWithdrawCurrencyNotAllowed
-		return err
-	}
+		if req.Balance > currentBalance.Balance {
+			return exception.ErrInsufficientFunds
+		}
 
-	currentBalance, err := uc.balanceRepository.GetBalance(ctx, tx, req.UserID, req.Currency)
-	if err != nil {
-		log.Printf("failed to get one balance for user id %d with error: %v\n", req.UserID, err)
-		return err
-	}
+		// the withdrawal fee comes out of the amount withdrawn
+		feeRule, err := uc.fees.rule(ctx, domain.FeeProductWithdrawal, req.Currency, req.Currency)
+		if err != nil {
+			log.Printf("failed to get withdrawal fee rule for %s with error: %v\n", req.Currency, err)
+			return err
+		}
+		fees, err := utils.CalculateFees(req.Balance, req.Currency, 0, feeRule)
+		if err != nil {
+			return err
+		}
+		fee := domain.TotalFees(fees)
 
-	if req.Balance > currentBalance.Balance {
-		return exception.ErrInsufficientFunds
-	}
+		if currentBalance != nil {
+			currentBalance.Balance -= req.Balance
+			if err = uc.balanceRepository.UpdateBalance(ctx, tx, currentBalance); err != nil {
+				return err
+			}
+		} else {
+			return exception.ErrBalanceNotFound
+		}
 
-	if currentBalance != nil {
-		currentBalance.Balance -= req.Balance
-		if err = uc.balanceRepository.UpdateBalance(ctx, tx, currentBalance); err != nil {
+		// money leaves the platform back to the user's card, less the fee kept as revenue
+		entry := &domain.JournalEntry{Type: domain.JournalWithdraw}
+		entry.Transfer(domain.UserBalanceAccount(req.UserID), domain.AccountExternal, req.Currency, req.Balance-fee).
+			Transfer(domain.UserBalanceAccount(req.UserID), domain.AccountRevenue, req.Currency, fee)
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for withdrawal for user id %d with error: %v\n", req.UserID, err)
 			return err
 		}
-	} else {
-		err = exception.ErrBalanceNotFound
-		return err
-	}
 
-	defer func() {
-		err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, currentBalance, req.Balance, "withdraw")
-	}()
+		if err = uc.fees.charge(ctx, tx, fees, entry.ID, 0, req.UserID); err != nil {
+			log.Printf("failed to record withdrawal fees for user id %d with error: %v\n", req.UserID, err)
+			return err
+		}
 
-	if err != nil {
-		log.Printf("failed to create balance history with error: %v\n", err)
-		return err
-	}
+		if err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, currentBalance, req.Balance, "withdraw"); err != nil {
+			log.Printf("failed to create balance history with error: %v\n", err)
+			return err
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (uc *balanceUsecase) CurrencyExchange(ctx context.Context, userID int, req dto.CurrencyExchangeRequest) error {
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
-		return err
+	var quote *domain.ExchangeQuote
+	if req.QuoteID != "" {
+		var err error
+		if quote, err = uc.exchangeQuotes.claim(ctx, userID, req.QuoteID); err != nil {
+			log.Printf("failed to claim exchange quote for user id %d with error: %v\n", userID, err)
+			return err
+		}
 	}
 
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		user, err := uc.userRepository.GetUserByID(ctx, userID)
+		if err != nil {
+			log.Printf("failed to get user with error: %v\n", err)
+			return err
+		}
+
+		homeCurrency, err := uc.currencies.homeCurrency(ctx, user.MobileCountryCode)
+		if err != nil {
+			log.Printf("failed to get home currency of mobile country code %s with error: %v\n", user.MobileCountryCode, err)
+			return err
+		}
+		fromCurrency := homeCurrency.Code
+
+		// check if toCurrency is in the currency catalogue and enabled
+		if _, err := uc.currencies.currency(ctx, req.ToCurrency); err != nil {
+			log.Printf("toCurrency %s is not allowed for exchange with error: %v\n", req.ToCurrency, err)
+			if errors.Is(err, exception.ErrCurrencyNotSupported) {
+				return exception.ErrToCurrencyNotAllowed
+			}
+			return err
+		}
+
+		// check if toCurrency is same as fromCurrency
+		if fromCurrency == req.ToCurrency {
+			log.Printf("fromCurrency %s is equal to toCurrency %s\n", fromCurrency, req.ToCurrency)
+			return exception.ErrFromCurrencyEqualToCurrency
+		}
+
+		// retrieve converted amount and fees, at the rate and fees locked in by the preview when there is a quote
+		var fees []domain.FeeCharge
+		var convertedAmount money.Amount
+		var conversion *domain.FXConversion
+		if quote != nil {
+			if quote.Product != domain.FeeProductExchange || quote.FromCurrency != fromCurrency || quote.ToCurrency != req.ToCurrency || quote.FromAmount != req.FromAmount {
+				return exception.ErrExchangeQuoteMismatch
+			}
+			fees, convertedAmount = quote.Fees, quote.ToAmount
+			conversion = domain.NewFXConversion(userID, quote.ExchangeRate(), domain.FXRateSourceQuote, req.FromAmount, convertedAmount, quote.Profit)
 		} else {
-			err = tx.Commit()
+			exchangeRate, err := utils.ResolveExchangeRate(ctx, uc.exchangeRateProvider, uc.baseCurrency, fromCurrency, req.ToCurrency)
+			if err != nil {
+				log.Printf("failed to get exchange rate from %s to %s with error: %v\n", fromCurrency, req.ToCurrency, err)
+				return err
+			}
+			feeRule, err := uc.fees.rule(ctx, domain.FeeProductExchange, fromCurrency, req.ToCurrency)
 			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
+				log.Printf("failed to get exchange fee rule from %s to %s with error: %v\n", fromCurrency, req.ToCurrency, err)
+				return err
 			}
+			fees, convertedAmount, err = utils.CalculateConversionDetails(req.FromAmount, *exchangeRate, feeRule)
+			if err != nil {
+				return err
+			}
+			conversion = domain.NewFXConversion(userID, *exchangeRate, exchangeRate.RateSource(), req.FromAmount, convertedAmount, domain.TotalFees(fees))
 		}
-	}()
+		profit := domain.TotalFees(fees)
 
-	user, err := uc.userRepository.GetUserByID(ctx, userID)
-	if err != nil {
-		log.Printf("failed to get user with error: %v\n", err)
-		return err
-	}
+		// retrieve main balance and check if sufficient funds
+		allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+		if err != nil {
+			log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	fromCurrency := constants.CountryCodeToCurrencyMap[user.MobileCountryCode]
+		// convert slice of user balances into map for faster performance of accessing keys in map
+		allBalancesMap := make(map[string]money.Amount)
+		var fromBalanceID, toBalanceID int
+		for _, b := range allBalances {
+			allBalancesMap[b.Currency] = b.Balance
 
-	// TODO: check if toCurrencies is allowed with a list of currencies stored in the database, for now use a map
-	if _, found := constants.ToCurrencies[req.ToCurrency]; !found {
-		log.Printf("toCurrency %s is not under the list of allowable currencies for exchange\n", req.ToCurrency)
-		return exception.ErrToCurrencyNotAllowed
-	}
+			if b.Currency == fromCurrency {
+				fromBalanceID = b.ID
+			}
+			if b.Currency == req.ToCurrency {
+				toBalanceID = b.ID
+			}
+		}
 
-	// check if toCurrency is same as fromCurrency
-	if fromCurrency == req.ToCurrency {
-		log.Printf("fromCurrency %s is equal to toCurrency %s\n", fromCurrency, req.ToCurrency)
-		return exception.ErrFromCurrencyEqualToCurrency
-	}
+		// check if user has sufficient primary balance to perform currency exchange
+		if req.FromAmount > allBalancesMap[fromCurrency] {
+			log.Printf("user %d has insufficient balance to perform currency exchange", userID)
+			return exception.ErrInsufficientFundsForCurrencyExchange
+		}
 
-	// retrieve converted amount and profit
-	profit, convertedAmount := utils.CalculateConversionDetails(req.FromAmount, fromCurrency, req.ToCurrency)
+		finalBalancesMap := make(map[string]money.Amount)
+		// add into finalBalancesMap on the fromAmount and toAmount
+		finalBalancesMap[fromCurrency] = allBalancesMap[fromCurrency] - req.FromAmount
 
-	if err = uc.balanceRepository.LogCreatorProfit(ctx, tx, profit, fromCurrency); err != nil {
-		log.Printf("failed to log creator profit with error %v\n", err)
-		return err
-	}
+		// check if user has existing toCurrency balance
+		if currentValue, found := allBalancesMap[req.ToCurrency]; found {
+			finalBalancesMap[req.ToCurrency] = currentValue + convertedAmount
+		} else {
+			finalBalancesMap[req.ToCurrency] = convertedAmount
+		}
 
-	// retrieve main balance and check if sufficient funds
-	allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
-	if err != nil {
-		log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		// update user balances
+		if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
+			log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// convert slice of user balances into map for faster performance of accessing keys in map
-	allBalancesMap := make(map[string]float64)
-	var fromBalanceID, toBalanceID int
-	for _, b := range allBalances {
-		allBalancesMap[b.Currency] = b.Balance
+		// record the exchange in the ledger, the fees stay in fromCurrency as revenue
+		entry := &domain.JournalEntry{Type: domain.JournalExchange}
+		entry.Exchange(domain.UserBalanceAccount(userID), domain.UserBalanceAccount(userID), fromCurrency, req.ToCurrency, req.FromAmount, profit, convertedAmount)
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for currency exchange for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-		if b.Currency == fromCurrency {
-			fromBalanceID = b.ID
+		// keep the rate that was applied for auditing
+		conversion.JournalEntryID = entry.ID
+		if err = uc.fxConversionRepository.CreateFXConversion(ctx, tx, conversion); err != nil {
+			log.Printf("failed to record fx conversion for currency exchange for user id %d with error: %v\n", userID, err)
+			return err
 		}
-		if b.Currency == req.ToCurrency {
-			toBalanceID = b.ID
+
+		if err = uc.fees.charge(ctx, tx, fees, entry.ID, 0, userID); err != nil {
+			log.Printf("failed to record currency exchange fees for user id %d with error: %v\n", userID, err)
+			return err
 		}
-	}
 
-	// check if user has sufficient primary balance to perform currency exchange
-	if req.FromAmount > allBalancesMap[fromCurrency] {
-		log.Printf("user %d has insufficient balance to perform currency exchange", userID)
-		err = exception.ErrInsufficientFundsForCurrencyExchange
-		return err
-	}
+		// create balance history for 'from' side
+		transferFromBalanceHistory := &domain.Balance{
+			ID:       fromBalanceID,
+			Currency: fromCurrency,
+			UserID:   userID,
+		}
+		if err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, transferFromBalanceHistory, req.FromAmount, "exchange"); err != nil {
+			log.Printf("failed to create balance history for 'transferFrom' for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	finalBalancesMap := make(map[string]float64)
-	// add into finalBalancesMap on the fromAmount and toAmount
-	finalBalancesMap[fromCurrency] = allBalancesMap[fromCurrency] - req.FromAmount
+		// create balance history for 'to' side
+		transferToBalanceHistory := &domain.Balance{
+			ID:       toBalanceID,
+			Currency: req.ToCurrency,
+			UserID:   userID,
+		}
+		if err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, transferToBalanceHistory, convertedAmount, "exchange"); err != nil {
+			log.Printf("failed to create balance history for 'transferTo' for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// check if user has existing toCurrency balance
-	if currentValue, found := allBalancesMap[req.ToCurrency]; found {
-		finalBalancesMap[req.ToCurrency] = currentValue + convertedAmount
-	} else {
-		finalBalancesMap[req.ToCurrency] = convertedAmount
+		return nil
+	})
+	if err != nil && quote != nil {
+		// nothing was exchanged, the user can still use the quote until it expires
+		uc.exchangeQuotes.release(ctx, quote.ID)
 	}
 
-	// update user balances
-	if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
-		log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
-		return err
-	}
+	return err
+}
 
-	// create balance history for 'from' side
-	transferFromBalanceHistory := &domain.Balance{
-		ID:       fromBalanceID,
-		Currency: fromCurrency,
-		UserID:   userID,
+func (uc *balanceUsecase) PreviewExchange(ctx context.Context, userID int, req dto.PreviewExchangeRequest) (dto.PreviewExchangeResponse, error) {
+	exchangeRate, err := utils.ResolveExchangeRate(ctx, uc.exchangeRateProvider, uc.baseCurrency, req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		log.Printf("failed to get exchange rate from %s to %s with error: %v\n", req.FromCurrency, req.ToCurrency, err)
+		return dto.PreviewExchangeResponse{}, err
 	}
-	if err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, transferFromBalanceHistory, req.FromAmount, "exchange"); err != nil {
-		log.Printf("failed to create balance history for 'transferFrom' for user id %d with error: %v\n", userID, err)
-		return err
+
+	feeRule, err := uc.fees.rule(ctx, req.Product, req.FromCurrency, req.ToCurrency)
+	if err != nil {
+		log.Printf("failed to get %s fee rule from %s to %s with error: %v\n", req.Product, req.FromCurrency, req.ToCurrency, err)
+		return dto.PreviewExchangeResponse{}, err
 	}
 
-	// create balance history for 'to' side
-	transferToBalanceHistory := &domain.Balance{
-		ID:       toBalanceID,
-		Currency: req.ToCurrency,
-		UserID:   userID,
+	fromAmount := req.FromAmount
+	// action type is "Amount to Receive", work out what has to be sent
+	if req.ActionType == "amountToReceive" {
+		if fromAmount, err = utils.CalculateFromAmount(req.ToAmount, *exchangeRate, feeRule); err != nil {
+			return dto.PreviewExchangeResponse{}, err
+		}
 	}
-	if err = uc.balanceRepository.CreateBalanceHistory(ctx, tx, transferToBalanceHistory, convertedAmount, "exchange"); err != nil {
-		log.Printf("failed to create balance history for 'transferTo' for user id %d with error: %v\n", userID, err)
-		return err
+	fees, toAmount, err := utils.CalculateConversionDetails(fromAmount, *exchangeRate, feeRule)
+	if err != nil {
+		return dto.PreviewExchangeResponse{}, err
 	}
 
-	return nil
-}
+	// lock the rate, fees and amounts so that the user is charged what they are shown here
+	quote, err := uc.exchangeQuotes.issue(ctx, userID, req.Product, *exchangeRate, fromAmount, toAmount, fees)
+	if err != nil {
+		log.Printf("failed to create exchange quote for user id %d with error: %v\n", userID, err)
+		return dto.PreviewExchangeResponse{}, err
+	}
 
-func (uc *balanceUsecase) PreviewExchange(ctx context.Context, req dto.PreviewExchangeRequest) dto.PreviewExchangeResponse {
 	resp := dto.PreviewExchangeResponse{
 		ActionType:   req.ActionType,
-		FromCurrency: req.FromCurrency,
-		ToCurrency:   req.ToCurrency,
-	}
-
-	// action type is "Amount to Send"
-	if req.ActionType == "amountToSend" {
-		_, receivedAmount := utils.CalculateConversionDetails(req.FromAmount, req.FromCurrency, req.ToCurrency)
-		resp.FromAmount = req.FromAmount
-		resp.ToAmount = receivedAmount
+		FromAmount:   quote.FromAmount,
+		FromCurrency: quote.FromCurrency,
+		ToAmount:     quote.ToAmount,
+		ToCurrency:   quote.ToCurrency,
+		QuoteID:      quote.ID,
+		Fee:          quote.Profit,
+		Rate:         quote.Rate,
+		Spread:       quote.Spread,
+		Path:         quote.Path,
+		ExpiresAt:    quote.ExpiresAt,
 	}
 
-	if req.ActionType == "amountToReceive" {
-		sendAmount := utils.CalculateFromAmount(req.ToAmount, req.ToCurrency, req.FromCurrency)
-		resp.ToAmount = req.ToAmount
-		resp.FromAmount = sendAmount
-	}
-
-	return resp
+	return resp, nil
 }
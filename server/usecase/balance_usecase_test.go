@@ -0,0 +1,269 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceUsecase_PreviewExchange(t *testing.T) {
+	testCases := []struct {
+		Title              string
+		GivenRequest       dto.PreviewExchangeRequest
+		ExpectedFromAmount money.Amount
+		ExpectedToAmount   money.Amount
+		ExpectedError      error
+	}{
+		{
+			Title: "ReturnsSuccessfully_AmountToSend",
+			GivenRequest: dto.PreviewExchangeRequest{
+				ActionType:   "amountToSend",
+				FromAmount:   money.MustParse("100.00"),
+				FromCurrency: "SGD",
+				ToCurrency:   "USD",
+				Product:      domain.FeeProductExchange,
+			},
+			ExpectedFromAmount: money.MustParse("100.00"),
+			ExpectedToAmount:   money.MustParse("75.99"),
+		},
+		{
+			Title: "ReturnsSuccessfully_AmountToReceive",
+			GivenRequest: dto.PreviewExchangeRequest{
+				ActionType:   "amountToReceive",
+				ToAmount:     money.MustParse("75.99"),
+				FromCurrency: "SGD",
+				ToCurrency:   "USD",
+				Product:      domain.FeeProductExchange,
+			},
+			ExpectedFromAmount: money.MustParse("100.00"),
+			ExpectedToAmount:   money.MustParse("75.99"),
+		},
+		{
+			Title: "ReturnsError_ExchangeRateNotFound",
+			GivenRequest: dto.PreviewExchangeRequest{
+				ActionType:   "amountToSend",
+				FromAmount:   money.MustParse("100.00"),
+				FromCurrency: "SGD",
+				ToCurrency:   "XYZ",
+				Product:      domain.FeeProductExchange,
+			},
+			ExpectedError: exception.ErrExchangeRateNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			exchangeQuoteRepo := new(mocks.ExchangeQuoteRepository)
+			balanceUsecase := usecase.NewBalanceUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), new(mocks.UserRepository), new(mocks.BalanceRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), exchangeQuoteRepo, newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			var quote domain.ExchangeQuote
+			exchangeQuoteRepo.On("CreateExchangeQuote", mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { quote = args.Get(1).(domain.ExchangeQuote) }).
+				Return(nil)
+
+			resp, err := balanceUsecase.PreviewExchange(context.Background(), 1, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedFromAmount, resp.FromAmount)
+				require.Equal(t, tc.ExpectedToAmount, resp.ToAmount)
+
+				// the quote locks exactly what the user was shown
+				require.Equal(t, quote.ID, resp.QuoteID)
+				require.Equal(t, 1, quote.UserID)
+				require.Equal(t, resp.FromAmount, quote.FromAmount)
+				require.Equal(t, resp.ToAmount, quote.ToAmount)
+				require.Equal(t, money.MustParseRate("0.76"), quote.Rate)
+				require.Equal(t, money.MustParse("0.01"), quote.Profit)
+				require.Equal(t, quote.Profit, resp.Fee)
+				require.Equal(t, domain.FeeProductExchange, quote.Product)
+				require.Len(t, quote.Fees, 1)
+				require.Equal(t, resp.ExpiresAt, quote.ExpiresAt)
+				require.WithinDuration(t, time.Now().Add(constants.DEFAULT_EXCHANGE_QUOTE_TTL), quote.ExpiresAt, 5*time.Second)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				exchangeQuoteRepo.AssertNotCalled(t, "CreateExchangeQuote", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestBalanceUsecase_CurrencyExchange_WithQuote(t *testing.T) {
+	const userID = 1
+
+	testCases := []struct {
+		Title             string
+		GivenUserID       int
+		GivenFromAmount   money.Amount
+		TamperQuoteID     bool
+		GetQuoteError     error
+		ClaimQuoteError   error
+		ExpectQuoteClaim  bool
+		ExpectedReleased  bool
+		ExpectedToBalance money.Amount
+		ExpectedError     error
+	}{
+		{
+			Title:             "ReturnsSuccessfully",
+			GivenUserID:       userID,
+			GivenFromAmount:   money.MustParse("100.00"),
+			ExpectQuoteClaim:  true,
+			ExpectedToBalance: money.MustParse("175.99"),
+		},
+		{
+			Title:           "ReturnsError_ExchangeQuoteInvalid",
+			GivenUserID:     userID,
+			GivenFromAmount: money.MustParse("100.00"),
+			TamperQuoteID:   true,
+			ExpectedError:   exception.ErrExchangeQuoteInvalid,
+		},
+		{
+			Title:           "ReturnsError_ExchangeQuoteInvalid_IssuedToAnotherUser",
+			GivenUserID:     userID + 1,
+			GivenFromAmount: money.MustParse("100.00"),
+			ExpectedError:   exception.ErrExchangeQuoteInvalid,
+		},
+		{
+			Title:           "ReturnsError_ExchangeQuoteExpired",
+			GivenUserID:     userID,
+			GivenFromAmount: money.MustParse("100.00"),
+			GetQuoteError:   exception.ErrExchangeQuoteExpired,
+			ExpectedError:   exception.ErrExchangeQuoteExpired,
+		},
+		{
+			Title:            "ReturnsError_ExchangeQuoteUsed",
+			GivenUserID:      userID,
+			GivenFromAmount:  money.MustParse("100.00"),
+			ClaimQuoteError:  exception.ErrExchangeQuoteUsed,
+			ExpectQuoteClaim: true,
+			ExpectedError:    exception.ErrExchangeQuoteUsed,
+		},
+		{
+			Title:            "ReturnsError_ExchangeQuoteMismatch",
+			GivenUserID:      userID,
+			GivenFromAmount:  money.MustParse("90.00"),
+			ExpectQuoteClaim: true,
+			ExpectedReleased: true,
+			ExpectedError:    exception.ErrExchangeQuoteMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			userRepo := new(mocks.UserRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+			exchangeQuoteRepo := new(mocks.ExchangeQuoteRepository)
+
+			// quote the rate first, then change it so that only a locked rate gives the expected amounts
+			rates := testdata.MockExchangeRates()
+			previewUsecase := usecase.NewBalanceUsecase(infrastructure.Config{}, txManager, userRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(rates), exchangeQuoteRepo, newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			var quote domain.ExchangeQuote
+			exchangeQuoteRepo.On("CreateExchangeQuote", mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { quote = args.Get(1).(domain.ExchangeQuote) }).
+				Return(nil)
+			_, err := previewUsecase.PreviewExchange(context.Background(), userID, dto.PreviewExchangeRequest{
+				ActionType:   "amountToSend",
+				FromAmount:   money.MustParse("100.00"),
+				FromCurrency: "SGD",
+				ToCurrency:   "USD",
+				Product:      domain.FeeProductExchange,
+			})
+			require.NoError(t, err)
+
+			for i := range rates {
+				rates[i].Rate = money.MustParseRate("0.50")
+			}
+			fxConversionRepo := newFXConversionRepository()
+			feeRepo := newFeeRepository()
+			balanceUsecase := usecase.NewBalanceUsecase(infrastructure.Config{}, txManager, userRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(rates), exchangeQuoteRepo, newCurrencyRepository(), fxConversionRepo, feeRepo)
+
+			quoteID := quote.ID
+			if tc.TamperQuoteID {
+				quoteID += "A"
+			}
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			if tc.GetQuoteError != nil {
+				exchangeQuoteRepo.On("GetExchangeQuote", mock.Anything, quote.ID).Return(nil, tc.GetQuoteError)
+			} else {
+				exchangeQuoteRepo.On("GetExchangeQuote", mock.Anything, quote.ID).Return(&quote, nil)
+			}
+			exchangeQuoteRepo.On("ClaimExchangeQuote", mock.Anything, quote.ID, mock.Anything).Return(tc.ClaimQuoteError)
+			exchangeQuoteRepo.On("ReleaseExchangeQuote", mock.Anything, quote.ID).Return(nil)
+
+			userRepo.On("GetUserByID", mock.Anything, tc.GivenUserID).Return(&domain.User{ID: tc.GivenUserID, MobileCountryCode: "+65"}, nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, tc.GivenUserID).Return(testdata.NewBalances(), nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, tc.GivenUserID, mock.Anything).Return(nil)
+			balanceRepo.On("CreateBalanceHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "exchange").Return(nil)
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			err = balanceUsecase.CurrencyExchange(context.Background(), tc.GivenUserID, dto.CurrencyExchangeRequest{
+				FromAmount: tc.GivenFromAmount,
+				ToCurrency: "USD",
+				QuoteID:    quoteID,
+			})
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				balanceRepo.AssertCalled(t, "UpdateBalances", mock.Anything, mock.Anything, tc.GivenUserID, map[string]money.Amount{
+					"SGD": money.MustParse("100.00"),
+					"USD": tc.ExpectedToBalance,
+				})
+				// the fees locked in by the quote are charged as line items
+				feeRepo.AssertCalled(t, "CreateFeeCharges", mock.Anything, mock.Anything, []domain.FeeCharge{{
+					UserID:        tc.GivenUserID,
+					FeeScheduleID: 1,
+					FeeRuleID:     1,
+					Product:       domain.FeeProductExchange,
+					Type:          domain.FeeTypeSpread,
+					Currency:      "SGD",
+					Amount:        money.MustParse("0.01"),
+				}})
+
+				// the audit trail keeps the quoted rate, not the one in effect at the time of the exchange
+				fxConversionRepo.AssertCalled(t, "CreateFXConversion", mock.Anything, mock.Anything, mock.MatchedBy(func(conversion *domain.FXConversion) bool {
+					return conversion.RateSource == domain.FXRateSourceQuote &&
+						conversion.MidRate == money.MustParseRate("0.76") &&
+						conversion.Spread == money.MustParseRate("0.005") &&
+						conversion.Profit == money.MustParse("0.01") &&
+						conversion.FromAmount == money.MustParse("100.00") &&
+						conversion.ToAmount == money.MustParse("75.99") &&
+						conversion.ConversionPath == "SGD>USD"
+				}))
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				balanceRepo.AssertNotCalled(t, "UpdateBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				fxConversionRepo.AssertNotCalled(t, "CreateFXConversion", mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			if tc.ExpectQuoteClaim {
+				exchangeQuoteRepo.AssertCalled(t, "ClaimExchangeQuote", mock.Anything, quote.ID, mock.Anything)
+			} else {
+				exchangeQuoteRepo.AssertNotCalled(t, "ClaimExchangeQuote", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tc.ExpectedReleased {
+				exchangeQuoteRepo.AssertCalled(t, "ReleaseExchangeQuote", mock.Anything, quote.ID)
+			} else {
+				exchangeQuoteRepo.AssertNotCalled(t, "ReleaseExchangeQuote", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"log"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+)
+
+type currencyUsecase struct {
+	currencyRepository domain.CurrencyRepository
+}
+
+func NewCurrencyUsecase(currencyRepository domain.CurrencyRepository) domain.CurrencyUsecase {
+	return &currencyUsecase{
+		currencyRepository: currencyRepository,
+	}
+}
+
+// GetCurrencies lists the enabled currencies along with the mobile country codes they are the home currency of
+func (uc *currencyUsecase) GetCurrencies(ctx context.Context) (*dto.GetCurrenciesResponse, error) {
+	currencies, err := uc.currencyRepository.GetCurrencies(ctx)
+	if err != nil {
+		log.Printf("failed to get currencies with error: %v\n", err)
+		return nil, err
+	}
+
+	countryCurrencies, err := uc.currencyRepository.GetCountryCurrencies(ctx)
+	if err != nil {
+		log.Printf("failed to get country currencies with error: %v\n", err)
+		return nil, err
+	}
+
+	mobileCountryCodes := make(map[string][]string)
+	for _, c := range countryCurrencies {
+		mobileCountryCodes[c.Currency] = append(mobileCountryCodes[c.Currency], c.MobileCountryCode)
+	}
+
+	resp := &dto.GetCurrenciesResponse{
+		Currencies: make([]dto.GetCurrencyResponse, 0, len(currencies)),
+	}
+	for _, c := range currencies {
+		if !c.Enabled {
+			continue
+		}
+		codes := mobileCountryCodes[c.Code]
+		if codes == nil {
+			codes = []string{}
+		}
+		resp.Currencies = append(resp.Currencies, dto.GetCurrencyResponse{
+			Code:               c.Code,
+			Name:               c.Name,
+			Symbol:             c.Symbol,
+			Exponent:           c.Exponent,
+			MobileCountryCodes: codes,
+		})
+	}
+
+	return resp, nil
+}
+
+// currencyCatalogue answers the currency questions asked before money moves,
+// from the catalogue kept by the currency repository
+type currencyCatalogue struct {
+	repository domain.CurrencyRepository
+}
+
+// currency returns the currency with the given ISO code, as long as money can move in it
+func (c currencyCatalogue) currency(ctx context.Context, code string) (*domain.Currency, error) {
+	currencies, err := c.repository.GetCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, currency := range currencies {
+		if currency.Code != code {
+			continue
+		}
+		if !currency.Enabled {
+			return nil, exception.ErrCurrencyDisabled
+		}
+		return &currency, nil
+	}
+
+	return nil, exception.ErrCurrencyNotSupported
+}
+
+// homeCurrency returns the currency users registered with mobileCountryCode deposit, withdraw and receive in
+func (c currencyCatalogue) homeCurrency(ctx context.Context, mobileCountryCode string) (*domain.Currency, error) {
+	countryCurrencies, err := c.repository.GetCountryCurrencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, countryCurrency := range countryCurrencies {
+		if countryCurrency.MobileCountryCode == mobileCountryCode {
+			return c.currency(ctx, countryCurrency.Currency)
+		}
+	}
+
+	return nil, exception.ErrCountryCurrencyNotFound
+}
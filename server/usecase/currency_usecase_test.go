@@ -0,0 +1,81 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newCurrencyRepository returns a currency repository holding the seeded currency catalogue
+func newCurrencyRepository() *mocks.CurrencyRepository {
+	currencyRepo := new(mocks.CurrencyRepository)
+	currencyRepo.On("GetCurrencies", mock.Anything).Return(testdata.MockCurrencies(), nil)
+	currencyRepo.On("GetCountryCurrencies", mock.Anything).Return(testdata.MockCountryCurrencies(), nil)
+	return currencyRepo
+}
+
+func TestCurrencyUsecase_GetCurrencies(t *testing.T) {
+	testCases := []struct {
+		Title                   string
+		GivenCurrencies         []domain.Currency
+		GivenCountryCurrencies  []domain.CountryCurrency
+		GetCurrenciesError      error
+		GetCountryCurrencyError error
+		ExpectedResponse        *dto.GetCurrenciesResponse
+		ExpectedError           error
+	}{
+		{
+			Title: "ReturnsSuccessfully",
+			GivenCurrencies: []domain.Currency{
+				{Code: "JPY", Name: "Japanese Yen", Symbol: "¥", Exponent: 0, Enabled: true},
+				{Code: "MYR", Name: "Malaysian Ringgit", Symbol: "RM", Exponent: 2, Enabled: false},
+				{Code: "SGD", Name: "Singapore Dollar", Symbol: "S$", Exponent: 2, Enabled: true},
+			},
+			GivenCountryCurrencies: testdata.MockCountryCurrencies(),
+			ExpectedResponse: &dto.GetCurrenciesResponse{
+				Currencies: []dto.GetCurrencyResponse{
+					{Code: "JPY", Name: "Japanese Yen", Symbol: "¥", Exponent: 0, MobileCountryCodes: []string{}},
+					{Code: "SGD", Name: "Singapore Dollar", Symbol: "S$", Exponent: 2, MobileCountryCodes: []string{"+65"}},
+				},
+			},
+		},
+		{
+			Title:              "ReturnsError_GetCurrencies",
+			GetCurrenciesError: errors.New("db down"),
+			ExpectedError:      errors.New("db down"),
+		},
+		{
+			Title:                   "ReturnsError_GetCountryCurrencies",
+			GivenCurrencies:         testdata.MockCurrencies(),
+			GetCountryCurrencyError: errors.New("db down"),
+			ExpectedError:           errors.New("db down"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			currencyRepo := new(mocks.CurrencyRepository)
+			currencyRepo.On("GetCurrencies", mock.Anything).Return(tc.GivenCurrencies, tc.GetCurrenciesError)
+			currencyRepo.On("GetCountryCurrencies", mock.Anything).Return(tc.GivenCountryCurrencies, tc.GetCountryCurrencyError)
+
+			resp, err := usecase.NewCurrencyUsecase(currencyRepo).GetCurrencies(context.Background())
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedResponse, resp)
+			} else {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				require.Nil(t, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/LeonLow97/go-clean-architecture/utils/ulid"
+)
+
+// exchangeQuotes hands out quotes that lock an exchange rate for a short while and
+// checks them when the user comes back to exchange or transfer at that rate
+type exchangeQuotes struct {
+	repository domain.ExchangeQuoteRepository
+	secret     []byte
+	ttl        time.Duration
+}
+
+func newExchangeQuotes(cfg infrastructure.Config, exchangeQuoteRepository domain.ExchangeQuoteRepository) exchangeQuotes {
+	ttl := cfg.ExchangeRate.QuoteTTL
+	if ttl <= 0 {
+		ttl = constants.DEFAULT_EXCHANGE_QUOTE_TTL
+	}
+
+	return exchangeQuotes{
+		repository: exchangeQuoteRepository,
+		secret:     []byte(cfg.ExchangeRate.QuoteSecret),
+		ttl:        ttl,
+	}
+}
+
+// issue locks rate, the fees of product and the amounts worked out with them for userID
+func (q exchangeQuotes) issue(ctx context.Context, userID int, product string, rate domain.ExchangeRate, fromAmount, toAmount money.Amount, fees []domain.FeeCharge) (*domain.ExchangeQuote, error) {
+	id := ulid.New()
+	quote := domain.ExchangeQuote{
+		ID:           id + "." + q.sign(id, userID),
+		UserID:       userID,
+		Product:      product,
+		FromCurrency: rate.FromCurrency,
+		ToCurrency:   rate.ToCurrency,
+		Rate:         rate.Rate,
+		Spread:       rate.Spread,
+		Path:         rate.ConversionPath(),
+		EffectiveAt:  rate.EffectiveAt,
+		FromAmount:   fromAmount,
+		ToAmount:     toAmount,
+		Profit:       domain.TotalFees(fees),
+		Fees:         fees,
+		ExpiresAt:    time.Now().Add(q.ttl).UTC(),
+	}
+
+	if err := q.repository.CreateExchangeQuote(ctx, quote, q.ttl); err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
+// claim returns the quote userID was given and marks it as used. Quote ids are checked
+// against their signature first, so guessed or tampered ids never reach redis.
+func (q exchangeQuotes) claim(ctx context.Context, userID int, quoteID string) (*domain.ExchangeQuote, error) {
+	id, signature, found := strings.Cut(quoteID, ".")
+	if !found || !ulid.IsValid(id) || !hmac.Equal([]byte(signature), []byte(q.sign(id, userID))) {
+		return nil, exception.ErrExchangeQuoteInvalid
+	}
+
+	quote, err := q.repository.GetExchangeQuote(ctx, quoteID)
+	if err != nil {
+		return nil, err
+	}
+	if quote.UserID != userID {
+		return nil, exception.ErrExchangeQuoteInvalid
+	}
+	if !time.Now().Before(quote.ExpiresAt) {
+		return nil, exception.ErrExchangeQuoteExpired
+	}
+
+	// the claim outlives the quote so that it cannot be used again in the moment before the quote expires
+	if err := q.repository.ClaimExchangeQuote(ctx, quoteID, q.ttl); err != nil {
+		return nil, err
+	}
+
+	return quote, nil
+}
+
+// release makes a claimed quote usable again when the exchange it was claimed for did not go through
+func (q exchangeQuotes) release(ctx context.Context, quoteID string) {
+	if err := q.repository.ReleaseExchangeQuote(ctx, quoteID); err != nil {
+		log.Printf("failed to release exchange quote %s with error: %v\n", quoteID, err)
+	}
+}
+
+func (q exchangeQuotes) sign(id string, userID int) string {
+	mac := hmac.New(sha256.New, q.secret)
+	mac.Write([]byte(fmt.Sprintf("%s:%d", id, userID)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+)
+
+type exchangeRateUsecase struct {
+	exchangeRateRepository domain.ExchangeRateRepository
+}
+
+func NewExchangeRateUsecase(exchangeRateRepository domain.ExchangeRateRepository) domain.ExchangeRateUsecase {
+	return &exchangeRateUsecase{
+		exchangeRateRepository: exchangeRateRepository,
+	}
+}
+
+// GetExchangeRateHistory lists the rates that applied to a pair over req.Range, oldest first
+func (uc *exchangeRateUsecase) GetExchangeRateHistory(ctx context.Context, req dto.GetExchangeRateHistoryRequest) (*dto.GetExchangeRateHistoryResponse, error) {
+	since := time.Now().Add(-constants.ExchangeRateHistoryRanges[req.Range])
+
+	exchangeRates, err := uc.exchangeRateRepository.GetExchangeRateHistory(ctx, req.From, req.To, since)
+	if err != nil {
+		log.Printf("failed to get exchange rate history from %s to %s with error: %v\n", req.From, req.To, err)
+		return nil, err
+	}
+
+	resp := &dto.GetExchangeRateHistoryResponse{
+		From:  req.From,
+		To:    req.To,
+		Range: req.Range,
+		Rates: make([]dto.ExchangeRateHistoryPoint, 0, len(exchangeRates)),
+	}
+	for _, rate := range exchangeRates {
+		resp.Rates = append(resp.Rates, dto.ExchangeRateHistoryPoint{
+			Rate:        rate.Rate,
+			Spread:      rate.Spread,
+			EffectiveAt: rate.EffectiveAt,
+		})
+	}
+
+	return resp, nil
+}
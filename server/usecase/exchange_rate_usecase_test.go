@@ -0,0 +1,106 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newFXConversionRepository returns an fx conversion repository that records every conversion it is given
+func newFXConversionRepository() *mocks.FXConversionRepository {
+	fxConversionRepo := new(mocks.FXConversionRepository)
+	fxConversionRepo.On("CreateFXConversion", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	fxConversionRepo.On("GetFXConversionByTransactionID", mock.Anything, mock.Anything).Return(nil, exception.ErrFXConversionNotFound)
+	return fxConversionRepo
+}
+
+func TestExchangeRateUsecase_GetExchangeRateHistory(t *testing.T) {
+	startingRate := domain.ExchangeRate{
+		FromCurrency: "SGD",
+		ToCurrency:   "USD",
+		Rate:         money.MustParseRate("0.76"),
+		Spread:       money.MustParseRate("0.005"),
+		EffectiveAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	latestRate := domain.ExchangeRate{
+		FromCurrency: "SGD",
+		ToCurrency:   "USD",
+		Rate:         money.MustParseRate("0.74"),
+		Spread:       money.MustParseRate("0.006"),
+		EffectiveAt:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		Title            string
+		GivenRequest     dto.GetExchangeRateHistoryRequest
+		GivenRates       []domain.ExchangeRate
+		GetHistoryError  error
+		ExpectedSince    time.Duration
+		ExpectedResponse *dto.GetExchangeRateHistoryResponse
+		ExpectedError    error
+	}{
+		{
+			Title:         "ReturnsSuccessfully",
+			GivenRequest:  dto.GetExchangeRateHistoryRequest{From: "SGD", To: "USD", Range: "7d"},
+			GivenRates:    []domain.ExchangeRate{startingRate, latestRate},
+			ExpectedSince: 7 * 24 * time.Hour,
+			ExpectedResponse: &dto.GetExchangeRateHistoryResponse{
+				From:  "SGD",
+				To:    "USD",
+				Range: "7d",
+				Rates: []dto.ExchangeRateHistoryPoint{
+					{Rate: startingRate.Rate, Spread: startingRate.Spread, EffectiveAt: startingRate.EffectiveAt},
+					{Rate: latestRate.Rate, Spread: latestRate.Spread, EffectiveAt: latestRate.EffectiveAt},
+				},
+			},
+		},
+		{
+			Title:           "ReturnsError_ExchangeRateNotFound",
+			GivenRequest:    dto.GetExchangeRateHistoryRequest{From: "SGD", To: "XYZ", Range: "1y"},
+			GetHistoryError: exception.ErrExchangeRateNotFound,
+			ExpectedSince:   365 * 24 * time.Hour,
+			ExpectedError:   exception.ErrExchangeRateNotFound,
+		},
+		{
+			Title:           "ReturnsError_Database",
+			GivenRequest:    dto.GetExchangeRateHistoryRequest{From: "SGD", To: "USD", Range: "30d"},
+			GetHistoryError: errors.New("db down"),
+			ExpectedSince:   30 * 24 * time.Hour,
+			ExpectedError:   errors.New("db down"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			exchangeRateRepo := new(mocks.ExchangeRateRepository)
+			exchangeRateUsecase := usecase.NewExchangeRateUsecase(exchangeRateRepo)
+
+			var since time.Time
+			exchangeRateRepo.On("GetExchangeRateHistory", mock.Anything, tc.GivenRequest.From, tc.GivenRequest.To, mock.Anything).
+				Run(func(args mock.Arguments) { since = args.Get(3).(time.Time) }).
+				Return(tc.GivenRates, tc.GetHistoryError)
+
+			resp, err := exchangeRateUsecase.GetExchangeRateHistory(context.Background(), tc.GivenRequest)
+
+			require.WithinDuration(t, time.Now().Add(-tc.ExpectedSince), since, 5*time.Second)
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedResponse, resp)
+			} else {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				require.Nil(t, resp)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/jmoiron/sqlx"
+)
+
+type feeUsecase struct {
+	txManager      domain.TxManager
+	userRepository domain.UserRepository
+	feeRepository  domain.FeeRepository
+	currencies     currencyCatalogue
+}
+
+func NewFeeUsecase(txManager domain.TxManager, userRepository domain.UserRepository, feeRepository domain.FeeRepository, currencyRepository domain.CurrencyRepository) domain.FeeUsecase {
+	return &feeUsecase{
+		txManager:      txManager,
+		userRepository: userRepository,
+		feeRepository:  feeRepository,
+		currencies:     currencyCatalogue{repository: currencyRepository},
+	}
+}
+
+// GetFeeSchedules lists every version of the fee schedule for an admin, marking the one in effect
+func (uc *feeUsecase) GetFeeSchedules(ctx context.Context, userID int) (*dto.GetFeeSchedulesResponse, error) {
+	if err := uc.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	schedules, err := uc.feeRepository.GetFeeSchedules(ctx)
+	if err != nil {
+		log.Printf("failed to get fee schedules with error: %v\n", err)
+		return nil, err
+	}
+
+	// schedules come latest version first, the first one that has taken effect is the active one
+	now := time.Now()
+	activeFound := false
+	resp := &dto.GetFeeSchedulesResponse{
+		Schedules: make([]dto.FeeSchedule, 0, len(schedules)),
+	}
+	for _, schedule := range schedules {
+		active := !activeFound && !schedule.EffectiveAt.After(now)
+		activeFound = activeFound || active
+		resp.Schedules = append(resp.Schedules, toFeeScheduleResponse(schedule, active))
+	}
+
+	return resp, nil
+}
+
+// CreateFeeSchedule publishes the rules of req as the next version of the fee schedule. Earlier
+// versions are kept as they are, so every fee already charged can still be traced to its rule.
+func (uc *feeUsecase) CreateFeeSchedule(ctx context.Context, userID int, req dto.CreateFeeScheduleRequest) (*dto.FeeSchedule, error) {
+	if err := uc.requireAdmin(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	schedule := domain.FeeSchedule{
+		Note:        req.Note,
+		EffectiveAt: time.Now().UTC(),
+		CreatedBy:   userID,
+		Rules:       make([]domain.FeeRule, 0, len(req.Rules)),
+	}
+	if req.EffectiveAt != nil {
+		schedule.EffectiveAt = req.EffectiveAt.UTC()
+	}
+
+	for _, r := range req.Rules {
+		rule := domain.FeeRule{
+			Product:      r.Product,
+			FromCurrency: r.FromCurrency,
+			ToCurrency:   r.ToCurrency,
+			SpreadShare:  r.SpreadShare,
+			Percentage:   r.Percentage,
+			FixedAmount:  r.FixedAmount,
+			MinFee:       r.MinFee,
+			MaxFee:       r.MaxFee,
+		}
+		for _, t := range r.Tiers {
+			rule.Tiers = append(rule.Tiers, domain.FeeTier{
+				MinAmount:   t.MinAmount,
+				Percentage:  t.Percentage,
+				FixedAmount: t.FixedAmount,
+			})
+		}
+
+		// rules may be written for currencies that are disabled for now, but not for unknown ones
+		for _, code := range []string{rule.FromCurrency, rule.ToCurrency} {
+			if code == domain.FeeCorridorAny {
+				continue
+			}
+			if _, err := uc.currencies.currency(ctx, code); err != nil && !errors.Is(err, exception.ErrCurrencyDisabled) {
+				return nil, err
+			}
+		}
+
+		schedule.Rules = append(schedule.Rules, rule)
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		return uc.feeRepository.CreateFeeSchedule(ctx, tx, &schedule)
+	}); err != nil {
+		log.Printf("failed to create fee schedule for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	resp := toFeeScheduleResponse(schedule, !schedule.EffectiveAt.After(time.Now()))
+	return &resp, nil
+}
+
+// requireAdmin only lets admins through to the fee schedule
+func (uc *feeUsecase) requireAdmin(ctx context.Context, userID int) error {
+	user, err := uc.userRepository.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get user with error: %v\n", err)
+		return err
+	}
+	if !user.Admin {
+		return exception.ErrUserNotAdmin
+	}
+	return nil
+}
+
+func toFeeScheduleResponse(schedule domain.FeeSchedule, active bool) dto.FeeSchedule {
+	resp := dto.FeeSchedule{
+		ID:          schedule.ID,
+		Version:     schedule.Version,
+		Note:        schedule.Note,
+		EffectiveAt: schedule.EffectiveAt,
+		CreatedBy:   schedule.CreatedBy,
+		Active:      active,
+		Rules:       make([]dto.FeeRule, 0, len(schedule.Rules)),
+	}
+	for _, rule := range schedule.Rules {
+		r := dto.FeeRule{
+			ID:           rule.ID,
+			Product:      rule.Product,
+			FromCurrency: rule.FromCurrency,
+			ToCurrency:   rule.ToCurrency,
+			SpreadShare:  rule.SpreadShare,
+			Percentage:   rule.Percentage,
+			FixedAmount:  rule.FixedAmount,
+			MinFee:       rule.MinFee,
+			MaxFee:       rule.MaxFee,
+		}
+		for _, tier := range rule.Tiers {
+			r.Tiers = append(r.Tiers, dto.FeeTier{
+				MinAmount:   tier.MinAmount,
+				Percentage:  tier.Percentage,
+				FixedAmount: tier.FixedAmount,
+			})
+		}
+		resp.Rules = append(resp.Rules, r)
+	}
+	return resp
+}
+
+// feeSchedules prices money movements with the fee schedule in effect and records what was charged
+type feeSchedules struct {
+	repository domain.FeeRepository
+}
+
+// rule returns the rule of the active fee schedule for product in the corridor. Without a
+// schedule or a matching rule nil is returned, and nothing is charged.
+func (f feeSchedules) rule(ctx context.Context, product, fromCurrency, toCurrency string) (*domain.FeeRule, error) {
+	schedule, err := f.repository.GetActiveFeeSchedule(ctx)
+	if err != nil {
+		if errors.Is(err, exception.ErrFeeScheduleNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return schedule.Rule(product, fromCurrency, toCurrency), nil
+}
+
+// charge records the line items of fees against the journal entry that moved them to revenue
+func (f feeSchedules) charge(ctx context.Context, tx *sqlx.Tx, fees []domain.FeeCharge, journalEntryID, transactionID, userID int) error {
+	if len(fees) == 0 {
+		return nil
+	}
+
+	charges := make([]domain.FeeCharge, len(fees))
+	for idx, fee := range fees {
+		fee.JournalEntryID = journalEntryID
+		fee.TransactionID = transactionID
+		fee.UserID = userID
+		charges[idx] = fee
+	}
+	return f.repository.CreateFeeCharges(ctx, tx, charges)
+}
@@ -0,0 +1,198 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newFeeRepository returns a fee repository holding the seeded fee schedule that records every fee it is given
+func newFeeRepository() *mocks.FeeRepository {
+	feeRepo := new(mocks.FeeRepository)
+	feeRepo.On("GetActiveFeeSchedule", mock.Anything).Return(testdata.MockFeeSchedule(), nil)
+	feeRepo.On("CreateFeeCharges", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	return feeRepo
+}
+
+func TestFeeUsecase_GetFeeSchedules(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		Title              string
+		GivenUser          *domain.User
+		GivenSchedules     []domain.FeeSchedule
+		GetSchedulesError  error
+		ExpectedActive     []bool
+		ExpectedError      error
+		ExpectRepoNotReach bool
+	}{
+		{
+			Title:     "ReturnsSuccessfully",
+			GivenUser: &domain.User{ID: 1, Admin: true},
+			GivenSchedules: []domain.FeeSchedule{
+				{ID: 3, Version: 3, EffectiveAt: future},
+				{ID: 2, Version: 2, EffectiveAt: past},
+				{ID: 1, Version: 1, EffectiveAt: past.Add(-time.Hour)},
+			},
+			ExpectedActive: []bool{false, true, false},
+		},
+		{
+			Title:              "ReturnsError_UserNotAdmin",
+			GivenUser:          &domain.User{ID: 1},
+			ExpectedError:      exception.ErrUserNotAdmin,
+			ExpectRepoNotReach: true,
+		},
+		{
+			Title:             "ReturnsError_GetFeeSchedules",
+			GivenUser:         &domain.User{ID: 1, Admin: true},
+			GetSchedulesError: exception.ErrFeeScheduleNotFound,
+			ExpectedError:     exception.ErrFeeScheduleNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			userRepo.On("GetUserByID", mock.Anything, tc.GivenUser.ID).Return(tc.GivenUser, nil)
+			feeRepo := new(mocks.FeeRepository)
+			feeRepo.On("GetFeeSchedules", mock.Anything).Return(tc.GivenSchedules, tc.GetSchedulesError)
+
+			feeUsecase := usecase.NewFeeUsecase(new(usecaseMocks.TxManager), userRepo, feeRepo, newCurrencyRepository())
+			resp, err := feeUsecase.GetFeeSchedules(context.Background(), tc.GivenUser.ID)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Len(t, resp.Schedules, len(tc.ExpectedActive))
+				for i, schedule := range resp.Schedules {
+					require.Equal(t, tc.ExpectedActive[i], schedule.Active, "version %d", schedule.Version)
+				}
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				require.Nil(t, resp)
+			}
+			if tc.ExpectRepoNotReach {
+				feeRepo.AssertNotCalled(t, "GetFeeSchedules", mock.Anything)
+			}
+		})
+	}
+}
+
+func TestFeeUsecase_CreateFeeSchedule(t *testing.T) {
+	tieredRule := dto.FeeRule{
+		Product:      "transfer",
+		FromCurrency: "SGD",
+		ToCurrency:   "*",
+		Percentage:   money.MustParseRate("0.01"),
+		MinFee:       money.MustParse("1.00"),
+		MaxFee:       money.MustParse("10.00"),
+		Tiers: []dto.FeeTier{
+			{MinAmount: money.MustParse("1000.00"), Percentage: money.MustParseRate("0.005")},
+		},
+	}
+
+	testCases := []struct {
+		Title             string
+		GivenUser         *domain.User
+		GivenRules        []dto.FeeRule
+		CreateError       error
+		ExpectedError     error
+		ExpectNotCreating bool
+	}{
+		{
+			Title:      "ReturnsSuccessfully",
+			GivenUser:  &domain.User{ID: 1, Admin: true},
+			GivenRules: []dto.FeeRule{{Product: "exchange", FromCurrency: "*", ToCurrency: "*", SpreadShare: money.MustParseRate("0.03")}, tieredRule},
+		},
+		{
+			Title:             "ReturnsError_UserNotAdmin",
+			GivenUser:         &domain.User{ID: 1},
+			GivenRules:        []dto.FeeRule{tieredRule},
+			ExpectedError:     exception.ErrUserNotAdmin,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:             "ReturnsError_FixedFeeWithoutSourceCurrency",
+			GivenUser:         &domain.User{ID: 1, Admin: true},
+			GivenRules:        []dto.FeeRule{{Product: "withdrawal", FromCurrency: "*", ToCurrency: "*", FixedAmount: money.MustParse("1.00")}},
+			ExpectedError:     exception.ErrFeeRuleCurrencyRequired,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:             "ReturnsError_MaximumBelowMinimum",
+			GivenUser:         &domain.User{ID: 1, Admin: true},
+			GivenRules:        []dto.FeeRule{{Product: "transfer", FromCurrency: "SGD", ToCurrency: "*", MinFee: money.MustParse("5.00"), MaxFee: money.MustParse("1.00")}},
+			ExpectedError:     exception.ErrFeeRuleInvalid,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:             "ReturnsError_DuplicatedCorridor",
+			GivenUser:         &domain.User{ID: 1, Admin: true},
+			GivenRules:        []dto.FeeRule{tieredRule, tieredRule},
+			ExpectedError:     exception.ErrFeeRuleDuplicated,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:             "ReturnsError_UnknownCurrency",
+			GivenUser:         &domain.User{ID: 1, Admin: true},
+			GivenRules:        []dto.FeeRule{{Product: "exchange", FromCurrency: "XYZ", ToCurrency: "*"}},
+			ExpectedError:     exception.ErrCurrencyNotSupported,
+			ExpectNotCreating: true,
+		},
+		{
+			Title:         "ReturnsError_CreateFeeSchedule",
+			GivenUser:     &domain.User{ID: 1, Admin: true},
+			GivenRules:    []dto.FeeRule{tieredRule},
+			CreateError:   errors.New("db down"),
+			ExpectedError: errors.New("db down"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			userRepo.On("GetUserByID", mock.Anything, tc.GivenUser.ID).Return(tc.GivenUser, nil)
+			feeRepo := new(mocks.FeeRepository)
+			feeRepo.On("CreateFeeSchedule", mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { args.Get(2).(*domain.FeeSchedule).Version = 2 }).
+				Return(tc.CreateError)
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+
+			feeUsecase := usecase.NewFeeUsecase(txManager, userRepo, feeRepo, newCurrencyRepository())
+			resp, err := feeUsecase.CreateFeeSchedule(context.Background(), tc.GivenUser.ID, dto.CreateFeeScheduleRequest{
+				Note:  "new pricing",
+				Rules: tc.GivenRules,
+			})
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, 2, resp.Version)
+				require.True(t, resp.Active)
+				require.Equal(t, tc.GivenRules, resp.Rules)
+				feeRepo.AssertCalled(t, "CreateFeeSchedule", mock.Anything, mock.Anything, mock.MatchedBy(func(schedule *domain.FeeSchedule) bool {
+					return schedule.CreatedBy == tc.GivenUser.ID && schedule.Note == "new pricing" && len(schedule.Rules) == len(tc.GivenRules)
+				}))
+			} else {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				require.Nil(t, resp)
+			}
+			if tc.ExpectNotCreating {
+				feeRepo.AssertNotCalled(t, "CreateFeeSchedule", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/jmoiron/sqlx"
+)
+
+// RequestPayment asks one of the user's beneficiaries for money. No money moves until the
+// beneficiary pays the request with PayPaymentRequest.
+func (uc *transactionUsecase) RequestPayment(ctx context.Context, userID int, req dto.RequestPaymentRequest) (*domain.PaymentRequest, error) {
+	// the payer has to be linked as a beneficiary of the requester
+	payerID, isPayerActive, _, err := uc.transactionRepository.CheckLinkageOfSenderAndBeneficiaryByMobileNumber(ctx, userID, req.BeneficiaryMobileCountryCode, req.BeneficiaryMobileNumber)
+	if err != nil {
+		log.Printf("failed to check linkage of requester id %d and beneficiary with error: %v\n", userID, err)
+		return nil, err
+	}
+	if !isPayerActive {
+		return nil, exception.ErrBeneficiaryIsInactive
+	}
+	if payerID == userID {
+		return nil, exception.ErrUserIDEqualBeneficiaryID
+	}
+
+	// money can only be asked for in currencies that are in the catalogue and enabled
+	if _, err = uc.currencies.currency(ctx, req.Currency); err != nil {
+		log.Printf("currency %s is not allowed for payment request with error: %v\n", req.Currency, err)
+		return nil, err
+	}
+
+	paymentRequest := &domain.PaymentRequest{
+		RequesterID: userID,
+		PayerID:     payerID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Note:        req.Note,
+		Status:      domain.PaymentRequestStatusPending,
+		ExpiresAt:   time.Now().Add(uc.paymentRequestExpiry),
+	}
+	if err = uc.transactionRepository.InsertPaymentRequest(ctx, paymentRequest); err != nil {
+		log.Printf("failed to create payment request for requester id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	return paymentRequest, nil
+}
+
+func (uc *transactionUsecase) GetPaymentRequests(ctx context.Context, userID int) ([]domain.PaymentRequest, error) {
+	paymentRequests, err := uc.transactionRepository.GetPaymentRequestsByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get payment requests for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	// requests the scheduler has not expired yet are shown as they will be
+	now := time.Now()
+	for i := range paymentRequests {
+		if paymentRequests[i].Expired(now) {
+			paymentRequests[i].Status = domain.PaymentRequestStatusExpired
+		}
+	}
+
+	return paymentRequests, nil
+}
+
+// PayPaymentRequest pays a request the user was asked to pay from one of their wallets. The
+// request is claimed before the transfer so that it cannot be paid twice, or declined or cancelled
+// while it is being paid. The transfer goes through CreateTransaction's path, and when it fails
+// the request is pending again with the reason recorded, so that the payer can try again. A request
+// whose outcome was never recorded is settled by RecoverPaymentRequests.
+func (uc *transactionUsecase) PayPaymentRequest(ctx context.Context, userID, paymentRequestID int, req dto.PayPaymentRequest) (*domain.PaymentRequest, error) {
+	paymentRequest, err := uc.transactionRepository.GetPaymentRequestByID(ctx, nil, paymentRequestID)
+	if err != nil {
+		log.Printf("failed to get payment request id %d with error: %v\n", paymentRequestID, err)
+		return nil, err
+	}
+	if paymentRequest.PayerID != userID {
+		return nil, exception.ErrPaymentRequestNotFound
+	}
+
+	// the transfer is made to the requester's mobile number, the same way the payer would send it themselves
+	requester, err := uc.userRepository.GetUserByID(ctx, paymentRequest.RequesterID)
+	if err != nil {
+		log.Printf("failed to get requester id %d of payment request id %d with error: %v\n", paymentRequest.RequesterID, paymentRequestID, err)
+		return nil, err
+	}
+
+	transferReq := dto.CreateTransactionRequest{
+		SenderWalletID:               req.SenderWalletID,
+		SourceCurrency:               paymentRequest.Currency,
+		SourceAmount:                 paymentRequest.Amount,
+		BeneficiaryMobileCountryCode: requester.MobileCountryCode,
+		BeneficiaryMobileNumber:      requester.MobileNumber,
+		AutoConvert:                  req.AutoConvert,
+	}
+
+	// the attempt is recorded along with the claim, so that a request left processing always points
+	// at the transaction that was paying it and RecoverPaymentRequests can tell whether it went through
+	var transaction *domain.Transaction
+	if paymentRequest, err = uc.resolvePaymentRequest(ctx, userID, paymentRequestID, false, domain.PaymentRequestStatusProcessing, func(ctx context.Context, tx *sqlx.Tx, paymentRequest *domain.PaymentRequest) error {
+		var err error
+		if transaction, err = uc.recordTransfer(ctx, tx, transferReq, userID); err != nil {
+			return err
+		}
+		paymentRequest.TransactionID = transaction.ID
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	transferErr := uc.makeTransfer(ctx, transferReq, userID, transaction)
+
+	status := domain.PaymentRequestStatusPaid
+	paymentRequest.FailureReason = ""
+	if transferErr != nil {
+		status = domain.PaymentRequestStatusPending
+		paymentRequest.FailureReason = transactionFailureReason(transferErr)
+		paymentRequest.TransactionID = 0
+	} else {
+		paymentRequest.TransactionReference = transaction.Reference
+	}
+
+	if err = uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		return uc.transitionPaymentRequest(ctx, tx, paymentRequest, status)
+	}); err != nil {
+		log.Printf("failed to move payment request id %d to %s with error: %v\n", paymentRequestID, status, err)
+		return nil, err
+	}
+	if transferErr != nil {
+		return nil, transferErr
+	}
+
+	return paymentRequest, nil
+}
+
+func (uc *transactionUsecase) DeclinePaymentRequest(ctx context.Context, userID, paymentRequestID int) (*domain.PaymentRequest, error) {
+	return uc.resolvePaymentRequest(ctx, userID, paymentRequestID, false, domain.PaymentRequestStatusDeclined, nil)
+}
+
+// CancelPaymentRequest withdraws a request the user made before it is paid
+func (uc *transactionUsecase) CancelPaymentRequest(ctx context.Context, userID, paymentRequestID int) (*domain.PaymentRequest, error) {
+	return uc.resolvePaymentRequest(ctx, userID, paymentRequestID, true, domain.PaymentRequestStatusCancelled, nil)
+}
+
+func (uc *transactionUsecase) ExpirePaymentRequests(ctx context.Context, now time.Time) error {
+	if _, err := uc.transactionRepository.ExpirePaymentRequests(ctx, now); err != nil {
+		log.Printf("failed to expire payment requests with error: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+// RecoverPaymentRequests settles the requests that have been processing for longer than paying them
+// can take, because the payment stopped before its outcome was recorded.
+func (uc *transactionUsecase) RecoverPaymentRequests(ctx context.Context, now time.Time) error {
+	paymentRequests, err := uc.transactionRepository.GetProcessingPaymentRequests(ctx, now.Add(-constants.PAYMENT_REQUEST_PROCESSING_TIMEOUT))
+	if err != nil {
+		log.Printf("failed to get processing payment requests with error: %v\n", err)
+		return err
+	}
+
+	for _, paymentRequest := range paymentRequests {
+		if err := uc.recoverPaymentRequest(ctx, paymentRequest.ID); err != nil {
+			log.Printf("failed to recover payment request id %d with error: %v\n", paymentRequest.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// recoverPaymentRequest settles a request left processing by the transaction that was paying it,
+// the request is paid if the transaction went through and pending again otherwise
+func (uc *transactionUsecase) recoverPaymentRequest(ctx context.Context, paymentRequestID int) error {
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		paymentRequest, err := uc.transactionRepository.GetPaymentRequestByID(ctx, tx, paymentRequestID)
+		if err != nil {
+			log.Printf("failed to get payment request id %d with error: %v\n", paymentRequestID, err)
+			return err
+		}
+		// the payment may have been recorded since the request was picked up
+		if paymentRequest.Status != domain.PaymentRequestStatusProcessing {
+			return nil
+		}
+
+		if paymentRequest.FailureReason, err = uc.settleTransfer(ctx, tx, paymentRequest.PayerID, paymentRequest.TransactionID); err != nil {
+			return err
+		}
+
+		status := domain.PaymentRequestStatusPaid
+		if paymentRequest.FailureReason != "" {
+			status = domain.PaymentRequestStatusPending
+			paymentRequest.TransactionID = 0
+		}
+		return uc.transitionPaymentRequest(ctx, tx, paymentRequest, status)
+	})
+}
+
+// resolvePaymentRequest moves a pending request to status while it is locked. The payer resolves
+// a request unless byRequester is set. A request that has passed its expiry is expired instead,
+// without waiting for the scheduler to get to it, and ErrPaymentRequestExpired is returned.
+// prepare, when given, runs in the same sql transaction just before the request is saved.
+func (uc *transactionUsecase) resolvePaymentRequest(ctx context.Context, userID, paymentRequestID int, byRequester bool, status string, prepare func(ctx context.Context, tx *sqlx.Tx, paymentRequest *domain.PaymentRequest) error) (*domain.PaymentRequest, error) {
+	var paymentRequest *domain.PaymentRequest
+	var expired bool
+
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		if paymentRequest, err = uc.transactionRepository.GetPaymentRequestByID(ctx, tx, paymentRequestID); err != nil {
+			log.Printf("failed to get payment request id %d with error: %v\n", paymentRequestID, err)
+			return err
+		}
+
+		// a request of other users is not found, rather than found and forbidden
+		partyID := paymentRequest.PayerID
+		if byRequester {
+			partyID = paymentRequest.RequesterID
+		}
+		if partyID != userID {
+			return exception.ErrPaymentRequestNotFound
+		}
+
+		if paymentRequest.Expired(time.Now()) {
+			expired = true
+			status = domain.PaymentRequestStatusExpired
+		} else if prepare != nil {
+			if err = prepare(ctx, tx, paymentRequest); err != nil {
+				return err
+			}
+		}
+		return uc.transitionPaymentRequest(ctx, tx, paymentRequest, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expired {
+		return nil, exception.ErrPaymentRequestExpired
+	}
+
+	return paymentRequest, nil
+}
+
+// transitionPaymentRequest moves a payment request to the next status of its lifecycle
+func (uc *transactionUsecase) transitionPaymentRequest(ctx context.Context, tx *sqlx.Tx, paymentRequest *domain.PaymentRequest, status string) error {
+	if err := domain.CheckPaymentRequestStatusTransition(paymentRequest.Status, status); err != nil {
+		return err
+	}
+
+	fromStatus := paymentRequest.Status
+	paymentRequest.Status = status
+	if err := uc.transactionRepository.UpdatePaymentRequest(ctx, tx, *paymentRequest); err != nil {
+		paymentRequest.Status = fromStatus
+		return err
+	}
+
+	return nil
+}
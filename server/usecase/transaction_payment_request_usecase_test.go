@@ -0,0 +1,382 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionUsecase_RequestPayment(t *testing.T) {
+	request := func(currency string) dto.RequestPaymentRequest {
+		return dto.RequestPaymentRequest{
+			BeneficiaryMobileCountryCode: "+65",
+			BeneficiaryMobileNumber:      "87654321",
+			Amount:                       money.FromInt(25),
+			Currency:                     currency,
+			Note:                         "Dinner",
+		}
+	}
+
+	testCases := []struct {
+		Title         string
+		GivenRequest  dto.RequestPaymentRequest
+		PayerID       int
+		PayerActive   bool
+		ExpectedError error
+	}{
+		{
+			Title:        "ReturnsSuccessfully",
+			GivenRequest: request("SGD"),
+			PayerID:      2,
+			PayerActive:  true,
+		},
+		{
+			Title:         "ReturnsError_BeneficiaryIsInactive",
+			GivenRequest:  request("SGD"),
+			PayerID:       2,
+			ExpectedError: exception.ErrBeneficiaryIsInactive,
+		},
+		{
+			Title:         "ReturnsError_UserIDEqualBeneficiaryID",
+			GivenRequest:  request("SGD"),
+			PayerID:       1,
+			PayerActive:   true,
+			ExpectedError: exception.ErrUserIDEqualBeneficiaryID,
+		},
+		{
+			Title:         "ReturnsError_CurrencyNotSupported",
+			GivenRequest:  request("XYZ"),
+			PayerID:       2,
+			PayerActive:   true,
+			ExpectedError: exception.ErrCurrencyNotSupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			transactionRepo := new(mocks.TransactionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			transactionRepo.On("CheckLinkageOfSenderAndBeneficiaryByMobileNumber", mock.Anything, 1, "+65", "87654321").
+				Return(tc.PayerID, tc.PayerActive, false, nil)
+			transactionRepo.On("InsertPaymentRequest", mock.Anything, mock.Anything).Return(nil)
+
+			paymentRequest, err := transactionUsecase.RequestPayment(context.Background(), 1, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, 1, paymentRequest.RequesterID)
+				require.Equal(t, tc.PayerID, paymentRequest.PayerID)
+				require.Equal(t, domain.PaymentRequestStatusPending, paymentRequest.Status)
+				// requests expire after the default expiry when none is configured
+				require.WithinDuration(t, time.Now().Add(constants.DEFAULT_PAYMENT_REQUEST_EXPIRY), paymentRequest.ExpiresAt, time.Minute)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				require.Nil(t, paymentRequest)
+				transactionRepo.AssertNotCalled(t, "InsertPaymentRequest", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_PayPaymentRequest(t *testing.T) {
+	const (
+		requesterID   = 1
+		payerID       = 2
+		transactionID = 7
+	)
+
+	testCases := []struct {
+		Title                 string
+		GivenUserID           int
+		GivenExpiresAt        time.Time
+		RequesterActive       bool
+		ExpectedStatus        string
+		ExpectedFailureReason string
+		ExpectedTransactionID int
+		ExpectedError         error
+	}{
+		{
+			Title:                 "ReturnsSuccessfully",
+			GivenUserID:           payerID,
+			GivenExpiresAt:        time.Now().Add(time.Hour),
+			RequesterActive:       true,
+			ExpectedStatus:        domain.PaymentRequestStatusPaid,
+			ExpectedTransactionID: transactionID,
+		},
+		{
+			Title:                 "ReturnsError_FailedTransferLeavesRequestPending",
+			GivenUserID:           payerID,
+			GivenExpiresAt:        time.Now().Add(time.Hour),
+			ExpectedStatus:        domain.PaymentRequestStatusPending,
+			ExpectedFailureReason: constants.REASON_BENEFICIARY_INACTIVE,
+			ExpectedError:         exception.ErrBeneficiaryIsInactive,
+		},
+		{
+			Title:          "ReturnsError_NotThePayer",
+			GivenUserID:    requesterID,
+			GivenExpiresAt: time.Now().Add(time.Hour),
+			ExpectedError:  exception.ErrPaymentRequestNotFound,
+		},
+		{
+			Title:          "ReturnsError_PaymentRequestExpired",
+			GivenUserID:    payerID,
+			GivenExpiresAt: time.Now().Add(-time.Minute),
+			ExpectedStatus: domain.PaymentRequestStatusExpired,
+			ExpectedError:  exception.ErrPaymentRequestExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			walletRepo := new(mocks.WalletRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			userRepo := new(mocks.UserRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, walletRepo, balanceRepo, userRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("GetPaymentRequestByID", mock.Anything, mock.Anything, 5).Return(&domain.PaymentRequest{
+				ID:          5,
+				RequesterID: requesterID,
+				PayerID:     payerID,
+				Amount:      money.FromInt(10),
+				Currency:    "SGD",
+				Status:      domain.PaymentRequestStatusPending,
+				ExpiresAt:   tc.GivenExpiresAt,
+			}, nil)
+			transactionRepo.On("UpdatePaymentRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			userRepo.On("GetUserByID", mock.Anything, requesterID).
+				Return(&domain.User{ID: requesterID, MobileCountryCode: "+65", MobileNumber: "12345678"}, nil)
+
+			// the transfer itself goes through the same path as CreateTransaction
+			transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, payerID, mock.Anything).Return(transactionID, nil)
+			transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, requesterID, mock.Anything).Return(transactionID+1, nil)
+			transactionRepo.On("UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("CheckLinkageOfSenderAndBeneficiaryByMobileNumber", mock.Anything, payerID, "+65", "12345678").
+				Return(requesterID, tc.RequesterActive, true, nil)
+			transactionRepo.On("CheckValidityOfSenderIDAndWalletID", mock.Anything, payerID, 1).Return(true, "Savings", nil)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, payerID, 1).
+				Return(testdata.MockWalletCurrencyAmounts(), nil)
+			walletRepo.On("CashOutWalletBalances", mock.Anything, mock.Anything, payerID, 1, mock.Anything).Return(nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, requesterID).Return(testdata.NewBalances(), nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, requesterID, mock.Anything).Return(nil)
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			paymentRequest, err := transactionUsecase.PayPaymentRequest(context.Background(), tc.GivenUserID, 5, dto.PayPaymentRequest{SenderWalletID: 1})
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, domain.PaymentRequestStatusPaid, paymentRequest.Status)
+				require.Equal(t, transactionID, paymentRequest.TransactionID)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				require.Nil(t, paymentRequest)
+			}
+
+			if tc.ExpectedStatus == "" {
+				transactionRepo.AssertNotCalled(t, "UpdatePaymentRequest", mock.Anything, mock.Anything, mock.Anything)
+				transactionRepo.AssertNotCalled(t, "InsertTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			transactionRepo.AssertCalled(t, "UpdatePaymentRequest", mock.Anything, mock.Anything, mock.MatchedBy(func(paymentRequest domain.PaymentRequest) bool {
+				return paymentRequest.Status == tc.ExpectedStatus && paymentRequest.FailureReason == tc.ExpectedFailureReason && paymentRequest.TransactionID == tc.ExpectedTransactionID
+			}))
+			if tc.ExpectedStatus == domain.PaymentRequestStatusExpired {
+				transactionRepo.AssertNotCalled(t, "InsertTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			// the request is claimed along with the transaction paying it, so that it can be recovered if the payment stops halfway
+			transactionRepo.AssertCalled(t, "UpdatePaymentRequest", mock.Anything, mock.Anything, mock.MatchedBy(func(paymentRequest domain.PaymentRequest) bool {
+				return paymentRequest.Status == domain.PaymentRequestStatusProcessing && paymentRequest.TransactionID == transactionID
+			}))
+		})
+	}
+}
+
+func TestTransactionUsecase_RecoverPaymentRequests(t *testing.T) {
+	const (
+		payerID       = 2
+		transactionID = 7
+	)
+
+	testCases := []struct {
+		Title                   string
+		GivenStatus             string
+		GivenTransaction        *domain.Transaction
+		GivenTransactionErr     error
+		ExpectedStatus          string
+		ExpectedFailureReason   string
+		ExpectedTransactionID   int
+		ExpectTransactionFailed bool
+	}{
+		{
+			Title:                 "ReturnsSuccessfully_TransferWentThroughPaysRequest",
+			GivenStatus:           domain.PaymentRequestStatusProcessing,
+			GivenTransaction:      &domain.Transaction{ID: transactionID, Reference: "01J0REFERENCE", Status: constants.SUCCESS},
+			ExpectedStatus:        domain.PaymentRequestStatusPaid,
+			ExpectedTransactionID: transactionID,
+		},
+		{
+			Title:                   "ReturnsSuccessfully_TransferNeverFinishedFailsItAndLeavesRequestPending",
+			GivenStatus:             domain.PaymentRequestStatusProcessing,
+			GivenTransaction:        &domain.Transaction{ID: transactionID, Status: constants.CREATED},
+			ExpectedStatus:          domain.PaymentRequestStatusPending,
+			ExpectedFailureReason:   constants.REASON_INTERNAL_ERROR,
+			ExpectTransactionFailed: true,
+		},
+		{
+			Title:                 "ReturnsSuccessfully_TransferFailedLeavesRequestPending",
+			GivenStatus:           domain.PaymentRequestStatusProcessing,
+			GivenTransaction:      &domain.Transaction{ID: transactionID, Status: constants.FAILED, FailureReason: constants.REASON_INSUFFICIENT_FUNDS},
+			ExpectedStatus:        domain.PaymentRequestStatusPending,
+			ExpectedFailureReason: constants.REASON_INSUFFICIENT_FUNDS,
+		},
+		{
+			Title:                 "ReturnsSuccessfully_NoTransactionLeavesRequestPending",
+			GivenStatus:           domain.PaymentRequestStatusProcessing,
+			GivenTransactionErr:   exception.ErrTransactionNotFound,
+			ExpectedStatus:        domain.PaymentRequestStatusPending,
+			ExpectedFailureReason: constants.REASON_INTERNAL_ERROR,
+		},
+		{
+			Title:       "ReturnsSuccessfully_AlreadySettledIsLeftAlone",
+			GivenStatus: domain.PaymentRequestStatusPaid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			now := time.Now()
+			paymentRequest := &domain.PaymentRequest{
+				ID:            5,
+				RequesterID:   1,
+				PayerID:       payerID,
+				Status:        tc.GivenStatus,
+				TransactionID: transactionID,
+				ExpiresAt:     now.Add(time.Hour),
+			}
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("GetProcessingPaymentRequests", mock.Anything, now.Add(-constants.PAYMENT_REQUEST_PROCESSING_TIMEOUT)).
+				Return([]domain.PaymentRequest{*paymentRequest}, nil)
+			transactionRepo.On("GetPaymentRequestByID", mock.Anything, mock.Anything, 5).Return(paymentRequest, nil)
+			transactionRepo.On("GetTransactionByID", mock.Anything, mock.Anything, payerID, transactionID).Return(tc.GivenTransaction, tc.GivenTransactionErr)
+			transactionRepo.On("UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("UpdatePaymentRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			err := transactionUsecase.RecoverPaymentRequests(context.Background(), now)
+			require.NoError(t, err)
+
+			if tc.ExpectedStatus == "" {
+				transactionRepo.AssertNotCalled(t, "UpdatePaymentRequest", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			transactionRepo.AssertCalled(t, "UpdatePaymentRequest", mock.Anything, mock.Anything, mock.MatchedBy(func(paymentRequest domain.PaymentRequest) bool {
+				return paymentRequest.Status == tc.ExpectedStatus && paymentRequest.FailureReason == tc.ExpectedFailureReason && paymentRequest.TransactionID == tc.ExpectedTransactionID
+			}))
+
+			// a transfer that never finished is failed, so that it cannot still go through once the request is pending
+			if tc.ExpectTransactionFailed {
+				transactionRepo.AssertCalled(t, "UpdateTransaction", mock.Anything, mock.Anything, constants.CREATED, mock.MatchedBy(func(transaction domain.Transaction) bool {
+					return transaction.Status == constants.FAILED
+				}))
+			} else {
+				transactionRepo.AssertNotCalled(t, "UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("ReturnsError_GetProcessingPaymentRequests_InternalServerError", func(t *testing.T) {
+		transactionRepo := new(mocks.TransactionRepository)
+		transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+		transactionRepo.On("GetProcessingPaymentRequests", mock.Anything, mock.Anything).Return(nil, errors.New("internal server error"))
+
+		err := transactionUsecase.RecoverPaymentRequests(context.Background(), time.Now())
+		require.EqualError(t, err, "internal server error")
+	})
+}
+
+func TestTransactionUsecase_DeclinePaymentRequest(t *testing.T) {
+	testCases := []struct {
+		Title          string
+		GivenUserID    int
+		GivenStatus    string
+		ExpectedError  error
+		ExpectNoUpdate bool
+	}{
+		{
+			Title:       "ReturnsSuccessfully",
+			GivenUserID: 2,
+			GivenStatus: domain.PaymentRequestStatusPending,
+		},
+		{
+			Title:          "ReturnsError_NotThePayer",
+			GivenUserID:    1,
+			GivenStatus:    domain.PaymentRequestStatusPending,
+			ExpectedError:  exception.ErrPaymentRequestNotFound,
+			ExpectNoUpdate: true,
+		},
+		{
+			Title:          "ReturnsError_PaymentRequestNotPending",
+			GivenUserID:    2,
+			GivenStatus:    domain.PaymentRequestStatusProcessing,
+			ExpectedError:  exception.ErrPaymentRequestNotPending,
+			ExpectNoUpdate: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("GetPaymentRequestByID", mock.Anything, mock.Anything, 5).Return(&domain.PaymentRequest{
+				ID:          5,
+				RequesterID: 1,
+				PayerID:     2,
+				Status:      tc.GivenStatus,
+				ExpiresAt:   time.Now().Add(time.Hour),
+			}, nil)
+			transactionRepo.On("UpdatePaymentRequest", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			paymentRequest, err := transactionUsecase.DeclinePaymentRequest(context.Background(), tc.GivenUserID, 5)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, domain.PaymentRequestStatusDeclined, paymentRequest.Status)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				require.Nil(t, paymentRequest)
+			}
+			if tc.ExpectNoUpdate {
+				transactionRepo.AssertNotCalled(t, "UpdatePaymentRequest", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
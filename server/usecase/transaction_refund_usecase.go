@@ -0,0 +1,379 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/LeonLow97/go-clean-architecture/utils/ulid"
+	"github.com/jmoiron/sqlx"
+)
+
+// RequestRefund lets the sender of a transaction ask the beneficiary to send some or all of it
+// back. No money moves until the beneficiary approves the request with RespondToRefund.
+func (uc *transactionUsecase) RequestRefund(ctx context.Context, userID, transactionID int, req dto.RefundTransactionRequest) (*domain.Refund, error) {
+	var refund *domain.Refund
+
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// lock the transaction so that two requests cannot both claim what is left to refund
+		transaction, err := uc.transactionRepository.GetTransactionByID(ctx, tx, userID, transactionID)
+		if err != nil {
+			log.Printf("failed to get transaction id %d for user id %d with error: %v\n", transactionID, userID, err)
+			return err
+		}
+
+		if err = uc.checkRefundable(transaction, userID); err != nil {
+			return err
+		}
+
+		refunds, err := uc.transactionRepository.GetRefundsByTransactionID(ctx, tx, transactionID)
+		if err != nil {
+			log.Printf("failed to get refunds for transaction id %d with error: %v\n", transactionID, err)
+			return err
+		}
+
+		// pending requests count against the transaction as well, so the beneficiary is never asked for more than was sent
+		refundable := transaction.DestinationAmount
+		for _, r := range refunds {
+			if r.Status != constants.DECLINED {
+				refundable -= r.Amount
+			}
+		}
+
+		amount := req.Amount
+		if amount.IsZero() {
+			amount = refundable
+		}
+		if !amount.IsPositive() || amount > refundable {
+			return exception.ErrRefundAmountExceeded
+		}
+
+		refund = &domain.Refund{
+			TransactionID:  transaction.ID,
+			SenderID:       transaction.SenderID,
+			BeneficiaryID:  transaction.BeneficiaryID,
+			Amount:         amount,
+			Currency:       transaction.DestinationCurrency,
+			RefundCurrency: transaction.SourceCurrency,
+			RateType:       req.RateType,
+			Reason:         req.Reason,
+			Status:         constants.REQUESTED,
+		}
+		if err = uc.transactionRepository.InsertRefund(ctx, tx, refund); err != nil {
+			log.Printf("failed to create refund for transaction id %d with error: %v\n", transactionID, err)
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// RespondToRefund records the beneficiary's decision on a refund request, moving the funds back to
+// the sender's wallet when it is approved
+func (uc *transactionUsecase) RespondToRefund(ctx context.Context, userID, refundID int, decision string) (*domain.Refund, error) {
+	var refund *domain.Refund
+
+	// concurrent refunds and transfers touching the same rows can deadlock or fail to serialize, respond again when they do
+	err := infrastructure.RetryTx(ctx, constants.MAX_TX_RETRY_ATTEMPTS, func() error {
+		return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+			var err error
+			refund, err = uc.transactionRepository.GetRefundByID(ctx, tx, refundID)
+			if err != nil {
+				log.Printf("failed to get refund id %d with error: %v\n", refundID, err)
+				return err
+			}
+
+			// only the beneficiary can consent, anyone else is told the refund does not exist
+			if refund.BeneficiaryID != userID {
+				return exception.ErrRefundNotFound
+			}
+			if refund.Status != constants.REQUESTED {
+				return exception.ErrRefundAlreadyResolved
+			}
+
+			if decision == constants.REFUND_DECLINE {
+				refund.Status = constants.DECLINED
+				if err = uc.transactionRepository.UpdateRefund(ctx, tx, *refund); err != nil {
+					log.Printf("failed to decline refund id %d with error: %v\n", refundID, err)
+					return err
+				}
+				return nil
+			}
+
+			transaction, err := uc.transactionRepository.GetTransactionByID(ctx, tx, refund.SenderID, refund.TransactionID)
+			if err != nil {
+				log.Printf("failed to get transaction id %d for refund id %d with error: %v\n", refund.TransactionID, refundID, err)
+				return err
+			}
+
+			// the window applies to the approval too, a request cannot be left open indefinitely
+			if err = uc.checkRefundable(transaction, refund.SenderID); err != nil {
+				return err
+			}
+
+			return uc.completeRefund(ctx, tx, transaction, refund)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+func (uc *transactionUsecase) GetRefunds(ctx context.Context, userID int) (*[]domain.Refund, error) {
+	refunds, err := uc.transactionRepository.GetRefundsByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get refunds for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+// checkRefundable returns an error unless the transaction is a settled transfer made by senderID
+// from one of their wallets within the refund window
+func (uc *transactionUsecase) checkRefundable(transaction *domain.Transaction, senderID int) error {
+	if transaction.SenderID != senderID ||
+		transaction.OriginalTransactionID != 0 ||
+		transaction.SenderWalletID == 0 ||
+		transaction.Status != constants.SUCCESS {
+		return exception.ErrTransactionNotRefundable
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, transaction.CreatedAt)
+	if err != nil {
+		log.Printf("failed to parse created at %q of transaction id %d with error: %v\n", transaction.CreatedAt, transaction.ID, err)
+		return err
+	}
+	if time.Since(createdAt) > uc.refundWindow {
+		return exception.ErrRefundWindowExpired
+	}
+
+	return nil
+}
+
+// completeRefund moves refund.Amount out of the beneficiary's main balance and back into the
+// wallet the original transaction was paid from, converting at the rate the sender asked for.
+// A wallet that has since been archived or closed no longer takes money in, the refund goes
+// to the sender's main balance instead.
+func (uc *transactionUsecase) completeRefund(ctx context.Context, tx *sqlx.Tx, transaction *domain.Transaction, refund *domain.Refund) error {
+	refunds, err := uc.transactionRepository.GetRefundsByTransactionID(ctx, tx, transaction.ID)
+	if err != nil {
+		log.Printf("failed to get refunds for transaction id %d with error: %v\n", transaction.ID, err)
+		return err
+	}
+
+	var refundedAmount, refundedSourceAmount money.Amount
+	for _, r := range refunds {
+		if r.Status == constants.COMPLETED {
+			refundedAmount += r.Amount
+			refundedSourceAmount += r.RefundAmount
+		}
+	}
+	fullyRefunded := refundedAmount+refund.Amount == transaction.DestinationAmount
+
+	// lock the sender's wallet first, same order as CreateTransaction, to see where the refund can go
+	senderWallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, refund.SenderID, transaction.SenderWalletID)
+	if err != nil {
+		log.Printf("failed to get wallet id %d of sender id %d with error: %v\n", transaction.SenderWalletID, refund.SenderID, err)
+		return err
+	}
+	toWallet := senderWallet.CheckActive() == nil
+
+	beneficiaryAccount := domain.UserBalanceAccount(refund.BeneficiaryID)
+	senderAccount := domain.UserBalanceAccount(refund.SenderID)
+	if toWallet {
+		senderAccount = domain.UserWalletAccount(refund.SenderID, transaction.SenderWalletID)
+	}
+	entry := &domain.JournalEntry{Type: domain.JournalRefund, Reference: fmt.Sprintf("refund:%d", refund.ID)}
+
+	// the reversal is a transfer in the other direction, recorded for both users under a reference of its own
+	reversal := domain.Transaction{
+		Reference:             ulid.New(),
+		SenderID:              refund.BeneficiaryID,
+		BeneficiaryID:         refund.SenderID,
+		SourceOfTransfer:      fmt.Sprintf("Main Balance %s", refund.Currency),
+		SourceAmount:          refund.Amount,
+		SourceCurrency:        refund.Currency,
+		DestinationCurrency:   refund.RefundCurrency,
+		Status:                constants.SUCCESS,
+		OriginalTransactionID: transaction.ID,
+	}
+
+	var conversion *domain.FXConversion
+	var fees []domain.FeeCharge
+	switch {
+	case refund.Currency == refund.RefundCurrency:
+		refund.RefundAmount = refund.Amount
+		reversal.FXRate = money.RateOne
+		entry.Transfer(beneficiaryAccount, senderAccount, refund.Currency, refund.Amount)
+	case refund.RateType == constants.REFUND_RATE_ORIGINAL:
+		// the sender gets back their share of what they paid, including the spread, and the last
+		// refund takes whatever is left so that rounding never leaves a cent behind
+		if fullyRefunded {
+			refund.RefundAmount = transaction.SourceAmount - refundedSourceAmount
+		} else {
+			refund.RefundAmount = transaction.SourceAmount.Prorate(refund.Amount, transaction.DestinationAmount, money.RoundDown)
+		}
+		reversal.FXRate = refund.RefundAmount.Ratio(refund.Amount, money.RoundHalfUp)
+		originalRate := domain.ExchangeRate{FromCurrency: refund.Currency, ToCurrency: refund.RefundCurrency, Rate: reversal.FXRate}
+		conversion = domain.NewFXConversion(refund.BeneficiaryID, originalRate, domain.FXRateSourceOriginal, refund.Amount, refund.RefundAmount, 0)
+		entry.Exchange(beneficiaryAccount, senderAccount, refund.Currency, refund.RefundCurrency, refund.Amount, 0, refund.RefundAmount)
+	default:
+		exchangeRate, err := utils.ResolveExchangeRate(ctx, uc.exchangeRateProvider, uc.baseCurrency, refund.Currency, refund.RefundCurrency)
+		if err != nil {
+			log.Printf("failed to get exchange rate from %s to %s with error: %v\n", refund.Currency, refund.RefundCurrency, err)
+			return err
+		}
+		// converting back at today's rate is priced like any other transfer, paid by the beneficiary
+		feeRule, err := uc.fees.rule(ctx, domain.FeeProductTransfer, refund.Currency, refund.RefundCurrency)
+		if err != nil {
+			log.Printf("failed to get transfer fee rule from %s to %s with error: %v\n", refund.Currency, refund.RefundCurrency, err)
+			return err
+		}
+		var convertedAmount money.Amount
+		if fees, convertedAmount, err = utils.CalculateConversionDetails(refund.Amount, *exchangeRate, feeRule); err != nil {
+			return err
+		}
+		profit := domain.TotalFees(fees)
+		refund.RefundAmount = convertedAmount
+		reversal.FXRate = exchangeRate.Rate
+		reversal.Profit = profit
+		conversion = domain.NewFXConversion(refund.BeneficiaryID, *exchangeRate, exchangeRate.RateSource(), refund.Amount, convertedAmount, profit)
+		entry.Exchange(beneficiaryAccount, senderAccount, refund.Currency, refund.RefundCurrency, refund.Amount, profit, convertedAmount)
+	}
+
+	// lock the sender's wallet balances before any main balances, same order as CreateTransaction
+	finalSenderBalancesMap := map[string]money.Amount{refund.RefundCurrency: refund.RefundAmount}
+	if toWallet {
+		senderWalletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, refund.SenderID, transaction.SenderWalletID)
+		if err != nil {
+			log.Printf("failed to retrieve wallet balances for user id %d and wallet id %d with error %v\n", refund.SenderID, transaction.SenderWalletID, err)
+			return err
+		}
+		for _, b := range senderWalletBalances {
+			if b.Currency == refund.RefundCurrency {
+				finalSenderBalancesMap[b.Currency] += b.Amount
+			}
+		}
+	}
+
+	// when the refund goes to the sender's main balance, both users' balances are locked in user id
+	// order so that refunds between the same two users in opposite directions cannot deadlock
+	lockOrder := []int{refund.BeneficiaryID}
+	if !toWallet {
+		lockOrder = []int{refund.SenderID, refund.BeneficiaryID}
+		if refund.BeneficiaryID < refund.SenderID {
+			lockOrder = []int{refund.BeneficiaryID, refund.SenderID}
+		}
+	}
+	balances := make(map[int][]domain.Balance, len(lockOrder))
+	for _, id := range lockOrder {
+		if balances[id], err = uc.balanceRepository.GetBalances(ctx, tx, id); err != nil {
+			log.Printf("failed to retrieve balances for user id %d with error: %v\n", id, err)
+			return err
+		}
+	}
+
+	if !toWallet {
+		for _, b := range balances[refund.SenderID] {
+			if b.Currency == refund.RefundCurrency {
+				finalSenderBalancesMap[b.Currency] += b.Balance
+			}
+		}
+	}
+	finalBeneficiaryBalancesMap := make(map[string]money.Amount)
+	for _, b := range balances[refund.BeneficiaryID] {
+		if b.Currency == refund.Currency {
+			finalBeneficiaryBalancesMap[b.Currency] = b.Balance - refund.Amount
+		}
+	}
+	if balance, found := finalBeneficiaryBalancesMap[refund.Currency]; !found || balance.IsNegative() {
+		return exception.ErrInsufficientFundsForRefund
+	}
+
+	if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+		log.Printf("failed to create journal entry for refund id %d with error: %v\n", refund.ID, err)
+		return err
+	}
+
+	if toWallet {
+		err = uc.walletRepository.TopUpWalletBalances(ctx, tx, refund.SenderID, transaction.SenderWalletID, finalSenderBalancesMap)
+	} else {
+		err = uc.balanceRepository.UpdateBalances(ctx, tx, refund.SenderID, finalSenderBalancesMap)
+	}
+	if err != nil {
+		log.Printf("failed to refund sender id %d with error: %v\n", refund.SenderID, err)
+		return err
+	}
+
+	if err = uc.balanceRepository.UpdateBalances(ctx, tx, refund.BeneficiaryID, finalBeneficiaryBalancesMap); err != nil {
+		log.Printf("failed to update beneficiary id %d balances with error: %v\n", refund.BeneficiaryID, err)
+		return err
+	}
+
+	reversal.DestinationAmount = refund.RefundAmount
+	if _, err = uc.transactionRepository.InsertTransaction(ctx, tx, refund.BeneficiaryID, reversal); err != nil {
+		log.Printf("failed to create reversal transaction for beneficiary id %d with error: %v\n", refund.BeneficiaryID, err)
+		return err
+	}
+
+	reversal.SourceOfTransfer = transaction.SourceOfTransfer
+	if !toWallet {
+		reversal.SourceOfTransfer = fmt.Sprintf("Main Balance %s", refund.RefundCurrency)
+	}
+	refund.ReversalTransactionID, err = uc.transactionRepository.InsertTransaction(ctx, tx, refund.SenderID, reversal)
+	if err != nil {
+		log.Printf("failed to create reversal transaction for sender id %d with error: %v\n", refund.SenderID, err)
+		return err
+	}
+
+	// keep the rate that was applied for auditing
+	if conversion != nil {
+		conversion.JournalEntryID = entry.ID
+		conversion.TransactionID = refund.ReversalTransactionID
+		if err = uc.fxConversionRepository.CreateFXConversion(ctx, tx, conversion); err != nil {
+			log.Printf("failed to record fx conversion for refund id %d with error: %v\n", refund.ID, err)
+			return err
+		}
+	}
+
+	if err = uc.fees.charge(ctx, tx, fees, entry.ID, refund.ReversalTransactionID, refund.BeneficiaryID); err != nil {
+		log.Printf("failed to record fees for refund id %d with error: %v\n", refund.ID, err)
+		return err
+	}
+
+	refund.Status = constants.COMPLETED
+	if err = uc.transactionRepository.UpdateRefund(ctx, tx, *refund); err != nil {
+		log.Printf("failed to complete refund id %d with error: %v\n", refund.ID, err)
+		return err
+	}
+
+	// both the sender's and the beneficiary's copies of a fully refunded transaction are reversed
+	if fullyRefunded {
+		if err = domain.CheckTransactionStatusTransition(transaction.Status, constants.REVERSED); err != nil {
+			return err
+		}
+		if err = uc.transactionRepository.UpdateTransactionStatusByReference(ctx, tx, transaction.Reference, transaction.Status, constants.REVERSED); err != nil {
+			log.Printf("failed to move transaction reference %s to %s with error: %v\n", transaction.Reference, constants.REVERSED, err)
+			return err
+		}
+		transaction.Status = constants.REVERSED
+	}
+
+	return nil
+}
@@ -0,0 +1,406 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/LeonLow97/go-clean-architecture/utils/ulid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// refundableTransaction is a settled SGD 100.00 transfer from user 1's wallet 1 that delivered USD 75.99 to user 2
+func refundableTransaction(createdAt time.Time) *domain.Transaction {
+	return &domain.Transaction{
+		ID:                  10,
+		SenderID:            1,
+		BeneficiaryID:       2,
+		SourceOfTransfer:    "Savings",
+		SourceAmount:        money.FromInt(100),
+		SourceCurrency:      "SGD",
+		DestinationAmount:   money.MustParse("75.99"),
+		DestinationCurrency: "USD",
+		SenderWalletID:      1,
+		Reference:           "01JBQ4W5X6Y7Z8A9B0C1D2E3F4",
+		Status:              constants.SUCCESS,
+		CreatedAt:           createdAt.Format(time.RFC3339Nano),
+	}
+}
+
+func TestTransactionUsecase_RequestRefund(t *testing.T) {
+	testCases := []struct {
+		Title              string
+		GivenRequest       dto.RefundTransactionRequest
+		GivenTransaction   *domain.Transaction
+		GivenRefunds       []domain.Refund
+		ExpectedAmount     money.Amount
+		ExpectedError      error
+		ExpectInsertRefund bool
+	}{
+		{
+			Title:              "ReturnsSuccessfully_FullRefundByDefault",
+			GivenRequest:       dto.RefundTransactionRequest{RateType: constants.REFUND_RATE_ORIGINAL},
+			GivenTransaction:   refundableTransaction(time.Now()),
+			ExpectedAmount:     money.MustParse("75.99"),
+			ExpectInsertRefund: true,
+		},
+		{
+			Title:            "ReturnsSuccessfully_PartialRefundIgnoresDeclined",
+			GivenRequest:     dto.RefundTransactionRequest{Amount: money.FromInt(40), RateType: constants.REFUND_RATE_CURRENT},
+			GivenTransaction: refundableTransaction(time.Now()),
+			GivenRefunds: []domain.Refund{
+				{Amount: money.FromInt(30), Status: constants.COMPLETED},
+				{Amount: money.FromInt(40), Status: constants.DECLINED},
+			},
+			ExpectedAmount:     money.FromInt(40),
+			ExpectInsertRefund: true,
+		},
+		{
+			Title:            "ReturnsError_AmountExceedsWhatIsLeft",
+			GivenRequest:     dto.RefundTransactionRequest{Amount: money.FromInt(50), RateType: constants.REFUND_RATE_ORIGINAL},
+			GivenTransaction: refundableTransaction(time.Now()),
+			GivenRefunds: []domain.Refund{
+				{Amount: money.FromInt(30), Status: constants.REQUESTED},
+			},
+			ExpectedError: exception.ErrRefundAmountExceeded,
+		},
+		{
+			Title:            "ReturnsError_WindowExpired",
+			GivenRequest:     dto.RefundTransactionRequest{RateType: constants.REFUND_RATE_ORIGINAL},
+			GivenTransaction: refundableTransaction(time.Now().Add(-constants.DEFAULT_REFUND_WINDOW - time.Minute)),
+			ExpectedError:    exception.ErrRefundWindowExpired,
+		},
+		{
+			Title:        "ReturnsError_NotSettled",
+			GivenRequest: dto.RefundTransactionRequest{RateType: constants.REFUND_RATE_ORIGINAL},
+			GivenTransaction: func() *domain.Transaction {
+				transaction := refundableTransaction(time.Now())
+				transaction.Status = constants.REVERSED
+				return transaction
+			}(),
+			ExpectedError: exception.ErrTransactionNotRefundable,
+		},
+		{
+			Title:        "ReturnsError_ReceivedNotSent",
+			GivenRequest: dto.RefundTransactionRequest{RateType: constants.REFUND_RATE_ORIGINAL},
+			GivenTransaction: func() *domain.Transaction {
+				transaction := refundableTransaction(time.Now())
+				transaction.SenderID = 3
+				return transaction
+			}(),
+			ExpectedError: exception.ErrTransactionNotRefundable,
+		},
+		{
+			Title:         "ReturnsError_TransactionNotFound",
+			GivenRequest:  dto.RefundTransactionRequest{RateType: constants.REFUND_RATE_ORIGINAL},
+			ExpectedError: exception.ErrTransactionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			if tc.GivenTransaction != nil {
+				transactionRepo.On("GetTransactionByID", mock.Anything, mock.Anything, 1, 10).Return(tc.GivenTransaction, nil)
+			} else {
+				transactionRepo.On("GetTransactionByID", mock.Anything, mock.Anything, 1, 10).Return(nil, exception.ErrTransactionNotFound)
+			}
+			transactionRepo.On("GetRefundsByTransactionID", mock.Anything, mock.Anything, 10).Return(tc.GivenRefunds, nil)
+			transactionRepo.On("InsertRefund", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			refund, err := transactionUsecase.RequestRefund(context.Background(), 1, 10, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedAmount, refund.Amount)
+				require.Equal(t, "USD", refund.Currency)
+				require.Equal(t, "SGD", refund.RefundCurrency)
+				require.Equal(t, 2, refund.BeneficiaryID)
+				require.Equal(t, constants.REQUESTED, refund.Status)
+			} else {
+				require.Equal(t, tc.ExpectedError, err)
+				require.Nil(t, refund)
+			}
+			if tc.ExpectInsertRefund {
+				transactionRepo.AssertNumberOfCalls(t, "InsertRefund", 1)
+			} else {
+				transactionRepo.AssertNotCalled(t, "InsertRefund", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_RespondToRefund(t *testing.T) {
+	requested := func(amount money.Amount) *domain.Refund {
+		return &domain.Refund{
+			ID:             5,
+			TransactionID:  10,
+			SenderID:       1,
+			BeneficiaryID:  2,
+			Amount:         amount,
+			Currency:       "USD",
+			RefundCurrency: "SGD",
+			RateType:       constants.REFUND_RATE_ORIGINAL,
+			Status:         constants.REQUESTED,
+		}
+	}
+
+	testCases := []struct {
+		Title                        string
+		GivenUserID                  int
+		GivenDecision                string
+		GivenRefund                  *domain.Refund
+		GivenPreviousRefunds         []domain.Refund
+		GivenBeneficiaryBalances     []domain.Balance
+		GivenSenderWalletStatus      string
+		ExpectedStatus               string
+		ExpectedRefundAmount         money.Amount
+		ExpectedSenderWalletBalances map[string]money.Amount
+		ExpectedSenderBalances       map[string]money.Amount
+		ExpectedStatusTransitions    []string
+		ExpectedError                error
+	}{
+		{
+			Title:                        "ReturnsSuccessfully_ApprovePartialAtOriginalRate",
+			GivenUserID:                  2,
+			GivenDecision:                constants.REFUND_APPROVE,
+			GivenRefund:                  requested(money.FromInt(30)),
+			GivenBeneficiaryBalances:     testdata.NewBalances(),
+			ExpectedStatus:               constants.COMPLETED,
+			ExpectedRefundAmount:         money.MustParse("39.47"),
+			ExpectedSenderWalletBalances: map[string]money.Amount{"SGD": money.MustParse("139.47")},
+		},
+		{
+			Title:         "ReturnsSuccessfully_ApproveLastRefundReversesTransaction",
+			GivenUserID:   2,
+			GivenDecision: constants.REFUND_APPROVE,
+			GivenRefund:   requested(money.MustParse("45.99")),
+			GivenPreviousRefunds: []domain.Refund{
+				{Amount: money.FromInt(30), RefundAmount: money.MustParse("39.47"), Status: constants.COMPLETED},
+			},
+			GivenBeneficiaryBalances:     testdata.NewBalances(),
+			ExpectedStatus:               constants.COMPLETED,
+			ExpectedRefundAmount:         money.MustParse("60.53"),
+			ExpectedSenderWalletBalances: map[string]money.Amount{"SGD": money.MustParse("160.53")},
+			ExpectedStatusTransitions:    []string{constants.REVERSED},
+		},
+		{
+			Title:                    "ReturnsSuccessfully_ApproveToClosedWalletCreditsMainBalance",
+			GivenUserID:              2,
+			GivenDecision:            constants.REFUND_APPROVE,
+			GivenRefund:              requested(money.FromInt(30)),
+			GivenBeneficiaryBalances: testdata.NewBalances(),
+			GivenSenderWalletStatus:  domain.WalletStatusClosed,
+			ExpectedStatus:           constants.COMPLETED,
+			ExpectedRefundAmount:     money.MustParse("39.47"),
+			ExpectedSenderBalances:   map[string]money.Amount{"SGD": money.MustParse("239.47")},
+		},
+		{
+			Title:                    "ReturnsSuccessfully_ApproveToArchivedWalletCreditsMainBalance",
+			GivenUserID:              2,
+			GivenDecision:            constants.REFUND_APPROVE,
+			GivenRefund:              requested(money.FromInt(30)),
+			GivenBeneficiaryBalances: testdata.NewBalances(),
+			GivenSenderWalletStatus:  domain.WalletStatusArchived,
+			ExpectedStatus:           constants.COMPLETED,
+			ExpectedRefundAmount:     money.MustParse("39.47"),
+			ExpectedSenderBalances:   map[string]money.Amount{"SGD": money.MustParse("239.47")},
+		},
+		{
+			Title:          "ReturnsSuccessfully_Decline",
+			GivenUserID:    2,
+			GivenDecision:  constants.REFUND_DECLINE,
+			GivenRefund:    requested(money.FromInt(30)),
+			ExpectedStatus: constants.DECLINED,
+		},
+		{
+			Title:         "ReturnsError_NotBeneficiary",
+			GivenUserID:   1,
+			GivenDecision: constants.REFUND_APPROVE,
+			GivenRefund:   requested(money.FromInt(30)),
+			ExpectedError: exception.ErrRefundNotFound,
+		},
+		{
+			Title:         "ReturnsError_AlreadyResolved",
+			GivenUserID:   2,
+			GivenDecision: constants.REFUND_APPROVE,
+			GivenRefund: func() *domain.Refund {
+				refund := requested(money.FromInt(30))
+				refund.Status = constants.DECLINED
+				return refund
+			}(),
+			ExpectedError: exception.ErrRefundAlreadyResolved,
+		},
+		{
+			Title:         "ReturnsError_InsufficientFundsForRefund",
+			GivenUserID:   2,
+			GivenDecision: constants.REFUND_APPROVE,
+			GivenRefund:   requested(money.FromInt(30)),
+			GivenBeneficiaryBalances: []domain.Balance{
+				{Balance: money.FromInt(20), Currency: "USD"},
+			},
+			ExpectedError: exception.ErrInsufficientFundsForRefund,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			walletRepo := new(mocks.WalletRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, walletRepo, balanceRepo, new(mocks.UserRepository), ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("GetRefundByID", mock.Anything, mock.Anything, 5).Return(tc.GivenRefund, nil)
+			transactionRepo.On("GetTransactionByID", mock.Anything, mock.Anything, 1, 10).Return(refundableTransaction(time.Now()), nil)
+			transactionRepo.On("GetRefundsByTransactionID", mock.Anything, mock.Anything, 10).Return(tc.GivenPreviousRefunds, nil)
+			senderWallet := testdata.MockWallet()
+			if tc.GivenSenderWalletStatus != "" {
+				senderWallet.Status = tc.GivenSenderWalletStatus
+			}
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, 1).Return(senderWallet, nil)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, 1, 1).Return(testdata.MockWalletCurrencyAmounts(), nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, 1).Return(testdata.NewBalances(), nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, 2).Return(tc.GivenBeneficiaryBalances, nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, 1, mock.Anything).Return(nil)
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+				return entry.CheckBalanced() == nil
+			})).Return(nil)
+			walletRepo.On("TopUpWalletBalances", mock.Anything, mock.Anything, 1, 1, mock.Anything).Return(nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, 2, mock.Anything).Return(nil)
+			transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, mock.Anything, mock.MatchedBy(func(transaction domain.Transaction) bool {
+				return transaction.OriginalTransactionID == 10
+			})).Return(11, nil)
+			transactionRepo.On("UpdateRefund", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			// the status of the original transaction moves on for both of its copies, found by its reference
+			var transitions []string
+			transactionRepo.On("UpdateTransactionStatusByReference", mock.Anything, mock.Anything, "01JBQ4W5X6Y7Z8A9B0C1D2E3F4", constants.SUCCESS, mock.Anything).
+				Run(func(args mock.Arguments) {
+					transitions = append(transitions, args.String(4))
+				}).
+				Return(nil)
+
+			refund, err := transactionUsecase.RespondToRefund(context.Background(), tc.GivenUserID, 5, tc.GivenDecision)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedStatus, refund.Status)
+				require.Equal(t, tc.ExpectedRefundAmount, refund.RefundAmount)
+			} else {
+				require.Equal(t, tc.ExpectedError, err)
+				require.Nil(t, refund)
+				transactionRepo.AssertNotCalled(t, "UpdateRefund", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tc.ExpectedSenderWalletBalances != nil {
+				walletRepo.AssertCalled(t, "TopUpWalletBalances", mock.Anything, mock.Anything, 1, 1, tc.ExpectedSenderWalletBalances)
+				transactionRepo.AssertNumberOfCalls(t, "InsertTransaction", 2)
+
+				// both copies of the reversal share a reference and the rate the refund was converted at
+				var reversals []domain.Transaction
+				for _, call := range transactionRepo.Calls {
+					if call.Method == "InsertTransaction" {
+						reversals = append(reversals, call.Arguments.Get(3).(domain.Transaction))
+					}
+				}
+				require.True(t, ulid.IsValid(reversals[0].Reference))
+				require.Equal(t, reversals[0].Reference, reversals[1].Reference)
+				require.Equal(t, tc.ExpectedRefundAmount.Ratio(tc.GivenRefund.Amount, money.RoundHalfUp), reversals[1].FXRate)
+			} else {
+				walletRepo.AssertNotCalled(t, "TopUpWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+			// a wallet that no longer takes money in is passed over for the sender's main balance
+			if tc.ExpectedSenderBalances != nil {
+				balanceRepo.AssertCalled(t, "UpdateBalances", mock.Anything, mock.Anything, 1, tc.ExpectedSenderBalances)
+			} else {
+				balanceRepo.AssertNotCalled(t, "UpdateBalances", mock.Anything, mock.Anything, 1, mock.Anything)
+			}
+			require.Equal(t, tc.ExpectedStatusTransitions, transitions)
+		})
+	}
+}
+
+func TestTransactionUsecase_RespondToRefund_Concurrency(t *testing.T) {
+	// user 3 sent user 2 a transfer from a wallet that has since been closed, so the refund locks both users' balances
+	newUsecase := func(txManager *usecaseMocks.TxManager, balanceRepo *mocks.BalanceRepository) domain.TransactionUsecase {
+		transactionRepo := new(mocks.TransactionRepository)
+		walletRepo := new(mocks.WalletRepository)
+		ledgerRepo := new(mocks.LedgerRepository)
+
+		transaction := refundableTransaction(time.Now())
+		transaction.SenderID = 3
+		senderWallet := testdata.MockWallet()
+		senderWallet.Status = domain.WalletStatusClosed
+
+		transactionRepo.On("GetRefundByID", mock.Anything, mock.Anything, 5).Return(&domain.Refund{
+			ID:             5,
+			TransactionID:  10,
+			SenderID:       3,
+			BeneficiaryID:  2,
+			Amount:         money.FromInt(30),
+			Currency:       "USD",
+			RefundCurrency: "SGD",
+			RateType:       constants.REFUND_RATE_ORIGINAL,
+			Status:         constants.REQUESTED,
+		}, nil)
+		transactionRepo.On("GetTransactionByID", mock.Anything, mock.Anything, 3, 10).Return(transaction, nil)
+		transactionRepo.On("GetRefundsByTransactionID", mock.Anything, mock.Anything, 10).Return(nil, nil)
+		walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 3, 1).Return(senderWallet, nil)
+		balanceRepo.On("GetBalances", mock.Anything, mock.Anything, mock.Anything).Return(testdata.NewBalances(), nil)
+		balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(11, nil)
+		transactionRepo.On("UpdateRefund", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+		return usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, walletRepo, balanceRepo, new(mocks.UserRepository), ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+	}
+
+	t.Run("ReturnsSuccessfully_LocksBalancesInUserIDOrder", func(t *testing.T) {
+		txManager := new(usecaseMocks.TxManager)
+		txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+		balanceRepo := new(mocks.BalanceRepository)
+
+		_, err := newUsecase(txManager, balanceRepo).RespondToRefund(context.Background(), 2, 5, constants.REFUND_APPROVE)
+		require.NoError(t, err)
+
+		// the beneficiary has the lower user id, so their balances are locked before the sender's
+		var lockedUserIDs []int
+		for _, call := range balanceRepo.Calls {
+			if call.Method == "GetBalances" {
+				lockedUserIDs = append(lockedUserIDs, call.Arguments.Int(2))
+			}
+		}
+		require.Equal(t, []int{2, 3}, lockedUserIDs)
+	})
+
+	t.Run("ReturnsSuccessfully_RetriesDeadlock", func(t *testing.T) {
+		txManager := new(usecaseMocks.TxManager)
+		txManager.On("WithTx", mock.Anything, mock.Anything).Return(&pgconn.PgError{Code: "40P01"}).Once()
+		txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+
+		refund, err := newUsecase(txManager, new(mocks.BalanceRepository)).RespondToRefund(context.Background(), 2, 5, constants.REFUND_APPROVE)
+		require.NoError(t, err)
+		require.Equal(t, constants.COMPLETED, refund.Status)
+		txManager.AssertNumberOfCalls(t, "WithTx", 2)
+	})
+}
@@ -0,0 +1,250 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/jmoiron/sqlx"
+)
+
+// CreateScheduledTransfer schedules a transfer to a linked beneficiary. The transfer is checked
+// the same way CreateTransaction checks it now, and again when each run is made, since the
+// beneficiary or wallet can change in between. Balances are only looked at when a run is made.
+func (uc *transactionUsecase) CreateScheduledTransfer(ctx context.Context, userID int, req dto.CreateScheduledTransferRequest) (*domain.ScheduledTransfer, error) {
+	if !req.StartAt.After(time.Now()) {
+		return nil, exception.ErrScheduledTransferStartPassed
+	}
+
+	transfer := &domain.ScheduledTransfer{
+		UserID:                       userID,
+		SenderWalletID:               req.SenderWalletID,
+		SourceAmount:                 req.SourceAmount,
+		SourceCurrency:               req.SourceCurrency,
+		BeneficiaryMobileCountryCode: req.BeneficiaryMobileCountryCode,
+		BeneficiaryMobileNumber:      req.BeneficiaryMobileNumber,
+		AutoConvert:                  req.AutoConvert,
+		Frequency:                    req.Frequency,
+		StartAt:                      req.StartAt,
+		NextRunAt:                    req.StartAt,
+		Status:                       domain.ScheduledTransferStatusActive,
+	}
+	// a transfer that runs once has nothing to end
+	if req.Frequency != domain.FrequencyOnce {
+		if req.EndAt != nil && req.EndAt.Before(req.StartAt) {
+			return nil, exception.ErrScheduledTransferEndBeforeStart
+		}
+		transfer.EndAt = req.EndAt
+		transfer.MaxRuns = req.MaxRuns
+	}
+
+	if _, _, err := uc.checkTransfer(ctx, userID, transfer.TransactionRequest()); err != nil {
+		return nil, err
+	}
+
+	if err := uc.transactionRepository.InsertScheduledTransfer(ctx, transfer); err != nil {
+		log.Printf("failed to create scheduled transfer for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (uc *transactionUsecase) GetScheduledTransfers(ctx context.Context, userID int) ([]domain.ScheduledTransfer, error) {
+	transfers, err := uc.transactionRepository.GetScheduledTransfers(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get scheduled transfers for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+func (uc *transactionUsecase) GetScheduledTransferRuns(ctx context.Context, userID, scheduledTransferID int) ([]domain.ScheduledTransferRun, error) {
+	// the transfer is looked up first so that a transfer of another user is not found, rather than found with no runs
+	if _, err := uc.transactionRepository.GetScheduledTransferByID(ctx, nil, userID, scheduledTransferID); err != nil {
+		log.Printf("failed to get scheduled transfer id %d for user id %d with error: %v\n", scheduledTransferID, userID, err)
+		return nil, err
+	}
+
+	runs, err := uc.transactionRepository.GetScheduledTransferRuns(ctx, userID, scheduledTransferID)
+	if err != nil {
+		log.Printf("failed to get runs of scheduled transfer id %d with error: %v\n", scheduledTransferID, err)
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (uc *transactionUsecase) PauseScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	return uc.changeScheduledTransfer(ctx, userID, scheduledTransferID, func(transfer *domain.ScheduledTransfer) error {
+		if transfer.Status != domain.ScheduledTransferStatusActive {
+			return exception.ErrScheduledTransferNotActive
+		}
+		transfer.Status = domain.ScheduledTransferStatusPaused
+		return nil
+	})
+}
+
+// ResumeScheduledTransfer activates a paused transfer from its next run, the runs it missed while
+// paused are not made up for
+func (uc *transactionUsecase) ResumeScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	return uc.changeScheduledTransfer(ctx, userID, scheduledTransferID, func(transfer *domain.ScheduledTransfer) error {
+		if transfer.Status != domain.ScheduledTransferStatusPaused {
+			return exception.ErrScheduledTransferNotPaused
+		}
+		transfer.Resume(time.Now())
+		return nil
+	})
+}
+
+// CancelScheduledTransfer stops a transfer for good, it is kept along with its runs as history
+func (uc *transactionUsecase) CancelScheduledTransfer(ctx context.Context, userID, scheduledTransferID int) (*domain.ScheduledTransfer, error) {
+	return uc.changeScheduledTransfer(ctx, userID, scheduledTransferID, func(transfer *domain.ScheduledTransfer) error {
+		if transfer.Status == domain.ScheduledTransferStatusCancelled || transfer.Status == domain.ScheduledTransferStatusCompleted {
+			return exception.ErrScheduledTransferFinished
+		}
+		transfer.Status = domain.ScheduledTransferStatusCancelled
+		return nil
+	})
+}
+
+// changeScheduledTransfer applies change to a transfer of the user while it is locked, so that a
+// run being claimed at the same time sees either the transfer before the change or after it
+func (uc *transactionUsecase) changeScheduledTransfer(ctx context.Context, userID, scheduledTransferID int, change func(transfer *domain.ScheduledTransfer) error) (*domain.ScheduledTransfer, error) {
+	var transfer *domain.ScheduledTransfer
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		if transfer, err = uc.transactionRepository.GetScheduledTransferByID(ctx, tx, userID, scheduledTransferID); err != nil {
+			log.Printf("failed to get scheduled transfer id %d for user id %d with error: %v\n", scheduledTransferID, userID, err)
+			return err
+		}
+
+		if err = change(transfer); err != nil {
+			return err
+		}
+
+		if err = uc.transactionRepository.UpdateScheduledTransfer(ctx, tx, *transfer); err != nil {
+			log.Printf("failed to update scheduled transfer id %d for user id %d with error: %v\n", scheduledTransferID, userID, err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (uc *transactionUsecase) RunDueScheduledTransfers(ctx context.Context, now time.Time) error {
+	transfers, err := uc.transactionRepository.GetDueScheduledTransfers(ctx, now, uc.scheduledBatchSize)
+	if err != nil {
+		log.Printf("failed to get due scheduled transfers with error: %v\n", err)
+		return err
+	}
+
+	for _, transfer := range transfers {
+		if err := uc.runScheduledTransfer(ctx, transfer.ID, now); err != nil {
+			log.Printf("failed to run scheduled transfer id %d for user id %d with error: %v\n", transfer.ID, transfer.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// runScheduledTransfer makes one run of a due transfer. The run is claimed, its transfer recorded
+// and the transfer moved on to its next run before any money moves, so that a run is never made
+// twice even if the scheduler stops halfway. A run left pending is settled by RecoverScheduledTransferRuns.
+func (uc *transactionUsecase) runScheduledTransfer(ctx context.Context, scheduledTransferID int, now time.Time) error {
+	var transfer *domain.ScheduledTransfer
+	var transaction *domain.Transaction
+	run := &domain.ScheduledTransferRun{Status: domain.ScheduledTransferRunPending}
+
+	err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// a transfer that another scheduler is running, or that was paused or cancelled since, is left alone
+		var err error
+		if transfer, err = uc.transactionRepository.LockDueScheduledTransfer(ctx, tx, scheduledTransferID, now); err != nil {
+			return err
+		}
+
+		// the run points at its transaction from the start, so that it can be told whether it went through
+		if transaction, err = uc.recordTransfer(ctx, tx, transfer.TransactionRequest(), transfer.UserID); err != nil {
+			return err
+		}
+
+		run.ScheduledTransferID = transfer.ID
+		run.UserID = transfer.UserID
+		run.TransactionID = transaction.ID
+		if err = uc.transactionRepository.InsertScheduledTransferRun(ctx, tx, run); err != nil {
+			return err
+		}
+
+		transfer.Advance(now)
+		return uc.transactionRepository.UpdateScheduledTransfer(ctx, tx, *transfer)
+	})
+	if err != nil {
+		if errors.Is(err, exception.ErrScheduledTransferNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// the transfer goes through CreateTransaction's path, so it is checked and recorded like any other
+	run.Status = domain.ScheduledTransferRunSucceeded
+	if transferErr := uc.makeTransfer(ctx, transfer.TransactionRequest(), transfer.UserID, transaction); transferErr != nil {
+		run.Status = domain.ScheduledTransferRunFailed
+		run.FailureReason = transactionFailureReason(transferErr)
+	}
+
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		return uc.transactionRepository.UpdateScheduledTransferRun(ctx, tx, *run)
+	})
+}
+
+func (uc *transactionUsecase) RecoverScheduledTransferRuns(ctx context.Context, now time.Time) error {
+	runs, err := uc.transactionRepository.GetPendingScheduledTransferRuns(ctx, now.Add(-constants.SCHEDULED_TRANSFER_RUN_PENDING_TIMEOUT))
+	if err != nil {
+		log.Printf("failed to get pending scheduled transfer runs with error: %v\n", err)
+		return err
+	}
+
+	for _, run := range runs {
+		if err := uc.recoverScheduledTransferRun(ctx, run.ID); err != nil {
+			log.Printf("failed to recover scheduled transfer run id %d with error: %v\n", run.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// recoverScheduledTransferRun settles a run left pending by the transaction it made, the run
+// succeeded if the transaction went through and failed otherwise
+func (uc *transactionUsecase) recoverScheduledTransferRun(ctx context.Context, runID int) error {
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		run, err := uc.transactionRepository.GetScheduledTransferRunByID(ctx, tx, runID)
+		if err != nil {
+			log.Printf("failed to get scheduled transfer run id %d with error: %v\n", runID, err)
+			return err
+		}
+		// the outcome may have been recorded since the run was picked up
+		if run.Status != domain.ScheduledTransferRunPending {
+			return nil
+		}
+
+		if run.FailureReason, err = uc.settleTransfer(ctx, tx, run.UserID, run.TransactionID); err != nil {
+			return err
+		}
+
+		run.Status = domain.ScheduledTransferRunSucceeded
+		if run.FailureReason != "" {
+			run.Status = domain.ScheduledTransferRunFailed
+		}
+		return uc.transactionRepository.UpdateScheduledTransferRun(ctx, tx, *run)
+	})
+}
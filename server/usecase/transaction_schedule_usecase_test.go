@@ -0,0 +1,365 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionUsecase_CreateScheduledTransfer(t *testing.T) {
+	startAt := time.Now().Add(24 * time.Hour)
+	endAt := startAt.AddDate(0, 3, 0)
+	endBeforeStart := startAt.Add(-time.Hour)
+
+	request := func(frequency string, startAt time.Time, endAt *time.Time) dto.CreateScheduledTransferRequest {
+		return dto.CreateScheduledTransferRequest{
+			SenderWalletID:               1,
+			SourceCurrency:               "SGD",
+			SourceAmount:                 money.FromInt(10),
+			BeneficiaryMobileCountryCode: "+65",
+			BeneficiaryMobileNumber:      "87654321",
+			Frequency:                    frequency,
+			StartAt:                      startAt,
+			EndAt:                        endAt,
+			MaxRuns:                      6,
+		}
+	}
+
+	testCases := []struct {
+		Title              string
+		GivenRequest       dto.CreateScheduledTransferRequest
+		BeneficiaryActive  bool
+		ValidSenderWallet  bool
+		ExpectedEndAt      *time.Time
+		ExpectedMaxRuns    int
+		ExpectedError      error
+		ExpectNotInserting bool
+	}{
+		{
+			Title:             "ReturnsSuccessfully_Recurring",
+			GivenRequest:      request(domain.FrequencyWeekly, startAt, &endAt),
+			BeneficiaryActive: true,
+			ValidSenderWallet: true,
+			ExpectedEndAt:     &endAt,
+			ExpectedMaxRuns:   6,
+		},
+		{
+			Title:             "ReturnsSuccessfully_OnceIgnoresEnd",
+			GivenRequest:      request(domain.FrequencyOnce, startAt, &endBeforeStart),
+			BeneficiaryActive: true,
+			ValidSenderWallet: true,
+		},
+		{
+			Title:              "ReturnsError_StartPassed",
+			GivenRequest:       request(domain.FrequencyWeekly, time.Now().Add(-time.Minute), nil),
+			BeneficiaryActive:  true,
+			ValidSenderWallet:  true,
+			ExpectedError:      exception.ErrScheduledTransferStartPassed,
+			ExpectNotInserting: true,
+		},
+		{
+			Title:              "ReturnsError_EndBeforeStart",
+			GivenRequest:       request(domain.FrequencyDaily, startAt, &endBeforeStart),
+			BeneficiaryActive:  true,
+			ValidSenderWallet:  true,
+			ExpectedError:      exception.ErrScheduledTransferEndBeforeStart,
+			ExpectNotInserting: true,
+		},
+		{
+			Title:              "ReturnsError_BeneficiaryIsInactive",
+			GivenRequest:       request(domain.FrequencyMonthly, startAt, nil),
+			ValidSenderWallet:  true,
+			ExpectedError:      exception.ErrBeneficiaryIsInactive,
+			ExpectNotInserting: true,
+		},
+		{
+			Title:              "ReturnsError_SenderWalletInvalid",
+			GivenRequest:       request(domain.FrequencyMonthly, startAt, nil),
+			BeneficiaryActive:  true,
+			ExpectedError:      exception.ErrSenderWalletInvalid,
+			ExpectNotInserting: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			transactionRepo := new(mocks.TransactionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			transactionRepo.On("CheckLinkageOfSenderAndBeneficiaryByMobileNumber", mock.Anything, 1, "+65", "87654321").
+				Return(2, tc.BeneficiaryActive, true, nil)
+			transactionRepo.On("CheckValidityOfSenderIDAndWalletID", mock.Anything, 1, 1).
+				Return(tc.ValidSenderWallet, "Savings", nil)
+			transactionRepo.On("InsertScheduledTransfer", mock.Anything, mock.Anything).Return(nil)
+
+			transfer, err := transactionUsecase.CreateScheduledTransfer(context.Background(), 1, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, domain.ScheduledTransferStatusActive, transfer.Status)
+				require.True(t, tc.GivenRequest.StartAt.Equal(transfer.NextRunAt))
+				require.Equal(t, tc.ExpectedEndAt, transfer.EndAt)
+				require.Equal(t, tc.ExpectedMaxRuns, transfer.MaxRuns)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				require.Nil(t, transfer)
+			}
+			if tc.ExpectNotInserting {
+				transactionRepo.AssertNotCalled(t, "InsertScheduledTransfer", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_RunDueScheduledTransfers(t *testing.T) {
+	const (
+		senderID      = 1
+		beneficiaryID = 2
+		transactionID = 7
+	)
+	now := time.Date(2026, time.November, 2, 9, 0, 30, 0, time.UTC)
+	startAt := time.Date(2026, time.October, 26, 9, 0, 0, 0, time.UTC)
+
+	due := func(maxRuns int) *domain.ScheduledTransfer {
+		return &domain.ScheduledTransfer{
+			ID:                           3,
+			UserID:                       senderID,
+			SenderWalletID:               1,
+			SourceAmount:                 money.FromInt(10),
+			SourceCurrency:               "SGD",
+			BeneficiaryMobileCountryCode: "+65",
+			BeneficiaryMobileNumber:      "87654321",
+			Frequency:                    domain.FrequencyWeekly,
+			StartAt:                      startAt,
+			MaxRuns:                      maxRuns,
+			RunCount:                     1,
+			NextRunAt:                    startAt.AddDate(0, 0, 7),
+			Status:                       domain.ScheduledTransferStatusActive,
+		}
+	}
+
+	testCases := []struct {
+		Title                 string
+		GivenTransfer         *domain.ScheduledTransfer
+		BeneficiaryActive     bool
+		ExpectedRunStatus     string
+		ExpectedFailureReason string
+		ExpectedStatus        string
+		ExpectedNextRunAt     time.Time
+	}{
+		{
+			Title:             "ReturnsSuccessfully_TransferMade",
+			GivenTransfer:     due(0),
+			BeneficiaryActive: true,
+			ExpectedRunStatus: domain.ScheduledTransferRunSucceeded,
+			ExpectedStatus:    domain.ScheduledTransferStatusActive,
+			ExpectedNextRunAt: startAt.AddDate(0, 0, 14),
+		},
+		{
+			Title:                 "ReturnsSuccessfully_FailedTransferRecorded",
+			GivenTransfer:         due(0),
+			ExpectedRunStatus:     domain.ScheduledTransferRunFailed,
+			ExpectedFailureReason: constants.REASON_BENEFICIARY_INACTIVE,
+			ExpectedStatus:        domain.ScheduledTransferStatusActive,
+			ExpectedNextRunAt:     startAt.AddDate(0, 0, 14),
+		},
+		{
+			Title:             "ReturnsSuccessfully_LastRunCompletes",
+			GivenTransfer:     due(2),
+			BeneficiaryActive: true,
+			ExpectedRunStatus: domain.ScheduledTransferRunSucceeded,
+			ExpectedStatus:    domain.ScheduledTransferStatusCompleted,
+			ExpectedNextRunAt: startAt.AddDate(0, 0, 7),
+		},
+		{
+			Title: "ReturnsSuccessfully_SkipsTransferClaimedElsewhere",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			walletRepo := new(mocks.WalletRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, walletRepo, balanceRepo, new(mocks.UserRepository), ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("GetDueScheduledTransfers", mock.Anything, now, constants.DEFAULT_SCHEDULED_TRANSFER_BATCH_SIZE).
+				Return([]domain.ScheduledTransfer{{ID: 3, UserID: senderID}}, nil)
+			if tc.GivenTransfer != nil {
+				transactionRepo.On("LockDueScheduledTransfer", mock.Anything, mock.Anything, 3, now).Return(tc.GivenTransfer, nil)
+			} else {
+				transactionRepo.On("LockDueScheduledTransfer", mock.Anything, mock.Anything, 3, now).Return(nil, exception.ErrScheduledTransferNotFound)
+			}
+			transactionRepo.On("InsertScheduledTransferRun", mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					// a run points at its transaction from when it is claimed, so that it can be recovered if it stops halfway
+					run := args.Get(2).(*domain.ScheduledTransferRun)
+					require.Equal(t, domain.ScheduledTransferRunPending, run.Status)
+					require.Equal(t, transactionID, run.TransactionID)
+					run.ID = 11
+				}).
+				Return(nil)
+			transactionRepo.On("UpdateScheduledTransfer", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("UpdateScheduledTransferRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			// the transfer itself goes through the same path as CreateTransaction
+			transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, senderID, mock.Anything).Return(transactionID, nil)
+			transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, beneficiaryID, mock.Anything).Return(transactionID+1, nil)
+			transactionRepo.On("UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("CheckLinkageOfSenderAndBeneficiaryByMobileNumber", mock.Anything, senderID, "+65", "87654321").
+				Return(beneficiaryID, tc.BeneficiaryActive, true, nil)
+			transactionRepo.On("CheckValidityOfSenderIDAndWalletID", mock.Anything, senderID, 1).Return(true, "Savings", nil)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, senderID, 1).
+				Return(testdata.MockWalletCurrencyAmounts(), nil)
+			walletRepo.On("CashOutWalletBalances", mock.Anything, mock.Anything, senderID, 1, mock.Anything).Return(nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, beneficiaryID).Return(testdata.NewBalances(), nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, beneficiaryID, mock.Anything).Return(nil)
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			err := transactionUsecase.RunDueScheduledTransfers(context.Background(), now)
+			require.NoError(t, err)
+
+			if tc.GivenTransfer == nil {
+				transactionRepo.AssertNotCalled(t, "InsertScheduledTransferRun", mock.Anything, mock.Anything, mock.Anything)
+				transactionRepo.AssertNotCalled(t, "InsertTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			transactionRepo.AssertCalled(t, "UpdateScheduledTransfer", mock.Anything, mock.Anything, mock.MatchedBy(func(transfer domain.ScheduledTransfer) bool {
+				return transfer.RunCount == 2 && transfer.Status == tc.ExpectedStatus && transfer.NextRunAt.Equal(tc.ExpectedNextRunAt)
+			}))
+			transactionRepo.AssertCalled(t, "UpdateScheduledTransferRun", mock.Anything, mock.Anything, domain.ScheduledTransferRun{
+				ID:                  11,
+				ScheduledTransferID: 3,
+				UserID:              senderID,
+				TransactionID:       transactionID,
+				Status:              tc.ExpectedRunStatus,
+				FailureReason:       tc.ExpectedFailureReason,
+			})
+		})
+	}
+}
+
+func TestTransactionUsecase_RecoverScheduledTransferRuns(t *testing.T) {
+	const (
+		senderID      = 1
+		transactionID = 7
+	)
+
+	testCases := []struct {
+		Title                   string
+		GivenStatus             string
+		GivenTransaction        *domain.Transaction
+		GivenTransactionErr     error
+		ExpectedStatus          string
+		ExpectedFailureReason   string
+		ExpectTransactionFailed bool
+	}{
+		{
+			Title:            "ReturnsSuccessfully_TransferWentThroughSucceedsRun",
+			GivenStatus:      domain.ScheduledTransferRunPending,
+			GivenTransaction: &domain.Transaction{ID: transactionID, Status: constants.SUCCESS},
+			ExpectedStatus:   domain.ScheduledTransferRunSucceeded,
+		},
+		{
+			Title:                   "ReturnsSuccessfully_TransferNeverFinishedFailsItAndTheRun",
+			GivenStatus:             domain.ScheduledTransferRunPending,
+			GivenTransaction:        &domain.Transaction{ID: transactionID, Status: constants.CREATED},
+			ExpectedStatus:          domain.ScheduledTransferRunFailed,
+			ExpectedFailureReason:   constants.REASON_INTERNAL_ERROR,
+			ExpectTransactionFailed: true,
+		},
+		{
+			Title:                 "ReturnsSuccessfully_TransferFailedFailsRun",
+			GivenStatus:           domain.ScheduledTransferRunPending,
+			GivenTransaction:      &domain.Transaction{ID: transactionID, Status: constants.FAILED, FailureReason: constants.REASON_INSUFFICIENT_FUNDS},
+			ExpectedStatus:        domain.ScheduledTransferRunFailed,
+			ExpectedFailureReason: constants.REASON_INSUFFICIENT_FUNDS,
+		},
+		{
+			Title:                 "ReturnsSuccessfully_NoTransactionFailsRun",
+			GivenStatus:           domain.ScheduledTransferRunPending,
+			GivenTransactionErr:   exception.ErrTransactionNotFound,
+			ExpectedStatus:        domain.ScheduledTransferRunFailed,
+			ExpectedFailureReason: constants.REASON_INTERNAL_ERROR,
+		},
+		{
+			Title:       "ReturnsSuccessfully_AlreadySettledIsLeftAlone",
+			GivenStatus: domain.ScheduledTransferRunSucceeded,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			now := time.Now()
+			run := &domain.ScheduledTransferRun{
+				ID:                  11,
+				ScheduledTransferID: 3,
+				UserID:              senderID,
+				TransactionID:       transactionID,
+				Status:              tc.GivenStatus,
+			}
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("GetPendingScheduledTransferRuns", mock.Anything, now.Add(-constants.SCHEDULED_TRANSFER_RUN_PENDING_TIMEOUT)).
+				Return([]domain.ScheduledTransferRun{*run}, nil)
+			transactionRepo.On("GetScheduledTransferRunByID", mock.Anything, mock.Anything, 11).Return(run, nil)
+			transactionRepo.On("GetTransactionByID", mock.Anything, mock.Anything, senderID, transactionID).Return(tc.GivenTransaction, tc.GivenTransactionErr)
+			transactionRepo.On("UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("UpdateScheduledTransferRun", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			err := transactionUsecase.RecoverScheduledTransferRuns(context.Background(), now)
+			require.NoError(t, err)
+
+			if tc.ExpectedStatus == "" {
+				transactionRepo.AssertNotCalled(t, "UpdateScheduledTransferRun", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			transactionRepo.AssertCalled(t, "UpdateScheduledTransferRun", mock.Anything, mock.Anything, mock.MatchedBy(func(run domain.ScheduledTransferRun) bool {
+				return run.Status == tc.ExpectedStatus && run.FailureReason == tc.ExpectedFailureReason && run.TransactionID == transactionID
+			}))
+
+			// a transfer that never finished is failed, so that it cannot still go through once the run has failed
+			if tc.ExpectTransactionFailed {
+				transactionRepo.AssertCalled(t, "UpdateTransaction", mock.Anything, mock.Anything, constants.CREATED, mock.MatchedBy(func(transaction domain.Transaction) bool {
+					return transaction.Status == constants.FAILED
+				}))
+			} else {
+				transactionRepo.AssertNotCalled(t, "UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("ReturnsError_GetPendingScheduledTransferRuns_InternalServerError", func(t *testing.T) {
+		transactionRepo := new(mocks.TransactionRepository)
+		transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+		transactionRepo.On("GetPendingScheduledTransferRuns", mock.Anything, mock.Anything).Return(nil, errors.New("internal server error"))
+
+		err := transactionUsecase.RecoverScheduledTransferRuns(context.Background(), time.Now())
+		require.EqualError(t, err, "internal server error")
+	})
+}
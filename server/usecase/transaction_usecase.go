@@ -2,217 +2,537 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
 	"github.com/LeonLow97/go-clean-architecture/utils"
 	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
+	"github.com/LeonLow97/go-clean-architecture/utils/ulid"
 	"github.com/jmoiron/sqlx"
 )
 
 type transactionUsecase struct {
-	dbConn                *sqlx.DB
-	transactionRepository domain.TransactionRepository
-	walletRepository      domain.WalletRepository
-	balanceRepository     domain.BalanceRepository
-	userRepository        domain.UserRepository
+	txManager              domain.TxManager
+	transactionRepository  domain.TransactionRepository
+	walletRepository       domain.WalletRepository
+	balanceRepository      domain.BalanceRepository
+	userRepository         domain.UserRepository
+	ledgerRepository       domain.LedgerRepository
+	exchangeRateProvider   domain.ExchangeRateProvider
+	fxConversionRepository domain.FXConversionRepository
+	exchangeQuotes         exchangeQuotes
+	currencies             currencyCatalogue
+	fees                   feeSchedules
+	walletExchanges        walletExchanges
+	refundWindow           time.Duration
+	baseCurrency           string
+	scheduledBatchSize     int
+	paymentRequestExpiry   time.Duration
 }
 
-func NewTransactionUsecase(dbConn *sqlx.DB, transactionRepo domain.TransactionRepository, walletRepo domain.WalletRepository, balanceRepo domain.BalanceRepository, userRepo domain.UserRepository) domain.TransactionUsecase {
+func NewTransactionUsecase(cfg infrastructure.Config, txManager domain.TxManager, transactionRepo domain.TransactionRepository, walletRepo domain.WalletRepository, balanceRepo domain.BalanceRepository, userRepo domain.UserRepository, ledgerRepo domain.LedgerRepository, exchangeRateProvider domain.ExchangeRateProvider, exchangeQuoteRepo domain.ExchangeQuoteRepository, currencyRepo domain.CurrencyRepository, fxConversionRepo domain.FXConversionRepository, feeRepo domain.FeeRepository) domain.TransactionUsecase {
+	refundWindow := cfg.Transaction.RefundWindow
+	if refundWindow <= 0 {
+		refundWindow = constants.DEFAULT_REFUND_WINDOW
+	}
+	baseCurrency := cfg.ExchangeRate.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = constants.DEFAULT_EXCHANGE_BASE_CURRENCY
+	}
+	scheduledBatchSize := cfg.Transaction.ScheduledTransferBatchSize
+	if scheduledBatchSize <= 0 {
+		scheduledBatchSize = constants.DEFAULT_SCHEDULED_TRANSFER_BATCH_SIZE
+	}
+	paymentRequestExpiry := cfg.Transaction.PaymentRequestExpiry
+	if paymentRequestExpiry <= 0 {
+		paymentRequestExpiry = constants.DEFAULT_PAYMENT_REQUEST_EXPIRY
+	}
+
 	return &transactionUsecase{
-		dbConn:                dbConn,
-		transactionRepository: transactionRepo,
-		walletRepository:      walletRepo,
-		balanceRepository:     balanceRepo,
-		userRepository:        userRepo,
+		txManager:              txManager,
+		transactionRepository:  transactionRepo,
+		walletRepository:       walletRepo,
+		balanceRepository:      balanceRepo,
+		userRepository:         userRepo,
+		ledgerRepository:       ledgerRepo,
+		exchangeRateProvider:   exchangeRateProvider,
+		fxConversionRepository: fxConversionRepo,
+		exchangeQuotes:         newExchangeQuotes(cfg, exchangeQuoteRepo),
+		currencies:             currencyCatalogue{repository: currencyRepo},
+		fees:                   feeSchedules{repository: feeRepo},
+		walletExchanges:        newWalletExchanges(cfg, exchangeRateProvider, fxConversionRepo, ledgerRepo, walletRepo, currencyRepo, feeRepo),
+		refundWindow:           refundWindow,
+		baseCurrency:           baseCurrency,
+		scheduledBatchSize:     scheduledBatchSize,
+		paymentRequestExpiry:   paymentRequestExpiry,
 	}
 }
 
 func (uc *transactionUsecase) CreateTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID int) error {
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
+	_, err := uc.transfer(ctx, req, userID)
+	return err
+}
+
+// transfer records the attempt and moves the funds. The attempt is returned even when the transfer
+// fails after it was recorded, so that callers can point at the failed transaction.
+func (uc *transactionUsecase) transfer(ctx context.Context, req dto.CreateTransactionRequest, userID int) (*domain.Transaction, error) {
+	// record the attempt in its own sql transaction first so that it is kept even if the transfer fails
+	var transaction *domain.Transaction
+	if err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		var err error
+		transaction, err = uc.recordTransfer(ctx, tx, req, userID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return transaction, uc.makeTransfer(ctx, req, userID, transaction)
+}
+
+// recordTransfer records the attempt at a transfer in tx, before any funds move
+func (uc *transactionUsecase) recordTransfer(ctx context.Context, tx *sqlx.Tx, req dto.CreateTransactionRequest, userID int) (*domain.Transaction, error) {
+	transaction := &domain.Transaction{
+		Reference:      ulid.New(),
+		SenderID:       userID,
+		SourceAmount:   req.SourceAmount,
+		SourceCurrency: req.SourceCurrency,
+		SenderWalletID: req.SenderWalletID,
+		Status:         constants.CREATED,
+	}
+
+	transactionID, err := uc.transactionRepository.InsertTransaction(ctx, tx, userID, *transaction)
+	if err != nil {
+		log.Printf("failed to record transaction attempt for sender id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+	transaction.ID = transactionID
+
+	return transaction, nil
+}
+
+// makeTransfer moves the funds of a recorded attempt, failing the attempt when the transfer fails
+func (uc *transactionUsecase) makeTransfer(ctx context.Context, req dto.CreateTransactionRequest, userID int, transaction *domain.Transaction) error {
+	// the quote is claimed once, outside of the retries, so that a retried transfer does not find it used
+	var quote *domain.ExchangeQuote
+	if req.QuoteID != "" {
+		var err error
+		if quote, err = uc.exchangeQuotes.claim(ctx, userID, req.QuoteID); err != nil {
+			log.Printf("failed to claim exchange quote for sender id %d with error: %v\n", userID, err)
+			uc.failTransaction(ctx, transaction, err)
+			return err
+		}
+		if quote.Product != domain.FeeProductTransfer || quote.FromCurrency != req.SourceCurrency || quote.FromAmount != req.SourceAmount {
+			uc.exchangeQuotes.release(ctx, quote.ID)
+			uc.failTransaction(ctx, transaction, exception.ErrExchangeQuoteMismatch)
+			return exception.ErrExchangeQuoteMismatch
+		}
+	}
+
+	// concurrent transfers touching the same rows can deadlock or fail to serialize, run the whole transfer again when they do
+	err := infrastructure.RetryTx(ctx, constants.MAX_TX_RETRY_ATTEMPTS, func() error {
+		return uc.createTransaction(ctx, req, userID, transaction, quote)
+	})
 	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
+		if quote != nil {
+			uc.exchangeQuotes.release(ctx, quote.ID)
+		}
+		uc.failTransaction(ctx, transaction, err)
 		return err
 	}
 
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
+	return nil
+}
+
+// createTransaction moves the funds. A transfer that needs converting goes through at the rate
+// locked in by quote when there is one, and at the rate currently in effect otherwise.
+func (uc *transactionUsecase) createTransaction(ctx context.Context, req dto.CreateTransactionRequest, userID int, transaction *domain.Transaction, quote *domain.ExchangeQuote) error {
+	// a retried attempt starts over from the status that was committed before the transfer began
+	transaction.Status = constants.CREATED
+
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		beneficiaryID, walletName, err := uc.checkTransfer(ctx, userID, req)
+		if err != nil {
+			return err
+		}
+
+		// retrieve and lock wallet balances for sender, wallet balances are locked before
+		// the beneficiary balances so that concurrent transfers lock rows in the same order
+		senderWalletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, userID, req.SenderWalletID)
+		if err != nil {
+			log.Printf("failed to retrieve wallet balances for user id %d and wallet id %d with error %v\n", userID, req.SenderWalletID, err)
+			return err
+		}
+
+		senderWalletBalancesMap := make(map[string]money.Amount)
+		for _, b := range senderWalletBalances {
+			senderWalletBalancesMap[b.Currency] = b.Amount
+		}
+
+		// check if sender wallet balance is sufficient for transfer, when the sender opted in the
+		// source currency is topped up from the other currencies of the wallet
+		var walletConversions []walletConversion
+		if senderWalletBalancesMap[req.SourceCurrency] < req.SourceAmount {
+			if !req.AutoConvert {
+				return exception.ErrInsufficientFundsInWallet
+			}
+			if walletConversions, err = uc.walletExchanges.cover(ctx, senderWalletBalancesMap, req.SourceCurrency, req.SourceAmount); err != nil {
+				log.Printf("failed to convert wallet currencies into %s for sender id %d with error: %v\n", req.SourceCurrency, userID, err)
+				return err
+			}
+		}
+
+		// retrieve beneficiary balances by beneficiary ID (equivalent to userID for beneficiary)
+		beneficiaryBalances, err := uc.balanceRepository.GetBalances(ctx, tx, beneficiaryID)
+		if err != nil {
+			log.Printf("failed to retrieve balances for beneficiary id %d with error: %v\n", beneficiaryID, err)
+			return err
+		}
+
+		beneficiaryBalancesMap := make(map[string]money.Amount)
+		for _, b := range beneficiaryBalances {
+			beneficiaryBalancesMap[b.Currency] = b.Balance
+		}
+
+		// update balance of sender wallet, along with the currencies converted into the source currency
+		finalSenderWalletBalancesMap := make(map[string]money.Amount)
+		finalSenderWalletBalancesMap[req.SourceCurrency] = senderWalletBalancesMap[req.SourceCurrency] - req.SourceAmount
+		for _, conversion := range walletConversions {
+			finalSenderWalletBalancesMap[conversion.rate.FromCurrency] = senderWalletBalancesMap[conversion.rate.FromCurrency]
+		}
+
+		// update balance of beneficiary
+		var finalDestinationAmount money.Amount
+		var finalDestinationCurrency string
+
+		senderAccount := domain.UserWalletAccount(userID, req.SenderWalletID)
+		beneficiaryAccount := domain.UserBalanceAccount(beneficiaryID)
+		entry := &domain.JournalEntry{Type: domain.JournalTransfer}
+		var conversion *domain.FXConversion
+		var fees []domain.FeeCharge
+
+		if _, found := beneficiaryBalancesMap[req.SourceCurrency]; found {
+			// beneficiary has balance of the same currency as source currency, the fees come out of what they receive.
+			// Nothing is converted so a quote cannot be used here, it is released when the transfer fails.
+			if quote != nil {
+				return exception.ErrExchangeQuoteMismatch
+			}
+			feeRule, err := uc.fees.rule(ctx, domain.FeeProductTransfer, req.SourceCurrency, req.SourceCurrency)
+			if err != nil {
+				log.Printf("failed to get transfer fee rule for %s with error: %v\n", req.SourceCurrency, err)
+				return err
+			}
+			if fees, err = utils.CalculateFees(req.SourceAmount, req.SourceCurrency, 0, feeRule); err != nil {
+				return err
+			}
+			fee := domain.TotalFees(fees)
+
+			beneficiaryBalancesMap[req.SourceCurrency] += req.SourceAmount - fee
+
+			finalDestinationAmount = req.SourceAmount - fee
+			finalDestinationCurrency = req.SourceCurrency
+			transaction.FXRate = money.RateOne
+			transaction.Profit = fee
+
+			entry.Transfer(senderAccount, beneficiaryAccount, req.SourceCurrency, req.SourceAmount-fee).
+				Transfer(senderAccount, domain.AccountRevenue, req.SourceCurrency, fee)
 		} else {
-			err = tx.Commit()
+			homeCurrency, err := uc.currencies.homeCurrency(ctx, req.BeneficiaryMobileCountryCode)
 			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
+				log.Printf("failed to get home currency of mobile country code %s with error: %v\n", req.BeneficiaryMobileCountryCode, err)
+				return err
+			}
+			mainDestinationCurrency := homeCurrency.Code
+			var exchangeRate domain.ExchangeRate
+			var transferAmount money.Amount
+			rateSource := domain.FXRateSourceQuote
+			if quote != nil {
+				if quote.ToCurrency != mainDestinationCurrency {
+					return exception.ErrExchangeQuoteMismatch
+				}
+				exchangeRate = quote.ExchangeRate()
+				fees, transferAmount = quote.Fees, quote.ToAmount
+			} else {
+				currentRate, err := utils.ResolveExchangeRate(ctx, uc.exchangeRateProvider, uc.baseCurrency, req.SourceCurrency, mainDestinationCurrency)
+				if err != nil {
+					log.Printf("failed to get exchange rate from %s to %s with error: %v\n", req.SourceCurrency, mainDestinationCurrency, err)
+					return err
+				}
+				exchangeRate = *currentRate
+				rateSource = exchangeRate.RateSource()
+				feeRule, err := uc.fees.rule(ctx, domain.FeeProductTransfer, req.SourceCurrency, mainDestinationCurrency)
+				if err != nil {
+					log.Printf("failed to get transfer fee rule from %s to %s with error: %v\n", req.SourceCurrency, mainDestinationCurrency, err)
+					return err
+				}
+				if fees, transferAmount, err = utils.CalculateConversionDetails(req.SourceAmount, exchangeRate, feeRule); err != nil {
+					return err
+				}
+			}
+			profit := domain.TotalFees(fees)
+			conversion = domain.NewFXConversion(userID, exchangeRate, rateSource, req.SourceAmount, transferAmount, profit)
+
+			beneficiaryBalancesMap[mainDestinationCurrency] += transferAmount
+
+			finalDestinationAmount = transferAmount
+			finalDestinationCurrency = mainDestinationCurrency
+			transaction.FXRate = exchangeRate.Rate
+			transaction.Profit = profit
+
+			entry.Exchange(senderAccount, beneficiaryAccount, req.SourceCurrency, mainDestinationCurrency, req.SourceAmount, profit, transferAmount)
+		}
+
+		// the transfer has been validated and priced, funds are moved from here on
+		transaction.BeneficiaryID = beneficiaryID
+		transaction.SourceOfTransfer = walletName
+		transaction.DestinationAmount = finalDestinationAmount
+		transaction.DestinationCurrency = finalDestinationCurrency
+		if err = uc.transitionTransaction(ctx, tx, transaction, constants.PENDING, ""); err != nil {
+			log.Printf("failed to move transaction id %d to %s with error: %v\n", transaction.ID, constants.PENDING, err)
+			return err
+		}
+
+		// the conversions inside the sender wallet are recorded ahead of the transfer they pay for
+		for _, conversion := range walletConversions {
+			if err = uc.walletExchanges.record(ctx, tx, userID, req.SenderWalletID, transaction.ID, conversion); err != nil {
+				log.Printf("failed to record wallet exchange for transaction id %d with error: %v\n", transaction.ID, err)
+				return err
 			}
 		}
-	}()
 
+		// record the transfer in the ledger before updating the cached balances
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for transfer from sender id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// keep the rate that was applied for auditing
+		if conversion != nil {
+			conversion.JournalEntryID = entry.ID
+			conversion.TransactionID = transaction.ID
+			if err = uc.fxConversionRepository.CreateFXConversion(ctx, tx, conversion); err != nil {
+				log.Printf("failed to record fx conversion for transaction id %d with error: %v\n", transaction.ID, err)
+				return err
+			}
+		}
+
+		if err = uc.fees.charge(ctx, tx, fees, entry.ID, transaction.ID, userID); err != nil {
+			log.Printf("failed to record fees for transaction id %d with error: %v\n", transaction.ID, err)
+			return err
+		}
+
+		// update sender wallet balances, a conversion can leave some of the source currency in a
+		// wallet that did not hold it before so its balance is created then
+		if len(walletConversions) > 0 {
+			err = uc.walletRepository.TopUpWalletBalances(ctx, tx, userID, req.SenderWalletID, finalSenderWalletBalancesMap)
+		} else {
+			err = uc.walletRepository.CashOutWalletBalances(ctx, tx, userID, req.SenderWalletID, finalSenderWalletBalancesMap)
+		}
+		if err != nil {
+			log.Printf("failed to cash out wallet balances for sender id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// update beneficiary balances
+		if err = uc.balanceRepository.UpdateBalances(ctx, tx, beneficiaryID, beneficiaryBalancesMap); err != nil {
+			log.Printf("failed to update beneficiary id %d balances with error: %v\n", beneficiaryID, err)
+			return err
+		}
+
+		if err = uc.transitionTransaction(ctx, tx, transaction, constants.SUCCESS, ""); err != nil {
+			log.Printf("failed to move transaction id %d to %s with error: %v\n", transaction.ID, constants.SUCCESS, err)
+			return err
+		}
+
+		// create transaction for beneficiary, their copy is only written once the transfer has settled
+		// and shares the reference of the sender's copy
+		beneficiaryTransaction := *transaction
+		beneficiaryTransaction.SourceOfTransfer = fmt.Sprintf("Main Balance %s", finalDestinationCurrency)
+		if _, err = uc.transactionRepository.InsertTransaction(ctx, tx, beneficiaryID, beneficiaryTransaction); err != nil {
+			log.Printf("failed to create transaction for beneficiary ID %d with error: %v\n", beneficiaryID, err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// checkTransfer runs the checks on who a transfer is between and what it is sent in, before any
+// balance is looked at. It returns the beneficiary and the name of the sender wallet.
+func (uc *transactionUsecase) checkTransfer(ctx context.Context, userID int, req dto.CreateTransactionRequest) (int, string, error) {
 	// check if sender id is linked to beneficiary id
 	beneficiaryID, isBeneficiaryActive, isMFAConfigured, err := uc.transactionRepository.CheckLinkageOfSenderAndBeneficiaryByMobileNumber(ctx, userID, req.BeneficiaryMobileCountryCode, req.BeneficiaryMobileNumber)
 	if err != nil {
 		// if not linked, error will be thrown here
 		log.Println("failed to check linkage of sender and beneficiary", err)
-		return err
+		return 0, "", err
 	}
 	if !isBeneficiaryActive {
-		err = exception.ErrBeneficiaryIsInactive
-		return err
+		return 0, "", exception.ErrBeneficiaryIsInactive
 	}
 	if !isMFAConfigured {
-		err = exception.ErrBeneficiaryMFANotConfigured
-		return err
+		return 0, "", exception.ErrBeneficiaryMFANotConfigured
 	}
 
 	// check if sender id is equal to beneficiary id
 	if userID == beneficiaryID {
 		log.Println("sender id cannot be equal to beneficiary id when performing transaction")
-		err = exception.ErrUserIDEqualBeneficiaryID
-		return err
+		return 0, "", exception.ErrUserIDEqualBeneficiaryID
 	}
 
 	// check if sender wallet id is linked to user id
 	isValidSenderWallet, walletName, err := uc.transactionRepository.CheckValidityOfSenderIDAndWalletID(ctx, userID, req.SenderWalletID)
 	if err != nil {
 		log.Printf("failed to validate sender wallet id %d with error: %v\n", req.SenderWalletID, err)
-		return err
+		return 0, "", err
 	}
 	if !isValidSenderWallet {
 		log.Println("sender wallet is invalid, forbid request")
-		err = exception.ErrSenderWalletInvalid
-		return err
+		return 0, "", exception.ErrSenderWalletInvalid
 	}
 
-	// retrieve wallet details and wallet balances for sender
-	senderWalletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, nil, userID, req.SenderWalletID)
-	if err != nil {
-		log.Printf("failed to retrieve wallet balances for user id %d and wallet id %d with error %v\n", userID, req.SenderWalletID, err)
-		return err
+	// money can only move in currencies that are in the catalogue and enabled
+	if _, err = uc.currencies.currency(ctx, req.SourceCurrency); err != nil {
+		log.Printf("source currency %s is not allowed for transfer with error: %v\n", req.SourceCurrency, err)
+		return 0, "", err
 	}
 
-	// TODO: allow internal transfer in same wallet
-	// Using a map to allow internal transfer in the wallet if the source currency does not have enough funds
-	senderWalletBalancesMap := make(map[string]float64)
-	for _, b := range senderWalletBalances {
-		senderWalletBalancesMap[b.Currency] = b.Amount
-	}
+	return beneficiaryID, walletName, nil
+}
 
-	// TODO: allow internal transfer in same wallet
-	// check if sender wallet balance if sufficient for transfer
-	if senderWalletBalancesMap[req.SourceCurrency] < req.SourceAmount {
-		err = exception.ErrInsufficientFundsInWallet
+// transitionTransaction moves a transaction to the next status of its lifecycle
+func (uc *transactionUsecase) transitionTransaction(ctx context.Context, tx *sqlx.Tx, transaction *domain.Transaction, status, failureReason string) error {
+	if err := domain.CheckTransactionStatusTransition(transaction.Status, status); err != nil {
 		return err
 	}
 
-	// retrieve beneficiary balances by beneficiary ID (equivalent to userID for beneficiary)
-	beneficiaryBalances, err := uc.balanceRepository.GetBalances(ctx, tx, beneficiaryID)
-	if err != nil {
-		log.Printf("failed to retrieve balances for beneficiary id %d with error: %v\n", beneficiaryID, err)
+	fromStatus := transaction.Status
+	transaction.Status = status
+	transaction.FailureReason = failureReason
+	if err := uc.transactionRepository.UpdateTransaction(ctx, tx, fromStatus, *transaction); err != nil {
+		transaction.Status = fromStatus
 		return err
 	}
 
-	beneficiaryBalancesMap := make(map[string]float64)
-	for _, b := range beneficiaryBalances {
-		beneficiaryBalancesMap[b.Currency] = b.Balance
-	}
-
-	// update balance of sender wallet
-	finalSenderWalletBalancesMap := make(map[string]float64)
-	finalSenderWalletBalancesMap[req.SourceCurrency] = senderWalletBalancesMap[req.SourceCurrency] - req.SourceAmount
-
-	// update balance of beneficiary
-	var finalDestinationAmount float64
-	var finalDestinationCurrency string
-
-	if _, found := beneficiaryBalancesMap[req.SourceCurrency]; found {
-		// beneficiary has balance of the same currency as source currency
-		beneficiaryBalancesMap[req.SourceCurrency] += req.SourceAmount
-
-		finalDestinationAmount = req.SourceAmount
-		finalDestinationCurrency = req.SourceCurrency
-	} else {
-		mainDestinationCurrency := constants.CountryCodeToCurrencyMap[req.BeneficiaryMobileCountryCode]
-		profit, transferAmount := utils.CalculateConversionDetails(req.SourceAmount, req.SourceCurrency, mainDestinationCurrency)
-
-		beneficiaryBalancesMap[mainDestinationCurrency] += transferAmount
+	return nil
+}
 
-		finalDestinationAmount = transferAmount
-		finalDestinationCurrency = mainDestinationCurrency
+// failTransaction records why a transfer failed. The transfer was rolled back, so
+// the attempt is still CREATED in the database whatever status it reached before.
+func (uc *transactionUsecase) failTransaction(ctx context.Context, transaction *domain.Transaction, cause error) {
+	transaction.Status = constants.CREATED
 
-		if err = uc.balanceRepository.LogCreatorProfit(ctx, tx, profit, req.SourceCurrency); err != nil {
-			log.Printf("failed to log creator profit with error %v\n", err)
-			return err
-		}
+	reason := transactionFailureReason(cause)
+	if err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		return uc.transitionTransaction(ctx, tx, transaction, constants.FAILED, reason)
+	}); err != nil {
+		log.Printf("failed to record failure %s for transaction id %d with error: %v\n", reason, transaction.ID, err)
 	}
+}
 
-	// update sender wallet balances
-	if err = uc.walletRepository.CashOutWalletBalances(ctx, tx, userID, req.SenderWalletID, finalSenderWalletBalancesMap); err != nil {
-		log.Printf("failed to cash out wallet balances for sender id %d with error: %v\n", userID, err)
-		return err
+// settleTransfer works out how a transfer that was recorded but whose outcome never was has ended,
+// returning the reason it failed or "" if the funds moved. A transfer that never finished is failed
+// while its transaction is locked, so that a transfer still making it waits and then cannot go through.
+func (uc *transactionUsecase) settleTransfer(ctx context.Context, tx *sqlx.Tx, userID, transactionID int) (string, error) {
+	transaction, err := uc.transactionRepository.GetTransactionByID(ctx, tx, userID, transactionID)
+	if err != nil {
+		// no transaction was recorded, so no funds moved for it
+		if errors.Is(err, exception.ErrTransactionNotFound) {
+			return constants.REASON_INTERNAL_ERROR, nil
+		}
+		log.Printf("failed to get transaction id %d for user id %d with error: %v\n", transactionID, userID, err)
+		return "", err
 	}
 
-	// update beneficiary balances
-	if err = uc.balanceRepository.UpdateBalances(ctx, tx, beneficiaryID, beneficiaryBalancesMap); err != nil {
-		log.Printf("failed to update beneficiary id %d balances with error: %v\n", beneficiaryID, err)
-		return err
+	switch transaction.Status {
+	case constants.CREATED:
+		if err = uc.transitionTransaction(ctx, tx, transaction, constants.FAILED, constants.REASON_INTERNAL_ERROR); err != nil {
+			log.Printf("failed to fail transaction id %d with error: %v\n", transactionID, err)
+			return "", err
+		}
+		return constants.REASON_INTERNAL_ERROR, nil
+	case constants.FAILED:
+		return transaction.FailureReason, nil
+	default:
+		return "", nil
 	}
+}
 
-	// create transaction for sender wallet
-	sender, err := uc.userRepository.GetUserByID(ctx, userID)
-	if err != nil {
-		log.Printf("failed to retrieve sender by user id %d with error: %v\n", userID, err)
-		return err
-	}
+// transactionFailureReasons maps the errors a transfer can fail with to the reason code stored against it
+var transactionFailureReasons = map[error]string{
+	exception.ErrUserNotLinkedToBeneficiary:  constants.REASON_BENEFICIARY_NOT_LINKED,
+	exception.ErrBeneficiaryIsInactive:       constants.REASON_BENEFICIARY_INACTIVE,
+	exception.ErrBeneficiaryMFANotConfigured: constants.REASON_BENEFICIARY_MFA_NOT_SET_UP,
+	exception.ErrUserIDEqualBeneficiaryID:    constants.REASON_SENDER_IS_BENEFICIARY,
+	exception.ErrSenderWalletInvalid:         constants.REASON_SENDER_WALLET_INVALID,
+	exception.ErrInsufficientFundsInWallet:   constants.REASON_INSUFFICIENT_FUNDS,
+	exception.ErrExchangeRateNotFound:        constants.REASON_EXCHANGE_RATE_NOT_FOUND,
+	exception.ErrExchangeQuoteInvalid:        constants.REASON_EXCHANGE_QUOTE_INVALID,
+	exception.ErrExchangeQuoteExpired:        constants.REASON_EXCHANGE_QUOTE_EXPIRED,
+	exception.ErrExchangeQuoteUsed:           constants.REASON_EXCHANGE_QUOTE_USED,
+	exception.ErrExchangeQuoteMismatch:       constants.REASON_EXCHANGE_QUOTE_MISMATCH,
+	exception.ErrCurrencyNotSupported:        constants.REASON_CURRENCY_NOT_SUPPORTED,
+	exception.ErrCountryCurrencyNotFound:     constants.REASON_CURRENCY_NOT_SUPPORTED,
+	exception.ErrCurrencyDisabled:            constants.REASON_CURRENCY_DISABLED,
+	exception.ErrFeesExceedAmount:            constants.REASON_FEES_EXCEED_AMOUNT,
+	exception.ErrAmountTooLarge:              constants.REASON_AMOUNT_TOO_LARGE,
+}
 
-	// transaction entity
-	transactionEntity := &domain.Transaction{
-		SenderID:                userID,
-		BeneficiaryID:           beneficiaryID,
-		SenderMobileNumber:      fmt.Sprintf("%s %s", sender.MobileCountryCode, sender.MobileNumber),
-		BeneficiaryMobileNumber: fmt.Sprintf("%s %s", req.BeneficiaryMobileCountryCode, req.BeneficiaryMobileNumber),
-		SourceAmount:            req.SourceAmount,
-		SourceCurrency:          req.SourceCurrency,
-		DestinationAmount:       finalDestinationAmount,
-		DestinationCurrency:     finalDestinationCurrency,
-		Status:                  constants.SUCCESS,
+func transactionFailureReason(err error) string {
+	for target, reason := range transactionFailureReasons {
+		if errors.Is(err, target) {
+			return reason
+		}
 	}
+	return constants.REASON_INTERNAL_ERROR
+}
 
-	// create transaction for sender
-	transactionEntity.SourceOfTransfer = walletName
-	if err = uc.transactionRepository.InsertTransaction(ctx, tx, userID, *transactionEntity); err != nil {
-		log.Printf("failed to create transaction for sender ID %d with error: %v\n", userID, err)
-		return err
+func (uc *transactionUsecase) GetTransactions(ctx context.Context, userID int, filter dto.TransactionFilter, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
+	if paginator.Keyset() {
+		// the cursor only holds created_at and id, so it cannot continue a list ordered by amount
+		if filter.Sort == constants.SORT_AMOUNT_DESC || filter.Sort == constants.SORT_AMOUNT_ASC {
+			return nil, exception.ErrCursorSortNotSupported
+		}
+	} else {
+		// the count uses the same filter so that the pagination headers describe the filtered history
+		if err := uc.transactionRepository.GetTotalTransactionsCount(ctx, userID, filter, paginator); err != nil {
+			log.Printf("failed to get total transaction count for user id %d with error: %v\n", userID, err)
+			return nil, err
+		}
 	}
 
-	// create transaction for beneficiary
-	transactionEntity.SourceOfTransfer = fmt.Sprintf("Main Balance %s", finalDestinationCurrency)
-	if err = uc.transactionRepository.InsertTransaction(ctx, tx, beneficiaryID, *transactionEntity); err != nil {
-		log.Printf("failed to create transaction for beneficiary ID %d with error: %v\n", beneficiaryID, err)
-		return err
+	transactions, err := uc.transactionRepository.GetTransactions(ctx, userID, filter, paginator)
+	if err != nil {
+		log.Printf("failed to get transactions for user id %d with error: %v\n", userID, err)
+		return nil, err
 	}
 
-	return nil
+	return transactions, nil
 }
 
-func (uc *transactionUsecase) GetTransactions(ctx context.Context, userID int, paginator *pagination.Paginator) (*[]domain.Transaction, error) {
-	if err := uc.transactionRepository.GetTotalTransactionsCount(ctx, userID, paginator); err != nil {
-		log.Printf("failed to get total transaction count for user id %d with error: %v\n", userID, err)
+func (uc *transactionUsecase) GetTransaction(ctx context.Context, userID int, reference string) (*domain.Transaction, error) {
+	transaction, err := uc.transactionRepository.GetTransactionByReference(ctx, userID, reference)
+	if err != nil {
+		log.Printf("failed to get transaction %s for user id %d with error: %v\n", reference, userID, err)
 		return nil, err
 	}
 
-	transactions, err := uc.transactionRepository.GetTransactions(ctx, userID, paginator)
-	if err != nil {
-		log.Printf("failed to get transactions for user id %d with error: %v\n", userID, err)
+	// transfers in a single currency have no conversion to show
+	conversion, err := uc.fxConversionRepository.GetFXConversionByTransactionID(ctx, transaction.ID)
+	switch {
+	case err == nil:
+		transaction.FXConversion = conversion
+	case !errors.Is(err, exception.ErrFXConversionNotFound):
+		log.Printf("failed to get fx conversion of transaction id %d with error: %v\n", transaction.ID, err)
 		return nil, err
 	}
 
-	return transactions, nil
+	return transaction, nil
 }
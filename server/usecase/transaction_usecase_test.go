@@ -0,0 +1,453 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
+	"github.com/LeonLow97/go-clean-architecture/utils/ulid"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionUsecase_CreateTransaction(t *testing.T) {
+	const (
+		senderID      = 1
+		beneficiaryID = 2
+		transactionID = 7
+	)
+
+	// a transfer of SGD 150 from a wallet holding SGD 100 and USD 50 converts just enough USD to cover it
+	autoConvertRate := testdata.MockExchangeRate("USD", "SGD")
+	autoConvertRule := testdata.MockFeeRule(domain.FeeProductExchange)
+	autoConvertFromAmount, err := utils.CalculateFromAmount(money.FromInt(50), autoConvertRate, autoConvertRule)
+	require.NoError(t, err)
+	_, autoConvertToAmount, err := utils.CalculateConversionDetails(autoConvertFromAmount, autoConvertRate, autoConvertRule)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Title                          string
+		GivenRequest                   dto.CreateTransactionRequest
+		BeneficiaryActive              bool
+		ExpectedError                  error
+		ExpectedStatusTransitions      []string
+		ExpectedFailureReason          string
+		ExpectBeneficiaryRecording     bool
+		ExpectedSenderWalletBalanceMap map[string]money.Amount
+	}{
+		{
+			Title: "ReturnsSuccessfully",
+			GivenRequest: dto.CreateTransactionRequest{
+				SenderWalletID:               1,
+				SourceCurrency:               "SGD",
+				SourceAmount:                 money.FromInt(10),
+				BeneficiaryMobileCountryCode: "+65",
+				BeneficiaryMobileNumber:      "87654321",
+			},
+			BeneficiaryActive:          true,
+			ExpectedStatusTransitions:  []string{constants.PENDING, constants.SUCCESS},
+			ExpectBeneficiaryRecording: true,
+		},
+		{
+			Title: "ReturnsError_BeneficiaryIsInactive_RecordsFailure",
+			GivenRequest: dto.CreateTransactionRequest{
+				SenderWalletID:               1,
+				SourceCurrency:               "SGD",
+				SourceAmount:                 money.FromInt(10),
+				BeneficiaryMobileCountryCode: "+65",
+				BeneficiaryMobileNumber:      "87654321",
+			},
+			BeneficiaryActive:         false,
+			ExpectedError:             exception.ErrBeneficiaryIsInactive,
+			ExpectedStatusTransitions: []string{constants.FAILED},
+			ExpectedFailureReason:     constants.REASON_BENEFICIARY_INACTIVE,
+		},
+		{
+			Title: "ReturnsError_InsufficientFundsInWallet_RecordsFailure",
+			GivenRequest: dto.CreateTransactionRequest{
+				SenderWalletID:               1,
+				SourceCurrency:               "SGD",
+				SourceAmount:                 money.FromInt(1000),
+				BeneficiaryMobileCountryCode: "+65",
+				BeneficiaryMobileNumber:      "87654321",
+			},
+			BeneficiaryActive:         true,
+			ExpectedError:             exception.ErrInsufficientFundsInWallet,
+			ExpectedStatusTransitions: []string{constants.FAILED},
+			ExpectedFailureReason:     constants.REASON_INSUFFICIENT_FUNDS,
+		},
+		{
+			Title: "ReturnsSuccessfully_AutoConvertsShortCurrency",
+			GivenRequest: dto.CreateTransactionRequest{
+				SenderWalletID:               1,
+				SourceCurrency:               "SGD",
+				SourceAmount:                 money.FromInt(150),
+				BeneficiaryMobileCountryCode: "+65",
+				BeneficiaryMobileNumber:      "87654321",
+				AutoConvert:                  true,
+			},
+			BeneficiaryActive:          true,
+			ExpectedStatusTransitions:  []string{constants.PENDING, constants.SUCCESS},
+			ExpectBeneficiaryRecording: true,
+			ExpectedSenderWalletBalanceMap: map[string]money.Amount{
+				"SGD": autoConvertToAmount - money.FromInt(50),
+				"USD": money.FromInt(50) - autoConvertFromAmount,
+			},
+		},
+		{
+			Title: "ReturnsError_AutoConvertInsufficientFundsInWallet_RecordsFailure",
+			GivenRequest: dto.CreateTransactionRequest{
+				SenderWalletID:               1,
+				SourceCurrency:               "SGD",
+				SourceAmount:                 money.FromInt(1000),
+				BeneficiaryMobileCountryCode: "+65",
+				BeneficiaryMobileNumber:      "87654321",
+				AutoConvert:                  true,
+			},
+			BeneficiaryActive:         true,
+			ExpectedError:             exception.ErrInsufficientFundsInWallet,
+			ExpectedStatusTransitions: []string{constants.FAILED},
+			ExpectedFailureReason:     constants.REASON_INSUFFICIENT_FUNDS,
+		},
+		{
+			Title: "ReturnsError_CurrencyNotSupported_RecordsFailure",
+			GivenRequest: dto.CreateTransactionRequest{
+				SenderWalletID:               1,
+				SourceCurrency:               "JPY",
+				SourceAmount:                 money.FromInt(10),
+				BeneficiaryMobileCountryCode: "+65",
+				BeneficiaryMobileNumber:      "87654321",
+			},
+			BeneficiaryActive:         true,
+			ExpectedError:             exception.ErrCurrencyNotSupported,
+			ExpectedStatusTransitions: []string{constants.FAILED},
+			ExpectedFailureReason:     constants.REASON_CURRENCY_NOT_SUPPORTED,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			walletRepo := new(mocks.WalletRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			userRepo := new(mocks.UserRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+			fxConversionRepo := newFXConversionRepository()
+
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, walletRepo, balanceRepo, userRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), fxConversionRepo, newFeeRepository())
+			require.NotNil(t, transactionUsecase)
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+
+			// the attempt is recorded for the sender before anything else happens
+			transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, senderID, mock.MatchedBy(func(transaction domain.Transaction) bool {
+				return transaction.Status == constants.CREATED
+			})).Return(transactionID, nil).Once()
+			transactionRepo.On("CheckLinkageOfSenderAndBeneficiaryByMobileNumber", mock.Anything, senderID, mock.Anything, mock.Anything).
+				Return(beneficiaryID, tc.BeneficiaryActive, true, nil)
+			transactionRepo.On("CheckValidityOfSenderIDAndWalletID", mock.Anything, senderID, tc.GivenRequest.SenderWalletID).
+				Return(true, "Savings", nil)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, senderID, tc.GivenRequest.SenderWalletID).
+				Return(testdata.MockWalletCurrencyAmounts(), nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, beneficiaryID).
+				Return(testdata.NewBalances(), nil)
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			walletRepo.On("CashOutWalletBalances", mock.Anything, mock.Anything, senderID, tc.GivenRequest.SenderWalletID, mock.Anything).Return(nil)
+			walletRepo.On("TopUpWalletBalances", mock.Anything, mock.Anything, senderID, tc.GivenRequest.SenderWalletID, mock.Anything).Return(nil)
+			walletRepo.On("CreateWalletHistory", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, beneficiaryID, mock.Anything).Return(nil)
+			if tc.ExpectBeneficiaryRecording {
+				transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, beneficiaryID, mock.MatchedBy(func(transaction domain.Transaction) bool {
+					return transaction.Status == constants.SUCCESS
+				})).Return(transactionID+1, nil).Once()
+			}
+
+			var transitions []string
+			transactionRepo.On("UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) {
+					transaction := args.Get(3).(domain.Transaction)
+					require.Equal(t, transactionID, transaction.ID)
+					require.Equal(t, tc.ExpectedFailureReason, transaction.FailureReason)
+					transitions = append(transitions, transaction.Status)
+				}).
+				Return(nil)
+
+			err := transactionUsecase.CreateTransaction(context.Background(), tc.GivenRequest, senderID)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.True(t, errors.Is(err, tc.ExpectedError))
+			}
+			require.Equal(t, tc.ExpectedStatusTransitions, transitions)
+			if tc.ExpectBeneficiaryRecording {
+				transactionRepo.AssertNumberOfCalls(t, "InsertTransaction", 2)
+			} else {
+				transactionRepo.AssertNumberOfCalls(t, "InsertTransaction", 1)
+			}
+
+			// both copies of the transfer are recorded under the same reference
+			var references []string
+			for _, call := range transactionRepo.Calls {
+				if call.Method == "InsertTransaction" {
+					transaction := call.Arguments.Get(3).(domain.Transaction)
+					require.True(t, ulid.IsValid(transaction.Reference), transaction.Reference)
+					references = append(references, transaction.Reference)
+				}
+			}
+			for _, reference := range references {
+				require.Equal(t, references[0], reference)
+			}
+			if tc.ExpectBeneficiaryRecording {
+				beneficiaryTransaction := transactionRepo.Calls[len(transactionRepo.Calls)-1].Arguments.Get(3).(domain.Transaction)
+				require.Equal(t, money.RateOne, beneficiaryTransaction.FXRate)
+				require.True(t, beneficiaryTransaction.Profit.IsZero())
+			}
+
+			// the short currency is converted inside the wallet, and the conversion is audited against the transfer
+			if tc.ExpectedSenderWalletBalanceMap != nil {
+				walletRepo.AssertCalled(t, "TopUpWalletBalances", mock.Anything, mock.Anything, senderID, tc.GivenRequest.SenderWalletID, tc.ExpectedSenderWalletBalanceMap)
+				walletRepo.AssertNotCalled(t, "CashOutWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				fxConversionRepo.AssertCalled(t, "CreateFXConversion", mock.Anything, mock.Anything, mock.MatchedBy(func(conversion *domain.FXConversion) bool {
+					return conversion.TransactionID == transactionID && conversion.FromCurrency == "USD" && conversion.FromAmount == autoConvertFromAmount
+				}))
+				ledgerRepo.AssertCalled(t, "CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+					return entry.Type == domain.JournalWalletExchange && entry.CheckBalanced() == nil
+				}))
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_GetTransactions(t *testing.T) {
+	filter := dto.TransactionFilter{
+		Currency:  "SGD",
+		Direction: constants.DIRECTION_SENT,
+		Status:    constants.SUCCESS,
+		MinAmount: money.FromInt(10),
+		Sort:      constants.SORT_AMOUNT_DESC,
+	}
+
+	testCases := []struct {
+		Title                             string
+		GivenCursor                       *string
+		CountReturnValue                  error
+		TransactionRepositoryReturnValues []interface{}
+		ExpectedError                     error
+	}{
+		{
+			Title:                             "ReturnsSuccessfully",
+			TransactionRepositoryReturnValues: []interface{}{&[]domain.Transaction{{ID: 1}}, nil},
+		},
+		{
+			Title:                             "ReturnsError_NoTransactionsFound",
+			TransactionRepositoryReturnValues: []interface{}{nil, exception.ErrNoTransactionsFound},
+			ExpectedError:                     exception.ErrNoTransactionsFound,
+		},
+		{
+			Title:            "ReturnsError_CountFailed",
+			CountReturnValue: errors.New("count failed"),
+			ExpectedError:    errors.New("count failed"),
+		},
+		{
+			Title:         "ReturnsError_CursorSortedByAmount",
+			GivenCursor:   new(string),
+			ExpectedError: exception.ErrCursorSortNotSupported,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			transactionRepo := new(mocks.TransactionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			paginator := &pagination.Paginator{Page: 1, PageSize: 10, Cursor: tc.GivenCursor}
+
+			// the count and the page must be filtered the same way for the pagination headers to be right
+			transactionRepo.On("GetTotalTransactionsCount", mock.Anything, 1, filter, paginator).Return(tc.CountReturnValue)
+			transactionRepo.On("GetTransactions", mock.Anything, 1, filter, paginator).Return(tc.TransactionRepositoryReturnValues...)
+
+			transactions, err := transactionUsecase.GetTransactions(context.Background(), 1, filter, paginator)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Len(t, *transactions, 1)
+			} else {
+				require.Equal(t, tc.ExpectedError, err)
+				require.Nil(t, transactions)
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_GetTransaction(t *testing.T) {
+	const reference = "01JAB3W0YQ5V9T7X2NKH4M8RZC"
+
+	conversion := &domain.FXConversion{ID: 3, TransactionID: 2, FromCurrency: "SGD", ToCurrency: "USD", MidRate: money.MustParseRate("0.76"), RateSource: domain.FXRateSourceDirect}
+
+	testCases := []struct {
+		Title                              string
+		TransactionRepositoryReturnValues  []interface{}
+		FXConversionRepositoryReturnValues []interface{}
+		ExpectedTransaction                *domain.Transaction
+		ExpectedError                      error
+	}{
+		{
+			Title:                              "ReturnsSuccessfully",
+			TransactionRepositoryReturnValues:  []interface{}{&domain.Transaction{ID: 1, Reference: reference, Status: constants.FAILED, FailureReason: constants.REASON_INSUFFICIENT_FUNDS}, nil},
+			FXConversionRepositoryReturnValues: []interface{}{nil, exception.ErrFXConversionNotFound},
+			ExpectedTransaction:                &domain.Transaction{ID: 1, Reference: reference, Status: constants.FAILED, FailureReason: constants.REASON_INSUFFICIENT_FUNDS},
+		},
+		{
+			Title:                              "ReturnsSuccessfully_WithFXConversion",
+			TransactionRepositoryReturnValues:  []interface{}{&domain.Transaction{ID: 2, Reference: reference, Status: constants.SUCCESS}, nil},
+			FXConversionRepositoryReturnValues: []interface{}{conversion, nil},
+			ExpectedTransaction:                &domain.Transaction{ID: 2, Reference: reference, Status: constants.SUCCESS, FXConversion: conversion},
+		},
+		{
+			Title:                              "ReturnsError_FXConversion",
+			TransactionRepositoryReturnValues:  []interface{}{&domain.Transaction{ID: 2, Reference: reference, Status: constants.SUCCESS}, nil},
+			FXConversionRepositoryReturnValues: []interface{}{nil, errors.New("db down")},
+			ExpectedError:                      errors.New("db down"),
+		},
+		{
+			Title:                             "ReturnsError_TransactionNotFound",
+			TransactionRepositoryReturnValues: []interface{}{nil, exception.ErrTransactionNotFound},
+			ExpectedError:                     exception.ErrTransactionNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			transactionRepo := new(mocks.TransactionRepository)
+			fxConversionRepo := new(mocks.FXConversionRepository)
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), transactionRepo, new(mocks.WalletRepository), new(mocks.BalanceRepository), new(mocks.UserRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), new(mocks.ExchangeQuoteRepository), newCurrencyRepository(), fxConversionRepo, newFeeRepository())
+
+			transactionRepo.On("GetTransactionByReference", mock.Anything, 1, reference).Return(tc.TransactionRepositoryReturnValues...)
+			fxConversionRepo.On("GetFXConversionByTransactionID", mock.Anything, mock.Anything).Return(tc.FXConversionRepositoryReturnValues...)
+
+			transaction, err := transactionUsecase.GetTransaction(context.Background(), 1, reference)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedTransaction, transaction)
+			} else {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				require.Nil(t, transaction)
+			}
+		})
+	}
+}
+
+func TestTransactionUsecase_CreateTransaction_WithQuote(t *testing.T) {
+	const (
+		senderID      = 1
+		beneficiaryID = 2
+		transactionID = 7
+	)
+
+	testCases := []struct {
+		Title                    string
+		GivenBeneficiaryBalances []domain.Balance
+		ExpectedReleased         bool
+		ExpectedError            error
+	}{
+		{
+			Title:                    "ReturnsSuccessfully",
+			GivenBeneficiaryBalances: []domain.Balance{{Balance: money.FromInt(100), Currency: "USD", UserID: beneficiaryID}},
+		},
+		{
+			Title:                    "ReturnsError_ExchangeQuoteMismatch_BeneficiaryHoldsSourceCurrency",
+			GivenBeneficiaryBalances: testdata.NewBalances(),
+			ExpectedReleased:         true,
+			ExpectedError:            exception.ErrExchangeQuoteMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			transactionRepo := new(mocks.TransactionRepository)
+			walletRepo := new(mocks.WalletRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+			exchangeQuoteRepo := new(mocks.ExchangeQuoteRepository)
+
+			// quote a transfer from SGD into the USD a beneficiary registered with +1 receives
+			var quote domain.ExchangeQuote
+			exchangeQuoteRepo.On("CreateExchangeQuote", mock.Anything, mock.Anything, mock.Anything).
+				Run(func(args mock.Arguments) { quote = args.Get(1).(domain.ExchangeQuote) }).
+				Return(nil)
+			balanceUsecase := usecase.NewBalanceUsecase(infrastructure.Config{}, txManager, new(mocks.UserRepository), balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), exchangeQuoteRepo, newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+			_, err := balanceUsecase.PreviewExchange(context.Background(), senderID, dto.PreviewExchangeRequest{
+				ActionType:   "amountToSend",
+				FromAmount:   money.FromInt(10),
+				FromCurrency: "SGD",
+				ToCurrency:   "USD",
+				Product:      domain.FeeProductTransfer,
+			})
+			require.NoError(t, err)
+
+			transactionUsecase := usecase.NewTransactionUsecase(infrastructure.Config{}, txManager, transactionRepo, walletRepo, balanceRepo, new(mocks.UserRepository), ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), exchangeQuoteRepo, newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			exchangeQuoteRepo.On("GetExchangeQuote", mock.Anything, quote.ID).Return(&quote, nil)
+			exchangeQuoteRepo.On("ClaimExchangeQuote", mock.Anything, quote.ID, mock.Anything).Return(nil)
+			exchangeQuoteRepo.On("ReleaseExchangeQuote", mock.Anything, quote.ID).Return(nil)
+
+			transactionRepo.On("InsertTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(transactionID, nil)
+			transactionRepo.On("UpdateTransaction", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			transactionRepo.On("CheckLinkageOfSenderAndBeneficiaryByMobileNumber", mock.Anything, senderID, "+1", "5551234").
+				Return(beneficiaryID, true, true, nil)
+			transactionRepo.On("CheckValidityOfSenderIDAndWalletID", mock.Anything, senderID, 1).Return(true, "Savings", nil)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, senderID, 1).
+				Return(testdata.MockWalletCurrencyAmounts(), nil)
+			walletRepo.On("CashOutWalletBalances", mock.Anything, mock.Anything, senderID, 1, mock.Anything).Return(nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, beneficiaryID).Return(tc.GivenBeneficiaryBalances, nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, beneficiaryID, mock.Anything).Return(nil)
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			err = transactionUsecase.CreateTransaction(context.Background(), dto.CreateTransactionRequest{
+				SenderWalletID:               1,
+				SourceCurrency:               "SGD",
+				SourceAmount:                 money.FromInt(10),
+				BeneficiaryMobileCountryCode: "+1",
+				BeneficiaryMobileNumber:      "5551234",
+				QuoteID:                      quote.ID,
+			}, senderID)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				balanceRepo.AssertCalled(t, "UpdateBalances", mock.Anything, mock.Anything, beneficiaryID, map[string]money.Amount{
+					"USD": money.FromInt(100) + quote.ToAmount,
+				})
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				balanceRepo.AssertNotCalled(t, "UpdateBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+
+			// a quote that was claimed but not used can be used again
+			if tc.ExpectedReleased {
+				exchangeQuoteRepo.AssertCalled(t, "ReleaseExchangeQuote", mock.Anything, quote.ID)
+			} else {
+				exchangeQuoteRepo.AssertNotCalled(t, "ReleaseExchangeQuote", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/jmoiron/sqlx"
+)
+
+type txManager struct {
+	dbConn *sqlx.DB
+}
+
+func NewTxManager(dbConn *sqlx.DB) domain.TxManager {
+	return &txManager{
+		dbConn: dbConn,
+	}
+}
+
+type txContextKey struct{}
+
+// txState is carried in the context of a running transaction so that nested
+// calls to WithTx can find it and open savepoints on it
+type txState struct {
+	tx         *sqlx.Tx
+	savepoints int
+}
+
+func (m *txManager) WithTx(ctx context.Context, fn func(ctx context.Context, tx *sqlx.Tx) error) (err error) {
+	if state, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return m.withSavepoint(ctx, state, fn)
+	}
+
+	tx, err := m.dbConn.BeginTxx(ctx, nil)
+	if err != nil {
+		log.Printf("failed to begin sql transaction with error: %v\n", err)
+		return err
+	}
+
+	// Rollback on panic so that the connection is returned to the pool, then let the panic continue
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	txCtx := context.WithValue(ctx, txContextKey{}, &txState{tx: tx})
+	if err = fn(txCtx, tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			log.Printf("failed to rollback sql transaction with error: %v\n", rbErr)
+		}
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		log.Printf("failed to commit sql transaction with error: %v\n", err)
+		return err
+	}
+
+	return nil
+}
+
+// withSavepoint runs fn inside a savepoint of an already running transaction. If fn
+// fails only its own work is undone and the outer transaction can still commit.
+func (m *txManager) withSavepoint(ctx context.Context, state *txState, fn func(ctx context.Context, tx *sqlx.Tx) error) error {
+	state.savepoints++
+	savepoint := fmt.Sprintf("sp_%d", state.savepoints)
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		log.Printf("failed to create savepoint %s with error: %v\n", savepoint, err)
+		return err
+	}
+
+	if err := fn(ctx, state.tx); err != nil {
+		if _, rbErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rbErr != nil {
+			log.Printf("failed to rollback to savepoint %s with error: %v\n", savepoint, rbErr)
+		}
+		return err
+	}
+
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		log.Printf("failed to release savepoint %s with error: %v\n", savepoint, err)
+		return err
+	}
+
+	return nil
+}
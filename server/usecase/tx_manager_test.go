@@ -0,0 +1,171 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxManager_WithTx(t *testing.T) {
+	testCases := []struct {
+		Title         string
+		Fn            func(ctx context.Context, txManager domain.TxManager) error
+		Expect        func(mock sqlmock.Sqlmock)
+		ExpectedError error
+	}{
+		{
+			Title: "ReturnsSuccessfully",
+			Fn: func(ctx context.Context, txManager domain.TxManager) error {
+				return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+					_, err := tx.ExecContext(ctx, "UPDATE balances")
+					return err
+				})
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE balances").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Title: "ReturnsError_BeginTx",
+			Fn: func(ctx context.Context, txManager domain.TxManager) error {
+				return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+					return nil
+				})
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin().WillReturnError(errors.New("connection refused"))
+			},
+			ExpectedError: errors.New("connection refused"),
+		},
+		{
+			Title: "ReturnsError_FnRollsBack",
+			Fn: func(ctx context.Context, txManager domain.TxManager) error {
+				return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+					return errors.New("insufficient funds")
+				})
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			ExpectedError: errors.New("insufficient funds"),
+		},
+		{
+			Title: "ReturnsError_Commit",
+			Fn: func(ctx context.Context, txManager domain.TxManager) error {
+				return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+					return nil
+				})
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit().WillReturnError(errors.New("could not serialize access"))
+			},
+			ExpectedError: errors.New("could not serialize access"),
+		},
+		{
+			Title: "ReturnsSuccessfully_NestedReleasesSavepoint",
+			Fn: func(ctx context.Context, txManager domain.TxManager) error {
+				return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+					return txManager.WithTx(ctx, func(ctx context.Context, nestedTx *sqlx.Tx) error {
+						if nestedTx != tx {
+							return errors.New("nested call started a new transaction")
+						}
+						return nil
+					})
+				})
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Title: "ReturnsSuccessfully_NestedErrorRollsBackToSavepoint",
+			Fn: func(ctx context.Context, txManager domain.TxManager) error {
+				return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+					err := txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+						return errors.New("optional step failed")
+					})
+					if err == nil {
+						return errors.New("expected nested error")
+					}
+					// the outer transaction carries on and commits
+					return nil
+				})
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			Title: "ReturnsError_NestedErrorRollsBackOuter",
+			Fn: func(ctx context.Context, txManager domain.TxManager) error {
+				return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+					return txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+						return errors.New("insufficient funds")
+					})
+				})
+			},
+			Expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			ExpectedError: errors.New("insufficient funds"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer db.Close()
+
+			txManager := usecase.NewTxManager(sqlx.NewDb(db, "sqlmock"))
+			tc.Expect(mock)
+
+			err = tc.Fn(context.Background(), txManager)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				require.Equal(t, tc.ExpectedError.Error(), err.Error())
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestTxManager_WithTx_RollsBackOnPanic(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	txManager := usecase.NewTxManager(sqlx.NewDb(db, "sqlmock"))
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	require.Panics(t, func() {
+		txManager.WithTx(context.Background(), func(ctx context.Context, tx *sqlx.Tx) error {
+			panic("unexpected")
+		})
+	})
+	require.NoError(t, mock.ExpectationsWereMet())
+}
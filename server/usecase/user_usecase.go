@@ -32,15 +32,17 @@ type userUsecase struct {
 	smtpClient     infrastructure.SMTPClient
 	userRepository domain.UserRepository
 	totpInstance   *infrastructure.TOTPMultiFactor
+	currencies     currencyCatalogue
 }
 
-func NewUserUsecase(cfg infrastructure.Config, userRepository domain.UserRepository, redisClient infrastructure.RedisClient, smtpClient infrastructure.SMTPClient, totpInstance *infrastructure.TOTPMultiFactor) domain.UserUsecase {
+func NewUserUsecase(cfg infrastructure.Config, userRepository domain.UserRepository, redisClient infrastructure.RedisClient, smtpClient infrastructure.SMTPClient, totpInstance *infrastructure.TOTPMultiFactor, currencyRepository domain.CurrencyRepository) domain.UserUsecase {
 	return &userUsecase{
 		cfg:            cfg,
 		redisClient:    redisClient,
 		smtpClient:     smtpClient,
 		userRepository: userRepository,
 		totpInstance:   totpInstance,
+		currencies:     currencyCatalogue{repository: currencyRepository},
 	}
 }
 
@@ -74,12 +76,18 @@ func (uc *userUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.Lo
 		LastName:          user.LastName,
 		Email:             user.Email,
 		Username:          user.Username,
-		SourceCurrency:    constants.CountryCodeToCurrencyMap[user.MobileCountryCode],
 		MobileCountryCode: user.MobileCountryCode,
 		MobileNumber:      user.MobileNumber,
 		IsMFAConfigured:   user.IsMFAConfigured,
 	}
 
+	// a user whose home currency is unavailable can still log in, they just cannot move money in it
+	if homeCurrency, err := uc.currencies.homeCurrency(ctx, user.MobileCountryCode); err != nil {
+		log.Printf("failed to get home currency of mobile country code %s with error: %v\n", user.MobileCountryCode, err)
+	} else {
+		resp.SourceCurrency = homeCurrency.Code
+	}
+
 	// if mfa is not configured, add the secret and url
 	if !user.IsMFAConfigured {
 		key, _, err := uc.totpInstance.GenerateTOTP(ctx, user.ID, user.Email)
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// walletExchanges converts between the currencies held in one wallet, priced the same way as an
+// exchange between main balances. It is shared by the wallet exchange operation and by transfers
+// that top up their source currency from the rest of the wallet.
+type walletExchanges struct {
+	exchangeRateProvider   domain.ExchangeRateProvider
+	fxConversionRepository domain.FXConversionRepository
+	ledgerRepository       domain.LedgerRepository
+	walletRepository       domain.WalletRepository
+	currencies             currencyCatalogue
+	fees                   feeSchedules
+	baseCurrency           string
+}
+
+func newWalletExchanges(cfg infrastructure.Config, exchangeRateProvider domain.ExchangeRateProvider, fxConversionRepository domain.FXConversionRepository, ledgerRepository domain.LedgerRepository, walletRepository domain.WalletRepository, currencyRepository domain.CurrencyRepository, feeRepository domain.FeeRepository) walletExchanges {
+	baseCurrency := cfg.ExchangeRate.BaseCurrency
+	if baseCurrency == "" {
+		baseCurrency = constants.DEFAULT_EXCHANGE_BASE_CURRENCY
+	}
+
+	return walletExchanges{
+		exchangeRateProvider:   exchangeRateProvider,
+		fxConversionRepository: fxConversionRepository,
+		ledgerRepository:       ledgerRepository,
+		walletRepository:       walletRepository,
+		currencies:             currencyCatalogue{repository: currencyRepository},
+		fees:                   feeSchedules{repository: feeRepository},
+		baseCurrency:           baseCurrency,
+	}
+}
+
+// walletConversion is a priced conversion of fromAmount into toAmount inside a wallet
+type walletConversion struct {
+	rate       domain.ExchangeRate
+	fromAmount money.Amount
+	toAmount   money.Amount
+	fees       []domain.FeeCharge
+}
+
+// pricing returns the rate and the exchange fee rule in effect from fromCurrency to toCurrency
+func (w walletExchanges) pricing(ctx context.Context, fromCurrency, toCurrency string) (*domain.ExchangeRate, *domain.FeeRule, error) {
+	exchangeRate, err := utils.ResolveExchangeRate(ctx, w.exchangeRateProvider, w.baseCurrency, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	feeRule, err := w.fees.rule(ctx, domain.FeeProductExchange, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, nil, err
+	}
+	return exchangeRate, feeRule, nil
+}
+
+// price works out what fromAmount of fromCurrency converts into
+func (w walletExchanges) price(ctx context.Context, fromCurrency, toCurrency string, fromAmount money.Amount) (*walletConversion, error) {
+	exchangeRate, feeRule, err := w.pricing(ctx, fromCurrency, toCurrency)
+	if err != nil {
+		return nil, err
+	}
+	fees, toAmount, err := utils.CalculateConversionDetails(fromAmount, *exchangeRate, feeRule)
+	if err != nil {
+		return nil, err
+	}
+	return &walletConversion{rate: *exchangeRate, fromAmount: fromAmount, toAmount: toAmount, fees: fees}, nil
+}
+
+// cover converts the other currencies of a wallet into currency until the wallet holds at least
+// amount of it. Currencies are used in alphabetical order, a currency that is disabled or has no
+// route to currency is left alone. balances is updated with what the conversions leave behind.
+func (w walletExchanges) cover(ctx context.Context, balances map[string]money.Amount, currency string, amount money.Amount) ([]walletConversion, error) {
+	shortfall := amount - balances[currency]
+	if !shortfall.IsPositive() {
+		return nil, nil
+	}
+
+	fromCurrencies := make([]string, 0, len(balances))
+	for fromCurrency, balance := range balances {
+		if fromCurrency != currency && balance.IsPositive() {
+			fromCurrencies = append(fromCurrencies, fromCurrency)
+		}
+	}
+	sort.Strings(fromCurrencies)
+
+	var conversions []walletConversion
+	for _, fromCurrency := range fromCurrencies {
+		if _, err := w.currencies.currency(ctx, fromCurrency); err != nil {
+			if errors.Is(err, exception.ErrCurrencyDisabled) || errors.Is(err, exception.ErrCurrencyNotSupported) {
+				continue
+			}
+			return nil, err
+		}
+
+		exchangeRate, feeRule, err := w.pricing(ctx, fromCurrency, currency)
+		if err != nil {
+			if errors.Is(err, exception.ErrExchangeRateNotFound) {
+				continue
+			}
+			return nil, err
+		}
+
+		// convert just enough to cover what is missing, or all of it when that is not enough
+		fromAmount, err := utils.CalculateFromAmount(shortfall, *exchangeRate, feeRule)
+		if err != nil {
+			return nil, err
+		}
+		if fromAmount > balances[fromCurrency] {
+			fromAmount = balances[fromCurrency]
+		}
+		fees, toAmount, err := utils.CalculateConversionDetails(fromAmount, *exchangeRate, feeRule)
+		if errors.Is(err, exception.ErrFeesExceedAmount) {
+			// too little left in this currency to pay for its conversion
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !toAmount.IsPositive() {
+			continue
+		}
+
+		conversions = append(conversions, walletConversion{rate: *exchangeRate, fromAmount: fromAmount, toAmount: toAmount, fees: fees})
+		balances[fromCurrency] -= fromAmount
+		balances[currency] += toAmount
+		shortfall -= toAmount
+		if !shortfall.IsPositive() {
+			return conversions, nil
+		}
+	}
+
+	return nil, exception.ErrInsufficientFundsInWallet
+}
+
+// record writes a conversion to the ledger with its fx audit record, fees and wallet history. The
+// wallet balances themselves are left to the caller. transactionID is zero outside of a transfer.
+func (w walletExchanges) record(ctx context.Context, tx *sqlx.Tx, userID, walletID, transactionID int, conversion walletConversion) error {
+	fromCurrency, toCurrency := conversion.rate.FromCurrency, conversion.rate.ToCurrency
+	profit := domain.TotalFees(conversion.fees)
+
+	// the fees stay in fromCurrency as revenue
+	account := domain.UserWalletAccount(userID, walletID)
+	entry := &domain.JournalEntry{Type: domain.JournalWalletExchange, Reference: fmt.Sprintf("wallet:%d", walletID)}
+	entry.Exchange(account, account, fromCurrency, toCurrency, conversion.fromAmount, profit, conversion.toAmount)
+	if err := w.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+		log.Printf("failed to create journal entry for wallet exchange for user id %d with error: %v\n", userID, err)
+		return err
+	}
+
+	// keep the rate that was applied for auditing
+	fxConversion := domain.NewFXConversion(userID, conversion.rate, conversion.rate.RateSource(), conversion.fromAmount, conversion.toAmount, profit)
+	fxConversion.JournalEntryID = entry.ID
+	fxConversion.TransactionID = transactionID
+	if err := w.fxConversionRepository.CreateFXConversion(ctx, tx, fxConversion); err != nil {
+		log.Printf("failed to record fx conversion for wallet exchange for user id %d with error: %v\n", userID, err)
+		return err
+	}
+
+	if err := w.fees.charge(ctx, tx, conversion.fees, entry.ID, transactionID, userID); err != nil {
+		log.Printf("failed to record wallet exchange fees for user id %d with error: %v\n", userID, err)
+		return err
+	}
+
+	history := []domain.WalletHistory{
+		{WalletID: walletID, UserID: userID, Type: domain.WalletHistoryExchangeOut, JournalEntryID: entry.ID, Currency: fromCurrency, Amount: conversion.fromAmount},
+		{WalletID: walletID, UserID: userID, Type: domain.WalletHistoryExchangeIn, JournalEntryID: entry.ID, Currency: toCurrency, Amount: conversion.toAmount},
+	}
+	if err := w.walletRepository.CreateWalletHistory(ctx, tx, history); err != nil {
+		log.Printf("failed to create wallet history for wallet exchange for user id %d with error: %v\n", userID, err)
+		return err
+	}
+
+	return nil
+}
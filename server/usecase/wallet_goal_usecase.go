@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/dto"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/jmoiron/sqlx"
+)
+
+// goalDeadlineLayout is the format of a goal deadline, a date without a time
+const goalDeadlineLayout = "2006-01-02"
+
+// SetWalletGoal sets the savings goal of a wallet, replacing the goal it had
+func (uc *walletUsecase) SetWalletGoal(ctx context.Context, userID, walletID int, req dto.SetWalletGoalRequest) (*domain.WalletGoal, error) {
+	wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, nil, userID, walletID)
+	if err != nil {
+		log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+	if wallet.Status == domain.WalletStatusClosed {
+		return nil, exception.ErrWalletClosed
+	}
+
+	if _, err = uc.exchanges.currencies.currency(ctx, req.Currency); err != nil {
+		log.Printf("user %d cannot set a wallet goal in currency %s with error: %v\n", userID, req.Currency, err)
+		return nil, err
+	}
+
+	goal := &domain.WalletGoal{
+		WalletID:     walletID,
+		UserID:       userID,
+		TargetAmount: req.TargetAmount,
+		Currency:     req.Currency,
+	}
+	if req.Deadline != "" {
+		// dates in the same layout compare in the same order as strings
+		if req.Deadline < time.Now().UTC().Format(goalDeadlineLayout) {
+			return nil, exception.ErrWalletGoalDeadlinePassed
+		}
+		goal.Deadline = &req.Deadline
+	}
+
+	if err = uc.walletRepository.UpsertWalletGoal(ctx, goal); err != nil {
+		log.Printf("failed to set goal of wallet id %d for user id %d with error: %v\n", walletID, userID, err)
+		return nil, err
+	}
+
+	// a wallet that has never been topped up has nothing saved yet
+	walletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, nil, userID, walletID)
+	if err != nil && !errors.Is(err, exception.ErrWalletBalancesNotFound) {
+		log.Printf("failed to get wallet balances for user id %d and wallet id %d with error: %v\n", userID, walletID, err)
+		return nil, err
+	}
+
+	goal.Progress = uc.goalProgress(ctx, *goal, walletBalances)
+
+	return goal, nil
+}
+
+func (uc *walletUsecase) DeleteWalletGoal(ctx context.Context, userID, walletID int) error {
+	if err := uc.walletRepository.DeleteWalletGoal(ctx, userID, walletID); err != nil {
+		log.Printf("failed to delete goal of wallet id %d for user id %d with error: %v\n", walletID, userID, err)
+		return err
+	}
+
+	return nil
+}
+
+// goalProgress works out how far balances are towards goal. Currencies other than the goal
+// currency count for what they would convert into once fees are taken, and currencies that
+// cannot be priced in the goal currency, for whatever reason, are left out. A goal is only
+// there to inform the user, so it never fails the wallet operation it is worked out for.
+func (uc *walletUsecase) goalProgress(ctx context.Context, goal domain.WalletGoal, balances []domain.WalletCurrencyAmount) *domain.WalletGoalProgress {
+	var saved money.Amount
+	for _, b := range balances {
+		if !b.Amount.IsPositive() {
+			continue
+		}
+		if b.Currency == goal.Currency {
+			saved += b.Amount
+			continue
+		}
+
+		conversion, err := uc.exchanges.price(ctx, b.Currency, goal.Currency, b.Amount)
+		if err != nil {
+			if !errors.Is(err, exception.ErrExchangeRateNotFound) && !errors.Is(err, exception.ErrFeesExceedAmount) {
+				log.Printf("failed to convert %s into %s for goal id %d, leaving it out with error: %v\n", b.Currency, goal.Currency, goal.ID, err)
+			}
+			continue
+		}
+		saved += conversion.toAmount
+	}
+
+	progress := &domain.WalletGoalProgress{SavedAmount: saved}
+	if saved >= goal.TargetAmount {
+		progress.Percentage = 100 * money.RateOne
+		progress.Reached = true
+		return progress
+	}
+
+	progress.RemainingAmount = goal.TargetAmount - saved
+	progress.Percentage = 100 * saved.Ratio(goal.TargetAmount, money.RoundDown)
+	return progress
+}
+
+// completeWalletGoal completes the goal of a wallet once balances reach it, recording the event
+// in the caller's transaction. A goal is only completed once, until it is set again.
+func (uc *walletUsecase) completeWalletGoal(ctx context.Context, tx *sqlx.Tx, userID, walletID int, balances []domain.WalletCurrencyAmount) error {
+	goal, err := uc.walletRepository.GetWalletGoal(ctx, tx, userID, walletID)
+	if err != nil {
+		if errors.Is(err, exception.ErrWalletGoalNotFound) {
+			return nil
+		}
+		log.Printf("failed to get goal of wallet id %d for user id %d with error: %v\n", walletID, userID, err)
+		return err
+	}
+	if goal.CompletedAt != nil {
+		return nil
+	}
+
+	progress := uc.goalProgress(ctx, *goal, balances)
+	if !progress.Reached {
+		return nil
+	}
+
+	if err = uc.walletRepository.CompleteWalletGoal(ctx, tx, goal.ID); err != nil {
+		log.Printf("failed to complete goal id %d for user id %d with error: %v\n", goal.ID, userID, err)
+		return err
+	}
+
+	event := &domain.WalletGoalEvent{
+		GoalID:      goal.ID,
+		WalletID:    walletID,
+		UserID:      userID,
+		Type:        domain.WalletGoalEventCompleted,
+		Currency:    goal.Currency,
+		SavedAmount: progress.SavedAmount,
+	}
+	if err = uc.walletRepository.CreateWalletGoalEvent(ctx, tx, event); err != nil {
+		log.Printf("failed to create completed event of goal id %d for user id %d with error: %v\n", goal.ID, userID, err)
+		return err
+	}
+
+	return nil
+}
@@ -2,31 +2,49 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"strings"
 
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/jmoiron/sqlx"
 )
 
 type walletUsecase struct {
-	dbConn            *sqlx.DB
+	txManager         domain.TxManager
 	walletRepository  domain.WalletRepository
 	balanceRepository domain.BalanceRepository
+	ledgerRepository  domain.LedgerRepository
+	exchanges         walletExchanges
+	maxWallets        int
 }
 
-func NewWalletUsecase(dbConn *sqlx.DB, walletRepository domain.WalletRepository, balanceRepository domain.BalanceRepository) domain.WalletUsecase {
+func NewWalletUsecase(cfg infrastructure.Config, txManager domain.TxManager, walletRepository domain.WalletRepository, balanceRepository domain.BalanceRepository, ledgerRepository domain.LedgerRepository, exchangeRateProvider domain.ExchangeRateProvider, currencyRepository domain.CurrencyRepository, fxConversionRepository domain.FXConversionRepository, feeRepository domain.FeeRepository) domain.WalletUsecase {
+	maxWallets := cfg.Wallet.MaxWalletsPerUser
+	if maxWallets <= 0 {
+		maxWallets = constants.DEFAULT_MAX_WALLETS_PER_USER
+	}
+
 	return &walletUsecase{
-		dbConn:            dbConn,
+		txManager:         txManager,
 		walletRepository:  walletRepository,
 		balanceRepository: balanceRepository,
+		ledgerRepository:  ledgerRepository,
+		exchanges:         newWalletExchanges(cfg, exchangeRateProvider, fxConversionRepository, ledgerRepository, walletRepository, currencyRepository, feeRepository),
+		maxWallets:        maxWallets,
 	}
 }
 
 func (uc *walletUsecase) GetWallet(ctx context.Context, userID, walletID int) (*domain.Wallet, error) {
 	// retrieve one wallet by user id and wallet ID
-	wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, userID, walletID)
+	wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, nil, userID, walletID)
 	if err != nil {
 		log.Printf("failed to get wallet for user id %d with error: %v\n", userID, err)
 		return nil, err
@@ -40,12 +58,23 @@ func (uc *walletUsecase) GetWallet(ctx context.Context, userID, walletID int) (*
 	}
 	wallet.CurrencyAmount = walletBalances
 
+	// a wallet with a savings goal comes with how far it is towards the goal
+	goal, err := uc.walletRepository.GetWalletGoal(ctx, nil, userID, walletID)
+	if err != nil && !errors.Is(err, exception.ErrWalletGoalNotFound) {
+		log.Printf("failed to get goal of wallet id %d for user id %d with error: %v\n", walletID, userID, err)
+		return nil, err
+	}
+	if goal != nil {
+		goal.Progress = uc.goalProgress(ctx, *goal, walletBalances)
+		wallet.Goal = goal
+	}
+
 	return wallet, nil
 }
 
-func (uc *walletUsecase) GetWallets(ctx context.Context, userID int) (*[]domain.Wallet, error) {
-	// retrieve wallets by user id
-	wallets, err := uc.walletRepository.GetWallets(ctx, userID)
+func (uc *walletUsecase) GetWallets(ctx context.Context, userID int, req dto.GetWalletsRequest) (*[]domain.Wallet, error) {
+	// retrieve wallets by user id, closed wallets only when asked for
+	wallets, err := uc.walletRepository.GetWallets(ctx, userID, req.IncludeClosed)
 	if err != nil {
 		log.Printf("failed to get wallets for user id %d with error: %v\n", userID, err)
 		return nil, err
@@ -67,10 +96,28 @@ func (uc *walletUsecase) GetWallets(ctx context.Context, userID int) (*[]domain.
 		walletBalancesMap[wb.WalletID] = append(walletBalancesMap[wb.WalletID], wb)
 	}
 
+	// retrieve the savings goals of the wallets by user id
+	goals, err := uc.walletRepository.GetWalletGoals(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get wallet goals for user id %d with error: %v\n", userID, err)
+		return nil, err
+	}
+
+	goalsMap := make(map[int]domain.WalletGoal)
+	for _, g := range goals {
+		goalsMap[g.WalletID] = g
+	}
+
 	for idx, w := range wallets {
 		if wb, found := walletBalancesMap[w.ID]; found {
 			wallets[idx].CurrencyAmount = wb
 		}
+
+		if g, found := goalsMap[w.ID]; found {
+			goal := g
+			goal.Progress = uc.goalProgress(ctx, goal, wallets[idx].CurrencyAmount)
+			wallets[idx].Goal = &goal
+		}
 	}
 
 	return &wallets, nil
@@ -87,318 +134,745 @@ func (uc *walletUsecase) GetWalletTypes(ctx context.Context) (*[]dto.GetWalletTy
 }
 
 func (uc *walletUsecase) CreateWallet(ctx context.Context, userID int, req dto.CreateWalletRequest) error {
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
-		return err
-	}
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// count the wallets the user has open, this locks the user so the limit holds for concurrent requests
+		walletCount, err := uc.walletRepository.CountWallets(ctx, tx, userID)
+		if err != nil {
+			log.Printf("failed to count wallets for user id %d with error: %v\n", userID, err)
+			return err
+		}
+		if walletCount >= uc.maxWallets {
+			return exception.ErrWalletLimitReached
+		}
 
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
-		} else {
-			err = tx.Commit()
+		// wallets without a name are named after their wallet type
+		name := req.Name
+		if name == "" {
+			name, err = uc.walletTypeName(ctx, req.WalletTypeID)
 			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
+				return err
 			}
 		}
-	}()
 
-	// check if user has already created these wallets
-	walletExists, err := uc.walletRepository.CheckWalletExistsByWalletTypeID(ctx, userID, req.WalletTypeID)
-	if err != nil {
-		log.Printf("failed to check wallet exists by user id %d; wallet type id %d; with error: %v\n", userID, req.WalletTypeID, err)
-		return err
-	}
-	if walletExists {
-		err = exception.ErrWalletAlreadyExists
-		return err
-	}
+		// wallet names are unique per user, closed wallets give up their name
+		walletExists, err := uc.walletRepository.CheckWalletExistsByName(ctx, userID, name)
+		if err != nil {
+			log.Printf("failed to check wallet exists by user id %d; name %s; with error: %v\n", userID, name, err)
+			return err
+		}
+		if walletExists {
+			return exception.ErrWalletAlreadyExists
+		}
 
-	// check if wallet type id is valid
-	walletTypeExists, err := uc.walletRepository.CheckWalletTypeExists(ctx, req.WalletTypeID)
-	if err != nil {
-		log.Printf("failed to check wallet type exists with wallet type id %d; with error: %v\n", req.WalletTypeID, err)
-		return err
-	}
-	if !walletTypeExists {
-		err = exception.ErrWalletTypeInvalid
-		return err
-	}
+		// check if wallet type id is valid
+		walletTypeExists, err := uc.walletRepository.CheckWalletTypeExists(ctx, req.WalletTypeID)
+		if err != nil {
+			log.Printf("failed to check wallet type exists with wallet type id %d; with error: %v\n", req.WalletTypeID, err)
+			return err
+		}
+		if !walletTypeExists {
+			return exception.ErrWalletTypeInvalid
+		}
 
-	// retrieve main balance and check if sufficient funds
-	allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+		// retrieve main balance and check if sufficient funds
+		allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+		if err != nil {
+			log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// convert slice of user balances into map for faster performance of accessing keys in map
+		allBalancesMap := make(map[string]money.Amount)
+		for _, b := range allBalances {
+			allBalancesMap[b.Currency] = b.Balance
+		}
+
+		finalBalancesMap := make(map[string]money.Amount)
+		currencyAmount := make([]domain.WalletCurrencyAmount, 0)
+
+		// ensure all balances are sufficient to top up new wallet
+		for _, a := range req.CurrencyAmount {
+			if currentBalance, found := allBalancesMap[a.Currency]; !found {
+				// user does not have a balance in this currency
+				log.Printf("user %d does not have a balance in this currency\n", userID)
+				return exception.ErrBalanceNotFound
+			} else {
+				if currentBalance < a.Amount {
+					log.Printf("user %d has insufficient funds to top up wallet\n", userID)
+					return exception.ErrInsufficientFunds
+				}
+
+				currencyAmount = append(currencyAmount, domain.WalletCurrencyAmount{
+					Amount:   a.Amount,
+					Currency: a.Currency,
+				})
+
+				finalBalance := allBalancesMap[a.Currency] - a.Amount
+				finalBalancesMap[a.Currency] = finalBalance
+			}
+		}
+
+		// update user balances
+		if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
+			log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// create wallets
+		newWallet := &domain.Wallet{
+			WalletTypeID: req.WalletTypeID,
+			UserID:       userID,
+			Name:         name,
+		}
+		walletID, err := uc.walletRepository.CreateWallet(ctx, tx, newWallet)
+		if err != nil {
+			log.Printf("failed to create wallet for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// insert amount and currency for the wallet
+		if err = uc.walletRepository.InsertWalletCurrencyAmount(ctx, tx, walletID, userID, currencyAmount); err != nil {
+			log.Printf("failed to insert wallet currency amount for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// record the funds moved from the main balance into the new wallet
+		entry := &domain.JournalEntry{Type: domain.JournalCreateWallet, Reference: fmt.Sprintf("wallet:%d", walletID)}
+		for _, a := range currencyAmount {
+			entry.Transfer(domain.UserBalanceAccount(userID), domain.UserWalletAccount(userID, walletID), a.Currency, a.Amount)
+		}
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for wallet creation for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// walletTypeName looks up the name of a wallet type, used to name wallets created without one
+func (uc *walletUsecase) walletTypeName(ctx context.Context, walletTypeID int) (string, error) {
+	walletTypes, err := uc.walletRepository.GetWalletTypes(ctx)
 	if err != nil {
-		log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
-		return err
+		log.Println("failed to retrieve wallet types with error:", err)
+		return "", err
 	}
 
-	// convert slice of user balances into map for faster performance of accessing keys in map
-	allBalancesMap := make(map[string]float64)
-	for _, b := range allBalances {
-		allBalancesMap[b.Currency] = b.Balance
+	for _, wt := range *walletTypes {
+		if wt.ID == walletTypeID {
+			return wt.WalletType, nil
+		}
 	}
+	return "", exception.ErrWalletTypeInvalid
+}
+
+func (uc *walletUsecase) TopUpWallet(ctx context.Context, userID, walletID int, req dto.UpdateWalletRequest) error {
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// get and lock wallet by walletID, money only moves in and out of active wallets
+		wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+			return err
+		}
+		if err = wallet.CheckActive(); err != nil {
+			return err
+		}
+
+		// retrieve and lock wallet balances, always locked before the main balances
+		walletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	finalBalancesMap := make(map[string]float64)
-	currencyAmount := make([]domain.WalletCurrencyAmount, 0)
+		// convert slice of wallet balances into map for faster performance of accessing keys in map
+		walletBalancesMap := make(map[string]money.Amount)
+		for _, b := range walletBalances {
+			walletBalancesMap[b.Currency] = b.Amount
+		}
 
-	// ensure all balances are sufficient to top up new wallet
-	for _, a := range req.CurrencyAmount {
-		if currentBalance, found := allBalancesMap[a.Currency]; !found {
-			// user does not have a balance in this currency
-			log.Printf("user %d does not have a balance in this currency\n", userID)
-			err = exception.ErrBalanceNotFound
+		// retrieve main balance and check if sufficient funds
+		allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+		if err != nil {
+			log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
 			return err
-		} else {
+		}
+
+		// convert slice of user balances into map for faster performance of accessing keys in map
+		allBalancesMap := make(map[string]money.Amount)
+		for _, b := range allBalances {
+			allBalancesMap[b.Currency] = b.Balance
+		}
+
+		finalBalancesMap := make(map[string]money.Amount)
+		finalWalletBalancesMap := make(map[string]money.Amount)
+
+		// ensure all balances are sufficient to top up the wallet
+		for _, a := range req.CurrencyAmount {
+			if _, err := uc.exchanges.currencies.currency(ctx, a.Currency); err != nil {
+				log.Printf("user %d cannot top up wallet in currency %s with error: %v\n", userID, a.Currency, err)
+				return err
+			}
+
+			// a currency given more than once is taken out of what is left after the earlier amounts
+			currentBalance, found := finalBalancesMap[a.Currency]
+			if !found {
+				currentBalance, found = allBalancesMap[a.Currency]
+			}
+			if !found {
+				// user does not have a balance in this currency
+				log.Printf("user %d does not have a balance in this currency\n", userID)
+				return exception.ErrBalanceNotFound
+			}
 			if currentBalance < a.Amount {
 				log.Printf("user %d has insufficient funds to top up wallet\n", userID)
-				err = exception.ErrInsufficientFunds
-				return err
+				return exception.ErrInsufficientFunds
 			}
+			finalBalancesMap[a.Currency] = currentBalance - a.Amount
 
-			currencyAmount = append(currencyAmount, domain.WalletCurrencyAmount{
-				Amount:   a.Amount,
-				Currency: a.Currency,
-			})
+			// a currency the wallet does not hold yet starts from zero, its row is inserted by the upsert
+			walletAmount, found := finalWalletBalancesMap[a.Currency]
+			if !found {
+				walletAmount = walletBalancesMap[a.Currency]
+			}
+			finalWalletBalancesMap[a.Currency] = walletAmount + a.Amount
+		}
 
-			finalBalance := allBalancesMap[a.Currency] - a.Amount
-			finalBalancesMap[a.Currency] = finalBalance
+		// update user balances
+		if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
+			log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
+			return err
 		}
-	}
 
-	// update user balances
-	if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
-		log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		// update wallet balances
+		if err = uc.walletRepository.TopUpWalletBalances(ctx, tx, userID, walletID, finalWalletBalancesMap); err != nil {
+			log.Printf("failed to top up wallet balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// create wallets
-	newWallet := &domain.Wallet{
-		WalletTypeID: req.WalletTypeID,
-		UserID:       userID,
-	}
-	walletID, err := uc.walletRepository.CreateWallet(ctx, tx, newWallet)
-	if err != nil {
-		log.Printf("failed to create wallet for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		// record the funds moved from the main balance into the wallet
+		entry := &domain.JournalEntry{Type: domain.JournalTopUpWallet, Reference: fmt.Sprintf("wallet:%d", walletID)}
+		for _, a := range req.CurrencyAmount {
+			entry.Transfer(domain.UserBalanceAccount(userID), domain.UserWalletAccount(userID, walletID), a.Currency, a.Amount)
+		}
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for wallet top up for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// insert amount and currency for the wallet
-	if err = uc.walletRepository.InsertWalletCurrencyAmount(ctx, tx, walletID, userID, currencyAmount); err != nil {
-		log.Printf("failed to insert wallet currency amount for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		// complete the wallet's savings goal when the top up reaches it
+		for currency, amount := range finalWalletBalancesMap {
+			walletBalancesMap[currency] = amount
+		}
+		toppedUpBalances := make([]domain.WalletCurrencyAmount, 0, len(walletBalancesMap))
+		for currency, amount := range walletBalancesMap {
+			toppedUpBalances = append(toppedUpBalances, domain.WalletCurrencyAmount{WalletID: walletID, Currency: currency, Amount: amount})
+		}
+		if err = uc.completeWalletGoal(ctx, tx, userID, walletID, toppedUpBalances); err != nil {
+			return err
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func (uc *walletUsecase) TopUpWallet(ctx context.Context, userID, walletID int, req dto.UpdateWalletRequest) error {
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
-		return err
-	}
+func (uc *walletUsecase) CashOutWallet(ctx context.Context, userID, walletID int, req dto.UpdateWalletRequest) error {
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// get and lock wallet by walletID, money only moves in and out of active wallets
+		wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+			return err
+		}
+		if err = wallet.CheckActive(); err != nil {
+			return err
+		}
 
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
+		// retrieve and lock wallet balances, always locked before the main balances
+		walletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// convert slice of wallet balances into map for faster performance of accessing keys in map
+		walletBalancesMap := make(map[string]money.Amount)
+		for _, b := range walletBalances {
+			walletBalancesMap[b.Currency] = b.Amount
+		}
+
+		// retrieve main balance and check if sufficient funds
+		allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+		if err != nil {
+			log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// convert slice of user balances into map for faster performance of accessing keys in map
+		allBalancesMap := make(map[string]money.Amount)
+		for _, b := range allBalances {
+			allBalancesMap[b.Currency] = b.Balance
+		}
+
+		finalBalancesMap := make(map[string]money.Amount)
+		finalWalletBalancesMap := make(map[string]money.Amount)
+
+		// ensure all wallet balances are sufficient for cashing out to main balance
+		for _, ca := range req.CurrencyAmount {
+			// a currency given more than once is taken out of what is left after the earlier amounts
+			walletAmount, found := finalWalletBalancesMap[ca.Currency]
+			if !found {
+				walletAmount, found = walletBalancesMap[ca.Currency]
 			}
+			if !found {
+				// user does not have this currency in the wallet
+				log.Printf("user %d does not have this currency in the wallet\n", userID)
+				return exception.ErrWalletBalanceNotFound
+			}
+			if ca.Amount > walletAmount {
+				log.Printf("user %d has insufficient funds in wallet for currency %s\n", userID, ca.Currency)
+				return exception.ErrInsufficientFundsForWithdrawal
+			}
+			finalWalletBalancesMap[ca.Currency] = walletAmount - ca.Amount
+
+			balance, found := finalBalancesMap[ca.Currency]
+			if !found {
+				balance = allBalancesMap[ca.Currency]
+			}
+			finalBalancesMap[ca.Currency] = balance + ca.Amount
 		}
-	}()
 
-	// get wallet by walletID
-	_, err = uc.walletRepository.GetWalletByWalletID(ctx, userID, walletID)
-	if err != nil {
-		log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		// update user balances
+		if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
+			log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// retrieve main balance and check if sufficient funds
-	allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
-	if err != nil {
-		log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		// update wallet balances
+		if err = uc.walletRepository.CashOutWalletBalances(ctx, tx, userID, walletID, finalWalletBalancesMap); err != nil {
+			log.Printf("failed to cash out wallet balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// convert slice of user balances into map for faster performance of accessing keys in map
-	allBalancesMap := make(map[string]float64)
-	for _, b := range allBalances {
-		allBalancesMap[b.Currency] = b.Balance
-	}
+		// record the funds moved from the wallet back to the main balance
+		entry := &domain.JournalEntry{Type: domain.JournalCashOutWallet, Reference: fmt.Sprintf("wallet:%d", walletID)}
+		for _, ca := range req.CurrencyAmount {
+			entry.Transfer(domain.UserWalletAccount(userID, walletID), domain.UserBalanceAccount(userID), ca.Currency, ca.Amount)
+		}
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for wallet cash out for user id %d with error: %v\n", userID, err)
+			return err
+		}
 
-	// retrieve main balance and check if sufficient funds
-	walletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, userID, walletID)
-	if err != nil {
-		log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
-		return err
+		return nil
+	})
+}
+
+// TransferBetweenWallets moves amounts in several currencies from one of the user's wallets to
+// another in a single transaction. Currencies the destination wallet does not hold yet are added to it.
+func (uc *walletUsecase) TransferBetweenWallets(ctx context.Context, userID int, req dto.TransferWalletRequest) (*dto.TransferWalletResponse, error) {
+	if req.FromWalletID == req.ToWalletID {
+		return nil, exception.ErrWalletTransferSameWallet
 	}
 
-	// convert slice of wallet balances into map for faster performance of accessing keys in map
-	walletBalancesMap := make(map[string]float64)
-	for _, b := range walletBalances {
-		walletBalancesMap[b.Currency] = b.Amount
+	// the same currency may be listed more than once, it is moved as one amount
+	transferAmounts := make(map[string]money.Amount)
+	for _, ca := range req.CurrencyAmount {
+		transferAmounts[ca.Currency] += ca.Amount
 	}
+	currencies := make([]string, 0, len(transferAmounts))
+	for currency := range transferAmounts {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
 
-	finalBalancesMap := make(map[string]float64)
-	finalWalletBalancesMap := make(map[string]float64)
+	resp := &dto.TransferWalletResponse{
+		FromWallet: dto.WalletBalances{WalletID: req.FromWalletID},
+		ToWallet:   dto.WalletBalances{WalletID: req.ToWalletID},
+	}
 
-	// ensure all balances are sufficient to top up new wallet
-	for _, a := range req.CurrencyAmount {
-		if currentBalance, found := allBalancesMap[a.Currency]; !found {
-			// user does not have a balance in this currency
-			log.Printf("user %d does not have a balance in this currency\n", userID)
-			err = exception.ErrBalanceNotFound
-			return err
-		} else {
-			if currentBalance < a.Amount {
-				log.Printf("user %d has insufficient funds to top up wallet\n", userID)
-				err = exception.ErrInsufficientFunds
+	if err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// both wallets must belong to the user and be active, they are locked in id order so that
+		// transfers between the same wallets in opposite directions wait on each other
+		walletIDs := []int{req.FromWalletID, req.ToWalletID}
+		sort.Ints(walletIDs)
+		for _, walletID := range walletIDs {
+			wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, userID, walletID)
+			if err != nil {
+				log.Printf("failed to get wallet by wallet ID %d for user id %d with error: %v\n", walletID, userID, err)
 				return err
 			}
+			if err = wallet.CheckActive(); err != nil {
+				return err
+			}
+		}
 
-			finalBalance := allBalancesMap[a.Currency] - a.Amount
-			if _, found := finalBalancesMap[a.Currency]; !found {
-				finalBalancesMap[a.Currency] = 0
+		// retrieve and lock the balances of both wallets together
+		walletBalances, err := uc.walletRepository.GetWalletBalancesByWalletIDs(ctx, tx, userID, req.FromWalletID, req.ToWalletID)
+		if err != nil {
+			log.Printf("failed to retrieve wallet balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		fromBalancesMap := make(map[string]money.Amount)
+		toBalancesMap := make(map[string]money.Amount)
+		for _, wb := range walletBalances {
+			if wb.WalletID == req.FromWalletID {
+				fromBalancesMap[wb.Currency] = wb.Amount
+			} else {
+				toBalancesMap[wb.Currency] = wb.Amount
 			}
-			finalBalancesMap[a.Currency] = finalBalance
+		}
+
+		finalFromBalancesMap := make(map[string]money.Amount)
+		finalToBalancesMap := make(map[string]money.Amount)
 
-			if walletAmount, found := walletBalancesMap[a.Currency]; found {
-				finalWalletBalance := walletAmount + a.Amount
-				finalWalletBalancesMap[a.Currency] = finalWalletBalance
+		// ensure the source wallet holds enough of every currency
+		for _, currency := range currencies {
+			amount := transferAmounts[currency]
+			fromAmount, found := fromBalancesMap[currency]
+			if !found {
+				log.Printf("user %d does not have currency %s in wallet %d\n", userID, currency, req.FromWalletID)
+				return exception.ErrWalletBalanceNotFound
 			}
+			if amount > fromAmount {
+				log.Printf("user %d has insufficient funds in wallet %d for currency %s\n", userID, req.FromWalletID, currency)
+				return exception.ErrInsufficientFundsInWallet
+			}
+
+			finalFromBalancesMap[currency] = fromAmount - amount
+			finalToBalancesMap[currency] = toBalancesMap[currency] + amount
 		}
-	}
 
-	// update user balances
-	if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
-		log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		if err = uc.walletRepository.CashOutWalletBalances(ctx, tx, userID, req.FromWalletID, finalFromBalancesMap); err != nil {
+			log.Printf("failed to update balances of wallet %d for user id %d with error: %v\n", req.FromWalletID, userID, err)
+			return err
+		}
+		if err = uc.walletRepository.TopUpWalletBalances(ctx, tx, userID, req.ToWalletID, finalToBalancesMap); err != nil {
+			log.Printf("failed to update balances of wallet %d for user id %d with error: %v\n", req.ToWalletID, userID, err)
+			return err
+		}
 
-	// update wallet balances
-	if err = uc.walletRepository.TopUpWalletBalances(ctx, tx, userID, walletID, finalWalletBalancesMap); err != nil {
-		log.Printf("failed to top up wallet balances for user id %d with error: %v\n", userID, err)
-		return err
+		// record the funds moved from one wallet to the other
+		entry := &domain.JournalEntry{Type: domain.JournalWalletTransfer, Reference: fmt.Sprintf("wallet:%d:wallet:%d", req.FromWalletID, req.ToWalletID)}
+		for _, currency := range currencies {
+			entry.Transfer(domain.UserWalletAccount(userID, req.FromWalletID), domain.UserWalletAccount(userID, req.ToWalletID), currency, transferAmounts[currency])
+		}
+		if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+			log.Printf("failed to create journal entry for wallet transfer for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		// record the movement on both wallets
+		history := make([]domain.WalletHistory, 0, 2*len(currencies))
+		for _, currency := range currencies {
+			history = append(history,
+				domain.WalletHistory{WalletID: req.FromWalletID, UserID: userID, Type: domain.WalletHistoryTransferOut, CounterpartyWalletID: req.ToWalletID, JournalEntryID: entry.ID, Currency: currency, Amount: transferAmounts[currency]},
+				domain.WalletHistory{WalletID: req.ToWalletID, UserID: userID, Type: domain.WalletHistoryTransferIn, CounterpartyWalletID: req.FromWalletID, JournalEntryID: entry.ID, Currency: currency, Amount: transferAmounts[currency]},
+			)
+		}
+		if err = uc.walletRepository.CreateWalletHistory(ctx, tx, history); err != nil {
+			log.Printf("failed to create wallet history for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		for currency, amount := range finalFromBalancesMap {
+			fromBalancesMap[currency] = amount
+		}
+		for currency, amount := range finalToBalancesMap {
+			toBalancesMap[currency] = amount
+		}
+		resp.FromWallet.CurrencyAmount = walletBalancesResponse(fromBalancesMap)
+		resp.ToWallet.CurrencyAmount = walletBalancesResponse(toBalancesMap)
+
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return resp, nil
 }
 
-func (uc *walletUsecase) CashOutWallet(ctx context.Context, userID, walletID int, req dto.UpdateWalletRequest) error {
-	// Start SQL Transaction, need to lock balance in case use POSTMAN and frontend to update balance at the same time
-	tx, err := uc.dbConn.BeginTxx(ctx, nil)
-	if err != nil {
-		log.Printf("failed to begin sql transaction with error: %v\n", err)
-		return err
+// ExchangeWalletCurrency converts an amount between two currencies held in the same wallet, with
+// the rate and fees of an exchange between main balances
+func (uc *walletUsecase) ExchangeWalletCurrency(ctx context.Context, userID, walletID int, req dto.WalletExchangeRequest) (*dto.WalletExchangeResponse, error) {
+	if req.FromCurrency == req.ToCurrency {
+		return nil, exception.ErrFromCurrencyEqualToCurrency
 	}
 
-	// Defer rollback or commit the transaction based on the outcome
-	defer func() {
-		if p := recover(); p != nil {
-			tx.Rollback()
-			panic(p)
-		} else if err != nil {
-			tx.Rollback()
-			log.Printf("failed to complete sql transaction with error: %v\n", err)
-		} else {
-			err = tx.Commit()
-			if err != nil {
-				log.Printf("failed to commit sql transaction with error: %v\n", err)
-			}
+	// money can only move in currencies that are in the catalogue and enabled
+	for _, currency := range []string{req.FromCurrency, req.ToCurrency} {
+		if _, err := uc.exchanges.currencies.currency(ctx, currency); err != nil {
+			log.Printf("currency %s is not allowed for wallet exchange with error: %v\n", currency, err)
+			return nil, err
 		}
-	}()
+	}
 
-	// get wallet by walletID
-	_, err = uc.walletRepository.GetWalletByWalletID(ctx, userID, walletID)
-	if err != nil {
-		log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
-		return err
+	var resp *dto.WalletExchangeResponse
+	if err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// get and lock wallet by walletID
+		wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+			return err
+		}
+		if err = wallet.CheckActive(); err != nil {
+			return err
+		}
+
+		// retrieve and lock wallet balances
+		walletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to retrieve wallet balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		walletBalancesMap := make(map[string]money.Amount)
+		for _, b := range walletBalances {
+			walletBalancesMap[b.Currency] = b.Amount
+		}
+
+		fromAmount, found := walletBalancesMap[req.FromCurrency]
+		if !found {
+			log.Printf("user %d does not have currency %s in wallet %d\n", userID, req.FromCurrency, walletID)
+			return exception.ErrWalletBalanceNotFound
+		}
+		if req.FromAmount > fromAmount {
+			log.Printf("user %d has insufficient funds in wallet %d for currency %s\n", userID, walletID, req.FromCurrency)
+			return exception.ErrInsufficientFundsInWallet
+		}
+
+		conversion, err := uc.exchanges.price(ctx, req.FromCurrency, req.ToCurrency, req.FromAmount)
+		if err != nil {
+			log.Printf("failed to price wallet exchange from %s to %s with error: %v\n", req.FromCurrency, req.ToCurrency, err)
+			return err
+		}
+
+		walletBalancesMap[req.FromCurrency] -= conversion.fromAmount
+		walletBalancesMap[req.ToCurrency] += conversion.toAmount
+		finalWalletBalancesMap := map[string]money.Amount{
+			req.FromCurrency: walletBalancesMap[req.FromCurrency],
+			req.ToCurrency:   walletBalancesMap[req.ToCurrency],
+		}
+
+		// the wallet may not hold toCurrency yet, its balance is created then
+		if err = uc.walletRepository.TopUpWalletBalances(ctx, tx, userID, walletID, finalWalletBalancesMap); err != nil {
+			log.Printf("failed to update wallet balances for user id %d with error: %v\n", userID, err)
+			return err
+		}
+
+		if err = uc.exchanges.record(ctx, tx, userID, walletID, 0, *conversion); err != nil {
+			return err
+		}
+
+		resp = &dto.WalletExchangeResponse{
+			WalletID:       walletID,
+			FromAmount:     conversion.fromAmount,
+			FromCurrency:   req.FromCurrency,
+			ToAmount:       conversion.toAmount,
+			ToCurrency:     req.ToCurrency,
+			Fee:            domain.TotalFees(conversion.fees),
+			Rate:           conversion.rate.Rate,
+			CurrencyAmount: walletBalancesResponse(walletBalancesMap),
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// RenameWallet gives a wallet a label of the user's choosing, closed wallets keep the name they had
+func (uc *walletUsecase) RenameWallet(ctx context.Context, userID, walletID int, req dto.RenameWalletRequest) error {
+	if req.Name == "" {
+		return exception.ErrWalletNameInvalid
 	}
 
-	// retrieve main balance and check if sufficient funds
-	allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+	wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, nil, userID, walletID)
 	if err != nil {
-		log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
+		log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
 		return err
 	}
+	if wallet.Status == domain.WalletStatusClosed {
+		return exception.ErrWalletClosed
+	}
 
-	// convert slice of user balances into map for faster performance of accessing keys in map
-	allBalancesMap := make(map[string]float64)
-	for _, b := range allBalances {
-		allBalancesMap[b.Currency] = b.Balance
+	// the name must not be taken by another of the user's wallets, changing the case of its own name is fine
+	if !strings.EqualFold(wallet.Name, req.Name) {
+		walletExists, err := uc.walletRepository.CheckWalletExistsByName(ctx, userID, req.Name)
+		if err != nil {
+			log.Printf("failed to check wallet exists by user id %d; name %s; with error: %v\n", userID, req.Name, err)
+			return err
+		}
+		if walletExists {
+			return exception.ErrWalletAlreadyExists
+		}
 	}
 
-	// retrieve wallet balances by user id and wallet id
-	walletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, userID, walletID)
-	if err != nil {
-		log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
+	if err = uc.walletRepository.UpdateWalletName(ctx, userID, walletID, req.Name); err != nil {
+		log.Printf("failed to rename wallet id %d for user id %d with error: %v\n", walletID, userID, err)
 		return err
 	}
 
-	// convert slice of wallet balances into map for faster performance of accessing keys in map
-	walletBalancesMap := make(map[string]float64)
-	for _, b := range walletBalances {
-		walletBalancesMap[b.Currency] = b.Amount
-	}
+	return nil
+}
 
-	finalBalancesMap := make(map[string]float64)
-	finalWalletBalancesMap := make(map[string]float64)
+// ArchiveWallet freezes an active wallet with its balances until it is restored or closed
+func (uc *walletUsecase) ArchiveWallet(ctx context.Context, userID, walletID int) error {
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+			return err
+		}
+		if err = wallet.CheckActive(); err != nil {
+			return err
+		}
 
-	// ensure all wallet balances are sufficient for cashing out to main balance
-	for _, ca := range req.CurrencyAmount {
-		if walletAmount, found := walletBalancesMap[ca.Currency]; !found {
-			// user does not have this currency in the wallet
-			log.Printf("user %d does not have this currency in the wallet\n", userID)
-			err = exception.ErrWalletBalanceNotFound
+		if err = uc.walletRepository.UpdateWalletStatus(ctx, tx, userID, walletID, domain.WalletStatusArchived); err != nil {
+			log.Printf("failed to archive wallet id %d for user id %d with error: %v\n", walletID, userID, err)
 			return err
-		} else {
-			if ca.Amount > walletAmount {
-				log.Printf("user %d has insufficient funds in wallet for currency %s\n", userID, ca.Currency)
-				err = exception.ErrInsufficientFundsForWithdrawal
+		}
+		return nil
+	})
+}
+
+// RestoreWallet makes an archived wallet active again
+func (uc *walletUsecase) RestoreWallet(ctx context.Context, userID, walletID int) error {
+	return uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+			return err
+		}
+		switch wallet.Status {
+		case domain.WalletStatusClosed:
+			return exception.ErrWalletClosed
+		case domain.WalletStatusActive:
+			return exception.ErrWalletNotArchived
+		}
+
+		if err = uc.walletRepository.UpdateWalletStatus(ctx, tx, userID, walletID, domain.WalletStatusActive); err != nil {
+			log.Printf("failed to restore wallet id %d for user id %d with error: %v\n", walletID, userID, err)
+			return err
+		}
+		return nil
+	})
+}
+
+// CloseWallet sweeps everything left in a wallet back to the main balance and closes it for good.
+// The wallet itself is kept, so the transactions and history that point at it stay visible.
+func (uc *walletUsecase) CloseWallet(ctx context.Context, userID, walletID int) (*dto.CloseWalletResponse, error) {
+	var resp *dto.CloseWalletResponse
+	if err := uc.txManager.WithTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		// get and lock wallet by walletID, an archived wallet can be closed without restoring it
+		wallet, err := uc.walletRepository.GetWalletByWalletID(ctx, tx, userID, walletID)
+		if err != nil {
+			log.Printf("failed to get wallet by wallet ID for user id %d with error: %v\n", userID, err)
+			return err
+		}
+		if wallet.Status == domain.WalletStatusClosed {
+			return exception.ErrWalletClosed
+		}
+
+		// retrieve and lock wallet balances, always locked before the main balances
+		walletBalances, err := uc.walletRepository.GetWalletBalancesByUserIDAndWalletID(ctx, tx, userID, walletID)
+		if err != nil && !errors.Is(err, exception.ErrWalletBalancesNotFound) {
+			log.Printf("failed to retrieve wallet balances for user id %d and wallet id %d with error: %v\n", userID, walletID, err)
+			return err
+		}
+
+		sweptBalancesMap := make(map[string]money.Amount)
+		finalWalletBalancesMap := make(map[string]money.Amount)
+		for _, b := range walletBalances {
+			if b.Amount.IsPositive() {
+				sweptBalancesMap[b.Currency] = b.Amount
+				finalWalletBalancesMap[b.Currency] = 0
+			}
+		}
+
+		if len(sweptBalancesMap) > 0 {
+			// retrieve and lock main balances, a currency missing from them is created by the upsert
+			allBalances, err := uc.balanceRepository.GetBalances(ctx, tx, userID)
+			if err != nil && !errors.Is(err, exception.ErrBalancesNotFound) {
+				log.Printf("failed to retrieve all balances for user id %d with error: %v\n", userID, err)
 				return err
 			}
 
-			finalBalance := allBalancesMap[ca.Currency] + ca.Amount
-			if _, found := finalBalancesMap[ca.Currency]; !found {
-				finalBalancesMap[ca.Currency] = 0
+			allBalancesMap := make(map[string]money.Amount)
+			for _, b := range allBalances {
+				allBalancesMap[b.Currency] = b.Balance
+			}
+
+			finalBalancesMap := make(map[string]money.Amount)
+			for currency, amount := range sweptBalancesMap {
+				finalBalancesMap[currency] = allBalancesMap[currency] + amount
+			}
+
+			// update user balances
+			if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
+				log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
+				return err
+			}
+
+			// empty the wallet balances
+			if err = uc.walletRepository.CashOutWalletBalances(ctx, tx, userID, walletID, finalWalletBalancesMap); err != nil {
+				log.Printf("failed to cash out wallet balances for user id %d with error: %v\n", userID, err)
+				return err
 			}
-			finalBalancesMap[ca.Currency] = finalBalance
 
-			finalWalletBalance := walletAmount - ca.Amount
-			if _, found := finalWalletBalancesMap[ca.Currency]; !found {
-				finalWalletBalancesMap[ca.Currency] = 0
+			// record the funds swept from the wallet back to the main balance
+			entry := &domain.JournalEntry{Type: domain.JournalCloseWallet, Reference: fmt.Sprintf("wallet:%d", walletID)}
+			history := make([]domain.WalletHistory, 0, len(sweptBalancesMap))
+			for _, b := range walletBalances {
+				amount, found := sweptBalancesMap[b.Currency]
+				if !found {
+					continue
+				}
+				entry.Transfer(domain.UserWalletAccount(userID, walletID), domain.UserBalanceAccount(userID), b.Currency, amount)
+				history = append(history, domain.WalletHistory{WalletID: walletID, UserID: userID, Type: domain.WalletHistoryCloseOut, Currency: b.Currency, Amount: amount})
+			}
+			if err = uc.ledgerRepository.CreateJournalEntry(ctx, tx, entry); err != nil {
+				log.Printf("failed to create journal entry for closing wallet for user id %d with error: %v\n", userID, err)
+				return err
+			}
+
+			for idx := range history {
+				history[idx].JournalEntryID = entry.ID
+			}
+			if err = uc.walletRepository.CreateWalletHistory(ctx, tx, history); err != nil {
+				log.Printf("failed to create wallet history for closing wallet for user id %d with error: %v\n", userID, err)
+				return err
 			}
-			finalWalletBalancesMap[ca.Currency] = finalWalletBalance
 		}
-	}
 
-	// update user balances
-	if err = uc.balanceRepository.UpdateBalances(ctx, tx, userID, finalBalancesMap); err != nil {
-		log.Printf("failed to update balances for user id %d with error: %v\n", userID, err)
-		return err
-	}
+		if err = uc.walletRepository.UpdateWalletStatus(ctx, tx, userID, walletID, domain.WalletStatusClosed); err != nil {
+			log.Printf("failed to close wallet id %d for user id %d with error: %v\n", walletID, userID, err)
+			return err
+		}
 
-	// update wallet balances
-	if err = uc.walletRepository.CashOutWalletBalances(ctx, tx, userID, walletID, finalWalletBalancesMap); err != nil {
-		log.Printf("failed to cash out wallet balances for user id %d with error: %v\n", userID, err)
-		return err
+		resp = &dto.CloseWalletResponse{
+			WalletID:    walletID,
+			Status:      domain.WalletStatusClosed,
+			SweptAmount: walletBalancesResponse(sweptBalancesMap),
+		}
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return resp, nil
+}
+
+// walletBalancesResponse lists the balances of a wallet ordered by currency
+func walletBalancesResponse(balances map[string]money.Amount) []dto.CurrencyAmount {
+	currencyAmount := make([]dto.CurrencyAmount, 0, len(balances))
+	for currency, amount := range balances {
+		currencyAmount = append(currencyAmount, dto.CurrencyAmount{Amount: amount, Currency: currency})
+	}
+	sort.Slice(currencyAmount, func(i, j int) bool {
+		return currencyAmount[i].Currency < currencyAmount[j].Currency
+	})
+	return currencyAmount
 }
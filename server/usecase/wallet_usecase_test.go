@@ -11,20 +11,27 @@ import (
 	"github.com/LeonLow97/go-clean-architecture/domain"
 	"github.com/LeonLow97/go-clean-architecture/dto"
 	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/infrastructure"
 	mocks "github.com/LeonLow97/go-clean-architecture/mocks/repository"
+	usecaseMocks "github.com/LeonLow97/go-clean-architecture/mocks/usecase"
+	"github.com/LeonLow97/go-clean-architecture/repository"
 	"github.com/LeonLow97/go-clean-architecture/testdata"
 	"github.com/LeonLow97/go-clean-architecture/usecase"
+	"github.com/LeonLow97/go-clean-architecture/utils"
+	"github.com/LeonLow97/go-clean-architecture/utils/constants"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
 func TestWalletUsecase_NewWalletUsecase(t *testing.T) {
-	dbConn := &sqlx.DB{}
+	txManager := new(usecaseMocks.TxManager)
 	walletRepo := new(mocks.WalletRepository)
 	balanceRepo := new(mocks.BalanceRepository)
+	ledgerRepo := new(mocks.LedgerRepository)
 
-	walletUsecase := usecase.NewWalletUsecase(dbConn, walletRepo, balanceRepo)
+	walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
 	require.NotNil(t, walletUsecase)
 
 	// Use require.Implements to check if walletUsecase implements WalletUsecase interface
@@ -32,6 +39,25 @@ func TestWalletUsecase_NewWalletUsecase(t *testing.T) {
 }
 
 func TestWalletUsecase_GetWallet(t *testing.T) {
+	// the USD held counts towards an SGD goal for what it converts into
+	_, convertedUSD, err := utils.CalculateConversionDetails(money.FromInt(50), testdata.MockExchangeRate("USD", "SGD"), testdata.MockFeeRule(domain.FeeProductExchange))
+	require.NoError(t, err)
+	savedSGD := money.FromInt(100) + convertedUSD
+
+	walletWithGoal := testdata.MockWalletWithCurrencyAmounts()
+	walletWithGoal.Goal = &domain.WalletGoal{
+		ID:           1,
+		WalletID:     1,
+		UserID:       1,
+		TargetAmount: money.FromInt(1000),
+		Currency:     "SGD",
+		Progress: &domain.WalletGoalProgress{
+			SavedAmount:     savedSGD,
+			RemainingAmount: money.FromInt(1000) - savedSGD,
+			Percentage:      100 * savedSGD.Ratio(money.FromInt(1000), money.RoundDown),
+		},
+	}
+
 	testCases := []struct {
 		Title                        string
 		GivenUserID                  int
@@ -47,9 +73,34 @@ func TestWalletUsecase_GetWallet(t *testing.T) {
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
 				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.MockWalletCurrencyAmounts(), nil},
+				GetWalletGoal:                        []interface{}{nil, exception.ErrWalletGoalNotFound},
 			},
 			ExpectedWallet: testdata.MockWalletWithCurrencyAmounts(),
 		},
+		{
+			Title:         "ReturnsSuccessfully_WithGoalProgress",
+			GivenUserID:   1,
+			GivenWalletID: 1,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.MockWalletCurrencyAmounts(), nil},
+				GetWalletGoal: []interface{}{&domain.WalletGoal{
+					ID: 1, WalletID: 1, UserID: 1, TargetAmount: money.FromInt(1000), Currency: "SGD",
+				}, nil},
+			},
+			ExpectedWallet: walletWithGoal,
+		},
+		{
+			Title:         "ReturnsError_GetWalletGoal",
+			GivenUserID:   1,
+			GivenWalletID: 1,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.MockWalletCurrencyAmounts(), nil},
+				GetWalletGoal:                        []interface{}{nil, errors.New("internal server error")},
+			},
+			ExpectedError: errors.New("internal server error"),
+		},
 		{
 			Title:         "ReturnsError_GetWalletByWalletID",
 			GivenUserID:   1,
@@ -73,17 +124,20 @@ func TestWalletUsecase_GetWallet(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.Title, func(t *testing.T) {
-			dbConn := &sqlx.DB{}
+			txManager := new(usecaseMocks.TxManager)
 			walletRepo := new(mocks.WalletRepository)
 			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
 
-			walletUsecase := usecase.NewWalletUsecase(dbConn, walletRepo, balanceRepo)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
 			require.NotNil(t, walletUsecase)
 
-			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, mock.Anything).
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.GetWalletByWalletID...)
 			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.GetWalletBalancesByUserIDAndWalletID...)
+			walletRepo.On("GetWalletGoal", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.GetWalletGoal...)
 
 			wallet, err := walletUsecase.GetWallet(context.Background(), tc.GivenUserID, tc.GivenWalletID)
 
@@ -105,6 +159,11 @@ func TestWalletUsecase_GetWallets(t *testing.T) {
 	walletBalances = append(walletBalances, testdata.MockWalletCurrencyAmountsByWalletID(2)...)
 	walletBalances = append(walletBalances, testdata.MockWalletCurrencyAmountsByWalletID(3)...)
 
+	// wallet 2 holds SGD 200 and USD 100, the USD counts towards an SGD goal for what it converts into
+	_, convertedUSD, err := utils.CalculateConversionDetails(money.FromInt(100), testdata.MockExchangeRate("USD", "SGD"), testdata.MockFeeRule(domain.FeeProductExchange))
+	require.NoError(t, err)
+	savedSGD := money.FromInt(200) + convertedUSD
+
 	testCases := []struct {
 		Title                        string
 		GivenUserID                  int
@@ -118,6 +177,7 @@ func TestWalletUsecase_GetWallets(t *testing.T) {
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
 				GetWallets:                []interface{}{testdata.MockWallets(3), nil},
 				GetWalletBalancesByUserID: []interface{}{walletBalances, nil},
+				GetWalletGoals:            []interface{}{[]domain.WalletGoal{}, nil},
 			},
 			ExpectedWallets: &[]domain.Wallet{
 				{ID: 1, WalletType: "Personal", WalletTypeID: 1, UserID: 1, CreatedAt: "2024-06-15T13:45:00Z", CurrencyAmount: testdata.MockWalletCurrencyAmountsByWalletID(1)},
@@ -125,6 +185,25 @@ func TestWalletUsecase_GetWallets(t *testing.T) {
 				{ID: 3, WalletType: "Personal", WalletTypeID: 1, UserID: 1, CreatedAt: "2024-06-15T13:45:00Z", CurrencyAmount: testdata.MockWalletCurrencyAmountsByWalletID(3)},
 			},
 		},
+		{
+			Title:       "ReturnsSuccessfully_WithGoalReached",
+			GivenUserID: 1,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWallets:                []interface{}{testdata.MockWallets(3), nil},
+				GetWalletBalancesByUserID: []interface{}{walletBalances, nil},
+				GetWalletGoals: []interface{}{[]domain.WalletGoal{
+					{ID: 1, WalletID: 2, UserID: 1, TargetAmount: money.FromInt(150), Currency: "SGD"},
+				}, nil},
+			},
+			ExpectedWallets: &[]domain.Wallet{
+				{ID: 1, WalletType: "Personal", WalletTypeID: 1, UserID: 1, CreatedAt: "2024-06-15T13:45:00Z", CurrencyAmount: testdata.MockWalletCurrencyAmountsByWalletID(1)},
+				{ID: 2, WalletType: "Personal", WalletTypeID: 1, UserID: 1, CreatedAt: "2024-06-15T13:45:00Z", CurrencyAmount: testdata.MockWalletCurrencyAmountsByWalletID(2), Goal: &domain.WalletGoal{
+					ID: 1, WalletID: 2, UserID: 1, TargetAmount: money.FromInt(150), Currency: "SGD",
+					Progress: &domain.WalletGoalProgress{SavedAmount: savedSGD, Percentage: money.MustParseRate("100"), Reached: true},
+				}},
+				{ID: 3, WalletType: "Personal", WalletTypeID: 1, UserID: 1, CreatedAt: "2024-06-15T13:45:00Z", CurrencyAmount: testdata.MockWalletCurrencyAmountsByWalletID(3)},
+			},
+		},
 		{
 			Title:       "ReturnsError_GetWalletsInternalServerError",
 			GivenUserID: 1,
@@ -142,23 +221,36 @@ func TestWalletUsecase_GetWallets(t *testing.T) {
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
+		{
+			Title:       "ReturnsError_GetWalletGoalsInternalServerError",
+			GivenUserID: 1,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWallets:                []interface{}{testdata.MockWallets(3), nil},
+				GetWalletBalancesByUserID: []interface{}{walletBalances, nil},
+				GetWalletGoals:            []interface{}{nil, errors.New("internal server error")},
+			},
+			ExpectedError: errors.New("internal server error"),
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.Title, func(t *testing.T) {
-			dbConn := &sqlx.DB{}
+			txManager := new(usecaseMocks.TxManager)
 			walletRepo := new(mocks.WalletRepository)
 			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
 
-			walletUsecase := usecase.NewWalletUsecase(dbConn, walletRepo, balanceRepo)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
 			require.NotNil(t, walletUsecase)
 
-			walletRepo.On("GetWallets", mock.Anything, mock.Anything).
+			walletRepo.On("GetWallets", mock.Anything, mock.Anything, false).
 				Return(tc.WalletRepositoryReturnValues.GetWallets...)
 			walletRepo.On("GetWalletBalancesByUserID", mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.GetWalletBalancesByUserID...)
+			walletRepo.On("GetWalletGoals", mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.GetWalletGoals...)
 
-			wallets, err := walletUsecase.GetWallets(context.Background(), tc.GivenUserID)
+			wallets, err := walletUsecase.GetWallets(context.Background(), tc.GivenUserID, dto.GetWalletsRequest{})
 
 			if !strings.HasPrefix(tc.Title, "ReturnsError") {
 				require.NoError(t, err)
@@ -197,11 +289,12 @@ func TestWalletUsecase_GetWalletTypes(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.Title, func(t *testing.T) {
-			dbConn := &sqlx.DB{}
+			txManager := new(usecaseMocks.TxManager)
 			walletRepo := new(mocks.WalletRepository)
 			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
 
-			walletUsecase := usecase.NewWalletUsecase(dbConn, walletRepo, balanceRepo)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
 			require.NotNil(t, walletUsecase)
 
 			walletRepo.On("GetWalletTypes", mock.Anything).
@@ -224,9 +317,10 @@ func TestWalletUsecase_GetWalletTypes(t *testing.T) {
 func TestWalletUsecase_CreateWallet(t *testing.T) {
 	basicRequest := &dto.CreateWalletRequest{
 		WalletTypeID: 1,
+		Name:         "Travel",
 		CurrencyAmount: []dto.CurrencyAmount{
-			{Amount: 100, Currency: "USD"},
-			{Amount: 200, Currency: "SGD"},
+			{Amount: money.FromInt(100), Currency: "USD"},
+			{Amount: money.FromInt(200), Currency: "SGD"},
 		},
 	}
 
@@ -235,35 +329,95 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 		GivenCreateWalletRequest      *dto.CreateWalletRequest
 		WalletRepositoryReturnValues  mocks.WalletRepositoryReturnValues
 		BalanceRepositoryReturnValues mocks.BalanceRepositoryReturnValues
+		LedgerRepositoryReturnValues  mocks.LedgerRepositoryReturnValues
+		ExpectedWalletName            string
 		ExpectedError                 error
 	}{
 		{
 			Title:                    "ReturnsSuccessfully",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
-				CreateWallet:                    []interface{}{1, nil},
-				InsertWalletCurrencyAmount:      []interface{}{nil},
+				CountWallets:               []interface{}{1, nil},
+				CheckWalletExistsByName:    []interface{}{false, nil},
+				CheckWalletTypeExists:      []interface{}{true, nil},
+				CreateWallet:               []interface{}{1, nil},
+				InsertWalletCurrencyAmount: []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			ExpectedWalletName: "Travel",
+		},
+		{
+			Title: "ReturnsSuccessfully_WithoutName_NamedAfterWalletType",
+			GivenCreateWalletRequest: &dto.CreateWalletRequest{
+				WalletTypeID:   2,
+				CurrencyAmount: basicRequest.CurrencyAmount,
+			},
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				CountWallets:               []interface{}{1, nil},
+				GetWalletTypes:             []interface{}{testdata.MockGetWalletTypesResponses(), nil},
+				CheckWalletExistsByName:    []interface{}{false, nil},
+				CheckWalletTypeExists:      []interface{}{true, nil},
+				CreateWallet:               []interface{}{1, nil},
+				InsertWalletCurrencyAmount: []interface{}{nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances:    []interface{}{testdata.NewBalances(), nil},
 				UpdateBalances: []interface{}{nil},
 			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			ExpectedWalletName: "Business",
+		},
+		{
+			Title:                    "ReturnsError_CountWallets_InternalServerError",
+			GivenCreateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				CountWallets: []interface{}{0, errors.New("internal server error")},
+			},
+			ExpectedError: errors.New("internal server error"),
+		},
+		{
+			Title:                    "ReturnsError_CountWallets_WalletLimitReached",
+			GivenCreateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				CountWallets: []interface{}{constants.DEFAULT_MAX_WALLETS_PER_USER, nil},
+			},
+			ExpectedError: exception.ErrWalletLimitReached,
+		},
+		{
+			Title: "ReturnsError_WithoutName_WalletTypeDoesNotExist",
+			GivenCreateWalletRequest: &dto.CreateWalletRequest{
+				WalletTypeID:   99,
+				CurrencyAmount: basicRequest.CurrencyAmount,
+			},
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				CountWallets:   []interface{}{1, nil},
+				GetWalletTypes: []interface{}{testdata.MockGetWalletTypesResponses(), nil},
+			},
+			ExpectedError: exception.ErrWalletTypeInvalid,
 		},
 		{
-			Title:                    "ReturnsError_CheckWalletExistsByWalletTypeID_InternalServerError",
+			Title:                    "ReturnsError_CheckWalletExistsByName_InternalServerError",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, errors.New("internal server error")},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, errors.New("internal server error")},
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
 		{
-			Title:                    "ReturnsError_CheckWalletExistsByWalletTypeID_WalletAlreadyExists",
+			Title:                    "ReturnsError_CheckWalletExistsByName_WalletAlreadyExists",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{true, nil},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{true, nil},
 			},
 			ExpectedError: exception.ErrWalletAlreadyExists,
 		},
@@ -271,8 +425,9 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_CheckWalletTypeExists_InternalServerError",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{false, errors.New("internal server error")},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{false, errors.New("internal server error")},
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
@@ -280,8 +435,9 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_CheckWalletTypeExists_WalletTypeDoesNotExist",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{false, nil},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{false, nil},
 			},
 			ExpectedError: exception.ErrWalletTypeInvalid,
 		},
@@ -289,8 +445,9 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_GetBalances_InternalServerError",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{true, nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances: []interface{}{nil, errors.New("internal server error")},
@@ -301,12 +458,13 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_ErrBalanceNotFound_ForOneCurrency",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{true, nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances: []interface{}{[]domain.Balance{
-					{ID: 1, Balance: 500.0, Currency: "EUR", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
+					{ID: 1, Balance: money.FromInt(500), Currency: "EUR", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 				}, nil},
 			},
 			ExpectedError: exception.ErrBalanceNotFound,
@@ -315,13 +473,14 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_ErrInsufficientFunds_ForOneCurrency",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{true, nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances: []interface{}{[]domain.Balance{
-					{ID: 1, Balance: 1.0, Currency: "USD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
-					{ID: 3, Balance: 200.0, Currency: "SGD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
+					{ID: 1, Balance: money.FromInt(1), Currency: "USD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
+					{ID: 3, Balance: money.FromInt(200), Currency: "SGD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 				}, nil},
 			},
 			ExpectedError: exception.ErrInsufficientFunds,
@@ -330,12 +489,13 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_ErrInsufficientFunds_ForAllCurrencies",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{true, nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances: []interface{}{[]domain.Balance{
-					{ID: 1, Balance: 1.0, Currency: "USD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
+					{ID: 1, Balance: money.FromInt(1), Currency: "USD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 					{ID: 3, Balance: 0, Currency: "SGD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 				}, nil},
 			},
@@ -345,8 +505,9 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_UpdateBalances_InternalServerError",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{true, nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances:    []interface{}{testdata.NewBalances(), nil},
@@ -358,9 +519,10 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_CreateWallet_InternalServerError",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
-				CreateWallet:                    []interface{}{0, errors.New("internal server error")},
+				CountWallets:            []interface{}{1, nil},
+				CheckWalletExistsByName: []interface{}{false, nil},
+				CheckWalletTypeExists:   []interface{}{true, nil},
+				CreateWallet:            []interface{}{0, errors.New("internal server error")},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances:    []interface{}{testdata.NewBalances(), nil},
@@ -372,15 +534,35 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			Title:                    "ReturnsError_InsertWalletCurrencyAmount_InternalServerError",
 			GivenCreateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				CheckWalletExistsByWalletTypeID: []interface{}{false, nil},
-				CheckWalletTypeExists:           []interface{}{true, nil},
-				CreateWallet:                    []interface{}{1, nil},
-				InsertWalletCurrencyAmount:      []interface{}{errors.New("internal server error")},
+				CountWallets:               []interface{}{1, nil},
+				CheckWalletExistsByName:    []interface{}{false, nil},
+				CheckWalletTypeExists:      []interface{}{true, nil},
+				CreateWallet:               []interface{}{1, nil},
+				InsertWalletCurrencyAmount: []interface{}{errors.New("internal server error")},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			ExpectedError: errors.New("internal server error"),
+		},
+		{
+			Title:                    "ReturnsError_CreateJournalEntry_InternalServerError",
+			GivenCreateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				CountWallets:               []interface{}{1, nil},
+				CheckWalletExistsByName:    []interface{}{false, nil},
+				CheckWalletTypeExists:      []interface{}{true, nil},
+				CreateWallet:               []interface{}{1, nil},
+				InsertWalletCurrencyAmount: []interface{}{nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances:    []interface{}{testdata.NewBalances(), nil},
 				UpdateBalances: []interface{}{nil},
 			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{errors.New("internal server error")},
+			},
 			ExpectedError: errors.New("internal server error"),
 		},
 	}
@@ -398,13 +580,18 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			// Initialize mocks for repository
 			walletRepo := new(mocks.WalletRepository)
 			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
 
 			// Create instance of usecase
-			walletUsecase := usecase.NewWalletUsecase(sqlxDB, walletRepo, balanceRepo)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, usecase.NewTxManager(sqlxDB), walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
 			require.NotNil(t, walletUsecase)
 
-			walletRepo.On("CheckWalletExistsByWalletTypeID", mock.Anything, mock.Anything, mock.Anything).
-				Return(tc.WalletRepositoryReturnValues.CheckWalletExistsByWalletTypeID...)
+			walletRepo.On("CountWallets", mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.CountWallets...)
+			walletRepo.On("GetWalletTypes", mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.GetWalletTypes...)
+			walletRepo.On("CheckWalletExistsByName", mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.CheckWalletExistsByName...)
 			walletRepo.On("CheckWalletTypeExists", mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.CheckWalletTypeExists...)
 			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, mock.Anything).
@@ -416,6 +603,12 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			walletRepo.On("InsertWalletCurrencyAmount", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.InsertWalletCurrencyAmount...)
 
+			// journal entries handed to the ledger must always balance per currency
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+				return entry.CheckBalanced() == nil
+			})).
+				Return(tc.LedgerRepositoryReturnValues.CreateJournalEntry...)
+
 			// Setting up expectations
 			sqlmock.ExpectBegin()
 
@@ -433,6 +626,9 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 			// Validate the outcome based on expected errors
 			if tc.ExpectedError == nil {
 				require.NoError(t, err)
+				walletRepo.AssertCalled(t, "CreateWallet", mock.Anything, mock.Anything, mock.MatchedBy(func(wallet *domain.Wallet) bool {
+					return wallet.Name == tc.ExpectedWalletName
+				}))
 			} else {
 				require.Error(t, err)
 				require.Equal(t, tc.ExpectedError.Error(), err.Error())
@@ -447,23 +643,31 @@ func TestWalletUsecase_CreateWallet(t *testing.T) {
 func TestWalletUsecase_TopUpWallet(t *testing.T) {
 	basicRequest := dto.UpdateWalletRequest{
 		CurrencyAmount: []dto.CurrencyAmount{
-			{Amount: 100, Currency: "USD"},
-			{Amount: 200, Currency: "SGD"},
+			{Amount: money.FromInt(100), Currency: "USD"},
+			{Amount: money.FromInt(200), Currency: "SGD"},
 		},
 	}
 
+	completedAt := "2024-06-15T13:45:00Z"
+
 	testCases := []struct {
 		Title                         string
 		GivenUpdateWalletRequest      dto.UpdateWalletRequest
 		WalletRepositoryReturnValues  mocks.WalletRepositoryReturnValues
 		BalanceRepositoryReturnValues mocks.BalanceRepositoryReturnValues
+		LedgerRepositoryReturnValues  mocks.LedgerRepositoryReturnValues
+		GivenCurrencies               []domain.Currency
+		GivenGoal                     *domain.WalletGoal
+		GivenFeeScheduleError         error
+		ExpectedWalletBalances        map[string]money.Amount
+		ExpectedGoalCompleted         bool
 		ExpectedError                 error
 	}{
 		{
 			Title:                    "ReturnSuccessfully",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 				TopUpWalletBalances:                  []interface{}{nil},
 			},
@@ -471,23 +675,196 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 				GetBalances:    []interface{}{testdata.NewBalances(), nil},
 				UpdateBalances: []interface{}{nil},
 			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			ExpectedWalletBalances: map[string]money.Amount{
+				"USD": money.MustParse("300.25"),
+				"SGD": money.MustParse("300.50"),
+			},
 		},
 		{
-			Title:                    "ReturnsError_GetWalletByWalletID_InternalServerError",
+			Title:                    "ReturnsSuccessfully_GoalReached",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID: []interface{}{nil, errors.New("internal server error")},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+				TopUpWalletBalances:                  []interface{}{nil},
 			},
-			ExpectedError: errors.New("internal server error"),
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			GivenGoal: &domain.WalletGoal{ID: 7, WalletID: 1, UserID: 1, TargetAmount: money.FromInt(300), Currency: "SGD"},
+			ExpectedWalletBalances: map[string]money.Amount{
+				"USD": money.MustParse("300.25"),
+				"SGD": money.MustParse("300.50"),
+			},
+			ExpectedGoalCompleted: true,
 		},
 		{
-			Title:                    "ReturnsError_GetBalances_InternalServerError",
+			Title:                    "ReturnsSuccessfully_GoalNotReached",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID: []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+				TopUpWalletBalances:                  []interface{}{nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
-				GetBalances: []interface{}{nil, errors.New("internal server error")},
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			GivenGoal: &domain.WalletGoal{ID: 7, WalletID: 1, UserID: 1, TargetAmount: money.FromInt(10000), Currency: "SGD"},
+			ExpectedWalletBalances: map[string]money.Amount{
+				"USD": money.MustParse("300.25"),
+				"SGD": money.MustParse("300.50"),
+			},
+		},
+		{
+			Title:                    "ReturnsSuccessfully_GoalCurrencyCannotBePriced",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+				TopUpWalletBalances:                  []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			// USD cannot be priced into SGD, so it is left out and SGD alone reaches the goal
+			GivenGoal:             &domain.WalletGoal{ID: 7, WalletID: 1, UserID: 1, TargetAmount: money.FromInt(300), Currency: "SGD"},
+			GivenFeeScheduleError: errors.New("internal server error"),
+			ExpectedWalletBalances: map[string]money.Amount{
+				"USD": money.MustParse("300.25"),
+				"SGD": money.MustParse("300.50"),
+			},
+			ExpectedGoalCompleted: true,
+		},
+		{
+			Title:                    "ReturnsSuccessfully_GoalAlreadyCompleted",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+				TopUpWalletBalances:                  []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			GivenGoal: &domain.WalletGoal{ID: 7, WalletID: 1, UserID: 1, TargetAmount: money.FromInt(300), Currency: "SGD", CompletedAt: &completedAt},
+			ExpectedWalletBalances: map[string]money.Amount{
+				"USD": money.MustParse("300.25"),
+				"SGD": money.MustParse("300.50"),
+			},
+		},
+		{
+			Title: "ReturnsSuccessfully_NewCurrencyCreditedToWallet",
+			GivenUpdateWalletRequest: dto.UpdateWalletRequest{
+				CurrencyAmount: []dto.CurrencyAmount{
+					{Amount: money.FromInt(40), Currency: "USD"},
+				},
+			},
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID: []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{[]domain.WalletCurrencyAmount{
+					{WalletID: 1, Amount: money.MustParse("100.50"), Currency: "SGD"},
+				}, nil},
+				TopUpWalletBalances: []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			ExpectedWalletBalances: map[string]money.Amount{
+				"USD": money.FromInt(40),
+			},
+		},
+		{
+			Title: "ReturnsSuccessfully_DuplicatedCurrencyAddedUp",
+			GivenUpdateWalletRequest: dto.UpdateWalletRequest{
+				CurrencyAmount: []dto.CurrencyAmount{
+					{Amount: money.FromInt(30), Currency: "USD"},
+					{Amount: money.FromInt(20), Currency: "USD"},
+				},
+			},
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{[]domain.WalletCurrencyAmount{}, nil},
+				TopUpWalletBalances:                  []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			ExpectedWalletBalances: map[string]money.Amount{
+				"USD": money.FromInt(50),
+			},
+		},
+		{
+			Title:                    "ReturnsError_CurrencyDisabled",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances: []interface{}{testdata.NewBalances(), nil},
+			},
+			GivenCurrencies: []domain.Currency{
+				{Code: "SGD", Name: "Singapore Dollar", Symbol: "S$", Exponent: 2, Enabled: false},
+				{Code: "USD", Name: "US Dollar", Symbol: "$", Exponent: 2, Enabled: true},
+			},
+			ExpectedError: exception.ErrCurrencyDisabled,
+		},
+		{
+			Title: "ReturnsError_CurrencyNotSupported",
+			GivenUpdateWalletRequest: dto.UpdateWalletRequest{
+				CurrencyAmount: []dto.CurrencyAmount{
+					{Amount: money.FromInt(10), Currency: "EUR"},
+				},
+			},
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances: []interface{}{testdata.NewBalances(), nil},
+			},
+			ExpectedError: exception.ErrCurrencyNotSupported,
+		},
+		{
+			Title:                    "ReturnsError_WalletClosed",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID: []interface{}{&domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusClosed}, nil},
+			},
+			ExpectedError: exception.ErrWalletClosed,
+		},
+		{
+			Title:                    "ReturnsError_GetWalletByWalletID_InternalServerError",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID: []interface{}{nil, errors.New("internal server error")},
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
@@ -495,11 +872,20 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			Title:                    "ReturnsError_GetWalletBalancesByUserIDAndWalletID_InternalServerError",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{nil, errors.New("internal server error")},
 			},
+			ExpectedError: errors.New("internal server error"),
+		},
+		{
+			Title:                    "ReturnsError_GetBalances_InternalServerError",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
-				GetBalances: []interface{}{testdata.NewBalances(), nil},
+				GetBalances: []interface{}{nil, errors.New("internal server error")},
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
@@ -507,12 +893,12 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			Title:                    "ReturnsError_ErrBalanceNotFound_ForOneCurrency",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances: []interface{}{[]domain.Balance{
-					{ID: 1, Balance: 500.0, Currency: "EUR", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
+					{ID: 1, Balance: money.FromInt(500), Currency: "EUR", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 				}, nil},
 			},
 			ExpectedError: exception.ErrBalanceNotFound,
@@ -521,12 +907,12 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			Title:                    "ReturnsError_InsufficientFundsForTopUpWallet",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances: []interface{}{[]domain.Balance{
-					{ID: 1, Balance: 1.0, Currency: "USD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
+					{ID: 1, Balance: money.FromInt(1), Currency: "USD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 					{ID: 3, Balance: 0, Currency: "SGD", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 				}, nil},
 			},
@@ -536,7 +922,7 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			Title:                    "ReturnsError_UpdateBalances_InternalServerError",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
@@ -549,7 +935,7 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			Title:                    "ReturnsError_TopUpWalletBalances_InternalServerError",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 				TopUpWalletBalances:                  []interface{}{errors.New("internal server error")},
 			},
@@ -559,6 +945,23 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
+		{
+			Title:                    "ReturnsError_CreateJournalEntry_InternalServerError",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+				TopUpWalletBalances:                  []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{errors.New("internal server error")},
+			},
+			ExpectedError: errors.New("internal server error"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -574,12 +977,24 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			// Initialize mocks for repository
 			walletRepo := new(mocks.WalletRepository)
 			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+
+			currencyRepo := newCurrencyRepository()
+			if tc.GivenCurrencies != nil {
+				currencyRepo = new(mocks.CurrencyRepository)
+				currencyRepo.On("GetCurrencies", mock.Anything).Return(tc.GivenCurrencies, nil)
+			}
+			feeRepo := newFeeRepository()
+			if tc.GivenFeeScheduleError != nil {
+				feeRepo = new(mocks.FeeRepository)
+				feeRepo.On("GetActiveFeeSchedule", mock.Anything).Return(nil, tc.GivenFeeScheduleError)
+			}
 
 			// Create instance of usecase
-			walletUsecase := usecase.NewWalletUsecase(sqlxDB, walletRepo, balanceRepo)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, usecase.NewTxManager(sqlxDB), walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), currencyRepo, newFXConversionRepository(), feeRepo)
 			require.NotNil(t, walletUsecase)
 
-			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, mock.Anything).
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.GetWalletByWalletID...)
 			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.BalanceRepositoryReturnValues.GetBalances...)
@@ -589,6 +1004,19 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 				Return(tc.WalletRepositoryReturnValues.GetWalletBalancesByUserIDAndWalletID...)
 			walletRepo.On("TopUpWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.TopUpWalletBalances...)
+			if tc.GivenGoal != nil {
+				walletRepo.On("GetWalletGoal", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tc.GivenGoal, nil)
+			} else {
+				walletRepo.On("GetWalletGoal", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, exception.ErrWalletGoalNotFound)
+			}
+			walletRepo.On("CompleteWalletGoal", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			walletRepo.On("CreateWalletGoalEvent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			// journal entries handed to the ledger must always balance per currency
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+				return entry.CheckBalanced() == nil
+			})).
+				Return(tc.LedgerRepositoryReturnValues.CreateJournalEntry...)
 
 			// Setting up expectations
 			sqlmock.ExpectBegin()
@@ -607,6 +1035,19 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 			// Validate the outcome based on expected errors
 			if tc.ExpectedError == nil {
 				require.NoError(t, err)
+				// every currency topped up is credited to the wallet, including ones it did not hold before
+				walletRepo.AssertCalled(t, "TopUpWalletBalances", mock.Anything, mock.Anything, givenUserID, givenWalletID, tc.ExpectedWalletBalances)
+
+				// a goal is completed once, by the top up that reaches it
+				if tc.ExpectedGoalCompleted {
+					walletRepo.AssertCalled(t, "CompleteWalletGoal", mock.Anything, mock.Anything, tc.GivenGoal.ID)
+					walletRepo.AssertCalled(t, "CreateWalletGoalEvent", mock.Anything, mock.Anything, mock.MatchedBy(func(event *domain.WalletGoalEvent) bool {
+						return event.GoalID == tc.GivenGoal.ID && event.Type == domain.WalletGoalEventCompleted && event.SavedAmount >= tc.GivenGoal.TargetAmount
+					}))
+				} else {
+					walletRepo.AssertNotCalled(t, "CompleteWalletGoal", mock.Anything, mock.Anything, mock.Anything)
+					walletRepo.AssertNotCalled(t, "CreateWalletGoalEvent", mock.Anything, mock.Anything, mock.Anything)
+				}
 			} else {
 				require.Error(t, err)
 				require.Equal(t, tc.ExpectedError.Error(), err.Error())
@@ -621,20 +1062,32 @@ func TestWalletUsecase_TopUpWallet(t *testing.T) {
 func TestWalletUsecase_CashOutWallet(t *testing.T) {
 	basicRequest := dto.UpdateWalletRequest{
 		CurrencyAmount: []dto.CurrencyAmount{
-			{Amount: 50, Currency: "USD"},
-			{Amount: 50, Currency: "SGD"},
+			{Amount: money.FromInt(50), Currency: "USD"},
+			{Amount: money.FromInt(50), Currency: "SGD"},
 		},
 	}
 	basicRequestCurrencyNotFoundInWallet := dto.UpdateWalletRequest{
 		CurrencyAmount: []dto.CurrencyAmount{
-			{Amount: 50, Currency: "USD"},
-			{Amount: 50, Currency: "IDR"},
+			{Amount: money.FromInt(50), Currency: "USD"},
+			{Amount: money.FromInt(50), Currency: "IDR"},
 		},
 	}
 	basicRequestInsufficientFundsInWalletForWithdrawal := dto.UpdateWalletRequest{
 		CurrencyAmount: []dto.CurrencyAmount{
-			{Amount: 1000000, Currency: "USD"},
-			{Amount: 2000000, Currency: "SGD"},
+			{Amount: money.FromInt(1000000), Currency: "USD"},
+			{Amount: money.FromInt(2000000), Currency: "SGD"},
+		},
+	}
+	basicRequestRepeatedCurrency := dto.UpdateWalletRequest{
+		CurrencyAmount: []dto.CurrencyAmount{
+			{Amount: money.FromInt(30), Currency: "SGD"},
+			{Amount: money.FromInt(40), Currency: "SGD"},
+		},
+	}
+	basicRequestRepeatedCurrencyInsufficientFunds := dto.UpdateWalletRequest{
+		CurrencyAmount: []dto.CurrencyAmount{
+			{Amount: money.FromInt(60), Currency: "SGD"},
+			{Amount: money.FromInt(60), Currency: "SGD"},
 		},
 	}
 
@@ -643,13 +1096,32 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 		GivenUpdateWalletRequest      dto.UpdateWalletRequest
 		WalletRepositoryReturnValues  mocks.WalletRepositoryReturnValues
 		BalanceRepositoryReturnValues mocks.BalanceRepositoryReturnValues
+		LedgerRepositoryReturnValues  mocks.LedgerRepositoryReturnValues
+		ExpectedBalances              map[string]money.Amount
+		ExpectedWalletBalances        map[string]money.Amount
 		ExpectedError                 error
 	}{
 		{
 			Title:                    "ReturnsSuccessfully",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+				CashOutWalletBalances:                []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+		},
+		{
+			Title:                    "ReturnsSuccessfully_RepeatedCurrencyAddsUp",
+			GivenUpdateWalletRequest: basicRequestRepeatedCurrency,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 				CashOutWalletBalances:                []interface{}{nil},
 			},
@@ -657,6 +1129,11 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 				GetBalances:    []interface{}{testdata.NewBalances(), nil},
 				UpdateBalances: []interface{}{nil},
 			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			ExpectedBalances:       map[string]money.Amount{"SGD": money.FromInt(270)},
+			ExpectedWalletBalances: map[string]money.Amount{"SGD": money.MustParse("30.50")},
 		},
 		{
 			Title:                    "ReturnsError_GetWalletByWalletID_InternalServerError",
@@ -667,25 +1144,23 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			ExpectedError: errors.New("internal server error"),
 		},
 		{
-			Title:                    "ReturnsError_GetBalances_InternalServerError",
+			Title:                    "ReturnsError_GetWalletBalancesByUserIDAndWalletID_InternalServerError",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID: []interface{}{nil, nil},
-			},
-			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
-				GetBalances: []interface{}{nil, errors.New("internal server error")},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{nil, errors.New("internal server error")},
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
 		{
-			Title:                    "ReturnsError_GetWalletBalancesByUserIDAndWalletID_InternalServerError",
+			Title:                    "ReturnsError_GetBalances_InternalServerError",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
-				GetWalletBalancesByUserIDAndWalletID: []interface{}{nil, errors.New("internal server error")},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
-				GetBalances: []interface{}{testdata.NewBalances(), nil},
+				GetBalances: []interface{}{nil, errors.New("internal server error")},
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
@@ -693,12 +1168,12 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			Title:                    "ReturnsError_ErrWalletBalanceNotFound_ForOneCurrency",
 			GivenUpdateWalletRequest: basicRequestCurrencyNotFoundInWallet,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
 				GetBalances: []interface{}{[]domain.Balance{
-					{ID: 1, Balance: 500.0, Currency: "EUR", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
+					{ID: 1, Balance: money.FromInt(500), Currency: "EUR", UserID: 1, CreatedAt: time.Now().Format(time.RFC3339), UpdatedAt: time.Now().Format(time.RFC3339)},
 				}, nil},
 			},
 			ExpectedError: exception.ErrWalletBalanceNotFound,
@@ -707,7 +1182,19 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			Title:                    "ReturnsError_ErrInsufficientFundsForWithdrawal",
 			GivenUpdateWalletRequest: basicRequestInsufficientFundsInWalletForWithdrawal,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances: []interface{}{testdata.NewBalances(), nil},
+			},
+			ExpectedError: exception.ErrInsufficientFundsForWithdrawal,
+		},
+		{
+			Title:                    "ReturnsError_ErrInsufficientFundsForWithdrawal_RepeatedCurrency",
+			GivenUpdateWalletRequest: basicRequestRepeatedCurrencyInsufficientFunds,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
@@ -719,7 +1206,7 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			Title:                    "ReturnsError_UpdateBalances_InternalServerError",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 			},
 			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
@@ -732,7 +1219,7 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			Title:                    "ReturnsError_CashOutWalletBalances_InternalServerError",
 			GivenUpdateWalletRequest: basicRequest,
 			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
-				GetWalletByWalletID:                  []interface{}{nil, nil},
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
 				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
 				CashOutWalletBalances:                []interface{}{errors.New("internal server error")},
 			},
@@ -742,6 +1229,23 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			},
 			ExpectedError: errors.New("internal server error"),
 		},
+		{
+			Title:                    "ReturnsError_CreateJournalEntry_InternalServerError",
+			GivenUpdateWalletRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:                  []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByUserIDAndWalletID: []interface{}{testdata.NewWalletCurrencyAmount(), nil},
+				CashOutWalletBalances:                []interface{}{nil},
+			},
+			BalanceRepositoryReturnValues: mocks.BalanceRepositoryReturnValues{
+				GetBalances:    []interface{}{testdata.NewBalances(), nil},
+				UpdateBalances: []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{errors.New("internal server error")},
+			},
+			ExpectedError: errors.New("internal server error"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -757,12 +1261,13 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			// Initialize mocks for repository
 			walletRepo := new(mocks.WalletRepository)
 			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
 
 			// Create instance of usecase
-			walletUsecase := usecase.NewWalletUsecase(sqlxDB, walletRepo, balanceRepo)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, usecase.NewTxManager(sqlxDB), walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
 			require.NotNil(t, walletUsecase)
 
-			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, mock.Anything).
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.GetWalletByWalletID...)
 			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.BalanceRepositoryReturnValues.GetBalances...)
@@ -773,6 +1278,12 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 			walletRepo.On("CashOutWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 				Return(tc.WalletRepositoryReturnValues.CashOutWalletBalances...)
 
+			// journal entries handed to the ledger must always balance per currency
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+				return entry.CheckBalanced() == nil
+			})).
+				Return(tc.LedgerRepositoryReturnValues.CreateJournalEntry...)
+
 			// Setting up expectations
 			sqlmock.ExpectBegin()
 
@@ -795,8 +1306,712 @@ func TestWalletUsecase_CashOutWallet(t *testing.T) {
 				require.Equal(t, tc.ExpectedError.Error(), err.Error())
 			}
 
+			// a currency given more than once is cashed out as the sum of its amounts
+			if tc.ExpectedBalances != nil {
+				balanceRepo.AssertCalled(t, "UpdateBalances", mock.Anything, mock.Anything, givenUserID, tc.ExpectedBalances)
+				walletRepo.AssertCalled(t, "CashOutWalletBalances", mock.Anything, mock.Anything, givenUserID, givenWalletID, tc.ExpectedWalletBalances)
+			}
+
 			// Ensure all expectations were met
 			require.NoError(t, sqlmock.ExpectationsWereMet())
 		})
 	}
 }
+
+func TestWalletUsecase_TransferBetweenWallets(t *testing.T) {
+	basicRequest := dto.TransferWalletRequest{
+		FromWalletID: 1,
+		ToWalletID:   2,
+		CurrencyAmount: []dto.CurrencyAmount{
+			{Amount: money.FromInt(30), Currency: "SGD"},
+			{Amount: money.FromInt(20), Currency: "USD"},
+			{Amount: money.FromInt(10), Currency: "SGD"},
+		},
+	}
+	walletBalances := []domain.WalletCurrencyAmount{
+		{WalletID: 1, Amount: money.FromInt(100), Currency: "SGD"},
+		{WalletID: 2, Amount: money.FromInt(5), Currency: "SGD"},
+		{WalletID: 1, Amount: money.FromInt(50), Currency: "USD"},
+	}
+
+	testCases := []struct {
+		Title                        string
+		GivenRequest                 dto.TransferWalletRequest
+		WalletRepositoryReturnValues mocks.WalletRepositoryReturnValues
+		LedgerRepositoryReturnValues mocks.LedgerRepositoryReturnValues
+		ExpectedResponse             *dto.TransferWalletResponse
+		ExpectedError                error
+	}{
+		{
+			Title:        "ReturnsSuccessfully",
+			GivenRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:          []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByWalletIDs: []interface{}{walletBalances, nil},
+				CashOutWalletBalances:        []interface{}{nil},
+				TopUpWalletBalances:          []interface{}{nil},
+				CreateWalletHistory:          []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{nil},
+			},
+			ExpectedResponse: &dto.TransferWalletResponse{
+				FromWallet: dto.WalletBalances{WalletID: 1, CurrencyAmount: []dto.CurrencyAmount{
+					{Amount: money.FromInt(60), Currency: "SGD"},
+					{Amount: money.FromInt(30), Currency: "USD"},
+				}},
+				ToWallet: dto.WalletBalances{WalletID: 2, CurrencyAmount: []dto.CurrencyAmount{
+					{Amount: money.FromInt(45), Currency: "SGD"},
+					{Amount: money.FromInt(20), Currency: "USD"},
+				}},
+			},
+		},
+		{
+			Title: "ReturnsError_SameWallet",
+			GivenRequest: dto.TransferWalletRequest{
+				FromWalletID:   1,
+				ToWalletID:     1,
+				CurrencyAmount: basicRequest.CurrencyAmount,
+			},
+			ExpectedError: exception.ErrWalletTransferSameWallet,
+		},
+		{
+			Title:        "ReturnsError_NoWalletFound",
+			GivenRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID: []interface{}{nil, exception.ErrNoWalletFound},
+			},
+			ExpectedError: exception.ErrNoWalletFound,
+		},
+		{
+			Title: "ReturnsError_CurrencyNotInSourceWallet",
+			GivenRequest: dto.TransferWalletRequest{
+				FromWalletID:   1,
+				ToWalletID:     2,
+				CurrencyAmount: []dto.CurrencyAmount{{Amount: money.FromInt(1), Currency: "EUR"}},
+			},
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:          []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByWalletIDs: []interface{}{walletBalances, nil},
+			},
+			ExpectedError: exception.ErrWalletBalanceNotFound,
+		},
+		{
+			Title: "ReturnsError_InsufficientFundsInWallet",
+			GivenRequest: dto.TransferWalletRequest{
+				FromWalletID: 1,
+				ToWalletID:   2,
+				CurrencyAmount: []dto.CurrencyAmount{
+					{Amount: money.FromInt(60), Currency: "SGD"},
+					{Amount: money.FromInt(60), Currency: "SGD"},
+				},
+			},
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:          []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByWalletIDs: []interface{}{walletBalances, nil},
+			},
+			ExpectedError: exception.ErrInsufficientFundsInWallet,
+		},
+		{
+			Title:        "ReturnsError_CreateJournalEntry_InternalServerError",
+			GivenRequest: basicRequest,
+			WalletRepositoryReturnValues: mocks.WalletRepositoryReturnValues{
+				GetWalletByWalletID:          []interface{}{testdata.MockWallet(), nil},
+				GetWalletBalancesByWalletIDs: []interface{}{walletBalances, nil},
+				CashOutWalletBalances:        []interface{}{nil},
+				TopUpWalletBalances:          []interface{}{nil},
+			},
+			LedgerRepositoryReturnValues: mocks.LedgerRepositoryReturnValues{
+				CreateJournalEntry: []interface{}{errors.New("internal server error")},
+			},
+			ExpectedError: errors.New("internal server error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			walletRepo := new(mocks.WalletRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.GetWalletByWalletID...)
+			walletRepo.On("GetWalletBalancesByWalletIDs", mock.Anything, mock.Anything, mock.Anything, []int{1, 2}).
+				Return(tc.WalletRepositoryReturnValues.GetWalletBalancesByWalletIDs...)
+			walletRepo.On("CashOutWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.CashOutWalletBalances...)
+			walletRepo.On("TopUpWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.TopUpWalletBalances...)
+			walletRepo.On("CreateWalletHistory", mock.Anything, mock.Anything, mock.Anything).
+				Return(tc.WalletRepositoryReturnValues.CreateWalletHistory...)
+
+			// journal entries handed to the ledger must always balance per currency
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+				return entry.CheckBalanced() == nil
+			})).Run(func(args mock.Arguments) {
+				args.Get(2).(*domain.JournalEntry).ID = 7
+			}).Return(tc.LedgerRepositoryReturnValues.CreateJournalEntry...)
+
+			resp, err := walletUsecase.TransferBetweenWallets(context.Background(), 1, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedResponse, resp)
+
+				walletRepo.AssertCalled(t, "CashOutWalletBalances", mock.Anything, mock.Anything, 1, 1, map[string]money.Amount{
+					"SGD": money.FromInt(60),
+					"USD": money.FromInt(30),
+				})
+				walletRepo.AssertCalled(t, "TopUpWalletBalances", mock.Anything, mock.Anything, 1, 2, map[string]money.Amount{
+					"SGD": money.FromInt(45),
+					"USD": money.FromInt(20),
+				})
+				walletRepo.AssertCalled(t, "CreateWalletHistory", mock.Anything, mock.Anything, []domain.WalletHistory{
+					{WalletID: 1, UserID: 1, Type: domain.WalletHistoryTransferOut, CounterpartyWalletID: 2, JournalEntryID: 7, Currency: "SGD", Amount: money.FromInt(40)},
+					{WalletID: 2, UserID: 1, Type: domain.WalletHistoryTransferIn, CounterpartyWalletID: 1, JournalEntryID: 7, Currency: "SGD", Amount: money.FromInt(40)},
+					{WalletID: 1, UserID: 1, Type: domain.WalletHistoryTransferOut, CounterpartyWalletID: 2, JournalEntryID: 7, Currency: "USD", Amount: money.FromInt(20)},
+					{WalletID: 2, UserID: 1, Type: domain.WalletHistoryTransferIn, CounterpartyWalletID: 1, JournalEntryID: 7, Currency: "USD", Amount: money.FromInt(20)},
+				})
+			} else {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				require.Nil(t, resp)
+				walletRepo.AssertNotCalled(t, "CreateWalletHistory", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWalletUsecase_ExchangeWalletCurrency(t *testing.T) {
+	// the wallet holds SGD 100 and USD 50
+	rate := testdata.MockExchangeRate("SGD", "AUD")
+	fees, toAmount, err := utils.CalculateConversionDetails(money.FromInt(100), rate, testdata.MockFeeRule(domain.FeeProductExchange))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		Title         string
+		GivenRequest  dto.WalletExchangeRequest
+		GetWalletErr  error
+		ExpectedError error
+	}{
+		{
+			Title:        "ReturnsSuccessfully",
+			GivenRequest: dto.WalletExchangeRequest{FromCurrency: "SGD", ToCurrency: "AUD", FromAmount: money.FromInt(100)},
+		},
+		{
+			Title:         "ReturnsError_SameCurrency",
+			GivenRequest:  dto.WalletExchangeRequest{FromCurrency: "SGD", ToCurrency: "SGD", FromAmount: money.FromInt(10)},
+			ExpectedError: exception.ErrFromCurrencyEqualToCurrency,
+		},
+		{
+			Title:         "ReturnsError_CurrencyNotSupported",
+			GivenRequest:  dto.WalletExchangeRequest{FromCurrency: "SGD", ToCurrency: "JPY", FromAmount: money.FromInt(10)},
+			ExpectedError: exception.ErrCurrencyNotSupported,
+		},
+		{
+			Title:         "ReturnsError_NoWalletFound",
+			GivenRequest:  dto.WalletExchangeRequest{FromCurrency: "SGD", ToCurrency: "AUD", FromAmount: money.FromInt(10)},
+			GetWalletErr:  exception.ErrNoWalletFound,
+			ExpectedError: exception.ErrNoWalletFound,
+		},
+		{
+			Title:         "ReturnsError_CurrencyNotInWallet",
+			GivenRequest:  dto.WalletExchangeRequest{FromCurrency: "AUD", ToCurrency: "SGD", FromAmount: money.FromInt(10)},
+			ExpectedError: exception.ErrWalletBalanceNotFound,
+		},
+		{
+			Title:         "ReturnsError_InsufficientFundsInWallet",
+			GivenRequest:  dto.WalletExchangeRequest{FromCurrency: "USD", ToCurrency: "SGD", FromAmount: money.FromInt(51)},
+			ExpectedError: exception.ErrInsufficientFundsInWallet,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			walletRepo := new(mocks.WalletRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+			feeRepo := newFeeRepository()
+
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, new(mocks.BalanceRepository), ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), feeRepo)
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, 1).Return(testdata.MockWallet(), tc.GetWalletErr)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, 1, 1).Return(testdata.MockWalletCurrencyAmounts(), nil)
+			walletRepo.On("TopUpWalletBalances", mock.Anything, mock.Anything, 1, 1, mock.Anything).Return(nil)
+			walletRepo.On("CreateWalletHistory", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+				return entry.CheckBalanced() == nil
+			})).Run(func(args mock.Arguments) {
+				args.Get(2).(*domain.JournalEntry).ID = 7
+			}).Return(nil)
+
+			resp, err := walletUsecase.ExchangeWalletCurrency(context.Background(), 1, 1, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, &dto.WalletExchangeResponse{
+					WalletID:     1,
+					FromAmount:   money.FromInt(100),
+					FromCurrency: "SGD",
+					ToAmount:     toAmount,
+					ToCurrency:   "AUD",
+					Fee:          domain.TotalFees(fees),
+					Rate:         rate.Rate,
+					CurrencyAmount: []dto.CurrencyAmount{
+						{Amount: toAmount, Currency: "AUD"},
+						{Amount: 0, Currency: "SGD"},
+						{Amount: money.FromInt(50), Currency: "USD"},
+					},
+				}, resp)
+
+				// the wallet did not hold AUD before, its balance is created by the upsert
+				walletRepo.AssertCalled(t, "TopUpWalletBalances", mock.Anything, mock.Anything, 1, 1, map[string]money.Amount{
+					"SGD": 0,
+					"AUD": toAmount,
+				})
+				walletRepo.AssertCalled(t, "CreateWalletHistory", mock.Anything, mock.Anything, []domain.WalletHistory{
+					{WalletID: 1, UserID: 1, Type: domain.WalletHistoryExchangeOut, JournalEntryID: 7, Currency: "SGD", Amount: money.FromInt(100)},
+					{WalletID: 1, UserID: 1, Type: domain.WalletHistoryExchangeIn, JournalEntryID: 7, Currency: "AUD", Amount: toAmount},
+				})
+				feeRepo.AssertCalled(t, "CreateFeeCharges", mock.Anything, mock.Anything, mock.MatchedBy(func(charges []domain.FeeCharge) bool {
+					return len(charges) == len(fees) && charges[0].JournalEntryID == 7 && charges[0].TransactionID == 0
+				}))
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				require.Nil(t, resp)
+				walletRepo.AssertNotCalled(t, "TopUpWalletBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWalletUsecase_RenameWallet(t *testing.T) {
+	testCases := []struct {
+		Title         string
+		GivenName     string
+		GivenWallet   *domain.Wallet
+		GetWalletErr  error
+		NameTaken     bool
+		ExpectedError error
+	}{
+		{
+			Title:       "ReturnsSuccessfully",
+			GivenName:   "Holiday",
+			GivenWallet: testdata.MockWallet(),
+		},
+		{
+			Title:       "ReturnsSuccessfully_SameNameDifferentCase",
+			GivenName:   "Holiday",
+			GivenWallet: &domain.Wallet{ID: 1, UserID: 1, Name: "holiday", Status: domain.WalletStatusActive},
+			NameTaken:   true,
+		},
+		{
+			Title:       "ReturnsSuccessfully_ArchivedWallet",
+			GivenName:   "Holiday",
+			GivenWallet: &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusArchived},
+		},
+		{
+			Title:         "ReturnsError_EmptyName",
+			GivenWallet:   testdata.MockWallet(),
+			ExpectedError: exception.ErrWalletNameInvalid,
+		},
+		{
+			Title:         "ReturnsError_NoWalletFound",
+			GivenName:     "Holiday",
+			GetWalletErr:  exception.ErrNoWalletFound,
+			ExpectedError: exception.ErrNoWalletFound,
+		},
+		{
+			Title:         "ReturnsError_WalletClosed",
+			GivenName:     "Holiday",
+			GivenWallet:   &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusClosed},
+			ExpectedError: exception.ErrWalletClosed,
+		},
+		{
+			Title:         "ReturnsError_WalletAlreadyExists",
+			GivenName:     "Holiday",
+			GivenWallet:   testdata.MockWallet(),
+			NameTaken:     true,
+			ExpectedError: exception.ErrWalletAlreadyExists,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletRepo := new(mocks.WalletRepository)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), walletRepo, new(mocks.BalanceRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenWallet, tc.GetWalletErr)
+			walletRepo.On("CheckWalletExistsByName", mock.Anything, 1, tc.GivenName).Return(tc.NameTaken, nil)
+			walletRepo.On("UpdateWalletName", mock.Anything, 1, 1, tc.GivenName).Return(nil)
+
+			err := walletUsecase.RenameWallet(context.Background(), 1, 1, dto.RenameWalletRequest{Name: tc.GivenName})
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				walletRepo.AssertCalled(t, "UpdateWalletName", mock.Anything, 1, 1, tc.GivenName)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				walletRepo.AssertNotCalled(t, "UpdateWalletName", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWalletUsecase_ArchiveWallet(t *testing.T) {
+	testCases := []struct {
+		Title         string
+		GivenWallet   *domain.Wallet
+		GetWalletErr  error
+		ExpectedError error
+	}{
+		{
+			Title:       "ReturnsSuccessfully",
+			GivenWallet: testdata.MockWallet(),
+		},
+		{
+			Title:         "ReturnsError_NoWalletFound",
+			GetWalletErr:  exception.ErrNoWalletFound,
+			ExpectedError: exception.ErrNoWalletFound,
+		},
+		{
+			Title:         "ReturnsError_WalletArchived",
+			GivenWallet:   &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusArchived},
+			ExpectedError: exception.ErrWalletArchived,
+		},
+		{
+			Title:         "ReturnsError_WalletClosed",
+			GivenWallet:   &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusClosed},
+			ExpectedError: exception.ErrWalletClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			walletRepo := new(mocks.WalletRepository)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, new(mocks.BalanceRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenWallet, tc.GetWalletErr)
+			walletRepo.On("UpdateWalletStatus", mock.Anything, mock.Anything, 1, 1, domain.WalletStatusArchived).Return(nil)
+
+			err := walletUsecase.ArchiveWallet(context.Background(), 1, 1)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				walletRepo.AssertCalled(t, "UpdateWalletStatus", mock.Anything, mock.Anything, 1, 1, domain.WalletStatusArchived)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				walletRepo.AssertNotCalled(t, "UpdateWalletStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWalletUsecase_RestoreWallet(t *testing.T) {
+	testCases := []struct {
+		Title         string
+		GivenWallet   *domain.Wallet
+		GetWalletErr  error
+		ExpectedError error
+	}{
+		{
+			Title:       "ReturnsSuccessfully",
+			GivenWallet: &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusArchived},
+		},
+		{
+			Title:         "ReturnsError_NoWalletFound",
+			GetWalletErr:  exception.ErrNoWalletFound,
+			ExpectedError: exception.ErrNoWalletFound,
+		},
+		{
+			Title:         "ReturnsError_WalletNotArchived",
+			GivenWallet:   testdata.MockWallet(),
+			ExpectedError: exception.ErrWalletNotArchived,
+		},
+		{
+			Title:         "ReturnsError_WalletClosed",
+			GivenWallet:   &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusClosed},
+			ExpectedError: exception.ErrWalletClosed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			walletRepo := new(mocks.WalletRepository)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, new(mocks.BalanceRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenWallet, tc.GetWalletErr)
+			walletRepo.On("UpdateWalletStatus", mock.Anything, mock.Anything, 1, 1, domain.WalletStatusActive).Return(nil)
+
+			err := walletUsecase.RestoreWallet(context.Background(), 1, 1)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				walletRepo.AssertCalled(t, "UpdateWalletStatus", mock.Anything, mock.Anything, 1, 1, domain.WalletStatusActive)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				walletRepo.AssertNotCalled(t, "UpdateWalletStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWalletUsecase_CloseWallet(t *testing.T) {
+	testCases := []struct {
+		Title                string
+		GivenWallet          *domain.Wallet
+		GetWalletErr         error
+		GivenWalletBalances  []domain.WalletCurrencyAmount
+		GetWalletBalancesErr error
+		UpdateBalancesErr    error
+		ExpectedBalances     map[string]money.Amount
+		ExpectedSweptAmount  []dto.CurrencyAmount
+		ExpectedError        error
+	}{
+		{
+			Title:       "ReturnsSuccessfully",
+			GivenWallet: testdata.MockWallet(),
+			GivenWalletBalances: append(testdata.MockWalletCurrencyAmounts(), domain.WalletCurrencyAmount{
+				WalletID: 1, Amount: 0, Currency: "AUD",
+			}),
+			ExpectedBalances: map[string]money.Amount{
+				"SGD": money.FromInt(300),
+				"USD": money.FromInt(150),
+			},
+			ExpectedSweptAmount: []dto.CurrencyAmount{
+				{Amount: money.FromInt(100), Currency: "SGD"},
+				{Amount: money.FromInt(50), Currency: "USD"},
+			},
+		},
+		{
+			Title:       "ReturnsSuccessfully_ArchivedWalletWithCurrencyMissingFromMainBalance",
+			GivenWallet: &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusArchived},
+			GivenWalletBalances: []domain.WalletCurrencyAmount{
+				{WalletID: 1, Amount: money.MustParse("20.50"), Currency: "AUD"},
+			},
+			ExpectedBalances: map[string]money.Amount{
+				"AUD": money.MustParse("20.50"),
+			},
+			ExpectedSweptAmount: []dto.CurrencyAmount{
+				{Amount: money.MustParse("20.50"), Currency: "AUD"},
+			},
+		},
+		{
+			Title:                "ReturnsSuccessfully_EmptyWallet",
+			GivenWallet:          testdata.MockWallet(),
+			GetWalletBalancesErr: exception.ErrWalletBalancesNotFound,
+			ExpectedSweptAmount:  []dto.CurrencyAmount{},
+		},
+		{
+			Title:         "ReturnsError_NoWalletFound",
+			GetWalletErr:  exception.ErrNoWalletFound,
+			ExpectedError: exception.ErrNoWalletFound,
+		},
+		{
+			Title:         "ReturnsError_WalletClosed",
+			GivenWallet:   &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusClosed},
+			ExpectedError: exception.ErrWalletClosed,
+		},
+		{
+			Title:               "ReturnsError_UpdateBalances_InternalServerError",
+			GivenWallet:         testdata.MockWallet(),
+			GivenWalletBalances: testdata.MockWalletCurrencyAmounts(),
+			UpdateBalancesErr:   errors.New("internal server error"),
+			ExpectedError:       errors.New("internal server error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			txManager := new(usecaseMocks.TxManager)
+			txManager.On("WithTx", mock.Anything, mock.Anything).Return(nil)
+			walletRepo := new(mocks.WalletRepository)
+			balanceRepo := new(mocks.BalanceRepository)
+			ledgerRepo := new(mocks.LedgerRepository)
+
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, txManager, walletRepo, balanceRepo, ledgerRepo, repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenWallet, tc.GetWalletErr)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenWalletBalances, tc.GetWalletBalancesErr)
+			walletRepo.On("CashOutWalletBalances", mock.Anything, mock.Anything, 1, 1, mock.Anything).Return(nil)
+			walletRepo.On("CreateWalletHistory", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			walletRepo.On("UpdateWalletStatus", mock.Anything, mock.Anything, 1, 1, domain.WalletStatusClosed).Return(nil)
+			balanceRepo.On("GetBalances", mock.Anything, mock.Anything, 1).Return(testdata.NewBalances(), nil)
+			balanceRepo.On("UpdateBalances", mock.Anything, mock.Anything, 1, mock.Anything).Return(tc.UpdateBalancesErr)
+
+			// journal entries handed to the ledger must always balance per currency
+			ledgerRepo.On("CreateJournalEntry", mock.Anything, mock.Anything, mock.MatchedBy(func(entry *domain.JournalEntry) bool {
+				return entry.Type == domain.JournalCloseWallet && entry.CheckBalanced() == nil
+			})).Run(func(args mock.Arguments) {
+				args.Get(2).(*domain.JournalEntry).ID = 7
+			}).Return(nil)
+
+			resp, err := walletUsecase.CloseWallet(context.Background(), 1, 1)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, &dto.CloseWalletResponse{
+					WalletID:    1,
+					Status:      domain.WalletStatusClosed,
+					SweptAmount: tc.ExpectedSweptAmount,
+				}, resp)
+				walletRepo.AssertCalled(t, "UpdateWalletStatus", mock.Anything, mock.Anything, 1, 1, domain.WalletStatusClosed)
+
+				if len(tc.ExpectedSweptAmount) == 0 {
+					balanceRepo.AssertNotCalled(t, "UpdateBalances", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+					ledgerRepo.AssertNotCalled(t, "CreateJournalEntry", mock.Anything, mock.Anything, mock.Anything)
+					return
+				}
+
+				// everything left in the wallet is added to the main balance and the wallet is emptied
+				balanceRepo.AssertCalled(t, "UpdateBalances", mock.Anything, mock.Anything, 1, tc.ExpectedBalances)
+				emptied := make(map[string]money.Amount)
+				history := make([]domain.WalletHistory, 0, len(tc.ExpectedSweptAmount))
+				for _, ca := range tc.ExpectedSweptAmount {
+					emptied[ca.Currency] = 0
+					history = append(history, domain.WalletHistory{WalletID: 1, UserID: 1, Type: domain.WalletHistoryCloseOut, JournalEntryID: 7, Currency: ca.Currency, Amount: ca.Amount})
+				}
+				walletRepo.AssertCalled(t, "CashOutWalletBalances", mock.Anything, mock.Anything, 1, 1, emptied)
+				walletRepo.AssertCalled(t, "CreateWalletHistory", mock.Anything, mock.Anything, history)
+			} else {
+				require.EqualError(t, err, tc.ExpectedError.Error())
+				require.Nil(t, resp)
+				walletRepo.AssertNotCalled(t, "UpdateWalletStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWalletUsecase_SetWalletGoal(t *testing.T) {
+	basicRequest := dto.SetWalletGoalRequest{TargetAmount: money.FromInt(400), Currency: "SGD"}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("2006-01-02")
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format("2006-01-02")
+
+	testCases := []struct {
+		Title               string
+		GivenRequest        dto.SetWalletGoalRequest
+		GivenWallet         *domain.Wallet
+		GivenWalletBalances []domain.WalletCurrencyAmount
+		GetBalancesErr      error
+		ExpectedGoal        *domain.WalletGoal
+		ExpectedError       error
+	}{
+		{
+			Title:        "ReturnsSuccessfully",
+			GivenRequest: dto.SetWalletGoalRequest{TargetAmount: money.FromInt(400), Currency: "SGD", Deadline: tomorrow},
+			GivenWallet:  testdata.MockWallet(),
+			GivenWalletBalances: []domain.WalletCurrencyAmount{
+				{WalletID: 1, Amount: money.FromInt(100), Currency: "SGD"},
+			},
+			ExpectedGoal: &domain.WalletGoal{
+				WalletID:     1,
+				UserID:       1,
+				TargetAmount: money.FromInt(400),
+				Currency:     "SGD",
+				Deadline:     &tomorrow,
+				Progress: &domain.WalletGoalProgress{
+					SavedAmount:     money.FromInt(100),
+					RemainingAmount: money.FromInt(300),
+					Percentage:      money.MustParseRate("25"),
+				},
+			},
+		},
+		{
+			Title:          "ReturnsSuccessfully_NothingSavedYet",
+			GivenRequest:   basicRequest,
+			GivenWallet:    testdata.MockWallet(),
+			GetBalancesErr: exception.ErrWalletBalancesNotFound,
+			ExpectedGoal: &domain.WalletGoal{
+				WalletID:     1,
+				UserID:       1,
+				TargetAmount: money.FromInt(400),
+				Currency:     "SGD",
+				Progress: &domain.WalletGoalProgress{
+					RemainingAmount: money.FromInt(400),
+				},
+			},
+		},
+		{
+			Title:         "ReturnsError_WalletClosed",
+			GivenRequest:  basicRequest,
+			GivenWallet:   &domain.Wallet{ID: 1, UserID: 1, Status: domain.WalletStatusClosed},
+			ExpectedError: exception.ErrWalletClosed,
+		},
+		{
+			Title:         "ReturnsError_CurrencyNotSupported",
+			GivenRequest:  dto.SetWalletGoalRequest{TargetAmount: money.FromInt(400), Currency: "XYZ"},
+			GivenWallet:   testdata.MockWallet(),
+			ExpectedError: exception.ErrCurrencyNotSupported,
+		},
+		{
+			Title:         "ReturnsError_DeadlinePassed",
+			GivenRequest:  dto.SetWalletGoalRequest{TargetAmount: money.FromInt(400), Currency: "SGD", Deadline: yesterday},
+			GivenWallet:   testdata.MockWallet(),
+			ExpectedError: exception.ErrWalletGoalDeadlinePassed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletRepo := new(mocks.WalletRepository)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), walletRepo, new(mocks.BalanceRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			walletRepo.On("GetWalletByWalletID", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenWallet, nil)
+			walletRepo.On("UpsertWalletGoal", mock.Anything, mock.Anything).Return(nil)
+			walletRepo.On("GetWalletBalancesByUserIDAndWalletID", mock.Anything, mock.Anything, 1, 1).Return(tc.GivenWalletBalances, tc.GetBalancesErr)
+
+			goal, err := walletUsecase.SetWalletGoal(context.Background(), 1, 1, tc.GivenRequest)
+
+			if !strings.HasPrefix(tc.Title, "ReturnsError") {
+				require.NoError(t, err)
+				require.Equal(t, tc.ExpectedGoal, goal)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				require.Nil(t, goal)
+				walletRepo.AssertNotCalled(t, "UpsertWalletGoal", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestWalletUsecase_DeleteWalletGoal(t *testing.T) {
+	testCases := []struct {
+		Title         string
+		DeleteErr     error
+		ExpectedError error
+	}{
+		{
+			Title: "ReturnsSuccessfully",
+		},
+		{
+			Title:         "ReturnsError_WalletGoalNotFound",
+			DeleteErr:     exception.ErrWalletGoalNotFound,
+			ExpectedError: exception.ErrWalletGoalNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			walletRepo := new(mocks.WalletRepository)
+			walletUsecase := usecase.NewWalletUsecase(infrastructure.Config{}, new(usecaseMocks.TxManager), walletRepo, new(mocks.BalanceRepository), new(mocks.LedgerRepository), repository.NewStaticExchangeRateProvider(testdata.MockExchangeRates()), newCurrencyRepository(), newFXConversionRepository(), newFeeRepository())
+
+			walletRepo.On("DeleteWalletGoal", mock.Anything, 1, 1).Return(tc.DeleteErr)
+
+			err := walletUsecase.DeleteWalletGoal(context.Background(), 1, 1)
+
+			if tc.ExpectedError == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.ExpectedError)
+			}
+		})
+	}
+}
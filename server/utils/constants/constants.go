@@ -4,28 +4,128 @@ import "time"
 
 const SESSION_EXPIRY = 15 * time.Minute
 const PASSWORD_RESET_AUTH_TOKEN_EXPIRY = 7 * 24 * time.Hour
+const IDEMPOTENCY_KEY_EXPIRY = 24 * time.Hour
+
+// Number of attempts for a money-moving sql transaction that hits a deadlock or serialization failure
+const MAX_TX_RETRY_ATTEMPTS = 3
 
 // Cookie Name
 const JWT_COOKIE = "mw-token"
 
 // Transaction Status
 const (
-	FAILED  = "FAILED"
-	PENDING = "PENDING"
-	SUCCESS = "SUCCESS"
+	CREATED  = "CREATED"
+	PENDING  = "PENDING"
+	SUCCESS  = "SUCCESS"
+	FAILED   = "FAILED"
+	REVERSED = "REVERSED"
 )
 
-// Assumption: Depending on the user's mobile country code, the user
-// will be allowed to deposit and withdraw with that currency
-var CountryCodeToCurrencyMap = map[string]string{
-	"+65": "SGD", "+60": "MYR", "+1": "AUD", "+61": "USD",
-}
+// Direction of a transaction as seen by the user it belongs to
+const (
+	DIRECTION_SENT     = "sent"
+	DIRECTION_RECEIVED = "received"
+)
+
+// Sort orders of the transaction history
+const (
+	SORT_DATE_DESC   = "date_desc"
+	SORT_DATE_ASC    = "date_asc"
+	SORT_AMOUNT_DESC = "amount_desc"
+	SORT_AMOUNT_ASC  = "amount_asc"
+)
+
+// How long after a transfer the sender can still ask for a refund, when not configured
+const DEFAULT_REFUND_WINDOW = 72 * time.Hour
+
+// How long an exchange rate is cached in redis before it is read from the database again, when not configured
+const DEFAULT_EXCHANGE_RATE_CACHE_TTL = time.Minute
+
+// How long the currency catalogue is cached in redis before it is read from the database again, when not configured
+const DEFAULT_CURRENCY_CACHE_TTL = 5 * time.Minute
+
+// How long a previewed exchange rate stays locked for the user, when not configured
+const DEFAULT_EXCHANGE_QUOTE_TTL = 30 * time.Second
+
+// The currency that pairs without a direct exchange rate are converted through, when not configured
+const DEFAULT_EXCHANGE_BASE_CURRENCY = "USD"
+
+// How many wallets a user can have open at once, closed wallets are not counted, when not configured
+const DEFAULT_MAX_WALLETS_PER_USER = 10
+
+// How often the scheduler looks for due jobs such as auto-save rules, when not configured
+const DEFAULT_SCHEDULER_INTERVAL = time.Minute
+
+// How many runs in a row an auto-save rule can fail for lack of funds before it is paused, when not configured
+const DEFAULT_AUTO_SAVE_MAX_FAILURES = 3
+
+// How many due auto-save rules are run on each tick of the scheduler, when not configured
+const DEFAULT_AUTO_SAVE_BATCH_SIZE = 100
+
+// How many due scheduled transfers are run on each tick of the scheduler, when not configured
+const DEFAULT_SCHEDULED_TRANSFER_BATCH_SIZE = 100
 
-// Allowed toCurrencies, TODO: store this in database instead
-var ToCurrencies = map[string]struct{}{
-	"SGD": {}, "MYR": {}, "AUD": {}, "USD": {},
+// How long a payment request can be paid for after it is made, when not configured
+const DEFAULT_PAYMENT_REQUEST_EXPIRY = 7 * 24 * time.Hour
+
+// How long a payment request can be processing before the scheduler settles it, well past how long paying it can take
+const PAYMENT_REQUEST_PROCESSING_TIMEOUT = 15 * time.Minute
+
+// How long a scheduled transfer run can be pending before the scheduler settles it, well past how long its transfer can take
+const SCHEDULED_TRANSFER_RUN_PENDING_TIMEOUT = 15 * time.Minute
+
+// Refund Status
+const (
+	REQUESTED = "REQUESTED"
+	COMPLETED = "COMPLETED"
+	DECLINED  = "DECLINED"
+)
+
+// Exchange rate used to convert a refund back into the currency the sender paid in
+const (
+	REFUND_RATE_ORIGINAL = "original"
+	REFUND_RATE_CURRENT  = "current"
+)
+
+// Time ranges that past exchange rates can be charted over, the range is counted back from now
+const DEFAULT_EXCHANGE_RATE_HISTORY_RANGE = "30d"
+
+var ExchangeRateHistoryRanges = map[string]time.Duration{
+	"1d":  24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+	"90d": 90 * 24 * time.Hour,
+	"1y":  365 * 24 * time.Hour,
 }
 
+// Beneficiary decisions on a refund request
+const (
+	REFUND_APPROVE = "approve"
+	REFUND_DECLINE = "decline"
+)
+
+// Reason codes recorded against a FAILED transaction
+const (
+	REASON_BENEFICIARY_NOT_LINKED     = "BENEFICIARY_NOT_LINKED"
+	REASON_BENEFICIARY_INACTIVE       = "BENEFICIARY_INACTIVE"
+	REASON_BENEFICIARY_MFA_NOT_SET_UP = "BENEFICIARY_MFA_NOT_SET_UP"
+	REASON_SENDER_IS_BENEFICIARY      = "SENDER_IS_BENEFICIARY"
+	REASON_SENDER_WALLET_INVALID      = "SENDER_WALLET_INVALID"
+	REASON_INSUFFICIENT_FUNDS         = "INSUFFICIENT_FUNDS"
+	REASON_EXCHANGE_RATE_NOT_FOUND    = "EXCHANGE_RATE_NOT_FOUND"
+	REASON_EXCHANGE_QUOTE_INVALID     = "EXCHANGE_QUOTE_INVALID"
+	REASON_EXCHANGE_QUOTE_EXPIRED     = "EXCHANGE_QUOTE_EXPIRED"
+	REASON_EXCHANGE_QUOTE_USED        = "EXCHANGE_QUOTE_USED"
+	REASON_EXCHANGE_QUOTE_MISMATCH    = "EXCHANGE_QUOTE_MISMATCH"
+	REASON_CURRENCY_NOT_SUPPORTED     = "CURRENCY_NOT_SUPPORTED"
+	REASON_CURRENCY_DISABLED          = "CURRENCY_DISABLED"
+	REASON_FEES_EXCEED_AMOUNT         = "FEES_EXCEED_AMOUNT"
+	REASON_AMOUNT_TOO_LARGE           = "AMOUNT_TOO_LARGE"
+	REASON_WALLET_ARCHIVED            = "WALLET_ARCHIVED"
+	REASON_WALLET_CLOSED              = "WALLET_CLOSED"
+	REASON_INTERNAL_ERROR             = "INTERNAL_ERROR"
+)
+
 // Pagination limits
 const (
 	DEFAULT_PAGE_SIZE = 10
@@ -6,6 +6,8 @@ const (
 	CacheControl            = "Cache-Control"
 	ContentType             = "Content-Type"
 	ContentSecurityPolicy   = "Content-Security-Policy"
+	IdempotencyKey          = "Idempotency-Key"
+	IdempotentReplayed      = "Idempotent-Replayed"
 	Origin                  = "Origin"
 	Pragma                  = "Pragma"
 	ReferrerPolicy          = "Referrer-Policy"
@@ -15,6 +17,7 @@ const (
 	XFrameOptions           = "X-Frame-Options"
 	XHasNextPage            = "X-Has-Next-Page"
 	XHasPreviousPage        = "X-Has-Previous-Page"
+	XNextCursor             = "X-Next-Cursor"
 	XPage                   = "X-Page"
 	XPageSize               = "X-Page-Size"
 	XTotal                  = "X-Total"
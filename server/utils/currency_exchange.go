@@ -1,78 +1,165 @@
 package utils
 
-var exchangeRates = map[string]map[string]float64{
-	"SGD": {
-		"USD": 0.76,
-		"AUD": 1.03,
-		"MYR": 3.29,
-	},
-	"USD": {
-		"SGD": 1.32,
-		"AUD": 1.41,
-		"MYR": 4.43,
-	},
-	"AUD": {
-		"SGD": 0.97,
-		"USD": 0.71,
-		"MYR": 3.35,
-	},
-	"MYR": {
-		"SGD": 0.30,
-		"USD": 0.22,
-		"AUD": 0.30,
-	},
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// ResolveExchangeRate returns the rate from fromCurrency to toCurrency. Pairs without a direct
+// rate are derived through baseCurrency, and the path taken is reported on the returned rate.
+// A *exception.NoExchangeRouteError is returned when neither route exists.
+func ResolveExchangeRate(ctx context.Context, provider domain.ExchangeRateProvider, baseCurrency, fromCurrency, toCurrency string) (*domain.ExchangeRate, error) {
+	rate, err := provider.GetExchangeRate(ctx, fromCurrency, toCurrency)
+	if err == nil {
+		rate.Path = []string{fromCurrency, toCurrency}
+		return rate, nil
+	}
+	if !errors.Is(err, exception.ErrExchangeRateNotFound) {
+		return nil, err
+	}
+
+	noRoute := &exception.NoExchangeRouteError{FromCurrency: fromCurrency, ToCurrency: toCurrency, BaseCurrency: baseCurrency}
+	if baseCurrency == "" || baseCurrency == fromCurrency || baseCurrency == toCurrency {
+		return nil, noRoute
+	}
+
+	fromLeg, err := provider.GetExchangeRate(ctx, fromCurrency, baseCurrency)
+	if err != nil {
+		if errors.Is(err, exception.ErrExchangeRateNotFound) {
+			return nil, noRoute
+		}
+		return nil, err
+	}
+	toLeg, err := provider.GetExchangeRate(ctx, baseCurrency, toCurrency)
+	if err != nil {
+		if errors.Is(err, exception.ErrExchangeRateNotFound) {
+			return nil, noRoute
+		}
+		return nil, err
+	}
+
+	crossRate := CrossExchangeRate(*fromLeg, *toLeg)
+	return &crossRate, nil
 }
 
-var spreads = map[string]map[string]float64{
-	"SGD": {
-		"USD": 0.005,
-		"AUD": 0.004,
-		"MYR": 0.007,
-	},
-	"USD": {
-		"SGD": 0.006,
-		"AUD": 0.005,
-		"MYR": 0.008,
-	},
-	"AUD": {
-		"SGD": 0.003,
-		"USD": 0.004,
-		"MYR": 0.006,
-	},
-	"MYR": {
-		"SGD": 0.008,
-		"USD": 0.007,
-		"AUD": 0.009,
-	},
+// CrossExchangeRate chains two rates that meet in a common currency into one. The spreads of
+// both legs add up so that a derived pair never costs less than converting twice.
+func CrossExchangeRate(fromLeg, toLeg domain.ExchangeRate) domain.ExchangeRate {
+	effectiveAt := fromLeg.EffectiveAt
+	if toLeg.EffectiveAt.After(effectiveAt) {
+		effectiveAt = toLeg.EffectiveAt
+	}
+
+	return domain.ExchangeRate{
+		FromCurrency: fromLeg.FromCurrency,
+		ToCurrency:   toLeg.ToCurrency,
+		Rate:         fromLeg.Rate.Mul(toLeg.Rate),
+		Spread:       fromLeg.Spread + toLeg.Spread,
+		EffectiveAt:  effectiveAt,
+		Path:         append(append([]string{}, fromLeg.ConversionPath()...), toLeg.ToCurrency),
+	}
 }
 
-var spreadPercentage = 0.02
+// maxAmount bounds the amounts converted here, far enough inside the int64 range that
+// converting them, or doubling them while searching for a from amount, cannot overflow
+const maxAmount = money.Amount(math.MaxInt64 / 4)
+
+// maxConvertibleAmount is the largest amount that converts to no more than maxAmount at rate
+func maxConvertibleAmount(rate money.Rate) money.Amount {
+	if rate > money.RateOne {
+		return maxAmount.DivRate(rate, money.RoundDown)
+	}
+	return maxAmount
+}
 
-func CalculateConversionDetails(transferAmount float64, fromCurrency, toCurrency string) (float64, float64) {
-	// Calculate pegged rate (because exchange rates are hardcoded)
-	peggedRate := exchangeRates[fromCurrency][toCurrency]
+// Rounding rules for conversions:
+//   - fees are rounded half up in the source currency
+//   - the amount credited in the destination currency is rounded down
+//   - amounts worked backwards from a destination amount are rounded up
+//
+// The source amount is always split exactly into fees + converted principal,
+// so no cent is created or lost on the debit side.
 
-	// Calculate conversion amount
-	conversionAmount := transferAmount * peggedRate
+// CalculateConversionDetails returns the fees charged by rule (in rate.FromCurrency) and the
+// amount credited (in rate.ToCurrency) when transferring transferAmount.
+func CalculateConversionDetails(transferAmount money.Amount, rate domain.ExchangeRate, rule *domain.FeeRule) ([]domain.FeeCharge, money.Amount, error) {
+	if rate.Rate <= 0 {
+		return nil, 0, &exception.NoExchangeRouteError{FromCurrency: rate.FromCurrency, ToCurrency: rate.ToCurrency}
+	}
+	if transferAmount > maxConvertibleAmount(rate.Rate) {
+		return nil, 0, exception.ErrAmountTooLarge
+	}
 
-	// Calculate profit after adding the spread
-	profit := transferAmount * spreads[fromCurrency][toCurrency] * spreadPercentage
+	// Calculate the fees, kept in the source currency
+	fees, err := CalculateFees(transferAmount, rate.FromCurrency, rate.Spread, rule)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// Calculate the beneficiary received value
-	beneficiaryAmount := conversionAmount - profit/peggedRate
+	// Convert what is left after fees, rounding down so the beneficiary is never over-credited
+	principal := transferAmount - domain.TotalFees(fees)
+	beneficiaryAmount := principal.MulRate(rate.Rate, money.RoundDown).RoundToCurrency(rate.ToCurrency, money.RoundDown)
 
-	return profit, beneficiaryAmount
+	return fees, beneficiaryAmount, nil
 }
 
-func CalculateFromAmount(beneficiaryAmount float64, toCurrency, fromCurrency string) float64 {
-	// Calculate the conversion amount
-	conversionAmount := beneficiaryAmount / (1 - spreads[fromCurrency][toCurrency]*spreadPercentage)
+// CalculateFromAmount returns the smallest amount in rate.FromCurrency that credits at
+// least beneficiaryAmount in rate.ToCurrency once the fees of rule are taken.
+func CalculateFromAmount(beneficiaryAmount money.Amount, rate domain.ExchangeRate, rule *domain.FeeRule) (money.Amount, error) {
+	if rate.Rate <= 0 {
+		return 0, &exception.NoExchangeRouteError{FromCurrency: rate.FromCurrency, ToCurrency: rate.ToCurrency}
+	}
+
+	// Calculate the principal needed before conversion, each step is checked against the
+	// largest amount that can be converted before it is taken so that none of them overflows
+	limit := maxConvertibleAmount(rate.Rate)
+	if beneficiaryAmount > maxAmount || (rate.Rate < money.RateOne && beneficiaryAmount > limit.MulRate(rate.Rate, money.RoundDown)) {
+		return 0, exception.ErrAmountTooLarge
+	}
+	principal := beneficiaryAmount.DivRate(rate.Rate, money.RoundUp)
 
-	// Calculate the transfer amount
-	transferAmount := conversionAmount / exchangeRates[fromCurrency][toCurrency]
+	// Gross up the principal so that it still covers the highest fees the rule can charge
+	feeRate := maxFeeRate(rate.Spread, rule)
+	if feeRate >= money.RateOne {
+		return 0, exception.ErrFeesExceedAmount
+	}
+	if principal > limit.MulRate(money.RateOne-feeRate, money.RoundDown) {
+		return 0, exception.ErrAmountTooLarge
+	}
+	transferAmount := principal.DivRate(money.RateOne-feeRate, money.RoundUp)
+	if rule != nil {
+		transferAmount += maxFixedAmount(rule) + rule.MinFee
+	}
+	if transferAmount > limit {
+		return 0, exception.ErrAmountTooLarge
+	}
 
-	// Calculate the profit
-	profit := transferAmount * spreads[fromCurrency][toCurrency] * spreadPercentage
+	// Rounding, caps and tiers leave the estimate off either way, settle on the smallest
+	// amount that still covers the beneficiary amount
+	covers := func(amount money.Amount) bool {
+		_, received, err := CalculateConversionDetails(amount, rate, rule)
+		return err == nil && received >= beneficiaryAmount
+	}
+	for !covers(transferAmount) {
+		if transferAmount > limit/2 {
+			return 0, exception.ErrAmountTooLarge
+		}
+		transferAmount += transferAmount + money.FromMinorUnits(1)
+	}
+	var notCovered money.Amount
+	for transferAmount-notCovered > money.FromMinorUnits(1) {
+		mid := notCovered + (transferAmount-notCovered)/2
+		if covers(mid) {
+			transferAmount = mid
+		} else {
+			notCovered = mid
+		}
+	}
 
-	return transferAmount + profit
+	return transferAmount, nil
 }
@@ -0,0 +1,336 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/repository"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/utils"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/require"
+)
+
+var testCurrencies = []string{"SGD", "USD", "AUD", "MYR"}
+
+var testAmounts = []money.Amount{
+	money.MustParse("0.01"),
+	money.MustParse("0.99"),
+	money.MustParse("1.00"),
+	money.MustParse("33.33"),
+	money.MustParse("100.10"),
+	money.MustParse("12345.67"),
+	money.MustParse("999999.99"),
+}
+
+func TestCalculateConversionDetails(t *testing.T) {
+	for _, from := range testCurrencies {
+		for _, to := range testCurrencies {
+			if from == to {
+				continue
+			}
+
+			for _, amount := range testAmounts {
+				fees, received, err := utils.CalculateConversionDetails(amount, testdata.MockExchangeRate(from, to), testdata.MockFeeRule(domain.FeeProductExchange))
+				profit := domain.TotalFees(fees)
+				require.NoError(t, err)
+
+				require.False(t, profit.IsNegative(), "%s->%s %s: negative profit", from, to, amount)
+				require.False(t, received.IsNegative(), "%s->%s %s: negative amount received", from, to, amount)
+				require.True(t, profit <= amount, "%s->%s %s: profit exceeds amount", from, to, amount)
+
+				// the beneficiary must never get more than the exact conversion of what
+				// is left after profit, otherwise cents are created out of thin air
+				exact := exactConversion(t, amount-profit, from, to)
+				got := new(big.Rat).SetFrac64(received.MinorUnits(), 100)
+				require.True(t, got.Cmp(exact) <= 0, "%s->%s %s: received %s exceeds exact %s", from, to, amount, received, exact.FloatString(8))
+
+				// and truncation never loses a whole cent or more
+				diff := new(big.Rat).Sub(exact, got)
+				require.True(t, diff.Cmp(big.NewRat(1, 100)) < 0, "%s->%s %s: lost %s", from, to, amount, diff.FloatString(8))
+			}
+		}
+	}
+}
+
+func TestCalculateConversionDetails_SplitsSourceExactly(t *testing.T) {
+	fees, received, err := utils.CalculateConversionDetails(money.MustParse("100.00"), testdata.MockExchangeRate("SGD", "USD"), testdata.MockFeeRule(domain.FeeProductExchange))
+	require.NoError(t, err)
+
+	// 100.00 * (0.005 * 0.02) = 0.01 profit, 99.99 * 0.76 = 75.9924 -> 75.99
+	require.Equal(t, money.MustParse("0.01"), domain.TotalFees(fees))
+	require.Equal(t, money.MustParse("75.99"), received)
+}
+
+func TestCalculateFromAmount(t *testing.T) {
+	for _, from := range testCurrencies {
+		for _, to := range testCurrencies {
+			if from == to {
+				continue
+			}
+
+			for _, target := range testAmounts {
+				transferAmount, err := utils.CalculateFromAmount(target, testdata.MockExchangeRate(from, to), testdata.MockFeeRule(domain.FeeProductExchange))
+				require.NoError(t, err)
+
+				_, received, _ := utils.CalculateConversionDetails(transferAmount, testdata.MockExchangeRate(from, to), testdata.MockFeeRule(domain.FeeProductExchange))
+				require.True(t, received >= target, "%s->%s %s: sending %s only credits %s", from, to, target, transferAmount, received)
+
+				// one cent less must not be enough, the user is never overcharged
+				_, receivedLess, _ := utils.CalculateConversionDetails(transferAmount-money.FromMinorUnits(1), testdata.MockExchangeRate(from, to), testdata.MockFeeRule(domain.FeeProductExchange))
+				require.True(t, receivedLess < target, "%s->%s %s: %s is not the smallest amount", from, to, target, transferAmount)
+			}
+		}
+	}
+}
+
+func TestCalculateFromAmount_UnknownPair(t *testing.T) {
+	amount, err := utils.CalculateFromAmount(money.FromInt(10), testdata.MockExchangeRate("SGD", "XYZ"), testdata.MockFeeRule(domain.FeeProductExchange))
+
+	var noRoute *exception.NoExchangeRouteError
+	require.ErrorAs(t, err, &noRoute)
+	require.ErrorIs(t, err, exception.ErrExchangeRateNotFound)
+	require.Equal(t, money.Amount(0), amount)
+}
+
+func TestCalculateFromAmount_AmountTooLarge(t *testing.T) {
+	testCases := []struct {
+		Title             string
+		GivenTargetAmount money.Amount
+		GivenRate         money.Rate
+	}{
+		{Title: "ReturnsError_TinyRate", GivenTargetAmount: money.FromInt(1_000_000_000), GivenRate: money.MustParseRate("0.00000001")},
+		{Title: "ReturnsError_LargestTargetAmount", GivenTargetAmount: money.Amount(math.MaxInt64), GivenRate: money.RateOne},
+		{Title: "ReturnsError_LargeRate", GivenTargetAmount: money.Amount(math.MaxInt64 / 2), GivenRate: money.MustParseRate("15000")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			rate := testdata.MockExchangeRate("SGD", "USD")
+			rate.Rate = tc.GivenRate
+
+			amount, err := utils.CalculateFromAmount(tc.GivenTargetAmount, rate, testdata.MockFeeRule(domain.FeeProductExchange))
+			require.ErrorIs(t, err, exception.ErrAmountTooLarge)
+			require.Equal(t, money.Amount(0), amount)
+		})
+	}
+}
+
+func TestCalculateConversionDetails_AmountTooLarge(t *testing.T) {
+	rate := testdata.MockExchangeRate("SGD", "USD")
+	rate.Rate = money.MustParseRate("15000")
+
+	fees, received, err := utils.CalculateConversionDetails(money.MustParse("1000000000000000.00"), rate, testdata.MockFeeRule(domain.FeeProductExchange))
+	require.ErrorIs(t, err, exception.ErrAmountTooLarge)
+	require.Empty(t, fees)
+	require.Equal(t, money.Amount(0), received)
+}
+
+func TestCalculateConversionDetails_UnknownPair(t *testing.T) {
+	fees, received, err := utils.CalculateConversionDetails(money.FromInt(10), testdata.MockExchangeRate("SGD", "XYZ"), testdata.MockFeeRule(domain.FeeProductExchange))
+
+	var noRoute *exception.NoExchangeRouteError
+	require.ErrorAs(t, err, &noRoute)
+	require.Equal(t, "SGD", noRoute.FromCurrency)
+	require.Equal(t, "XYZ", noRoute.ToCurrency)
+	require.Empty(t, fees)
+	require.Equal(t, money.Amount(0), received)
+}
+
+// baseLegRates returns only the seeded rates into and out of baseCurrency,
+// so that every other pair has to be derived through it
+func baseLegRates(baseCurrency string) []domain.ExchangeRate {
+	var rates []domain.ExchangeRate
+	for _, rate := range testdata.MockExchangeRates() {
+		if rate.FromCurrency == baseCurrency || rate.ToCurrency == baseCurrency {
+			rates = append(rates, rate)
+		}
+	}
+	return rates
+}
+
+func enabledCurrencies() []string {
+	var codes []string
+	for _, currency := range testdata.MockCurrencies() {
+		if currency.Enabled {
+			codes = append(codes, currency.Code)
+		}
+	}
+	return codes
+}
+
+func TestResolveExchangeRate(t *testing.T) {
+	const baseCurrency = "USD"
+
+	type testCase struct {
+		Title          string
+		FromCurrency   string
+		ToCurrency     string
+		Rates          []domain.ExchangeRate
+		ExpectedPath   []string
+		ExpectedRate   money.Rate
+		ExpectedSpread money.Rate
+	}
+
+	var testCases []testCase
+	for _, from := range enabledCurrencies() {
+		for _, to := range enabledCurrencies() {
+			if from == to {
+				continue
+			}
+
+			direct := testdata.MockExchangeRate(from, to)
+			testCases = append(testCases, testCase{
+				Title:          fmt.Sprintf("ReturnsSuccessfully_Direct_%s_%s", from, to),
+				FromCurrency:   from,
+				ToCurrency:     to,
+				Rates:          testdata.MockExchangeRates(),
+				ExpectedPath:   []string{from, to},
+				ExpectedRate:   direct.Rate,
+				ExpectedSpread: direct.Spread,
+			})
+
+			// pairs touching the base currency are still direct when only the base legs exist
+			tc := testCase{
+				Title:          fmt.Sprintf("ReturnsSuccessfully_ThroughBase_%s_%s", from, to),
+				FromCurrency:   from,
+				ToCurrency:     to,
+				Rates:          baseLegRates(baseCurrency),
+				ExpectedPath:   []string{from, to},
+				ExpectedRate:   direct.Rate,
+				ExpectedSpread: direct.Spread,
+			}
+			if from != baseCurrency && to != baseCurrency {
+				fromLeg, toLeg := testdata.MockExchangeRate(from, baseCurrency), testdata.MockExchangeRate(baseCurrency, to)
+				tc.ExpectedPath = []string{from, baseCurrency, to}
+				tc.ExpectedRate = fromLeg.Rate.Mul(toLeg.Rate)
+				tc.ExpectedSpread = fromLeg.Spread + toLeg.Spread
+			}
+			testCases = append(testCases, tc)
+		}
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			provider := repository.NewStaticExchangeRateProvider(tc.Rates)
+
+			rate, err := utils.ResolveExchangeRate(context.Background(), provider, baseCurrency, tc.FromCurrency, tc.ToCurrency)
+			require.NoError(t, err)
+			require.Equal(t, tc.FromCurrency, rate.FromCurrency)
+			require.Equal(t, tc.ToCurrency, rate.ToCurrency)
+			require.Equal(t, tc.ExpectedPath, rate.Path)
+			require.Equal(t, tc.ExpectedRate, rate.Rate)
+			require.Equal(t, tc.ExpectedSpread, rate.Spread)
+
+			// a resolved pair always credits the beneficiary with something
+			_, received, err := utils.CalculateConversionDetails(money.FromInt(100), *rate, testdata.MockFeeRule(domain.FeeProductExchange))
+			require.NoError(t, err)
+			require.True(t, received.IsPositive(), "%s->%s credited %s", tc.FromCurrency, tc.ToCurrency, received)
+		})
+	}
+}
+
+type failingExchangeRateProvider struct {
+	err error
+}
+
+func (p failingExchangeRateProvider) GetExchangeRate(ctx context.Context, fromCurrency, toCurrency string) (*domain.ExchangeRate, error) {
+	return nil, p.err
+}
+
+func TestResolveExchangeRate_NoRoute(t *testing.T) {
+	errRedisDown := errors.New("redis down")
+
+	testCases := []struct {
+		Title         string
+		Provider      domain.ExchangeRateProvider
+		BaseCurrency  string
+		FromCurrency  string
+		ToCurrency    string
+		ExpectNoRoute bool
+		ExpectedError error
+	}{
+		{
+			Title:         "ReturnsError_NoBaseCurrency",
+			Provider:      repository.NewStaticExchangeRateProvider(baseLegRates("USD")),
+			FromCurrency:  "SGD",
+			ToCurrency:    "MYR",
+			ExpectNoRoute: true,
+			ExpectedError: exception.ErrExchangeRateNotFound,
+		},
+		{
+			Title:         "ReturnsError_BaseCurrencyIsPartOfThePair",
+			Provider:      repository.NewStaticExchangeRateProvider(baseLegRates("SGD")),
+			BaseCurrency:  "USD",
+			FromCurrency:  "USD",
+			ToCurrency:    "MYR",
+			ExpectNoRoute: true,
+			ExpectedError: exception.ErrExchangeRateNotFound,
+		},
+		{
+			Title:         "ReturnsError_MissingFromLeg",
+			Provider:      repository.NewStaticExchangeRateProvider(baseLegRates("USD")),
+			BaseCurrency:  "USD",
+			FromCurrency:  "XYZ",
+			ToCurrency:    "SGD",
+			ExpectNoRoute: true,
+			ExpectedError: exception.ErrExchangeRateNotFound,
+		},
+		{
+			Title:         "ReturnsError_MissingToLeg",
+			Provider:      repository.NewStaticExchangeRateProvider(baseLegRates("USD")),
+			BaseCurrency:  "USD",
+			FromCurrency:  "SGD",
+			ToCurrency:    "XYZ",
+			ExpectNoRoute: true,
+			ExpectedError: exception.ErrExchangeRateNotFound,
+		},
+		{
+			Title:         "ReturnsError_ProviderFailure",
+			Provider:      failingExchangeRateProvider{err: errRedisDown},
+			BaseCurrency:  "USD",
+			FromCurrency:  "SGD",
+			ToCurrency:    "MYR",
+			ExpectedError: errRedisDown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			rate, err := utils.ResolveExchangeRate(context.Background(), tc.Provider, tc.BaseCurrency, tc.FromCurrency, tc.ToCurrency)
+
+			require.Nil(t, rate)
+			require.ErrorIs(t, err, tc.ExpectedError)
+
+			var noRoute *exception.NoExchangeRouteError
+			require.Equal(t, tc.ExpectNoRoute, errors.As(err, &noRoute))
+			if tc.ExpectNoRoute {
+				require.Equal(t, tc.FromCurrency, noRoute.FromCurrency)
+				require.Equal(t, tc.ToCurrency, noRoute.ToCurrency)
+				require.Equal(t, tc.BaseCurrency, noRoute.BaseCurrency)
+			}
+		})
+	}
+}
+
+// pegged rates as seeded in testdata/exchange_rate.go
+var testRates = map[string]map[string]string{
+	"SGD": {"USD": "0.76", "AUD": "1.03", "MYR": "3.29"},
+	"USD": {"SGD": "1.32", "AUD": "1.41", "MYR": "4.43"},
+	"AUD": {"SGD": "0.97", "USD": "0.71", "MYR": "3.35"},
+	"MYR": {"SGD": "0.30", "USD": "0.22", "AUD": "0.30"},
+}
+
+func exactConversion(t *testing.T, principal money.Amount, from, to string) *big.Rat {
+	t.Helper()
+
+	rate, ok := new(big.Rat).SetString(testRates[from][to])
+	require.True(t, ok)
+	return new(big.Rat).Mul(new(big.Rat).SetFrac64(principal.MinorUnits(), 100), rate)
+}
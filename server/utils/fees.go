@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+)
+
+// CalculateFees returns the line items rule charges on amount, in currency. The spread
+// line is the share of spread the rule keeps, the percentage and fixed lines come from the
+// tier amount falls in, and together they are capped at the maximum fee or topped up to the
+// minimum fee of the rule. No fees are charged without a rule.
+func CalculateFees(amount money.Amount, currency string, spread money.Rate, rule *domain.FeeRule) ([]domain.FeeCharge, error) {
+	if rule == nil {
+		return nil, nil
+	}
+
+	percentage, fixedFee := rule.Percentage, rule.FixedAmount
+	if tier := rule.Tier(amount); tier != nil {
+		percentage, fixedFee = tier.Percentage, tier.FixedAmount
+	}
+
+	spreadFee := amount.MulRate(spread.Mul(rule.SpreadShare), money.RoundHalfUp)
+	percentageFee := amount.MulRate(percentage, money.RoundHalfUp)
+
+	// the cap comes off the percentage fee first, the fixed fee is only cut once that is gone
+	var minimumFee money.Amount
+	commission := percentageFee + fixedFee
+	switch {
+	case rule.MaxFee.IsPositive() && commission > rule.MaxFee:
+		excess := commission - rule.MaxFee
+		if excess > percentageFee {
+			fixedFee -= excess - percentageFee
+			excess = percentageFee
+		}
+		percentageFee -= excess
+	case commission < rule.MinFee:
+		minimumFee = rule.MinFee - commission
+	}
+
+	var fees []domain.FeeCharge
+	for _, line := range []struct {
+		feeType string
+		amount  money.Amount
+	}{
+		{domain.FeeTypeSpread, spreadFee},
+		{domain.FeeTypePercentage, percentageFee},
+		{domain.FeeTypeFixed, fixedFee},
+		{domain.FeeTypeMinimum, minimumFee},
+	} {
+		if !line.amount.IsPositive() {
+			continue
+		}
+		fees = append(fees, domain.FeeCharge{
+			FeeScheduleID: rule.FeeScheduleID,
+			FeeRuleID:     rule.ID,
+			Product:       rule.Product,
+			Type:          line.feeType,
+			Currency:      currency,
+			Amount:        line.amount,
+		})
+	}
+
+	if domain.TotalFees(fees) > amount {
+		return nil, exception.ErrFeesExceedAmount
+	}
+
+	return fees, nil
+}
+
+// maxFeeRate is the highest fraction of an amount rule can charge before fixed and minimum fees
+func maxFeeRate(spread money.Rate, rule *domain.FeeRule) money.Rate {
+	if rule == nil {
+		return 0
+	}
+
+	percentage := rule.Percentage
+	for _, tier := range rule.Tiers {
+		if tier.Percentage > percentage {
+			percentage = tier.Percentage
+		}
+	}
+	return spread.Mul(rule.SpreadShare) + percentage
+}
+
+// maxFixedAmount is the highest fixed fee rule can charge
+func maxFixedAmount(rule *domain.FeeRule) money.Amount {
+	fixedAmount := rule.FixedAmount
+	for _, tier := range rule.Tiers {
+		if tier.FixedAmount > fixedAmount {
+			fixedAmount = tier.FixedAmount
+		}
+	}
+	return fixedAmount
+}
@@ -0,0 +1,153 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/domain"
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/testdata"
+	"github.com/LeonLow97/go-clean-architecture/utils"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateFees(t *testing.T) {
+	transferRule := domain.FeeRule{
+		ID:            7,
+		FeeScheduleID: 3,
+		Product:       domain.FeeProductTransfer,
+		FromCurrency:  "SGD",
+		ToCurrency:    domain.FeeCorridorAny,
+		SpreadShare:   money.MustParseRate("0.5"),
+		Percentage:    money.MustParseRate("0.01"),
+		FixedAmount:   money.MustParse("0.50"),
+		MinFee:        money.MustParse("2.00"),
+		MaxFee:        money.MustParse("20.00"),
+		Tiers: []domain.FeeTier{
+			{MinAmount: money.MustParse("1000.00"), Percentage: money.MustParseRate("0.005"), FixedAmount: money.MustParse("0.20")},
+			{MinAmount: money.MustParse("500.00"), Percentage: money.MustParseRate("0.008")},
+		},
+	}
+
+	fee := func(feeType, amount string) domain.FeeCharge {
+		return domain.FeeCharge{FeeScheduleID: 3, FeeRuleID: 7, Product: domain.FeeProductTransfer, Type: feeType, Currency: "SGD", Amount: money.MustParse(amount)}
+	}
+
+	testCases := []struct {
+		Title        string
+		Amount       money.Amount
+		Spread       money.Rate
+		Rule         *domain.FeeRule
+		ExpectedFees []domain.FeeCharge
+		ExpectedErr  error
+	}{
+		{
+			Title:  "ReturnsSuccessfully_PercentageAndFixedFee",
+			Amount: money.MustParse("300.00"),
+			Rule:   &transferRule,
+			ExpectedFees: []domain.FeeCharge{
+				fee(domain.FeeTypePercentage, "3.00"),
+				fee(domain.FeeTypeFixed, "0.50"),
+			},
+		},
+		{
+			Title:  "ReturnsSuccessfully_SpreadShare",
+			Amount: money.MustParse("300.00"),
+			Spread: money.MustParseRate("0.004"),
+			Rule:   &transferRule,
+			ExpectedFees: []domain.FeeCharge{
+				fee(domain.FeeTypeSpread, "0.60"),
+				fee(domain.FeeTypePercentage, "3.00"),
+				fee(domain.FeeTypeFixed, "0.50"),
+			},
+		},
+		{
+			Title:  "ReturnsSuccessfully_ToppedUpToMinimumFee",
+			Amount: money.MustParse("100.00"),
+			Rule:   &transferRule,
+			ExpectedFees: []domain.FeeCharge{
+				fee(domain.FeeTypePercentage, "1.00"),
+				fee(domain.FeeTypeFixed, "0.50"),
+				fee(domain.FeeTypeMinimum, "0.50"),
+			},
+		},
+		{
+			Title:  "ReturnsSuccessfully_TierReached",
+			Amount: money.MustParse("500.00"),
+			Rule:   &transferRule,
+			ExpectedFees: []domain.FeeCharge{
+				fee(domain.FeeTypePercentage, "4.00"),
+			},
+		},
+		{
+			Title:  "ReturnsSuccessfully_HighestTierReached",
+			Amount: money.MustParse("2000.00"),
+			Rule:   &transferRule,
+			ExpectedFees: []domain.FeeCharge{
+				fee(domain.FeeTypePercentage, "10.00"),
+				fee(domain.FeeTypeFixed, "0.20"),
+			},
+		},
+		{
+			Title:  "ReturnsSuccessfully_CappedAtMaximumFee",
+			Amount: money.MustParse("5000.00"),
+			Rule:   &transferRule,
+			ExpectedFees: []domain.FeeCharge{
+				fee(domain.FeeTypePercentage, "19.80"),
+				fee(domain.FeeTypeFixed, "0.20"),
+			},
+		},
+		{
+			Title:  "ReturnsSuccessfully_NoRuleIsFree",
+			Amount: money.MustParse("300.00"),
+			Spread: money.MustParseRate("0.004"),
+		},
+		{
+			Title:       "ReturnsError_FeesExceedAmount",
+			Amount:      money.MustParse("1.00"),
+			Rule:        &transferRule,
+			ExpectedErr: exception.ErrFeesExceedAmount,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Title, func(t *testing.T) {
+			fees, err := utils.CalculateFees(tc.Amount, "SGD", tc.Spread, tc.Rule)
+			if tc.ExpectedErr != nil {
+				require.ErrorIs(t, err, tc.ExpectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.ExpectedFees, fees)
+		})
+	}
+}
+
+func TestCalculateFromAmount_WithFixedAndTieredFees(t *testing.T) {
+	rule := &domain.FeeRule{
+		Product:      domain.FeeProductExchange,
+		FromCurrency: "SGD",
+		ToCurrency:   domain.FeeCorridorAny,
+		SpreadShare:  money.MustParseRate("0.02"),
+		Percentage:   money.MustParseRate("0.01"),
+		FixedAmount:  money.MustParse("1.00"),
+		MinFee:       money.MustParse("3.00"),
+		MaxFee:       money.MustParse("15.00"),
+		Tiers: []domain.FeeTier{
+			{MinAmount: money.MustParse("1000.00"), Percentage: money.MustParseRate("0.005")},
+		},
+	}
+	rate := testdata.MockExchangeRate("SGD", "USD")
+
+	for _, target := range testAmounts {
+		transferAmount, err := utils.CalculateFromAmount(target, rate, rule)
+		require.NoError(t, err)
+
+		_, received, err := utils.CalculateConversionDetails(transferAmount, rate, rule)
+		require.NoError(t, err)
+		require.True(t, received >= target, "%s: sending %s only credits %s", target, transferAmount, received)
+
+		_, receivedLess, err := utils.CalculateConversionDetails(transferAmount-money.FromMinorUnits(1), rate, rule)
+		require.True(t, err != nil || receivedLess < target, "%s: %s is not the smallest amount", target, transferAmount)
+	}
+}
@@ -6,12 +6,16 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	apiErr "github.com/LeonLow97/go-clean-architecture/exception/response"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/go-playground/form"
 	"github.com/gorilla/mux"
 )
 
+const dateLayout = "2006-01-02"
+
 // ReadJSONBody decodes the JSON body from an HTTP request into the provided struct.
 func ReadJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
 	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
@@ -56,9 +60,28 @@ func ReadURLParamsString(w http.ResponseWriter, r *http.Request, paramName strin
 
 // ReadQueryParams decodes query parameters into a struct
 func ReadQueryParams(dest interface{}, r *http.Request) error {
-	if err := form.NewDecoder().Decode(dest, r.URL.Query()); err != nil {
+	if err := newQueryDecoder().Decode(dest, r.URL.Query()); err != nil {
 		log.Printf("failed to read query params with error: %v\n", err)
 		return err
 	}
 	return nil
 }
+
+func newQueryDecoder() *form.Decoder {
+	decoder := form.NewDecoder()
+
+	// amounts are decimal strings such as "10.50"
+	decoder.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		return money.Parse(vals[0])
+	}, money.Amount(0))
+
+	// times are either a date on its own, taken as midnight UTC, or an RFC 3339 timestamp
+	decoder.RegisterCustomTypeFunc(func(vals []string) (interface{}, error) {
+		if t, err := time.Parse(dateLayout, vals[0]); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.RFC3339, vals[0])
+	}, time.Time{})
+
+	return decoder
+}
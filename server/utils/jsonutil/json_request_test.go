@@ -6,8 +6,10 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/LeonLow97/go-clean-architecture/utils/jsonutil"
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/require"
 )
@@ -133,4 +135,28 @@ func TestReadQueryParams(t *testing.T) {
 		require.Error(t, err)
 		require.Equal(t, err.Error(), "Field Namespace:key ERROR:Invalid Integer Value 'value' Type 'int' Namespace 'key'")
 	})
+	t.Run("AmountsAndTimes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?amount=10.50&from=2026-10-01&to=2026-10-17T08:30:00%2B08:00", nil)
+		var params struct {
+			Amount money.Amount `form:"amount"`
+			From   time.Time    `form:"from"`
+			To     time.Time    `form:"to"`
+		}
+
+		err := jsonutil.ReadQueryParams(&params, req)
+		require.NoError(t, err)
+		require.Equal(t, money.MustParse("10.50"), params.Amount)
+		require.Equal(t, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC), params.From)
+		require.True(t, time.Date(2026, time.October, 17, 0, 30, 0, 0, time.UTC).Equal(params.To))
+	})
+
+	t.Run("InvalidAmount", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/?amount=ten", nil)
+		var params struct {
+			Amount money.Amount `form:"amount"`
+		}
+
+		err := jsonutil.ReadQueryParams(&params, req)
+		require.Error(t, err)
+	})
 }
@@ -112,8 +112,19 @@ func ErrorJSON(w http.ResponseWriter, errMessage string, status ...int) error {
 	return WriteJSON(w, statusCode, payload)
 }
 
-// SetPaginatorHeaders sets the paginator details in the response headers
+// SetPaginatorHeaders sets the paginator details in the response headers. Keyset pages are not
+// counted, they carry the cursor of the next page instead of totals and page numbers.
 func SetPaginatorHeaders(w http.ResponseWriter, paginator *pagination.Paginator) {
+	if paginator.Keyset() {
+		w.Header().Set(headers.XPageSize, strconv.FormatInt(paginator.PageSize, 10))
+		w.Header().Set(headers.XHasNextPage, strconv.FormatBool(paginator.HasNextPage()))
+		w.Header().Set(headers.XHasPreviousPage, strconv.FormatBool(paginator.HasPreviousPage()))
+		if paginator.NextCursor != "" {
+			w.Header().Set(headers.XNextCursor, paginator.NextCursor)
+		}
+		return
+	}
+
 	if paginator.Page > paginator.TotalPages() {
 		paginator.Page = paginator.TotalPages()
 	}
@@ -229,6 +229,25 @@ func TestSetPaginatorHeaders(t *testing.T) {
 				headers.XHasPreviousPage: "false",
 			},
 		},
+		{
+			name: "KeysetPaginator",
+			paginator: func() *pagination.Paginator {
+				cursor := "MTc2MDY5MzQxNTEyMzQ1Njo0Mg"
+				return &pagination.Paginator{
+					PageSize:   10,
+					Cursor:     &cursor,
+					NextCursor: "MTc2MDY5MzQxMDAwMDAwMDo0MQ",
+				}
+			}(),
+			expectedHeaders: map[string]string{
+				headers.XTotal:           "",
+				headers.XPage:            "",
+				headers.XPageSize:        "10",
+				headers.XNextCursor:      "MTc2MDY5MzQxMDAwMDAwMDo0MQ",
+				headers.XHasNextPage:     "true",
+				headers.XHasPreviousPage: "true",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,439 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Amount is a monetary value stored as an integer number of hundredths.
+// The scale is fixed at two decimal places to match the NUMERIC(20,2) columns
+// in Postgres, so additions and subtractions are always exact. Only
+// multiplication or division by a Rate can produce fractions of a cent, and
+// those operations take an explicit RoundingMode.
+type Amount int64
+
+// Rate is a non-monetary multiplier (exchange rate, spread or fee percentage)
+// stored as an integer number of 1e-8 units, matching NUMERIC(20,8).
+type Rate int64
+
+const (
+	// Scale is the number of decimal places held by an Amount
+	Scale = 2
+	// RateScale is the number of decimal places held by a Rate
+	RateScale = 8
+
+	amountUnit = 100
+	rateUnit   = 100_000_000
+
+	// RateOne is the multiplicative identity for rates
+	RateOne Rate = rateUnit
+)
+
+// maxDigits is the largest number of integer digits that can be parsed without
+// overflowing an int64 once the fractional digits are appended.
+const maxDigits = 18
+
+var (
+	ErrInvalidAmount   = errors.New("money: invalid amount")
+	ErrTooManyDecimals = errors.New("money: too many decimal places")
+	ErrOutOfRange      = errors.New("money: value out of range")
+)
+
+// RoundingMode decides what happens to the digits that do not fit in the
+// target precision after a multiplication or division.
+type RoundingMode int
+
+const (
+	// RoundDown truncates towards zero. Used for amounts credited to a user after
+	// a conversion so the platform never pays out more than it received.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero. Used when working backwards from a target
+	// amount so the user is never short of what they asked for.
+	RoundUp
+	// RoundHalfUp rounds to the nearest value, with ties away from zero.
+	// Used for fees and profit.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest value, with ties to the even neighbour.
+	RoundHalfEven
+)
+
+// FromInt returns an Amount for a whole number of major units, e.g. FromInt(5) is 5.00
+func FromInt(major int64) Amount {
+	return Amount(major * amountUnit)
+}
+
+// FromMinorUnits returns an Amount from a number of hundredths, e.g. FromMinorUnits(5) is 0.05
+func FromMinorUnits(minor int64) Amount {
+	return Amount(minor)
+}
+
+// Parse converts a decimal string such as "12.34" into an Amount. Values with
+// more than two decimal places are rejected rather than silently rounded.
+func Parse(s string) (Amount, error) {
+	v, err := parseFixed(s, Scale)
+	return Amount(v), err
+}
+
+// MustParse is like Parse but panics on error. Intended for constants and tests.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// MinorUnits returns the amount as an integer number of hundredths
+func (a Amount) MinorUnits() int64 {
+	return int64(a)
+}
+
+// IsZero reports whether the amount is exactly zero
+func (a Amount) IsZero() bool {
+	return a == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (a Amount) IsPositive() bool {
+	return a > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (a Amount) IsNegative() bool {
+	return a < 0
+}
+
+// Neg returns the amount with its sign flipped
+func (a Amount) Neg() Amount {
+	return -a
+}
+
+// String formats the amount with exactly two decimal places, e.g. "-12.30"
+func (a Amount) String() string {
+	return formatFixed(int64(a), Scale)
+}
+
+// MulRate multiplies the amount by a rate and rounds the result back to two
+// decimal places using the given mode.
+func (a Amount) MulRate(r Rate, mode RoundingMode) Amount {
+	num := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(r)))
+	return Amount(divRound(num, big.NewInt(rateUnit), mode))
+}
+
+// DivRate divides the amount by a rate and rounds the result back to two
+// decimal places using the given mode. Dividing by a zero rate panics.
+func (a Amount) DivRate(r Rate, mode RoundingMode) Amount {
+	if r == 0 {
+		panic("money: division by zero rate")
+	}
+	num := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(rateUnit))
+	return Amount(divRound(num, big.NewInt(int64(r)), mode))
+}
+
+// Prorate scales the amount by part/whole and rounds the result with the given
+// mode, e.g. the share of a payment that belongs to a partial refund. A zero
+// whole panics.
+func (a Amount) Prorate(part, whole Amount, mode RoundingMode) Amount {
+	if whole == 0 {
+		panic("money: prorate over a zero amount")
+	}
+	num := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(int64(part)))
+	den := big.NewInt(int64(whole))
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+	return Amount(divRound(num, den, mode))
+}
+
+// Ratio returns the amount divided by another as a Rate, rounded to eight decimal
+// places with the given mode, e.g. the effective exchange rate between what was paid
+// and what was received. A zero divisor panics.
+func (a Amount) Ratio(o Amount, mode RoundingMode) Rate {
+	if o == 0 {
+		panic("money: ratio over a zero amount")
+	}
+	num := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(rateUnit))
+	den := big.NewInt(int64(o))
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+	return Rate(divRound(num, den, mode))
+}
+
+// RoundToCurrency rounds the amount to the number of minor units used by the
+// currency. Currencies with two or more minor units are returned unchanged
+// because an Amount cannot hold more than two decimal places.
+func (a Amount) RoundToCurrency(currency string, mode RoundingMode) Amount {
+	exponent := Exponent(currency)
+	if exponent >= Scale {
+		return a
+	}
+
+	factor := int64(1)
+	for i := exponent; i < Scale; i++ {
+		factor *= 10
+	}
+
+	q := divRound(big.NewInt(int64(a)), big.NewInt(factor), mode)
+	return Amount(q * factor)
+}
+
+// Scan implements sql.Scanner so that NUMERIC columns can be read with sqlx
+func (a *Amount) Scan(src interface{}) error {
+	v, err := scanFixed(src, Scale)
+	if err != nil {
+		return err
+	}
+	*a = Amount(v)
+	return nil
+}
+
+// Value implements driver.Valuer. The amount is sent as a decimal string so
+// that Postgres stores it without going through a float.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// MarshalJSON encodes the amount as a JSON number with two decimal places
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a quoted decimal string
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalFixed(data, Scale)
+	if err != nil {
+		return err
+	}
+	*a = Amount(v)
+	return nil
+}
+
+// ParseRate converts a decimal string such as "0.76" into a Rate
+func ParseRate(s string) (Rate, error) {
+	v, err := parseFixed(s, RateScale)
+	return Rate(v), err
+}
+
+// MustParseRate is like ParseRate but panics on error. Intended for constants and tests.
+func MustParseRate(s string) Rate {
+	r, err := ParseRate(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Mul multiplies two rates, rounding half up to eight decimal places
+func (r Rate) Mul(o Rate) Rate {
+	num := new(big.Int).Mul(big.NewInt(int64(r)), big.NewInt(int64(o)))
+	return Rate(divRound(num, big.NewInt(rateUnit), RoundHalfUp))
+}
+
+// String formats the rate with trailing zeros removed, e.g. "0.76"
+func (r Rate) String() string {
+	s := formatFixed(int64(r), RateScale)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// Scan implements sql.Scanner so that NUMERIC columns can be read with sqlx
+func (r *Rate) Scan(src interface{}) error {
+	v, err := scanFixed(src, RateScale)
+	if err != nil {
+		return err
+	}
+	*r = Rate(v)
+	return nil
+}
+
+// Value implements driver.Valuer
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// MarshalJSON encodes the rate as a JSON number
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a quoted decimal string
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalFixed(data, RateScale)
+	if err != nil {
+		return err
+	}
+	*r = Rate(v)
+	return nil
+}
+
+// exponents holds the number of minor units for currencies that do not use two.
+// Anything missing from the map is assumed to use two.
+var (
+	exponentsMu sync.RWMutex
+	exponents   = map[string]int{
+		"JPY": 0, "KRW": 0, "VND": 0, "IDR": 0,
+	}
+)
+
+// Exponent returns the number of minor units (decimal places) used by a currency
+func Exponent(currency string) int {
+	exponentsMu.RLock()
+	defer exponentsMu.RUnlock()
+
+	if e, found := exponents[currency]; found {
+		return e
+	}
+	return Scale
+}
+
+// SetExponent overrides the number of minor units used by a currency
+func SetExponent(currency string, exponent int) {
+	exponentsMu.Lock()
+	defer exponentsMu.Unlock()
+
+	exponents[currency] = exponent
+}
+
+// divRound divides num by den (den > 0) and rounds the quotient with the given mode
+func divRound(num, den *big.Int, mode RoundingMode) int64 {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() != 0 {
+		// the direction to move q if rounding away from zero
+		step := int64(num.Sign())
+
+		switch mode {
+		case RoundUp:
+			q.Add(q, big.NewInt(step))
+		case RoundHalfUp, RoundHalfEven:
+			twice := new(big.Int).Abs(r)
+			twice.Lsh(twice, 1)
+			switch twice.Cmp(den) {
+			case 1:
+				q.Add(q, big.NewInt(step))
+			case 0:
+				if mode == RoundHalfUp || q.Bit(0) == 1 {
+					q.Add(q, big.NewInt(step))
+				}
+			}
+		}
+	}
+
+	if !q.IsInt64() {
+		panic("money: result out of range")
+	}
+	return q.Int64()
+}
+
+func formatFixed(v int64, scale int) string {
+	sign := ""
+	u := uint64(v)
+	if v < 0 {
+		sign = "-"
+		u = uint64(-v)
+	}
+
+	digits := strconv.FormatUint(u, 10)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	split := len(digits) - scale
+	return sign + digits[:split] + "." + digits[split:]
+}
+
+func parseFixed(s string, scale int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	if hasDot && fracPart == "" {
+		return 0, ErrInvalidAmount
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, ErrInvalidAmount
+	}
+
+	// extra decimal places are only accepted if they are zeros
+	if len(fracPart) > scale {
+		if strings.Trim(fracPart[scale:], "0") != "" {
+			return 0, ErrTooManyDecimals
+		}
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if len(intPart)+scale > maxDigits {
+		return 0, ErrOutOfRange
+	}
+
+	v, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, ErrOutOfRange
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func scanFixed(src interface{}, scale int) (int64, error) {
+	switch v := src.(type) {
+	case nil:
+		return 0, nil
+	case string:
+		return parseFixed(v, scale)
+	case []byte:
+		return parseFixed(string(v), scale)
+	case int64:
+		return parseFixed(strconv.FormatInt(v, 10), scale)
+	case float64:
+		// floats only reach here from drivers that do not return NUMERIC as text,
+		// round to the target scale before parsing so no binary noise leaks in
+		return parseFixed(strconv.FormatFloat(v, 'f', scale, 64), scale)
+	default:
+		return 0, fmt.Errorf("money: cannot scan %T", src)
+	}
+}
+
+func unmarshalFixed(data []byte, scale int) (int64, error) {
+	s := string(data)
+	if s == "null" {
+		return 0, nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return parseFixed(s, scale)
+}
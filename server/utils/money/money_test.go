@@ -0,0 +1,163 @@
+package money_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/money"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected money.Amount
+		err      error
+	}{
+		{"0", 0, nil},
+		{"12.34", money.FromMinorUnits(1234), nil},
+		{"-12.3", money.FromMinorUnits(-1230), nil},
+		{"+5", money.FromInt(5), nil},
+		{".5", money.FromMinorUnits(50), nil},
+		{"0.10000", money.FromMinorUnits(10), nil}, // trailing zeros are not extra precision
+		{"0.001", 0, money.ErrTooManyDecimals},
+		{"1.", 0, money.ErrInvalidAmount},
+		{"1e5", 0, money.ErrInvalidAmount},
+		{"", 0, money.ErrInvalidAmount},
+		{"1000000000000000000", 0, money.ErrOutOfRange},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			amount, err := money.Parse(tc.input)
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.expected, amount)
+		})
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	require.Equal(t, "0.00", money.Amount(0).String())
+	require.Equal(t, "0.05", money.FromMinorUnits(5).String())
+	require.Equal(t, "-0.05", money.FromMinorUnits(-5).String())
+	require.Equal(t, "1234.50", money.MustParse("1234.5").String())
+}
+
+func TestAmount_MulRate(t *testing.T) {
+	rate := money.MustParseRate("0.5")
+
+	tests := []struct {
+		name     string
+		amount   money.Amount
+		mode     money.RoundingMode
+		expected money.Amount
+	}{
+		{"RoundDown", money.MustParse("0.05"), money.RoundDown, money.MustParse("0.02")},
+		{"RoundUp", money.MustParse("0.05"), money.RoundUp, money.MustParse("0.03")},
+		{"RoundHalfUp", money.MustParse("0.05"), money.RoundHalfUp, money.MustParse("0.03")},
+		{"RoundHalfEven_Down", money.MustParse("0.05"), money.RoundHalfEven, money.MustParse("0.02")},
+		{"RoundHalfEven_Up", money.MustParse("0.07"), money.RoundHalfEven, money.MustParse("0.04")},
+		{"RoundDown_Negative", money.MustParse("-0.05"), money.RoundDown, money.MustParse("-0.02")},
+		{"RoundUp_Negative", money.MustParse("-0.05"), money.RoundUp, money.MustParse("-0.03")},
+		{"Exact", money.MustParse("10.00"), money.RoundUp, money.MustParse("5.00")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.amount.MulRate(rate, tc.mode))
+		})
+	}
+}
+
+func TestAmount_DivRate(t *testing.T) {
+	rate := money.MustParseRate("3")
+
+	require.Equal(t, money.MustParse("3.33"), money.FromInt(10).DivRate(rate, money.RoundDown))
+	require.Equal(t, money.MustParse("3.34"), money.FromInt(10).DivRate(rate, money.RoundUp))
+	require.Panics(t, func() { money.FromInt(1).DivRate(0, money.RoundDown) })
+}
+
+func TestAmount_Prorate(t *testing.T) {
+	// refunding 30.00 of a transfer that delivered 75.99 for 100.00
+	require.Equal(t, money.MustParse("39.47"), money.FromInt(100).Prorate(money.FromInt(30), money.MustParse("75.99"), money.RoundDown))
+	require.Equal(t, money.MustParse("39.48"), money.FromInt(100).Prorate(money.FromInt(30), money.MustParse("75.99"), money.RoundUp))
+	require.Equal(t, money.FromInt(100), money.FromInt(100).Prorate(money.MustParse("75.99"), money.MustParse("75.99"), money.RoundDown))
+	require.Panics(t, func() { money.FromInt(1).Prorate(money.FromInt(1), 0, money.RoundDown) })
+}
+
+func TestAmount_Ratio(t *testing.T) {
+	require.Equal(t, money.MustParseRate("0.7599"), money.MustParse("75.99").Ratio(money.FromInt(100), money.RoundHalfUp))
+	require.Equal(t, money.MustParseRate("0.33333333"), money.FromInt(1).Ratio(money.FromInt(3), money.RoundDown))
+	require.Equal(t, money.MustParseRate("0.33333334"), money.FromInt(1).Ratio(money.FromInt(3), money.RoundUp))
+	require.Panics(t, func() { money.FromInt(1).Ratio(0, money.RoundDown) })
+}
+
+func TestAmount_RoundToCurrency(t *testing.T) {
+	require.Equal(t, money.MustParse("12.34"), money.MustParse("12.34").RoundToCurrency("SGD", money.RoundDown))
+	require.Equal(t, money.FromInt(12), money.MustParse("12.99").RoundToCurrency("JPY", money.RoundDown))
+	require.Equal(t, money.FromInt(13), money.MustParse("12.01").RoundToCurrency("JPY", money.RoundUp))
+}
+
+func TestAmount_Scan(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      interface{}
+		expected money.Amount
+	}{
+		{"Nil", nil, 0},
+		{"String", "12.34", money.MustParse("12.34")},
+		{"Bytes", []byte("99.90"), money.MustParse("99.90")},
+		{"Int64", int64(7), money.FromInt(7)},
+		{"Float64", 0.1 + 0.2, money.MustParse("0.30")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var amount money.Amount
+			require.NoError(t, amount.Scan(tc.src))
+			require.Equal(t, tc.expected, amount)
+		})
+	}
+
+	var amount money.Amount
+	require.Error(t, amount.Scan(true))
+}
+
+func TestAmount_Value(t *testing.T) {
+	v, err := money.MustParse("-1.5").Value()
+	require.NoError(t, err)
+	require.Equal(t, "-1.50", v)
+}
+
+func TestAmount_JSON(t *testing.T) {
+	type payload struct {
+		Amount money.Amount `json:"amount"`
+	}
+
+	b, err := json.Marshal(payload{Amount: money.MustParse("10.5")})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount":10.50}`, string(b))
+
+	var p payload
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":0.3}`), &p))
+	require.Equal(t, money.MustParse("0.30"), p.Amount)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"amount":"20.01"}`), &p))
+	require.Equal(t, money.MustParse("20.01"), p.Amount)
+
+	require.Error(t, json.Unmarshal([]byte(`{"amount":0.001}`), &p))
+}
+
+func TestRate(t *testing.T) {
+	r := money.MustParseRate("0.76")
+	require.Equal(t, "0.76", r.String())
+	require.Equal(t, "1", money.RateOne.String())
+	require.Equal(t, money.MustParseRate("0.0001"), money.MustParseRate("0.005").Mul(money.MustParseRate("0.02")))
+
+	_, err := money.ParseRate("0.123456789")
+	require.ErrorIs(t, err, money.ErrTooManyDecimals)
+
+	var scanned money.Rate
+	require.NoError(t, scanned.Scan("1.32000000"))
+	require.Equal(t, money.MustParseRate("1.32"), scanned)
+}
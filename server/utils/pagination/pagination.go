@@ -1,8 +1,14 @@
 package pagination
 
 import (
+	"encoding/base64"
+	"fmt"
 	"math"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/LeonLow97/go-clean-architecture/exception"
 	"github.com/LeonLow97/go-clean-architecture/utils/constants"
 )
 
@@ -10,18 +16,103 @@ type Paginator struct {
 	TotalRecords int64 `json:"-"`
 	PageSize     int64 `json:"pageSize" form:"pageSize"`
 	Page         int64 `json:"page" form:"page"`
+
+	// Cursor switches to keyset pagination whenever it is in the query, even empty. An empty
+	// cursor starts from the first row and each page hands out the cursor of the next in NextCursor.
+	Cursor     *string `json:"-" form:"cursor"`
+	NextCursor string  `json:"-"`
+}
+
+// Position is the created_at and id of the last row of a keyset page
+type Position struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// Keyset reports whether rows are paged by cursor rather than by page number
+func (p *Paginator) Keyset() bool {
+	return p.Cursor != nil
+}
+
+// After returns the position the page starts after, nil for the first page
+func (p *Paginator) After() (*Position, error) {
+	if p.Cursor == nil || *p.Cursor == "" {
+		return nil, nil
+	}
+
+	position, err := DecodeCursor(*p.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	return &position, nil
+}
+
+// SetNextCursor records the last row of the page as the place to continue from, createdAt is
+// a timestamp in RFC 3339 as it is scanned into a string by database/sql
+func (p *Paginator) SetNextCursor(createdAt string, id int) error {
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return err
+	}
+	p.NextCursor = EncodeCursor(Position{CreatedAt: t, ID: id})
+	return nil
+}
+
+// EncodeCursor turns a position into an opaque cursor, postgres keeps timestamps to the
+// microsecond so nothing is lost
+func EncodeCursor(position Position) string {
+	raw := fmt.Sprintf("%d:%d", position.CreatedAt.UnixMicro(), position.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
 }
 
-// Offset calculates the OFFSET value for SQL queries
+// DecodeCursor reverses EncodeCursor
+func DecodeCursor(cursor string) (Position, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return Position{}, exception.ErrInvalidCursor
+	}
+
+	micros, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return Position{}, exception.ErrInvalidCursor
+	}
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return Position{}, exception.ErrInvalidCursor
+	}
+	rowID, err := strconv.Atoi(id)
+	if err != nil || rowID < 1 {
+		return Position{}, exception.ErrInvalidCursor
+	}
+
+	return Position{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: rowID}, nil
+}
+
+// Offset calculates the OFFSET value for SQL queries, always 0 in keyset mode
 func (p *Paginator) Offset() int64 {
+	if p.Keyset() {
+		return 0
+	}
 	return (p.Page - 1) * p.PageSize
 }
 
-// Limit returns the LIMIT value for SQL queries
+// Limit returns the LIMIT value for SQL queries. In keyset mode one extra row is read
+// to find out whether there is a next page, see Trim.
 func (p *Paginator) Limit() int64 {
+	if p.Keyset() {
+		return p.PageSize + 1
+	}
 	return p.PageSize
 }
 
+// Trim returns how many of the n rows read belong on the page and whether a next page follows
+func (p *Paginator) Trim(n int) (int, bool) {
+	if p.Keyset() && int64(n) > p.PageSize {
+		return int(p.PageSize), true
+	}
+	return n, false
+}
+
 // TotalPages calculates the number of pages based on total records and page size
 func (p *Paginator) TotalPages() int64 {
 	return int64(math.Ceil(float64(p.TotalRecords) / float64(p.PageSize)))
@@ -44,10 +135,16 @@ func (p *Paginator) SanitizePaginator() {
 
 // HasNextPage checks if there is a next page
 func (p *Paginator) HasNextPage() bool {
+	if p.Keyset() {
+		return p.NextCursor != ""
+	}
 	return p.Page < p.TotalPages()
 }
 
 // HasPreviousPage checks it there is a previous page
 func (p *Paginator) HasPreviousPage() bool {
+	if p.Keyset() {
+		return *p.Cursor != ""
+	}
 	return p.Page > 1
 }
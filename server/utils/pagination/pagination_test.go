@@ -1 +1,77 @@
 package pagination_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/exception"
+	"github.com/LeonLow97/go-clean-architecture/utils/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	position := pagination.Position{
+		CreatedAt: time.Date(2026, time.October, 17, 9, 30, 15, 123456000, time.UTC),
+		ID:        42,
+	}
+
+	decoded, err := pagination.DecodeCursor(pagination.EncodeCursor(position))
+	require.NoError(t, err)
+	require.Equal(t, position, decoded)
+
+	for _, cursor := range []string{"not base64!", "MTIz", "YWJjOjQy", "MTIzOjA"} {
+		_, err := pagination.DecodeCursor(cursor)
+		require.Equal(t, exception.ErrInvalidCursor, err, cursor)
+	}
+}
+
+func TestPaginator_Keyset(t *testing.T) {
+	t.Run("FirstPage", func(t *testing.T) {
+		cursor := ""
+		paginator := pagination.Paginator{Page: 3, PageSize: 10, Cursor: &cursor}
+
+		after, err := paginator.After()
+		require.NoError(t, err)
+		require.Nil(t, after)
+		require.True(t, paginator.Keyset())
+		require.Equal(t, int64(11), paginator.Limit())
+		require.Equal(t, int64(0), paginator.Offset())
+		require.False(t, paginator.HasPreviousPage())
+
+		n, hasNextPage := paginator.Trim(11)
+		require.Equal(t, 10, n)
+		require.True(t, hasNextPage)
+
+		require.NoError(t, paginator.SetNextCursor("2026-10-17T09:30:15.123456Z", 42))
+		require.True(t, paginator.HasNextPage())
+
+		next := paginator.NextCursor
+		nextPaginator := pagination.Paginator{PageSize: 10, Cursor: &next}
+		after, err = nextPaginator.After()
+		require.NoError(t, err)
+		require.Equal(t, 42, after.ID)
+		require.True(t, nextPaginator.HasPreviousPage())
+	})
+
+	t.Run("LastPage", func(t *testing.T) {
+		cursor := ""
+		paginator := pagination.Paginator{PageSize: 10, Cursor: &cursor}
+
+		n, hasNextPage := paginator.Trim(4)
+		require.Equal(t, 4, n)
+		require.False(t, hasNextPage)
+		require.False(t, paginator.HasNextPage())
+	})
+
+	t.Run("PageMode", func(t *testing.T) {
+		paginator := pagination.Paginator{Page: 3, PageSize: 10}
+
+		require.False(t, paginator.Keyset())
+		require.Equal(t, int64(10), paginator.Limit())
+		require.Equal(t, int64(20), paginator.Offset())
+
+		n, hasNextPage := paginator.Trim(10)
+		require.Equal(t, 10, n)
+		require.False(t, hasNextPage)
+	})
+}
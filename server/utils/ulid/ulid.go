@@ -0,0 +1,85 @@
+// Package ulid generates Universally Unique Lexicographically Sortable Identifiers.
+// A ULID is a 48 bit millisecond timestamp followed by 80 random bits, written as 26
+// characters of Crockford's base32 so that references sort in the order they were created.
+package ulid
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Length is the number of characters in an encoded ULID
+const Length = 26
+
+// encoding is Crockford's base32 alphabet, which leaves out I, L, O and U
+const encoding = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// maxTime is the largest millisecond timestamp that fits in 48 bits
+const maxTime = 1<<48 - 1
+
+// New returns a ULID for the current time. It panics if the system's secure
+// random number generator fails, which leaves nothing sensible to fall back on.
+func New() string {
+	return NewAt(time.Now())
+}
+
+// NewAt returns a ULID for the given time
+func NewAt(t time.Time) string {
+	var id [16]byte
+
+	ms := uint64(t.UnixMilli())
+	if ms > maxTime {
+		panic("ulid: time is too far in the future")
+	}
+	var timestamp [8]byte
+	binary.BigEndian.PutUint64(timestamp[:], ms)
+	copy(id[:6], timestamp[2:])
+
+	if _, err := rand.Read(id[6:]); err != nil {
+		panic("ulid: failed to read random bytes: " + err.Error())
+	}
+
+	return encode(id)
+}
+
+// IsValid reports whether s is an upper case ULID
+func IsValid(s string) bool {
+	if len(s) != Length {
+		return false
+	}
+	// 26 characters hold 130 bits, the first one can only carry the top 3 of the 128
+	if s[0] > '7' {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(encoding, s[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Time returns the time a valid ULID was created at, to the millisecond
+func Time(s string) time.Time {
+	var ms int64
+	for i := 0; i < 10; i++ {
+		ms = ms<<5 | int64(strings.IndexByte(encoding, s[i]))
+	}
+	return time.UnixMilli(ms)
+}
+
+func encode(id [16]byte) string {
+	n := new(big.Int).SetBytes(id[:])
+	base := big.NewInt(32)
+	digit := new(big.Int)
+
+	dst := make([]byte, Length)
+	for i := Length - 1; i >= 0; i-- {
+		n.DivMod(n, base, digit)
+		dst[i] = encoding[digit.Int64()]
+	}
+	return string(dst)
+}
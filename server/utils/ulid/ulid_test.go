@@ -0,0 +1,76 @@
+package ulid_test
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/LeonLow97/go-clean-architecture/utils/ulid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	seen := make(map[string]struct{})
+	for i := 0; i < 1000; i++ {
+		id := ulid.New()
+		require.Len(t, id, ulid.Length)
+		require.True(t, ulid.IsValid(id), id)
+
+		_, duplicate := seen[id]
+		require.False(t, duplicate, "duplicate ulid %s", id)
+		seen[id] = struct{}{}
+	}
+}
+
+func TestNewAt_SortsByTime(t *testing.T) {
+	start := time.Date(2026, time.October, 17, 9, 30, 0, 0, time.UTC)
+
+	var ids []string
+	for i := 0; i < 100; i++ {
+		ids = append(ids, ulid.NewAt(start.Add(time.Duration(i)*time.Millisecond)))
+	}
+
+	require.True(t, sort.StringsAreSorted(ids))
+	require.Equal(t, start, ulid.Time(ids[0]).UTC())
+	require.Equal(t, start.Add(99*time.Millisecond), ulid.Time(ids[99]).UTC())
+}
+
+func TestIsValid(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		ID    string
+		Valid bool
+	}{
+		{
+			Name:  "ReturnsSuccessfully",
+			ID:    "01JAB3W0YQ5V9T7X2NKH4M8RZC",
+			Valid: true,
+		},
+		{
+			Name:  "ReturnsError_TooShort",
+			ID:    "01JAB3W0YQ5V9T7X2NKH4M8RZ",
+			Valid: false,
+		},
+		{
+			Name:  "ReturnsError_ExcludedLetter",
+			ID:    "01JAB3W0YQ5V9T7X2NKH4M8RZU",
+			Valid: false,
+		},
+		{
+			Name:  "ReturnsError_LowerCase",
+			ID:    "01jab3w0yq5v9t7x2nkh4m8rzc",
+			Valid: false,
+		},
+		{
+			Name:  "ReturnsError_Overflow",
+			ID:    "81JAB3W0YQ5V9T7X2NKH4M8RZC",
+			Valid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			require.Equal(t, tc.Valid, ulid.IsValid(tc.ID))
+		})
+	}
+}